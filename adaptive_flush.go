@@ -0,0 +1,48 @@
+package statsig
+
+import "time"
+
+const defaultIdleBatchSize = 10
+
+// Flushes events early once FlushThreshold are buffered, and backs the flush interval off while
+// flushes are small, with the same doubling as adaptive polling. Guarded by logger.mu
+type adaptiveFlush struct {
+	threshold     int
+	idleBatchSize int
+	backoff       adaptivePolling
+	closed        bool
+}
+
+func newAdaptiveFlush(loggingInterval time.Duration, maxEvents int, options AdaptiveFlushOptions) adaptiveFlush {
+	threshold := options.FlushThreshold
+	if threshold <= 0 || threshold > maxEvents {
+		threshold = maxEvents
+	}
+	idleBatchSize := options.IdleBatchSize
+	if idleBatchSize <= 0 {
+		idleBatchSize = defaultIdleBatchSize
+	}
+	return adaptiveFlush{
+		threshold:     threshold,
+		idleBatchSize: idleBatchSize,
+		backoff: newAdaptivePolling(loggingInterval, AdaptivePollingOptions{
+			MaxInterval:            options.MaxInterval,
+			IdleSyncsBeforeBackoff: options.IdleFlushesBeforeBackoff,
+		}),
+	}
+}
+
+// Records a flush of the given number of events, resetting the ticker when the interval changes
+func (l *logger) recordFlush(events int) {
+	previous := l.flushing.backoff.interval
+	l.flushing.backoff.recordSync(events >= l.flushing.idleBatchSize)
+	if interval := l.flushing.backoff.interval; interval != previous && !l.flushing.closed {
+		l.tick.Reset(interval)
+	}
+}
+
+func (l *logger) getFlushInterval() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flushing.backoff.interval
+}
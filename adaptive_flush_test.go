@@ -0,0 +1,87 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveFlushDefaults(t *testing.T) {
+	flushing := newAdaptiveFlush(time.Minute, 1000, AdaptiveFlushOptions{})
+	if flushing.threshold != 1000 || flushing.idleBatchSize != defaultIdleBatchSize || flushing.backoff.enabled() {
+		t.Errorf("Expected flushing at LoggingMaxBufferSize without backoff, received %+v", flushing)
+	}
+	flushing = newAdaptiveFlush(time.Minute, 1000, AdaptiveFlushOptions{FlushThreshold: 5000})
+	if flushing.threshold != 1000 {
+		t.Errorf("Expected the threshold to be capped at LoggingMaxBufferSize, received %d", flushing.threshold)
+	}
+}
+
+func TestAdaptiveFlush(t *testing.T) {
+	var requests, events int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "log_event") {
+			var input logEventInput
+			_ = json.NewDecoder(req.Body).Decode(&input)
+			atomic.AddInt32(&requests, 1)
+			atomic.AddInt32(&events, int32(len(input.Events)))
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	newFlushClient := func(interval time.Duration, options AdaptiveFlushOptions) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			LoggingInterval:      interval,
+			StatsigLoggerOptions: StatsigLoggerOptions{DisableInitDiagnostics: true, DisableSyncDiagnostics: true, DisableApiDiagnostics: true},
+			AdaptiveFlushOptions: options,
+		})
+	}
+	user := User{UserID: "123"}
+
+	t.Run("flushes early at the threshold", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		atomic.StoreInt32(&events, 0)
+		c := newFlushClient(time.Hour, AdaptiveFlushOptions{FlushThreshold: 3})
+		defer c.Shutdown()
+		c.LogEvent(Event{EventName: "a", User: user})
+		c.LogEvent(Event{EventName: "b", User: user})
+		time.Sleep(50 * time.Millisecond)
+		if atomic.LoadInt32(&requests) != 0 {
+			t.Errorf("Expected no flush below the threshold")
+		}
+		c.LogEvent(Event{EventName: "c", User: user})
+		waitForConditionWithMessage(t, func() bool { return atomic.LoadInt32(&events) == 3 }, "Expected the buffered events to be flushed")
+	})
+
+	t.Run("backs off while idle and resets under load", func(t *testing.T) {
+		c := newFlushClient(20*time.Millisecond, AdaptiveFlushOptions{
+			FlushThreshold:           3,
+			MaxInterval:              160 * time.Millisecond,
+			IdleBatchSize:            2,
+			IdleFlushesBeforeBackoff: 1,
+		})
+		defer c.Shutdown()
+		waitForConditionWithMessage(t, func() bool { return c.logger.getFlushInterval() == 160*time.Millisecond }, "Expected the interval to back off to MaxInterval")
+		for _, name := range []string{"a", "b", "c"} {
+			c.LogEvent(Event{EventName: name, User: user})
+		}
+		if interval := c.logger.getFlushInterval(); interval != 20*time.Millisecond {
+			t.Errorf("Expected a threshold flush to reset the interval, received %s", interval)
+		}
+	})
+
+	t.Run("keeps a fixed interval by default", func(t *testing.T) {
+		c := newFlushClient(20*time.Millisecond, AdaptiveFlushOptions{})
+		defer c.Shutdown()
+		time.Sleep(150 * time.Millisecond)
+		if interval := c.logger.getFlushInterval(); interval != 20*time.Millisecond {
+			t.Errorf("Expected the interval to be unchanged, received %s", interval)
+		}
+	})
+}
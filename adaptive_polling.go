@@ -0,0 +1,58 @@
+package statsig
+
+import "time"
+
+const defaultIdleSyncsBeforeBackoff = 3
+
+// Tracks consecutive config spec syncs without updates, doubling the sync interval
+// once the SDK has been idle for long enough. Guarded by store.mu, or logger.mu for adaptive flush
+type adaptivePolling struct {
+	baseInterval time.Duration
+	maxInterval  time.Duration
+	idleSyncs    int
+	threshold    int
+	interval     time.Duration
+}
+
+func newAdaptivePolling(baseInterval time.Duration, options AdaptivePollingOptions) adaptivePolling {
+	threshold := options.IdleSyncsBeforeBackoff
+	if threshold <= 0 {
+		threshold = defaultIdleSyncsBeforeBackoff
+	}
+	return adaptivePolling{
+		baseInterval: baseInterval,
+		maxInterval:  options.MaxInterval,
+		threshold:    threshold,
+		interval:     baseInterval,
+	}
+}
+
+func (p *adaptivePolling) enabled() bool {
+	return p.maxInterval > p.baseInterval
+}
+
+// Failed syncs are not recorded, so the interval is unchanged until the next successful sync
+func (p *adaptivePolling) recordSync(updated bool) {
+	if !p.enabled() {
+		return
+	}
+	if updated {
+		p.idleSyncs = 0
+		p.interval = p.baseInterval
+		return
+	}
+	p.idleSyncs++
+	if p.idleSyncs < p.threshold {
+		return
+	}
+	p.interval *= 2
+	if p.interval > p.maxInterval {
+		p.interval = p.maxInterval
+	}
+}
+
+func (s *store) getConfigSyncInterval() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.polling.interval
+}
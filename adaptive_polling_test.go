@@ -0,0 +1,105 @@
+package statsig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptivePollingBackoff(t *testing.T) {
+	polling := newAdaptivePolling(10*time.Second, AdaptivePollingOptions{MaxInterval: time.Minute, IdleSyncsBeforeBackoff: 2})
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for i, interval := range expected {
+		polling.recordSync(false)
+		if polling.interval != interval {
+			t.Errorf("Expected interval %s after %d idle syncs, received %s", interval, i+1, polling.interval)
+		}
+	}
+	polling.recordSync(true)
+	if polling.interval != 10*time.Second || polling.idleSyncs != 0 {
+		t.Errorf("Expected an update to reset the interval, received %s", polling.interval)
+	}
+
+	disabled := newAdaptivePolling(10*time.Second, AdaptivePollingOptions{})
+	for i := 0; i < 10; i++ {
+		disabled.recordSync(false)
+	}
+	if disabled.interval != 10*time.Second {
+		t.Errorf("Expected a fixed interval without MaxInterval, received %s", disabled.interval)
+	}
+}
+
+func TestAdaptivePollingSync(t *testing.T) {
+	dcs, _ := os.ReadFile("download_config_specs.json")
+	var requests int32
+	var hasUpdates int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			count := atomic.AddInt32(&requests, 1)
+			if count == 1 || atomic.CompareAndSwapInt32(&hasUpdates, 1, 0) {
+				_, _ = res.Write(dcs)
+			} else {
+				_, _ = res.Write([]byte("{\"has_updates\":false}"))
+			}
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	// The ruleset poller reports each interval it waits for and syncs when the test ticks.
+	// The ID list poller waits for its hour long interval until shutdown
+	waits := make(chan time.Duration, 10)
+	ticks := make(chan time.Time)
+	defer func(previous func(time.Duration) (<-chan time.Time, func() bool)) { newPollTimer = previous }(newPollTimer)
+	newPollTimer = func(interval time.Duration) (<-chan time.Time, func() bool) {
+		if interval == time.Hour {
+			return nil, func() bool { return true }
+		}
+		waits <- interval
+		return ticks, func() bool { return true }
+	}
+
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                    testServer.URL,
+		ConfigSyncInterval:     10 * time.Second,
+		IDListSyncInterval:     time.Hour,
+		AdaptivePollingOptions: AdaptivePollingOptions{MaxInterval: 80 * time.Second, IdleSyncsBeforeBackoff: 1},
+		OutputLoggerOptions:    getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions:   getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	expectWait := func(expected time.Duration) {
+		t.Helper()
+		select {
+		case interval := <-waits:
+			if interval != expected {
+				t.Errorf("Expected to wait %s before the next sync, received %s", expected, interval)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for the poller to wait %s", expected)
+		}
+	}
+
+	expectWait(10 * time.Second)
+	for _, interval := range []time.Duration{20 * time.Second, 40 * time.Second, 80 * time.Second, 80 * time.Second} {
+		ticks <- time.Now()
+		expectWait(interval)
+	}
+	if interval := c.GetConfigSpecSyncMetrics().SyncInterval; interval != 80*time.Second {
+		t.Errorf("Expected the interval to back off to the max, received %s", interval)
+	}
+
+	atomic.StoreInt32(&hasUpdates, 1)
+	ticks <- time.Now()
+	expectWait(10 * time.Second)
+	if count := atomic.LoadInt32(&requests); count != 6 {
+		t.Errorf("Expected a request for each tick after initialize, received %d", count)
+	}
+}
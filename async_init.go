@@ -0,0 +1,29 @@
+package statsig
+
+import "fmt"
+
+// Returns from initialization without waiting for the initial network sync, so a slow network does not block
+// startup. Evaluations return defaults with the Uninitialized reason until the sync completes. See Client.Ready
+type AsyncInitOptions struct {
+	Enabled bool
+	OnReady func() // Called once the initial config spec and ID list sync completes, whether or not it succeeded
+}
+
+func (s *store) callOnReady() {
+	onReady := s.options.AsyncInitOptions.OnReady
+	if onReady == nil {
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("AsyncInitOptions.OnReady panicked: %s\n", toError(err).Error()))
+		}
+	}()
+	onReady()
+}
+
+// Returns a channel closed once the initial config spec and ID list sync completes, whether or not it succeeded.
+// Already closed unless AsyncInitOptions is enabled
+func (c *Client) Ready() <-chan struct{} {
+	return c.evaluator.store.ready
+}
@@ -0,0 +1,137 @@
+package statsig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncInit(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	release := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			<-release
+			_, _ = res.Write(specs)
+			return
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	var readyCalls int32
+	options := &Options{
+		API:                  testServer.URL,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		AsyncInitOptions: AsyncInitOptions{
+			Enabled: true,
+			OnReady: func() { atomic.AddInt32(&readyCalls, 1) },
+		},
+	}
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", options)
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	select {
+	case <-c.Ready():
+		t.Fatalf("Expected the client to be returned before the initial sync")
+	default:
+	}
+	if c.CheckGate(user, "always_on_gate") || c.evaluator.store.getInitReason() != reasonUninitialized {
+		t.Errorf("Expected defaults until the initial sync completes")
+	}
+
+	close(release)
+	select {
+	case <-c.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the client to be ready after the initial sync")
+	}
+	if atomic.LoadInt32(&readyCalls) != 1 {
+		t.Errorf("Expected OnReady to be called once, received %d", readyCalls)
+	}
+	if !c.CheckGate(user, "always_on_gate") {
+		t.Errorf("Expected the gate to be evaluated once ready")
+	}
+}
+
+func TestAsyncInitTimeout(t *testing.T) {
+	release := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			<-release
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	start := time.Now()
+	InitializeWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		InitTimeout:          100 * time.Millisecond,
+		AsyncInitOptions:     AsyncInitOptions{Enabled: true},
+	})
+	// Shutdown waits for the pending sync
+	defer ShutdownAndDangerouslyClearInstance()
+	defer close(release)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected initialization to return after InitTimeout, took %s", elapsed)
+	}
+	if !IsInitialized() {
+		t.Fatalf("Expected the instance to be kept after timing out")
+	}
+	if CheckGate(User{UserID: "123"}, "always_on_gate") {
+		t.Errorf("Expected defaults while the sync is pending")
+	}
+}
+
+func TestAsyncInitShutdown(t *testing.T) {
+	release := make(chan struct{})
+	var idListRequests int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			<-release
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			atomic.AddInt32(&idListRequests, 1)
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		AsyncInitOptions:     AsyncInitOptions{Enabled: true},
+	})
+
+	// Shutdown waits for the initial sync in flight, and for the pollers it starts to exit
+	shutdown := make(chan struct{})
+	go func() {
+		c.Shutdown()
+		close(shutdown)
+	}()
+	select {
+	case <-shutdown:
+		t.Fatalf("Expected shutdown to wait for the initial sync")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-shutdown:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected shutdown to complete once the initial sync did")
+	}
+	select {
+	case <-c.Ready():
+	default:
+		t.Errorf("Expected the initial sync to have completed before shutdown returned")
+	}
+	if requests := atomic.LoadInt32(&idListRequests); requests != 1 {
+		t.Errorf("Expected only the initial ID list sync, received %d", requests)
+	}
+}
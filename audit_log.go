@@ -0,0 +1,102 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// One line of the evaluation audit log. Only the user's ID and custom IDs are recorded,
+// never their private attributes.
+type AuditRecord struct {
+	Time           int64             `json:"time"` // Unix milliseconds
+	Type           string            `json:"type"` // gate, config, experiment or layer
+	Name           string            `json:"name"`
+	UserID         string            `json:"userID"`
+	CustomIDs      map[string]string `json:"customIDs,omitempty"`
+	Value          interface{}       `json:"value"` // The gate's boolean, or the config, experiment or layer's value
+	RuleID         string            `json:"ruleID"`
+	GroupName      string            `json:"groupName,omitempty"`
+	Reason         string            `json:"reason,omitempty"`
+	ConfigSyncTime int64             `json:"configSyncTime,omitempty"`
+}
+
+type auditLog struct {
+	options AuditLogOptions
+	names   map[string]bool
+	mu      sync.Mutex
+	writer  io.Writer
+	written int64
+}
+
+func newAuditLog(options *Options) *auditLog {
+	auditOptions := options.AuditLogOptions
+	if auditOptions.Writer == nil {
+		return nil
+	}
+	var names map[string]bool
+	if len(auditOptions.SpecNames) > 0 {
+		names = make(map[string]bool, len(auditOptions.SpecNames))
+		for _, name := range auditOptions.SpecNames {
+			names[name] = true
+		}
+	}
+	return &auditLog{options: auditOptions, names: names, writer: auditOptions.Writer}
+}
+
+func (a *auditLog) shouldRecord(name string) bool {
+	if a == nil || (a.names != nil && !a.names[name]) {
+		return false
+	}
+	rate := a.options.SampleRate
+	return rate <= 0 || rate >= 1 || rand.Float64() < rate
+}
+
+func (a *auditLog) record(specType string, name string, user User, value interface{}, res *evalResult) {
+	if !a.shouldRecord(name) {
+		return
+	}
+	record := AuditRecord{
+		Time:      time.Now().UnixNano() / int64(time.Millisecond),
+		Type:      specType,
+		Name:      name,
+		UserID:    user.UserID,
+		CustomIDs: user.CustomIDs,
+		Value:     value,
+		RuleID:    res.RuleID,
+		GroupName: res.GroupName,
+	}
+	if res.EvaluationDetails != nil {
+		record.Reason = string(res.EvaluationDetails.reason)
+		record.ConfigSyncTime = res.EvaluationDetails.configSyncTime
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to encode audit record for %s: %s", name, err.Error()))
+		return
+	}
+	a.write(append(line, '\n'))
+}
+
+// Lines are written whole, one at a time, so the log stays valid JSONL with concurrent evaluations
+func (a *auditLog) write(line []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.options.MaxBytes > 0 && a.written > 0 && a.written+int64(len(line)) > a.options.MaxBytes && a.options.Rotate != nil {
+		next, err := a.options.Rotate(a.writer)
+		if err != nil {
+			Logger().LogError(fmt.Sprintf("Failed to rotate audit log: %s", err.Error()))
+		} else if next != nil {
+			a.writer = next
+			a.written = 0
+		}
+	}
+	n, err := a.writer.Write(line)
+	a.written += int64(n)
+	if err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to write audit record: %s", err.Error()))
+	}
+}
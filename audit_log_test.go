@@ -0,0 +1,118 @@
+package statsig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	newAuditedClient := func(options AuditLogOptions) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      string(specs),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			AuditLogOptions:      options,
+		})
+	}
+	readRecords := func(t *testing.T, r io.Reader) []AuditRecord {
+		var records []AuditRecord
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			var record AuditRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("Expected every line to be JSON, received %q", scanner.Text())
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+	user := User{
+		UserID:            "123",
+		Email:             "a@statsig.com",
+		CustomIDs:         map[string]string{"companyID": "c1"},
+		PrivateAttributes: map[string]interface{}{"secret": "value"},
+	}
+
+	t.Run("records every evaluation", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		c := newAuditedClient(AuditLogOptions{Writer: output})
+		defer c.Shutdown()
+		c.CheckGate(user, "always_on_gate")
+		c.CheckGateWithExposureLoggingDisabled(user, "on_for_statsig_email")
+		c.GetConfig(user, "test_config")
+		c.GetExperiment(user, "sample_experiment")
+		c.GetLayer(user, "a_layer")
+
+		if strings.Contains(output.String(), "secret") {
+			t.Errorf("Expected private attributes to be omitted")
+		}
+		records := readRecords(t, output)
+		if len(records) != 5 {
+			t.Fatalf("Expected 5 records, received %d", len(records))
+		}
+		gate := records[0]
+		if gate.Type != "gate" || gate.Name != "always_on_gate" || gate.Value != true || gate.RuleID != "6N6Z8ODekNYZ7F8gFdoLP5" {
+			t.Errorf("Unexpected gate record %+v", gate)
+		}
+		if gate.UserID != "123" || gate.CustomIDs["companyID"] != "c1" || gate.Reason != "Bootstrap" || gate.ConfigSyncTime == 0 {
+			t.Errorf("Expected the user and evaluation details to be recorded, received %+v", gate)
+		}
+		config := records[2]
+		if config.Type != "config" || config.RuleID != "1kNmlB23wylPFZi1M0Divl" || config.Value.(map[string]interface{})["number"] != float64(7) {
+			t.Errorf("Unexpected config record %+v", config)
+		}
+		if records[3].Type != "experiment" || records[4].Type != "layer" {
+			t.Errorf("Expected experiment and layer records, received %+v", records[3:])
+		}
+	})
+
+	t.Run("filters by spec name", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		c := newAuditedClient(AuditLogOptions{Writer: output, SpecNames: []string{"test_config"}})
+		defer c.Shutdown()
+		c.CheckGate(user, "always_on_gate")
+		c.GetConfig(user, "test_config")
+		records := readRecords(t, output)
+		if len(records) != 1 || records[0].Name != "test_config" {
+			t.Errorf("Expected only test_config to be recorded, received %+v", records)
+		}
+	})
+
+	t.Run("rotates writers", func(t *testing.T) {
+		first, second := new(bytes.Buffer), new(bytes.Buffer)
+		var rotated io.Writer
+		c := newAuditedClient(AuditLogOptions{
+			Writer:   first,
+			MaxBytes: 1,
+			Rotate: func(current io.Writer) (io.Writer, error) {
+				rotated = current
+				return second, nil
+			},
+		})
+		defer c.Shutdown()
+		c.CheckGate(user, "always_on_gate")
+		c.CheckGate(user, "always_on_gate")
+		if rotated != first {
+			t.Errorf("Expected the first writer to be passed to Rotate")
+		}
+		if len(readRecords(t, first)) != 1 || len(readRecords(t, second)) != 1 {
+			t.Errorf("Expected one record per writer")
+		}
+	})
+
+	t.Run("is disabled without a writer", func(t *testing.T) {
+		c := newAuditedClient(AuditLogOptions{SpecNames: []string{"always_on_gate"}})
+		defer c.Shutdown()
+		if c.auditLog != nil {
+			t.Errorf("Expected no audit log without a writer")
+		}
+		c.CheckGate(user, "always_on_gate")
+	})
+}
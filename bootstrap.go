@@ -0,0 +1,87 @@
+package statsig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const bootstrapWarningInterval = time.Minute
+
+// Returned through InitializeDetails when Options.BootstrapValues could not be used
+type BootstrapParseError struct {
+	Err error
+}
+
+func (e *BootstrapParseError) Error() string {
+	return fmt.Sprintf("Failed to parse BootstrapValues: %s", e.Err.Error())
+}
+
+func (e *BootstrapParseError) Unwrap() error {
+	return e.Err
+}
+
+// The outcome of client initialization
+type InitializeDetails struct {
+	Source string // The evaluation reason of the initial config specs, e.g. Bootstrap or Network
+	Error  error  // Set to a *BootstrapParseError if BootstrapValues could not be used
+}
+
+var bootstrapWarningLimiter = struct {
+	lastWarning time.Time
+	mu          sync.Mutex
+}{}
+
+// Bootstrap failures tend to repeat for every client a process creates, so warn at most once per interval
+func logBootstrapWarning(err error) {
+	bootstrapWarningLimiter.mu.Lock()
+	defer bootstrapWarningLimiter.mu.Unlock()
+	if !bootstrapWarningLimiter.lastWarning.IsZero() && time.Since(bootstrapWarningLimiter.lastWarning) < bootstrapWarningInterval {
+		return
+	}
+	bootstrapWarningLimiter.lastWarning = time.Now()
+	Logger().LogError(err)
+}
+
+// Options.BootstrapFileOptions.Path, then Options.BootstrapReader, are streamed in place of BootstrapValues when set
+func (s *store) processBootstrap(bootstrapValues string, bootstrapReader io.Reader) error {
+	if path := s.options.BootstrapFileOptions.Path; path != "" {
+		loaded, err := s.loadBootstrapFile(path)
+		s.pollers.Add(1)
+		go s.watchBootstrapFile(path, loaded)
+		return err
+	}
+	if bootstrapReader == nil {
+		return s.processBootstrapValues(bootstrapValues)
+	}
+	specs, _, err := decodeConfigSpecsStream(bootstrapReader)
+	return s.processBootstrapSpecs(specs, err)
+}
+
+func (s *store) processBootstrapValues(bootstrapValues string) error {
+	specs := downloadConfigSpecResponse{}
+	err := json.Unmarshal([]byte(bootstrapValues), &specs)
+	return s.processBootstrapSpecs(specs, err)
+}
+
+func (s *store) processBootstrapSpecs(specs downloadConfigSpecResponse, decodeErr error) error {
+	diagnosticsMarker := s.addDiagnostics().bootstrap()
+	if err := decodeErr; err != nil {
+		diagnosticsMarker.process().start().mark()
+		diagnosticsMarker.process().end().success(false).mark()
+		return &BootstrapParseError{Err: err}
+	}
+	parsed, updated := s.processConfigSpecs(specs, diagnosticsMarker)
+	if !parsed {
+		return &BootstrapParseError{Err: errors.New("values were rejected, check that they were generated for this SDK key")}
+	}
+	if updated {
+		s.mu.Lock()
+		s.initReason = reasonBootstrap
+		s.mu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package statsig
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const defaultBootstrapFilePollInterval = 5 * time.Second
+
+// Loads config specs from a file in download_config_specs format in place of BootstrapValues, and reloads
+// it whenever it changes, e.g. to deliver rules through GitOps without network access. Combine with
+// LocalMode to only ever evaluate the file's specs. If network sync is also enabled, whichever source
+// changed last is served.
+type BootstrapFileOptions struct {
+	Path         string        // Disabled unless this is set
+	PollInterval time.Duration // How often the file's modification time and size are checked. Defaults to 5 seconds
+}
+
+type bootstrapFileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// Streams the file's specs into the store, returning the version that was read
+func (s *store) loadBootstrapFile(path string) (bootstrapFileVersion, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return bootstrapFileVersion{}, &BootstrapParseError{Err: err}
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return bootstrapFileVersion{}, &BootstrapParseError{Err: err}
+	}
+	specs, _, err := decodeConfigSpecsStream(file)
+	return bootstrapFileVersion{modTime: info.ModTime(), size: info.Size()}, s.processBootstrapSpecs(specs, err)
+}
+
+func (s *store) watchBootstrapFile(path string, loaded bootstrapFileVersion) {
+	defer s.pollers.Done()
+	interval := s.options.BootstrapFileOptions.PollInterval
+	if interval <= 0 {
+		interval = defaultBootstrapFilePollInterval
+	}
+	for s.waitForNextPoll(interval) {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Equal(loaded.modTime) && info.Size() == loaded.size {
+			continue
+		}
+		// A file that fails to parse is read again once it next changes, e.g. when a partial write completes
+		version, err := s.loadBootstrapFile(path)
+		if err != nil {
+			Logger().LogError(fmt.Sprintf("Failed to reload bootstrap file %s, keeping the current ruleset: %s\n", path, err.Error()))
+		}
+		loaded = version
+	}
+}
@@ -0,0 +1,87 @@
+package statsig
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBootstrapFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "bootstrap_file")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "specs.json")
+	version := 0
+	writeSpecs := func(contents string) {
+		version++
+		if err := ioutil.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatalf("Failed to write bootstrap file: %v", err)
+		}
+		// Coarse filesystem timestamps must not hide the change
+		modTime := time.Now().Add(time.Duration(version) * time.Second)
+		_ = os.Chtimes(path, modTime, modTime)
+	}
+	ruleset := func(time int, pass int) string {
+		return fmt.Sprintf(`{"has_updates": true, "time": %d, "feature_gates": [
+			{"name": "rollout", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+				{"name": "all", "id": "all_rule", "salt": "s", "passPercentage": %d, "returnValue": true, "conditions": [{"type": "public"}]}]}
+		], "dynamic_configs": [], "layer_configs": []}`, time, pass)
+	}
+	newFileClient := func() *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapFileOptions: BootstrapFileOptions{Path: path, PollInterval: 10 * time.Millisecond},
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	user := User{UserID: "a"}
+	waitForGate := func(c *Client, expected bool) {
+		deadline := time.Now().Add(5 * time.Second)
+		for c.CheckGate(user, "rollout") != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected the gate to be %v after the file changed", expected)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	writeSpecs(ruleset(1, 100))
+	c := newFileClient()
+	if !c.CheckGate(user, "rollout") || c.evaluator.store.bootstrapError != nil {
+		t.Errorf("Expected the specs to be loaded from the file, received %v", c.evaluator.store.bootstrapError)
+	}
+	if reason := c.evaluator.store.initReason; reason != reasonBootstrap {
+		t.Errorf("Expected the bootstrap reason, received %s", reason)
+	}
+
+	writeSpecs(ruleset(2, 0))
+	waitForGate(c, false)
+
+	// Invalid files keep the current ruleset until they are fixed
+	writeSpecs(`{"has_updates": true, "time": 3, "feature_gates": [`)
+	time.Sleep(50 * time.Millisecond)
+	if c.CheckGate(user, "rollout") {
+		t.Errorf("Expected an invalid file to keep the current ruleset")
+	}
+	writeSpecs(ruleset(4, 100))
+	waitForGate(c, true)
+	c.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.evaluator.store.drainPollers(ctx); err != nil {
+		t.Errorf("Expected the file watcher to stop on Shutdown, received %v", err)
+	}
+
+	os.Remove(path)
+	d := newFileClient()
+	defer d.Shutdown()
+	if _, ok := d.evaluator.store.bootstrapError.(*BootstrapParseError); !ok {
+		t.Errorf("Expected a missing file to be reported as a bootstrap error, received %v", d.evaluator.store.bootstrapError)
+	}
+	writeSpecs(ruleset(5, 100))
+	waitForGate(d, true)
+}
@@ -0,0 +1,103 @@
+package statsig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBootstrapParseError(t *testing.T) {
+	var dcsCalls, exceptionCalls int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			atomic.AddInt32(&dcsCalls, 1)
+			bytes, _ := os.ReadFile("download_config_specs.json")
+			_, _ = res.Write(bytes)
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		} else if strings.Contains(req.URL.Path, "sdk_exception") {
+			atomic.AddInt32(&exceptionCalls, 1)
+		}
+	}))
+	defer testServer.Close()
+
+	newOptions := func(bootstrapValues string, strict bool) *Options {
+		return &Options{
+			API:                  testServer.URL,
+			BootstrapValues:      bootstrapValues,
+			StrictBootstrap:      strict,
+			OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		}
+	}
+
+	t.Run("falls back to the network and reports the error", func(t *testing.T) {
+		atomic.StoreInt32(&dcsCalls, 0)
+		atomic.StoreInt32(&exceptionCalls, 0)
+		c := NewClientWithOptions("secret-key", newOptions("{not json", false))
+		defer c.Shutdown()
+
+		details := c.GetInitializeDetails()
+		var parseErr *BootstrapParseError
+		if !errors.As(details.Error, &parseErr) {
+			t.Errorf("Expected a BootstrapParseError, received %v", details.Error)
+		}
+		if details.Source != string(reasonNetwork) {
+			t.Errorf("Expected source %s, received %s", reasonNetwork, details.Source)
+		}
+		if atomic.LoadInt32(&dcsCalls) != 1 {
+			t.Errorf("Expected a network fallback")
+		}
+		if atomic.LoadInt32(&exceptionCalls) != 1 {
+			t.Errorf("Expected the error to be logged to the error boundary")
+		}
+		if !c.CheckGate(User{UserID: "a"}, "always_on_gate") {
+			t.Errorf("Expected gates from the network to be evaluated")
+		}
+	})
+
+	t.Run("fails initialization in strict mode", func(t *testing.T) {
+		atomic.StoreInt32(&dcsCalls, 0)
+		c := NewClientWithOptions("secret-key", newOptions("{not json", true))
+		defer c.Shutdown()
+
+		details := c.GetInitializeDetails()
+		var parseErr *BootstrapParseError
+		if !errors.As(details.Error, &parseErr) || details.Source != string(reasonUninitialized) {
+			t.Errorf("Expected an uninitialized client with a BootstrapParseError, received %+v", details)
+		}
+		if atomic.LoadInt32(&dcsCalls) != 0 {
+			t.Errorf("Expected no network fallback in strict mode")
+		}
+		if c.CheckGate(User{UserID: "a"}, "always_on_gate") {
+			t.Errorf("Expected gates to evaluate to their defaults")
+		}
+	})
+
+	t.Run("does not panic in strict mode with an init timeout", func(t *testing.T) {
+		options := newOptions("{not json", true)
+		options.InitTimeout = time.Second
+		InitializeWithOptions("secret-key", options)
+		defer ShutdownAndDangerouslyClearInstance()
+		if details := instance.GetInitializeDetails(); details.Error == nil {
+			t.Errorf("Expected the bootstrap error to be reported, received %+v", details)
+		}
+	})
+
+	t.Run("reports no error for valid values", func(t *testing.T) {
+		bytes, _ := os.ReadFile("download_config_specs.json")
+		c := NewClientWithOptions("secret-key", newOptions(string(bytes), true))
+		defer c.Shutdown()
+
+		details := c.GetInitializeDetails()
+		if details.Error != nil || details.Source != string(reasonBootstrap) {
+			t.Errorf("Expected bootstrap initialization without error, received %+v", details)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package statsig
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const maxCallerFrames = 32
+
+// Evaluation counts for a single spec from a single call site
+type CallSiteMetric struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // gate, config, experiment or layer
+	CallSite string `json:"callSite"`
+	Count    int64  `json:"count"`
+}
+
+type callSiteKey struct {
+	name     string
+	specType string
+	callSite string
+}
+
+type callSiteMetrics struct {
+	depth  int
+	counts map[callSiteKey]int64
+	mu     sync.Mutex
+}
+
+var sdkSourceDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+func newCallSiteMetrics(options *Options) *callSiteMetrics {
+	if options.CallerAttributionOptions.Depth <= 0 {
+		return nil
+	}
+	return &callSiteMetrics{
+		depth:  options.CallerAttributionOptions.Depth,
+		counts: make(map[callSiteKey]int64),
+	}
+}
+
+func (m *callSiteMetrics) record(specType string, name string) {
+	if m == nil {
+		return
+	}
+	key := callSiteKey{name: name, specType: specType, callSite: m.getCallSite()}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[key]++
+}
+
+// Formats the first depth frames outside of the SDK, innermost first
+func (m *callSiteMetrics) getCallSite() string {
+	pcs := make([]uintptr, maxCallerFrames)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	sites := make([]string, 0, m.depth)
+	for len(sites) < m.depth {
+		frame, more := frames.Next()
+		if !isSDKFrame(frame) {
+			sites = append(sites, fmt.Sprintf("%s (%s:%d)", frame.Function, filepath.Base(frame.File), frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.Join(sites, " <- ")
+}
+
+func isSDKFrame(frame runtime.Frame) bool {
+	return filepath.Dir(frame.File) == sdkSourceDir && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+func (m *callSiteMetrics) snapshot() []CallSiteMetric {
+	if m == nil {
+		return []CallSiteMetric{}
+	}
+	m.mu.Lock()
+	metrics := make([]CallSiteMetric, 0, len(m.counts))
+	for key, count := range m.counts {
+		metrics = append(metrics, CallSiteMetric{Name: key.name, Type: key.specType, CallSite: key.callSite, Count: count})
+	}
+	m.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Count != metrics[j].Count {
+			return metrics[i].Count > metrics[j].Count
+		}
+		return metrics[i].CallSite < metrics[j].CallSite
+	})
+	return metrics
+}
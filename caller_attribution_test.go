@@ -0,0 +1,78 @@
+package statsig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func checkDeprecatedGate(c *Client) bool {
+	return c.CheckGate(User{UserID: "a_user"}, "always_on_gate")
+}
+
+func getTestConfig(c *Client) DynamicConfig {
+	return c.GetConfig(User{UserID: "a_user"}, "test_config")
+}
+
+func TestCallerAttribution(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	newClient := func(depth int) *Client {
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:                true,
+			BootstrapValues:          string(bytes),
+			StatsigLoggerOptions:     getStatsigLoggerOptionsForTest(t),
+			CallerAttributionOptions: CallerAttributionOptions{Depth: depth},
+		})
+	}
+
+	t.Run("counts evaluations per call site", func(t *testing.T) {
+		c := newClient(1)
+		defer c.Shutdown()
+		checkDeprecatedGate(c)
+		checkDeprecatedGate(c)
+		getTestConfig(c)
+
+		metrics := c.GetCallSiteMetrics()
+		if len(metrics) != 2 {
+			t.Fatalf("Expected 2 call sites, received %+v", metrics)
+		}
+		if metrics[0].Name != "always_on_gate" || metrics[0].Type != "gate" || metrics[0].Count != 2 {
+			t.Errorf("Unexpected gate metric %+v", metrics[0])
+		}
+		if !strings.HasSuffix(strings.Split(metrics[0].CallSite, " ")[0], ".checkDeprecatedGate") {
+			t.Errorf("Expected call site to be checkDeprecatedGate, received %s", metrics[0].CallSite)
+		}
+		if !strings.Contains(metrics[0].CallSite, "caller_attribution_test.go:") {
+			t.Errorf("Expected call site to include the file, received %s", metrics[0].CallSite)
+		}
+		if metrics[1].Name != "test_config" || metrics[1].Type != "config" || metrics[1].Count != 1 {
+			t.Errorf("Unexpected config metric %+v", metrics[1])
+		}
+	})
+
+	t.Run("records the configured number of frames", func(t *testing.T) {
+		c := newClient(2)
+		defer c.Shutdown()
+		checkDeprecatedGate(c)
+
+		metrics := c.GetCallSiteMetrics()
+		if len(metrics) != 1 {
+			t.Fatalf("Expected 1 call site, received %+v", metrics)
+		}
+		frames := strings.Split(metrics[0].CallSite, " <- ")
+		if len(frames) != 2 || !strings.Contains(frames[1], "TestCallerAttribution") {
+			t.Errorf("Expected the test function as the second frame, received %s", metrics[0].CallSite)
+		}
+	})
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		c := newClient(0)
+		defer c.Shutdown()
+		checkDeprecatedGate(c)
+
+		if len(c.GetCallSiteMetrics()) != 0 {
+			t.Errorf("Expected no call site metrics")
+		}
+	})
+}
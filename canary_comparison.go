@@ -0,0 +1,154 @@
+package statsig
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+)
+
+// Evaluates with a primary client, e.g. one for the production SDK key, while comparing a sample of
+// evaluations against a canary client, e.g. one for the project being migrated to. Results and exposures
+// always come from the primary client, the canary is evaluated with exposure logging disabled.
+type CanaryComparison struct {
+	primary *Client
+	canary  *Client
+	options CanaryComparisonOptions
+	stats   CanaryComparisonStats
+	mu      sync.Mutex
+}
+
+type CanaryComparisonOptions struct {
+	SampleRate   float64                           // Fraction of evaluations compared, between 0 and 1. Defaults to 1
+	OnDivergence func(divergence CanaryDivergence) // Called synchronously for each divergence. Divergences are logged if unset
+}
+
+// An evaluation whose value differs between the primary and canary clients.
+// Rule IDs are included for debugging, but not compared since they differ between projects
+type CanaryDivergence struct {
+	Type          string // gate, config, experiment or layer
+	Name          string
+	User          User
+	PrimaryValue  interface{}
+	CanaryValue   interface{}
+	PrimaryRuleID string
+	CanaryRuleID  string
+}
+
+type CanaryComparisonStats struct {
+	Compared          int64
+	Diverged          int64
+	DivergencesBySpec map[string]int64 // Keyed by type:name, e.g. gate:new_checkout
+}
+
+// Compares evaluations between the two clients. Shutdown shuts down both of them
+func NewCanaryComparison(primary *Client, canary *Client, options CanaryComparisonOptions) *CanaryComparison {
+	return &CanaryComparison{
+		primary: primary,
+		canary:  canary,
+		options: options,
+		stats:   CanaryComparisonStats{DivergencesBySpec: make(map[string]int64)},
+	}
+}
+
+// Initializes the primary and canary clients with their own SDK keys and options, and compares evaluations between them
+func NewCanaryComparisonWithKeys(primaryKey string, primaryOptions *Options, canaryKey string, canaryOptions *Options, options CanaryComparisonOptions) *CanaryComparison {
+	return NewCanaryComparison(NewClientWithOptions(primaryKey, primaryOptions), NewClientWithOptions(canaryKey, canaryOptions), options)
+}
+
+func (c *CanaryComparison) CheckGate(user User, gate string) bool {
+	return c.GetGate(user, gate).Value
+}
+
+func (c *CanaryComparison) GetGate(user User, gate string) FeatureGate {
+	result := c.primary.GetGate(user, gate)
+	if c.shouldCompare() {
+		canary := c.canary.GetGateWithExposureLoggingDisabled(user, gate)
+		c.compare("gate", gate, user, result.Value, canary.Value, result.RuleID, canary.RuleID)
+	}
+	return result
+}
+
+func (c *CanaryComparison) GetConfig(user User, config string) DynamicConfig {
+	result := c.primary.GetConfig(user, config)
+	if c.shouldCompare() {
+		canary := c.canary.GetConfigWithExposureLoggingDisabled(user, config)
+		c.compare("config", config, user, result.Value, canary.Value, result.RuleID, canary.RuleID)
+	}
+	return result
+}
+
+func (c *CanaryComparison) GetExperiment(user User, experiment string) DynamicConfig {
+	result := c.primary.GetExperiment(user, experiment)
+	if c.shouldCompare() {
+		canary := c.canary.GetExperimentWithExposureLoggingDisabled(user, experiment)
+		c.compare("experiment", experiment, user, result.Value, canary.Value, result.RuleID, canary.RuleID)
+	}
+	return result
+}
+
+func (c *CanaryComparison) GetLayer(user User, layer string) Layer {
+	result := c.primary.GetLayer(user, layer)
+	if c.shouldCompare() {
+		canary := c.canary.GetLayerWithExposureLoggingDisabled(user, layer)
+		c.compare("layer", layer, user, result.Value, canary.Value, result.RuleID, canary.RuleID)
+	}
+	return result
+}
+
+// Gets the number of comparisons and divergences so far
+func (c *CanaryComparison) GetStats() CanaryComparisonStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.DivergencesBySpec = make(map[string]int64, len(c.stats.DivergencesBySpec))
+	for key, count := range c.stats.DivergencesBySpec {
+		stats.DivergencesBySpec[key] = count
+	}
+	return stats
+}
+
+// Shuts down both the primary and canary clients
+func (c *CanaryComparison) Shutdown() {
+	c.primary.Shutdown()
+	c.canary.Shutdown()
+}
+
+func (c *CanaryComparison) shouldCompare() bool {
+	rate := c.options.SampleRate
+	return rate <= 0 || rate >= 1 || rand.Float64() < rate
+}
+
+func (c *CanaryComparison) compare(specType string, name string, user User, primary interface{}, canary interface{}, primaryRuleID string, canaryRuleID string) {
+	diverged := !reflect.DeepEqual(primary, canary)
+	c.mu.Lock()
+	c.stats.Compared++
+	if diverged {
+		c.stats.Diverged++
+		c.stats.DivergencesBySpec[specType+":"+name]++
+	}
+	c.mu.Unlock()
+	if !diverged {
+		return
+	}
+	divergence := CanaryDivergence{
+		Type:          specType,
+		Name:          name,
+		User:          user,
+		PrimaryValue:  primary,
+		CanaryValue:   canary,
+		PrimaryRuleID: primaryRuleID,
+		CanaryRuleID:  canaryRuleID,
+	}
+	if c.options.OnDivergence == nil {
+		Logger().Log(fmt.Sprintf("Canary divergence for %s %s: primary %v (rule %s), canary %v (rule %s)\n",
+			specType, name, primary, primaryRuleID, canary, canaryRuleID), nil)
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("CanaryComparisonOptions.OnDivergence panicked: %s\n", toError(err).Error()))
+		}
+	}()
+	c.options.OnDivergence(divergence)
+}
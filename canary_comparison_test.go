@@ -0,0 +1,60 @@
+package statsig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCanaryComparison(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	// The canary project has the same specs, except that test_config returns a different number
+	canarySpecs := strings.Replace(string(specs), `"number": 7`, `"number": 8`, 1)
+	newComparisonClient := func(bootstrapValues string) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      bootstrapValues,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	var divergences []CanaryDivergence
+	comparison := NewCanaryComparison(newComparisonClient(string(specs)), newComparisonClient(canarySpecs), CanaryComparisonOptions{
+		OnDivergence: func(divergence CanaryDivergence) { divergences = append(divergences, divergence) },
+	})
+	defer comparison.Shutdown()
+	user := User{UserID: "123", Email: "a@statsig.com"}
+
+	if !comparison.CheckGate(user, "always_on_gate") {
+		t.Errorf("Expected the primary gate value")
+	}
+	if config := comparison.GetConfig(user, "test_config"); config.GetNumber("number", 0) != 7 {
+		t.Errorf("Expected the primary config value, received %v", config.Value)
+	}
+	comparison.GetExperiment(user, "sample_experiment")
+	comparison.GetLayer(user, "a_layer")
+
+	stats := comparison.GetStats()
+	if stats.Compared != 4 || stats.Diverged != 1 || stats.DivergencesBySpec["config:test_config"] != 1 {
+		t.Errorf("Expected one divergence in four comparisons, received %+v", stats)
+	}
+	if len(divergences) != 1 || divergences[0].Name != "test_config" || divergences[0].CanaryValue.(map[string]interface{})["number"] != float64(8) {
+		t.Errorf("Expected the test_config divergence to be reported, received %+v", divergences)
+	}
+	if len(comparison.canary.logger.events) != 0 {
+		t.Errorf("Expected no exposures from the canary client, received %d", len(comparison.canary.logger.events))
+	}
+	if len(comparison.primary.logger.events) == 0 {
+		t.Errorf("Expected exposures from the primary client")
+	}
+
+	t.Run("skips unsampled evaluations", func(t *testing.T) {
+		comparison.options.SampleRate = 0.000001
+		for i := 0; i < 100; i++ {
+			comparison.GetConfig(user, "test_config")
+		}
+		if stats := comparison.GetStats(); stats.Compared > 5 {
+			t.Errorf("Expected evaluations to be sampled, received %+v", stats)
+		}
+	})
+}
@@ -1,10 +1,13 @@
 package statsig
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
 // An instance of a StatsigClient for interfacing with Statsig Feature Gates, Dynamic Configs, Experiments, and Event Logging
@@ -16,6 +19,14 @@ type Client struct {
 	errorBoundary *errorBoundary
 	options       *Options
 	diagnostics   *diagnostics
+	statsReporter *sdkStatsReporter
+	callSites     *callSiteMetrics
+	tenants       *tenantRegistry
+	auditLog      *auditLog
+	coalescer     *evaluationCoalescer
+	metrics       *metricsReporter
+	failsafe      *failsafeCache
+	shutdown      *clientShutdown
 }
 
 // Initializes a Statsig Client with the given sdkKey
@@ -36,8 +47,10 @@ func NewClientWithOptions(sdkKey string, options *Options) *Client {
 		panic(err)
 	}
 	transport := newTransport(sdkKey, options)
+	errorBoundary.instanceID = transport.metadata.InstanceID
 	logger := newLogger(transport, options, diagnostics)
 	evaluator := newEvaluator(transport, errorBoundary, options, diagnostics, sdkKey)
+	statsReporter := newSDKStatsReporter(evaluator, logger, options)
 	diagnostics.initialize().overall().end().success(true).mark()
 	return &Client{
 		sdkKey:        sdkKey,
@@ -47,6 +60,14 @@ func NewClientWithOptions(sdkKey string, options *Options) *Client {
 		errorBoundary: errorBoundary,
 		options:       options,
 		diagnostics:   diagnostics,
+		statsReporter: statsReporter,
+		callSites:     newCallSiteMetrics(options),
+		tenants:       newTenantRegistry(),
+		auditLog:      newAuditLog(options),
+		coalescer:     newEvaluationCoalescer(options),
+		metrics:       newMetricsReporter(options),
+		failsafe:      newFailsafeCache(evaluator.store, options),
+		shutdown:      &clientShutdown{done: make(chan struct{})},
 	}
 }
 
@@ -62,12 +83,32 @@ func (c *Client) CheckGateWithExposureLoggingDisabled(user User, gate string) bo
 	return c.checkGateImpl(user, gate, options).Value
 }
 
+// Checks the value of a Feature Gate for the given user. Network requests made during the evaluation, e.g. with
+// UnknownSpecFetchOptions, are bound to ctx, so its deadline and cancellation apply and its values reach the http.RoundTripper
+func (c *Client) CheckGateCtx(ctx context.Context, user User, gate string) bool {
+	options := checkGateOptions{disableLogExposures: false, ctx: ctx}
+	return c.checkGateImpl(user, gate, options).Value
+}
+
+// Get the Feature Gate for the given user, with network requests made during the evaluation bound to ctx
+func (c *Client) GetGateCtx(ctx context.Context, user User, gate string) FeatureGate {
+	options := checkGateOptions{disableLogExposures: false, ctx: ctx}
+	return c.checkGateImpl(user, gate, options)
+}
+
 // Get the Feature Gate for the given user
 func (c *Client) GetGate(user User, gate string) FeatureGate {
 	options := checkGateOptions{disableLogExposures: false}
 	return c.checkGateImpl(user, gate, options)
 }
 
+// Gets the variant a gate with weighted variants assigns to the given user, logging a gate exposure
+// with the variant. Users failing the gate receive the gate's default variant, or "" if it has none
+func (c *Client) GetVariant(user User, gate string) string {
+	options := checkGateOptions{disableLogExposures: false}
+	return c.checkGateImpl(user, gate, options).Variant
+}
+
 // Checks the value of a Feature Gate for the given user without logging an exposure event
 func (c *Client) GetGateWithExposureLoggingDisabled(user User, gate string) FeatureGate {
 	options := checkGateOptions{disableLogExposures: true}
@@ -81,8 +122,8 @@ func (c *Client) ManuallyLogGateExposure(user User, gate string) {
 			return
 		}
 		user = normalizeUser(user, *c.options)
-		res := c.evaluator.checkGate(user, gate)
-		context := &logContext{isManualExposure: true}
+		res := c.evaluator.checkGate(context.Background(), user, gate)
+		context := &logContext{isManualExposure: true, unitIDType: res.IDType}
 		c.logger.logGateExposure(user, gate, res.Pass, res.RuleID, res.SecondaryExposures, res.EvaluationDetails, context)
 	})
 }
@@ -94,6 +135,13 @@ func (c *Client) GetConfig(user User, config string) DynamicConfig {
 	return c.getConfigImpl(user, config, context)
 }
 
+// Gets the DynamicConfig value for the given user, with network requests made during the evaluation bound to ctx
+func (c *Client) GetConfigCtx(ctx context.Context, user User, config string) DynamicConfig {
+	options := &getConfigOptions{disableLogExposures: false}
+	context := getConfigImplContext{configOptions: options, ctx: ctx}
+	return c.getConfigImpl(user, config, context)
+}
+
 // Gets the DynamicConfig value for the given user without logging an exposure event
 func (c *Client) GetConfigWithExposureLoggingDisabled(user User, config string) DynamicConfig {
 	options := &getConfigOptions{disableLogExposures: true}
@@ -108,8 +156,8 @@ func (c *Client) ManuallyLogConfigExposure(user User, config string) {
 			return
 		}
 		user = normalizeUser(user, *c.options)
-		res := c.evaluator.getConfig(user, config, nil)
-		context := &logContext{isManualExposure: true}
+		res := c.evaluator.getConfig(context.Background(), user, config, nil)
+		context := &logContext{isManualExposure: true, unitIDType: res.IDType}
 		c.logger.logConfigExposure(user, config, res.RuleID, res.SecondaryExposures, res.EvaluationDetails, context)
 	})
 }
@@ -124,6 +172,16 @@ func (c *Client) GetExperiment(user User, experiment string) DynamicConfig {
 	return c.getConfigImpl(user, experiment, context)
 }
 
+// Gets the DynamicConfig value of an Experiment for the given user, with network requests made during the evaluation bound to ctx
+func (c *Client) GetExperimentCtx(ctx context.Context, user User, experiment string) DynamicConfig {
+	if !c.verifyUser(user) {
+		return *NewConfig(experiment, nil, "", "", nil)
+	}
+	options := &GetExperimentOptions{DisableLogExposures: false}
+	context := getConfigImplContext{experimentOptions: options, ctx: ctx}
+	return c.getConfigImpl(user, experiment, context)
+}
+
 // Gets the DynamicConfig value of an Experiment for the given user without logging an exposure event
 func (c *Client) GetExperimentWithExposureLoggingDisabled(user User, experiment string) DynamicConfig {
 	if !c.verifyUser(user) {
@@ -165,6 +223,12 @@ func (c *Client) GetLayer(user User, layer string) Layer {
 	return c.getLayerImpl(user, layer, options)
 }
 
+// Gets the Layer object for the given user, with network requests made during the evaluation bound to ctx
+func (c *Client) GetLayerCtx(ctx context.Context, user User, layer string) Layer {
+	options := getLayerOptions{disableLogExposures: false, ctx: ctx}
+	return c.getLayerImpl(user, layer, options)
+}
+
 // Gets the Layer object for the given user without logging an exposure event
 func (c *Client) GetLayerWithExposureLoggingDisabled(user User, layer string) Layer {
 	options := getLayerOptions{disableLogExposures: true}
@@ -178,13 +242,23 @@ func (c *Client) ManuallyLogLayerParameterExposure(user User, layer string, para
 			return
 		}
 		user = normalizeUser(user, *c.options)
-		res := c.evaluator.getLayer(user, layer)
+		res := c.evaluator.getLayer(context.Background(), user, layer)
 		config := NewLayer(layer, res.ConfigValue.Value, res.ConfigValue.RuleID, res.ConfigValue.GroupName, nil).configBase
-		context := &logContext{isManualExposure: true}
+		context := &logContext{isManualExposure: true, unitIDType: res.IDType}
 		c.logger.logLayerExposure(user, config, parameter, *res, res.EvaluationDetails, context)
 	})
 }
 
+// Gets the Layer object for a unit other than a user (e.g. a stableID or sessionID for logged-out experimentation)
+func (c *Client) GetLayerWithUnit(unitType string, unitID string, layer string) Layer {
+	return c.GetLayer(newUserForUnit(unitType, unitID), layer)
+}
+
+// Gets the DynamicConfig value of an Experiment for a unit other than a user (e.g. a stableID or sessionID)
+func (c *Client) GetExperimentWithUnit(unitType string, unitID string, experiment string) DynamicConfig {
+	return c.GetExperiment(newUserForUnit(unitType, unitID), experiment)
+}
+
 // Logs an event to Statsig for analysis in the Statsig Console
 func (c *Client) LogEvent(event Event) {
 	c.errorBoundary.captureVoid(func() {
@@ -196,6 +270,27 @@ func (c *Client) LogEvent(event Event) {
 	})
 }
 
+// Sends the event, and any events derived from it with RegisterEventAlias, in their own request and
+// waits for Statsig to acknowledge them, bound to ctx. Failed events are neither retried nor spooled.
+func (c *Client) LogEventSync(ctx context.Context, event Event) error {
+	var err error
+	c.errorBoundary.captureVoid(func() {
+		if event.EventName == "" {
+			err = errors.New("event name is required")
+			return
+		}
+		event.User = normalizeUser(event.User, *c.options)
+		err = c.logger.logCustomSync(contextOrBackground(ctx), event)
+	})
+	return err
+}
+
+// Maps a logged event name to a Statsig metric event name. Every event logged with eventName
+// is followed by an event named alias.MetricEventName, with the value transformed by alias.Value.
+func (c *Client) RegisterEventAlias(eventName string, alias EventAlias) {
+	c.errorBoundary.captureVoid(func() { c.logger.aliases.register(eventName, alias) })
+}
+
 // Override the value of a Feature Gate for the given user
 func (c *Client) OverrideGate(gate string, val bool) {
 	c.errorBoundary.captureVoid(func() { c.evaluator.OverrideGate(gate, val) })
@@ -211,6 +306,26 @@ func (c *Client) OverrideLayer(layer string, val map[string]interface{}) {
 	c.errorBoundary.captureVoid(func() { c.evaluator.OverrideLayer(layer, val) })
 }
 
+// Remove the override of a Feature Gate, so it is evaluated normally again
+func (c *Client) RemoveGateOverride(gate string) {
+	c.errorBoundary.captureVoid(func() { c.evaluator.RemoveGateOverride(gate) })
+}
+
+// Remove the override of a DynamicConfig or Experiment
+func (c *Client) RemoveConfigOverride(config string) {
+	c.errorBoundary.captureVoid(func() { c.evaluator.RemoveConfigOverride(config) })
+}
+
+// Remove the override of a Layer
+func (c *Client) RemoveLayerOverride(layer string) {
+	c.errorBoundary.captureVoid(func() { c.evaluator.RemoveLayerOverride(layer) })
+}
+
+// Remove all gate, config and layer overrides
+func (c *Client) ClearOverrides() {
+	c.errorBoundary.captureVoid(func() { c.evaluator.ClearOverrides() })
+}
+
 func (c *Client) LogImmediate(events []Event) (*http.Response, error) {
 	if len(events) > 500 {
 		err := errors.New(EventBatchSizeError)
@@ -238,6 +353,53 @@ func (c *Client) GetClientInitializeResponse(user User, clientKey string) Client
 	})
 }
 
+// Adds a listener called whenever new config specs are applied, after Options.RulesUpdatedCallback
+// and any listeners added before it. Listeners are called one at a time, so they should return quickly;
+// those that panic are logged without affecting the others, and those slower than
+// RulesetListenerOptions.SlowThreshold are logged. Returns a function that removes the listener
+func (c *Client) AddRulesetListener(listener RulesetListener) func() {
+	remove := func() {}
+	c.errorBoundary.captureVoid(func() {
+		remove = c.evaluator.store.rulesetListeners.add(listener, getListenerCallSite())
+	})
+	return remove
+}
+
+// Adds a listener receiving the specs that changed whenever new config specs are applied, called in
+// the same order as listeners added with AddRulesetListener. Returns a function that removes the listener
+func (c *Client) AddRulesetUpdateListener(listener RulesetUpdateListener) func() {
+	remove := func() {}
+	c.errorBoundary.captureVoid(func() {
+		remove = c.evaluator.store.rulesetListeners.addUpdateListener(listener, getListenerCallSite())
+	})
+	return remove
+}
+
+// Gets the ID unique to this Client, sent with every request and event to correlate them with this instance
+func (c *Client) GetInstanceID() string {
+	return c.transport.metadata.InstanceID
+}
+
+// Gets a snapshot of the SDK's internal sizes and the process memory usage
+func (c *Client) GetSDKStats() SDKStats {
+	return collectSDKStats(c.evaluator, c.logger)
+}
+
+// Gets this SDK's version and the minimum and recommended versions last advertised by the Statsig API
+func (c *Client) GetSDKVersionStatus() SDKVersionStatus {
+	return c.transport.getSDKVersionStatus()
+}
+
+// Gets the number of evaluations compared with ShadowEvaluationOptions and the divergences so far
+func (c *Client) GetShadowEvaluationStats() ShadowEvaluationStats {
+	return c.evaluator.shadow.getStats()
+}
+
+// Gets the size and sync state of each ID list, sorted by name
+func (c *Client) GetIDListStats() []IDListStats {
+	return c.evaluator.store.getIDListStats()
+}
+
 func (c *Client) verifyUser(user User) bool {
 	if user.UserID == "" && len(user.CustomIDs) == 0 {
 		err := errors.New(EmptyUserError)
@@ -247,17 +409,292 @@ func (c *Client) verifyUser(user User) bool {
 	return true
 }
 
+// Gets evaluation counts per call site, most frequent first. Requires CallerAttributionOptions
+func (c *Client) GetCallSiteMetrics() []CallSiteMetric {
+	return c.callSites.snapshot()
+}
+
+// Stops applying ruleset updates until UnfreezeRuleset is called, e.g. during a sensitive traffic window.
+// Updates are still fetched, and the latest is applied on unfreeze.
+func (c *Client) FreezeRuleset() {
+	c.errorBoundary.captureVoid(func() {
+		c.evaluator.store.freezeRuleset()
+	})
+}
+
+// Resumes applying ruleset updates, starting with the latest one received while frozen
+func (c *Client) UnfreezeRuleset() {
+	c.errorBoundary.captureVoid(func() {
+		c.evaluator.store.unfreezeRuleset()
+	})
+}
+
+// Gets whether ruleset updates are frozen, when the freeze expires and the updates held back so far
+func (c *Client) GetRulesetFreezeStatus() RulesetFreezeStatus {
+	return c.evaluator.store.getRulesetFreezeStatus()
+}
+
+// Stops sending events until ResumeLogging is called, e.g. during a log_event outage. Evaluations
+// are unaffected. Events are held in memory, up to each queue's limit, and sent on resume; those
+// still held at Shutdown are sent as the pause is lifted.
+func (c *Client) PauseLogging() {
+	c.errorBoundary.captureVoid(func() {
+		c.logger.pauseLogging()
+	})
+}
+
+// Resumes sending events, starting with those held while paused
+func (c *Client) ResumeLogging() {
+	c.errorBoundary.captureVoid(func() {
+		c.logger.resumeLogging()
+	})
+}
+
+// Reports whether event logging is paused, since when and until when, and the events dropped meanwhile
+func (c *Client) GetLoggingPauseStatus() LoggingPauseStatus {
+	return c.logger.getLoggingPauseStatus()
+}
+
+// Evaluates a gate against the ruleset that was being served at the given time, e.g. to answer
+// what a user received during an incident. Requires Options.RulesetHistorySize. ID lists are
+// evaluated as they are now, and no exposure is logged.
+func (c *Client) CheckGateAtTime(user User, gateName string, at time.Time) (FeatureGate, error) {
+	var gate FeatureGate
+	var err error
+	c.errorBoundary.captureVoid(func() {
+		if !c.verifyUser(user) {
+			gate = *NewGate(gateName, false, "", "")
+			return
+		}
+		var res *evalResult
+		if res, err = c.evaluator.checkGateAtTime(normalizeUser(user, *c.options), gateName, at); err == nil {
+			gate = *NewGate(gateName, res.Pass, res.RuleID, res.GroupName)
+		}
+	})
+	return gate, err
+}
+
+// Evaluates a one line query such as `gate:new_checkout user.userID=42 user.country=DE`, e.g. to
+// simulate a user from support tooling, explaining which rules matched. No exposure is logged.
+func (c *Client) Query(query string) (QueryResult, error) {
+	var result QueryResult
+	var err error
+	c.errorBoundary.captureVoid(func() {
+		var kind, name string
+		var user User
+		if kind, name, user, err = parseQuery(query); err != nil {
+			return
+		}
+		if user.UserID == "" && len(user.CustomIDs) == 0 {
+			err = errors.New("query must set user.userID or a user.customIDs field")
+			return
+		}
+		normalized := normalizeUser(user, *c.options)
+		res := c.evaluator.evalQuery(normalized, kind, name)
+		result = QueryResult{
+			Kind:        kind,
+			Name:        name,
+			User:        user,
+			Pass:        res.Pass,
+			RuleID:      res.RuleID,
+			GroupName:   res.GroupName,
+			Reason:      string(reasonUnrecognized),
+			Explanation: c.evaluator.explainQuery(normalized, kind, name, res),
+		}
+		if kind != queryKindGate {
+			result.Value = res.ConfigValue.Value
+		}
+		if res.EvaluationDetails != nil {
+			result.Reason = string(res.EvaluationDetails.reason)
+		}
+	})
+	return result, err
+}
+
+// Registers a tenant whose evaluations only consider the specs matching filter, or the specs
+// named "<tenant>::<name>" when filter is nil. Registering a tenant again replaces its filter.
+func (c *Client) RegisterTenant(tenant string, filter func(specName string) bool) *TenantClient {
+	return c.tenants.register(c, tenant, filter)
+}
+
+// Gets a tenant registered with RegisterTenant
+func (c *Client) GetTenant(tenant string) (*TenantClient, bool) {
+	return c.tenants.get(tenant)
+}
+
+// Gets evaluation and rejected lookup counts of every registered tenant
+func (c *Client) GetTenantStats() map[string]TenantStats {
+	return c.tenants.stats()
+}
+
+// Lists the rulesets retained with Options.RulesetHistorySize, oldest first
+func (c *Client) ListRulesetSnapshots() []RulesetSnapshotInfo {
+	return c.evaluator.store.listRulesetSnapshots()
+}
+
+// Gets the retained ruleset with the given time as download_config_specs JSON
+func (c *Client) GetRulesetSnapshot(rulesetTime int64) (string, error) {
+	snapshot, err := c.evaluator.store.getRulesetSnapshot(rulesetTime)
+	if err != nil {
+		return "", err
+	}
+	serialized, err := snapshot.decompress()
+	return string(serialized), err
+}
+
+// Decodes the default value of the named config into target, a pointer to a struct or map, and again
+// whenever a ruleset sync changes the config. Each decode fills a fresh value that replaces *target
+// only if decoding succeeded, so target never holds a partial or stale mix of values. onChange is
+// called after every decode, including the first, with nil or the error that prevented decoding.
+// Decodes happen on the SDK's sync goroutine, so target must be guarded by the caller if it is read
+// concurrently. Call the returned function to stop watching.
+func (c *Client) WatchConfig(name string, target interface{}, onChange func(err error)) func() {
+	unwatch := func() {}
+	c.errorBoundary.captureVoid(func() {
+		watcher := &configWatcher{name: name, target: target, onChange: onChange}
+		c.evaluator.store.configWatchers.add(watcher)
+		c.evaluator.store.decodeWatchedConfig(watcher)
+		unwatch = func() { c.evaluator.store.configWatchers.remove(watcher) }
+	})
+	return unwatch
+}
+
+// Serves CheckGate, GetConfig, GetExperiment and GetLayer for the given user from a precomputed
+// ClientInitializeResponse payload, e.g. one produced by an edge service. Specs missing from the
+// payload are evaluated from the ruleset as usual. Exposures are logged with reason "Precomputed".
+func (c *Client) LoadPrecomputedEvaluations(user User, payload string) error {
+	var err error
+	c.errorBoundary.captureVoid(func() { err = c.evaluator.loadPrecomputedEvaluations(user, payload) })
+	return err
+}
+
+// Stops serving the given user from evaluations added with LoadPrecomputedEvaluations
+func (c *Client) ClearPrecomputedEvaluations(user User) {
+	c.errorBoundary.captureVoid(func() { c.evaluator.clearPrecomputedEvaluations(user) })
+}
+
+// Evaluates the gates in Options.EvaluationBaggageOptions for the user, without logging exposures,
+// for propagation to downstream services as baggage
+func (c *Client) GetEvaluationBaggage(user User) EvaluationBaggage {
+	var baggage EvaluationBaggage
+	c.errorBoundary.captureVoid(func() { baggage = c.getEvaluationBaggage(user) })
+	return baggage
+}
+
+// Returns a context derived from ctx that serves CheckGateCtx and GetGateCtx for the user from gate results
+// in upstream baggage, logging exposures with reason "Precomputed". The baggage only applies to evaluations
+// made with the returned context, so one request's baggage never reaches another. Returns ctx unchanged
+// and an error if the baggage was generated for a different user.
+func (c *Client) LoadEvaluationBaggage(ctx context.Context, user User, baggage EvaluationBaggage) (context.Context, error) {
+	loaded := ctx
+	var err error
+	c.errorBoundary.captureVoid(func() { loaded, err = loadEvaluationBaggage(ctx, user, baggage) })
+	return loaded, err
+}
+
+// Gets the size, parse duration and number of changed specs of config spec syncs from the network
+func (c *Client) GetConfigSpecSyncMetrics() ConfigSpecSyncMetrics {
+	return c.evaluator.store.getConfigSpecSyncMetrics()
+}
+
+// Calls fn for every loaded spec, ordered by type then name, until fn returns false.
+// Specs are read from a snapshot, so fn may call back into the client.
+func (c *Client) ForEachSpec(fn func(spec SpecInfo) bool) {
+	c.errorBoundary.captureVoid(func() {
+		for _, spec := range c.evaluator.store.getSpecInfos() {
+			if !fn(spec) {
+				return
+			}
+		}
+	})
+}
+
+// Blocks until the named specs, and with WaitForIDLists the ID lists they reference, are loaded,
+// re-syncing every RetryInterval while waiting. Returns an error naming what is still missing
+// if ctx is done first.
+func (c *Client) WarmUp(ctx context.Context, options WarmUpOptions) error {
+	var err error
+	c.errorBoundary.captureVoid(func() { err = c.evaluator.store.warmUp(ctx, options) })
+	return err
+}
+
+// Returns where the initial config specs came from and any error encountered loading them
+func (c *Client) GetInitializeDetails() InitializeDetails {
+	c.evaluator.store.mu.RLock()
+	defer c.evaluator.store.mu.RUnlock()
+	return InitializeDetails{
+		Source: string(c.evaluator.store.initReason),
+		Error:  c.evaluator.store.bootstrapError,
+	}
+}
+
+// Sends buffered events now, bound to ctx, returning an error if any failed to send. Events that failed are
+// dropped, or with EventQueueOptions.Partitioned requeued for the next flush
+func (c *Client) Flush(ctx context.Context) error {
+	var err error
+	c.errorBoundary.captureVoid(func() {
+		err = c.logger.flushCtx(ctx)
+	})
+	return err
+}
+
 // Cleans up Statsig, persisting any Event Logs and cleanup processes
 // Using any method is undefined after Shutdown() has been called
 func (c *Client) Shutdown() {
-	c.errorBoundary.captureVoid(func() {
-		c.logger.flush(true)
-		c.evaluator.shutdown()
+	_ = c.ShutdownCtx(context.Background())
+}
+
+// Shuts the client down, returning an error if events failed to send or ctx was done first. Polling stops
+// immediately, then pending events are flushed with retries and in-flight syncs, including ID list downloads,
+// are waited for. If ctx is done first, the shutdown carries on in the background. Concurrent and repeated
+// calls wait for the same shutdown.
+func (c *Client) ShutdownCtx(ctx context.Context) error {
+	c.shutdown.once.Do(func() {
+		go func() {
+			defer close(c.shutdown.done)
+			c.errorBoundary.captureVoid(func() {
+				c.shutdown.err = c.shutdownInternal(ctx)
+			})
+		}()
 	})
+	select {
+	case <-c.shutdown.done:
+		return c.shutdown.err
+	case <-ctx.Done():
+		return fmt.Errorf("Shutdown did not complete: %s", ctx.Err().Error())
+	}
+}
+
+type clientShutdown struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func (c *Client) shutdownInternal(ctx context.Context) error {
+	c.statsReporter.stop()
+	c.evaluator.store.stopPolling()
+	var errs []string
+	if err := c.logger.flushCtx(ctx); err != nil && err != errLoggingPaused {
+		errs = append(errs, err.Error())
+	}
+	c.logger.flush(true)
+	c.failsafe.stop()
+	// ID list files are closed once no download can be writing to them
+	if err := c.evaluator.store.drainPollers(ctx); err != nil {
+		errs = append(errs, fmt.Sprintf("Syncs still running: %s", err.Error()))
+		_ = c.evaluator.store.drainPollers(context.Background())
+	}
+	c.evaluator.shutdown()
+	if len(errs) > 0 {
+		return fmt.Errorf("Shutdown: %s", strings.Join(errs, "; "))
+	}
+	return nil
 }
 
 type checkGateOptions struct {
 	disableLogExposures bool
+	ctx                 context.Context
 }
 
 type getConfigOptions struct {
@@ -271,6 +708,7 @@ type GetExperimentOptions struct {
 
 type getLayerOptions struct {
 	disableLogExposures bool
+	ctx                 context.Context
 }
 
 type gateResponse struct {
@@ -298,35 +736,53 @@ type getConfigInput struct {
 }
 
 func (c *Client) checkGateImpl(user User, gate string, options checkGateOptions) FeatureGate {
+	c.callSites.record("gate", gate)
+	c.metrics.evaluation("gate")
 	return c.errorBoundary.captureCheckGate(func() FeatureGate {
 		if !c.verifyUser(user) {
 			return *NewGate(gate, false, "", "")
 		}
 		user = normalizeUser(user, *c.options)
-		res := c.evaluator.checkGate(user, gate)
-		if res.FetchFromServer {
-			serverRes := fetchGate(user, gate, c.transport)
-			res = &evalResult{Pass: serverRes.Value, RuleID: serverRes.RuleID}
-		} else {
-			var exposure *ExposureEvent = nil
-			if !options.disableLogExposures {
-				context := &logContext{isManualExposure: false}
-				exposure = c.logger.logGateExposure(user, gate, res.Pass, res.RuleID, res.SecondaryExposures, res.EvaluationDetails, context)
-			}
-			if c.options.EvaluationCallbacks.GateEvaluationCallback != nil {
-				c.options.EvaluationCallbacks.GateEvaluationCallback(gate, res.Pass, exposure)
+		ctx := contextOrBackground(options.ctx)
+		variant := exposureVariant(options.disableLogExposures)
+		return c.coalescer.do(ctx, "gate", gate, variant, user, func() interface{} {
+			res := c.applyGateFallback(user, gate, c.failsafe.resolve(user, "gate", gate, c.evaluator.checkGate(ctx, user, gate)))
+			if res.FetchFromServer {
+				serverRes := fetchGate(ctx, user, gate, c.transport)
+				res = &evalResult{Pass: serverRes.Value, RuleID: serverRes.RuleID, EvaluationDetails: c.evaluator.createEvaluationDetails(reasonNetwork)}
+			} else {
+				var exposure *ExposureEvent = nil
+				if !options.disableLogExposures {
+					context := &logContext{isManualExposure: false, unitIDType: res.IDType, variant: res.Variant, ctx: ctx}
+					exposure = c.logger.logGateExposure(user, gate, res.Pass, res.RuleID, res.SecondaryExposures, res.EvaluationDetails, context)
+				}
+				if c.options.EvaluationCallbacks.GateEvaluationCallback != nil {
+					c.options.EvaluationCallbacks.GateEvaluationCallback(gate, res.Pass, exposure)
+				}
 			}
-		}
-		return *NewGate(gate, res.Pass, res.RuleID, res.GroupName)
+			c.auditLog.record("gate", gate, user, res.Pass, res)
+			result := *NewGate(gate, res.Pass, res.RuleID, res.GroupName)
+			result.Variant = res.Variant
+			result.evaluationDetails = res.EvaluationDetails
+			return result
+		}).(FeatureGate)
 	})
 }
 
 type getConfigImplContext struct {
 	configOptions     *getConfigOptions
 	experimentOptions *GetExperimentOptions
+	ctx               context.Context
 }
 
 func (c *Client) getConfigImpl(user User, config string, context getConfigImplContext) DynamicConfig {
+	if context.experimentOptions != nil {
+		c.callSites.record("experiment", config)
+		c.metrics.evaluation("experiment")
+	} else {
+		c.callSites.record("config", config)
+		c.metrics.evaluation("config")
+	}
 	return c.errorBoundary.captureGetConfig(func() DynamicConfig {
 		if !c.verifyUser(user) {
 			return *NewConfig(config, nil, "", "", nil)
@@ -337,67 +793,108 @@ func (c *Client) getConfigImpl(user User, config string, context getConfigImplCo
 			persistedValues = context.experimentOptions.PersistedValues
 		}
 		user = normalizeUser(user, *c.options)
-		res := c.evaluator.getConfig(user, config, persistedValues)
-		if res.FetchFromServer {
-			res = c.fetchConfigFromServer(user, config)
-		} else {
-			var exposure *ExposureEvent = nil
-			var logExposure bool
-			if isExperiment {
-				logExposure = !context.experimentOptions.DisableLogExposures
-			} else {
-				logExposure = !context.configOptions.disableLogExposures
-			}
-			if logExposure {
-				context := &logContext{isManualExposure: false}
-				exposure = c.logger.logConfigExposure(user, config, res.RuleID, res.SecondaryExposures, res.EvaluationDetails, context)
-			}
-			if isExperiment && c.options.EvaluationCallbacks.ExperimentEvaluationCallback != nil {
-				c.options.EvaluationCallbacks.ExperimentEvaluationCallback(config, res.ConfigValue, exposure)
-			} else if c.options.EvaluationCallbacks.ConfigEvaluationCallback != nil {
-				c.options.EvaluationCallbacks.ConfigEvaluationCallback(config, res.ConfigValue, exposure)
-			}
+		ctx := contextOrBackground(context.ctx)
+		specType := "config"
+		if isExperiment {
+			specType = "experiment"
+		}
+		evaluate := func() interface{} {
+			return c.evaluateConfig(ctx, user, config, persistedValues, context)
 		}
-		return res.ConfigValue
+		// Persisted values are specific to the caller, so those evaluations are not shared
+		if persistedValues != nil {
+			return evaluate().(DynamicConfig)
+		}
+		variant := exposureVariant(!c.shouldLogConfigExposure(context))
+		return c.coalescer.do(ctx, specType, config, variant, user, evaluate).(DynamicConfig)
 	})
 }
 
+func (c *Client) shouldLogConfigExposure(context getConfigImplContext) bool {
+	if context.experimentOptions != nil {
+		return !context.experimentOptions.DisableLogExposures
+	}
+	return !context.configOptions.disableLogExposures
+}
+
+func (c *Client) evaluateConfig(ctx context.Context, user User, config string, persistedValues UserPersistedValues, implContext getConfigImplContext) DynamicConfig {
+	isExperiment := implContext.experimentOptions != nil
+	res := c.evaluator.getConfig(ctx, user, config, persistedValues)
+	if persistedValues == nil {
+		res = c.failsafe.resolve(user, "config", config, res)
+	}
+	if res.FetchFromServer {
+		res = c.fetchConfigFromServer(ctx, user, config)
+	} else {
+		var exposure *ExposureEvent = nil
+		if c.shouldLogConfigExposure(implContext) {
+			context := &logContext{isManualExposure: false, unitIDType: res.IDType, ctx: ctx}
+			exposure = c.logger.logConfigExposure(user, config, res.RuleID, res.SecondaryExposures, res.EvaluationDetails, context)
+		}
+		if isExperiment && c.options.EvaluationCallbacks.ExperimentEvaluationCallback != nil {
+			c.options.EvaluationCallbacks.ExperimentEvaluationCallback(config, res.ConfigValue, exposure)
+		} else if c.options.EvaluationCallbacks.ConfigEvaluationCallback != nil {
+			c.options.EvaluationCallbacks.ConfigEvaluationCallback(config, res.ConfigValue, exposure)
+		}
+	}
+	if isExperiment {
+		c.auditLog.record("experiment", config, user, res.ConfigValue.Value, res)
+	} else {
+		c.auditLog.record("config", config, user, res.ConfigValue.Value, res)
+	}
+	res.ConfigValue.EvaluationDetails = res.EvaluationDetails
+	if isExperiment {
+		res.ConfigValue.experiment = c.experimentAssignment(config, res)
+	}
+	return res.ConfigValue
+}
+
 func (c *Client) getLayerImpl(user User, layer string, options getLayerOptions) Layer {
+	c.callSites.record("layer", layer)
+	c.metrics.evaluation("layer")
 	return c.errorBoundary.captureGetLayer(func() Layer {
 		if !c.verifyUser(user) {
 			return *NewLayer(layer, nil, "", "", nil)
 		}
 
 		user = normalizeUser(user, *c.options)
-		res := c.evaluator.getLayer(user, layer)
-
-		if res.FetchFromServer {
-			res = c.fetchConfigFromServer(user, layer)
-		}
-
-		logFunc := func(config configBase, parameterName string) {
-			var exposure *ExposureEvent = nil
-			if !options.disableLogExposures {
-				context := &logContext{isManualExposure: false}
-				exposure = c.logger.logLayerExposure(user, config, parameterName, *res, res.EvaluationDetails, context)
+		ctx := contextOrBackground(options.ctx)
+		// Layer exposures are logged when parameters are read, so callers sharing the layer log their own
+		variant := exposureVariant(options.disableLogExposures)
+		return c.coalescer.do(ctx, "layer", layer, variant, user, func() interface{} {
+			res := c.failsafe.resolve(user, "layer", layer, c.evaluator.getLayer(ctx, user, layer))
+
+			if res.FetchFromServer {
+				res = c.fetchConfigFromServer(ctx, user, layer)
 			}
-			if c.options.EvaluationCallbacks.LayerEvaluationCallback != nil {
-				c.options.EvaluationCallbacks.LayerEvaluationCallback(layer, parameterName, res.ConfigValue, exposure)
+			c.auditLog.record("layer", layer, user, res.ConfigValue.Value, res)
+
+			logFunc := func(config configBase, parameterName string) {
+				var exposure *ExposureEvent = nil
+				if !options.disableLogExposures {
+					context := &logContext{isManualExposure: false, unitIDType: res.IDType, ctx: ctx}
+					exposure = c.logger.logLayerExposure(user, config, parameterName, *res, res.EvaluationDetails, context)
+				}
+				if c.options.EvaluationCallbacks.LayerEvaluationCallback != nil {
+					c.options.EvaluationCallbacks.LayerEvaluationCallback(layer, parameterName, res.ConfigValue, exposure)
+				}
 			}
-		}
 
-		return *NewLayer(layer, res.ConfigValue.Value, res.ConfigValue.RuleID, res.ConfigValue.GroupName, &logFunc)
+			result := *NewLayer(layer, res.ConfigValue.Value, res.ConfigValue.RuleID, res.ConfigValue.GroupName, &logFunc)
+			result.EvaluationDetails = res.EvaluationDetails
+			return result
+		}).(Layer)
 	})
 }
 
-func fetchGate(user User, gateName string, t *transport) gateResponse {
+func fetchGate(ctx context.Context, user User, gateName string, t *transport) gateResponse {
 	input := &checkGateInput{
 		GateName:        gateName,
 		User:            user,
 		StatsigMetadata: t.metadata,
 	}
 	var res gateResponse
-	_, err := t.post("/check_gate", input, &res, RequestOptions{})
+	_, err := t.post("/check_gate", input, &res, RequestOptions{ctx: ctx})
 	if err != nil {
 		return gateResponse{
 			Name:   gateName,
@@ -408,14 +905,14 @@ func fetchGate(user User, gateName string, t *transport) gateResponse {
 	return res
 }
 
-func fetchConfig(user User, configName string, t *transport) configResponse {
+func fetchConfig(ctx context.Context, user User, configName string, t *transport) configResponse {
 	input := &getConfigInput{
 		ConfigName:      configName,
 		User:            user,
 		StatsigMetadata: t.metadata,
 	}
 	var res configResponse
-	_, err := t.post("/get_config", input, &res, RequestOptions{})
+	_, err := t.post("/get_config", input, &res, RequestOptions{ctx: ctx})
 	if err != nil {
 		return configResponse{
 			Name:   configName,
@@ -425,6 +922,13 @@ func fetchConfig(user User, configName string, t *transport) configResponse {
 	return res
 }
 
+func newUserForUnit(unitType string, unitID string) User {
+	if strings.ToLower(unitType) == "userid" {
+		return User{UserID: unitID}
+	}
+	return User{CustomIDs: map[string]string{unitType: unitID}}
+}
+
 func normalizeUser(user User, options Options) User {
 	env := make(map[string]string)
 	// Copy to avoid data race. We modify the map below.
@@ -438,13 +942,15 @@ func normalizeUser(user User, options Options) User {
 		env[k] = v
 	}
 	user.StatsigEnvironment = env
-	return user
+	return fillUnitIDsFromAttributes(user, options)
 }
 
-func (c *Client) fetchConfigFromServer(user User, configName string) *evalResult {
-	serverRes := fetchConfig(user, configName, c.transport)
+func (c *Client) fetchConfigFromServer(ctx context.Context, user User, configName string) *evalResult {
+	serverRes := fetchConfig(ctx, user, configName, c.transport)
+	evalDetails := c.evaluator.createEvaluationDetails(reasonNetwork)
 	return &evalResult{
-		ConfigValue: *NewConfig(configName, serverRes.Value, serverRes.RuleID, "", nil),
-		RuleID:      serverRes.RuleID,
+		ConfigValue:       *NewConfig(configName, serverRes.Value, serverRes.RuleID, "", evalDetails),
+		RuleID:            serverRes.RuleID,
+		EvaluationDetails: evalDetails,
 	}
 }
@@ -0,0 +1,57 @@
+package statsig
+
+import (
+	"time"
+)
+
+// Date headers have a resolution of one second, so smaller skews cannot be measured reliably
+const defaultClockSyncMinOffset = 2 * time.Second
+
+// Corrects for a local clock that has drifted from Statsig's servers, by the skew measured from
+// the Date header of the latest response (SDKStats.ClockSkewMs). Until a response is received,
+// the local clock is used as is. The sinceTime of config spec syncs is the server's own ruleset
+// time, so it is never affected by the local clock.
+type ClockSyncOptions struct {
+	AdjustEventTimes bool          // Stamps events, exposures and their serverTime with the corrected time. Times set on an Event by the caller are kept
+	MinOffset        time.Duration // Skews smaller than this are not corrected. Defaults to 2 seconds
+}
+
+// Milliseconds to add to the local clock to approximate the server's
+func (transport *transport) clockOffset() int64 {
+	if transport == nil {
+		return 0
+	}
+	skew := transport.getClockSkew()
+	minOffset := transport.options.ClockSyncOptions.MinOffset
+	if minOffset <= 0 {
+		minOffset = defaultClockSyncMinOffset
+	}
+	if skew < minOffset.Milliseconds() && -skew < minOffset.Milliseconds() {
+		return 0
+	}
+	return -skew
+}
+
+// The current time in Unix milliseconds, corrected to the server's clock
+func (transport *transport) serverNow() int64 {
+	return getUnixMilli() + transport.clockOffset()
+}
+
+// The time events are stamped with, corrected to the server's clock with ClockSyncOptions.AdjustEventTimes
+func (transport *transport) eventTime() int64 {
+	if transport == nil || !transport.options.ClockSyncOptions.AdjustEventTimes {
+		return getUnixMilli()
+	}
+	return transport.serverNow()
+}
+
+// How long ago, by the server's clock, the served ruleset was generated. 0 before one is loaded
+func (s *store) rulesetAge() time.Duration {
+	s.mu.RLock()
+	lastSyncTime := s.lastSyncTime
+	s.mu.RUnlock()
+	if lastSyncTime == 0 {
+		return 0
+	}
+	return time.Duration(s.transport.serverNow()-lastSyncTime) * time.Millisecond
+}
@@ -0,0 +1,91 @@
+package statsig
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClockSync(t *testing.T) {
+	// The local clock is an hour ahead of the server
+	const skew = time.Hour
+	serverNow := func() time.Time { return time.Now().Add(-skew) }
+	rulesetTime := serverNow().Add(-5*time.Second).UnixNano() / int64(time.Millisecond)
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Date", serverNow().UTC().Format(http.TimeFormat))
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write([]byte(fmt.Sprintf(`{"has_updates": true, "time": %d, "feature_gates": [
+				{"name": "gate", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+					{"name": "everyone", "id": "public", "salt": "s", "passPercentage": 100, "returnValue": true,
+						"conditions": [{"type": "public", "operator": null, "targetValue": null}]}]}],
+				"dynamic_configs": [], "layer_configs": []}`, rulesetTime)))
+		} else {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+	newClockClient := func(options ClockSyncOptions) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                testServer.URL,
+			ConfigSyncInterval: time.Hour,
+			IDListSyncInterval: time.Hour,
+			ClockSyncOptions:   options,
+			LoggingInterval:    time.Hour,
+		})
+	}
+	near := func(actual int64, expected time.Time) bool {
+		diff := actual - expected.UnixNano()/int64(time.Millisecond)
+		return diff > -3000 && diff < 3000
+	}
+
+	adjusted := newClockClient(ClockSyncOptions{AdjustEventTimes: true})
+	defer adjusted.Shutdown()
+	stats := adjusted.GetSDKStats()
+	if stats.ClockSkewMs < (skew - 3*time.Second).Milliseconds() {
+		t.Errorf("Expected a skew of about an hour, received %dms", stats.ClockSkewMs)
+	}
+	if stats.RulesetAgeMs < 2000 || stats.RulesetAgeMs > 8000 {
+		t.Errorf("Expected the ruleset to be about 5 seconds old by the server's clock, received %dms", stats.RulesetAgeMs)
+	}
+	adjusted.LogEvent(Event{EventName: "purchase", User: User{UserID: "a"}})
+	adjusted.LogEvent(Event{EventName: "backfill", User: User{UserID: "a"}, Time: 42})
+	adjusted.CheckGate(User{UserID: "a"}, "gate")
+	adjusted.logger.mu.Lock()
+	events := append([]interface{}{}, adjusted.logger.events...)
+	adjusted.logger.mu.Unlock()
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, received %d", len(events))
+	}
+	if evt := events[0].(Event); !near(evt.Time, serverNow()) {
+		t.Errorf("Expected the event to be stamped with server time, received %d", evt.Time)
+	}
+	if evt := events[1].(Event); evt.Time != 42 {
+		t.Errorf("Expected the caller's time to be kept, received %d", evt.Time)
+	}
+	exposure := events[2].(ExposureEvent)
+	exposureServerTime, _ := strconv.ParseInt(exposure.Metadata["serverTime"], 10, 64)
+	if !near(exposure.Time, serverNow()) || !near(exposureServerTime, serverNow()) {
+		t.Errorf("Expected the exposure and its serverTime to use server time, received %d and %s", exposure.Time, exposure.Metadata["serverTime"])
+	}
+
+	unadjusted := newClockClient(ClockSyncOptions{})
+	defer unadjusted.Shutdown()
+	unadjusted.LogEvent(Event{EventName: "purchase", User: User{UserID: "a"}})
+	if evt := unadjusted.logger.events[0].(Event); !near(evt.Time, time.Now()) {
+		t.Errorf("Expected events to keep the local time by default, received %d", evt.Time)
+	}
+	if age := unadjusted.GetSDKStats().RulesetAgeMs; age > 8000 {
+		t.Errorf("Expected the ruleset age to be corrected regardless of AdjustEventTimes, received %dms", age)
+	}
+
+	tolerant := newClockClient(ClockSyncOptions{AdjustEventTimes: true, MinOffset: 2 * skew})
+	defer tolerant.Shutdown()
+	if offset := tolerant.transport.clockOffset(); offset != 0 {
+		t.Errorf("Expected skews below MinOffset not to be corrected, received %dms", offset)
+	}
+}
@@ -0,0 +1,105 @@
+package statsig
+
+import (
+	"time"
+)
+
+// Size and change counts of config spec syncs from the network
+type ConfigSpecSyncMetrics struct {
+	LastSync             ConfigSpecSync `json:"lastSync"`
+	LastUpdate           ConfigSpecSync `json:"lastUpdate"`           // The last sync that returned updated specs
+	SyncCount            int64          `json:"syncCount"`            // Successful network syncs, including those without updates
+	TotalBytesDownloaded int64          `json:"totalBytesDownloaded"` // Decompressed response bytes across all network syncs
+	SyncInterval         time.Duration  `json:"syncInterval"`         // The current interval, which grows while idle with AdaptivePollingOptions
+}
+
+type ConfigSpecSync struct {
+	Time            int64         `json:"time"`
+	BytesDownloaded int64         `json:"bytesDownloaded"`
+	ParseDuration   time.Duration `json:"parseDuration"`
+	HasUpdates      bool          `json:"hasUpdates"`
+	SpecsAdded      int           `json:"specsAdded"`
+	SpecsRemoved    int           `json:"specsRemoved"`
+	SpecsChanged    int           `json:"specsChanged"`
+}
+
+type specDelta struct {
+	added   int
+	removed int
+	changed int
+}
+
+// Collects the hashes of every spec's raw JSON taken while decoding, keyed by type and name, to count changes between syncs
+func hashConfigSpecs(specs downloadConfigSpecResponse) map[string]uint64 {
+	hashes := make(map[string]uint64, len(specs.FeatureGates)+len(specs.DynamicConfigs)+len(specs.LayerConfigs))
+	add := func(prefix string, list []configSpec) {
+		for _, spec := range list {
+			hashes[prefix+spec.Name] = spec.hash
+		}
+	}
+	add("gate:", specs.FeatureGates)
+	add("config:", specs.DynamicConfigs)
+	add("layer:", specs.LayerConfigs)
+	return hashes
+}
+
+func diffConfigSpecHashes(previous map[string]uint64, current map[string]uint64) specDelta {
+	delta := specDelta{}
+	for key, hash := range current {
+		previousHash, ok := previous[key]
+		if !ok {
+			delta.added++
+		} else if previousHash != hash {
+			delta.changed++
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			delta.removed++
+		}
+	}
+	return delta
+}
+
+func (s *store) recordConfigSpecSync(bytes int64, parseDuration time.Duration, updated bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sync := ConfigSpecSync{
+		Time:            getUnixMilli(),
+		BytesDownloaded: bytes,
+		ParseDuration:   parseDuration,
+		HasUpdates:      updated,
+	}
+	if updated {
+		sync.SpecsAdded = s.lastSpecDelta.added
+		sync.SpecsRemoved = s.lastSpecDelta.removed
+		sync.SpecsChanged = s.lastSpecDelta.changed
+	}
+	s.syncMetrics.LastSync = sync
+	if updated {
+		s.syncMetrics.LastUpdate = sync
+	}
+	s.syncMetrics.SyncCount++
+	s.syncMetrics.TotalBytesDownloaded += bytes
+	level := logLevelDebug
+	if updated {
+		level = logLevelInfo
+	}
+	Logger().logRecord(level, "Synced config specs",
+		logAttr{"has_updates", updated},
+		logAttr{"time", s.lastSyncTime},
+		logAttr{"bytes_downloaded", bytes},
+		logAttr{"parse_duration", parseDuration},
+		logAttr{"specs_added", sync.SpecsAdded},
+		logAttr{"specs_removed", sync.SpecsRemoved},
+		logAttr{"specs_changed", sync.SpecsChanged},
+	)
+}
+
+func (s *store) getConfigSpecSyncMetrics() ConfigSpecSyncMetrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	metrics := s.syncMetrics
+	metrics.SyncInterval = s.polling.interval
+	return metrics
+}
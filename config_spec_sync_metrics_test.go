@@ -0,0 +1,79 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConfigSpecSyncMetrics(t *testing.T) {
+	// Both payloads are re-serialized so that only the intended edits differ
+	fixture, _ := os.ReadFile("download_config_specs.json")
+	var modified map[string]interface{}
+	_ = json.Unmarshal(fixture, &modified)
+	dcs, _ := json.Marshal(modified)
+	gates := modified["feature_gates"].([]interface{})
+	gates[1].(map[string]interface{})["enabled"] = false
+	modified["feature_gates"] = gates[1:]
+	modified["time"] = 1631638014812
+	modifiedDCS, _ := json.Marshal(modified)
+
+	var serveModified int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			if atomic.CompareAndSwapInt32(&serveModified, 1, 2) {
+				_, _ = res.Write(modifiedDCS)
+			} else if atomic.LoadInt32(&serveModified) == 2 {
+				_, _ = res.Write([]byte("{\"has_updates\":false}"))
+			} else {
+				_, _ = res.Write(dcs)
+			}
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	metrics := c.GetConfigSpecSyncMetrics()
+	if metrics.SyncCount != 1 || metrics.TotalBytesDownloaded != int64(len(dcs)) {
+		t.Errorf("Unexpected metrics after initialize %+v", metrics)
+	}
+	if metrics.LastSync != metrics.LastUpdate || !metrics.LastSync.HasUpdates {
+		t.Errorf("Unexpected initial sync %+v", metrics.LastSync)
+	}
+	if metrics.LastSync.SpecsAdded != 9 || metrics.LastSync.SpecsChanged != 0 || metrics.LastSync.SpecsRemoved != 0 {
+		t.Errorf("Expected all 9 specs to be added, received %+v", metrics.LastSync)
+	}
+
+	atomic.StoreInt32(&serveModified, 1)
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	metrics = c.GetConfigSpecSyncMetrics()
+	last := metrics.LastUpdate
+	if last.SpecsAdded != 0 || last.SpecsChanged != 1 || last.SpecsRemoved != 1 {
+		t.Errorf("Expected 1 changed and 1 removed spec, received %+v", last)
+	}
+	if metrics.LastSync.HasUpdates || metrics.LastSync.Time < last.Time {
+		t.Errorf("Expected the last sync to have no updates, received %+v", metrics.LastSync)
+	}
+	if metrics.SyncCount != 3 || metrics.TotalBytesDownloaded <= int64(len(modifiedDCS)) {
+		t.Errorf("Expected totals to accumulate, received %+v", metrics)
+	}
+	if c.GetSDKStats().ConfigSpecSync.SyncCount != 3 {
+		t.Errorf("Expected sync metrics in SDK stats")
+	}
+}
@@ -0,0 +1,36 @@
+package statsig
+
+import (
+	"net/http"
+)
+
+// The ETag of the last download_config_specs response, sent as If-None-Match so that an unchanged
+// ruleset is confirmed with a 304 instead of being downloaded and parsed again
+type configSpecsETag struct {
+	value        string
+	lastSyncTime int64 // The ruleset the response left in place. Specs applied from other sources invalidate the ETag
+}
+
+func (s *store) getConfigSpecsETag() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.etag.value == "" || s.etag.lastSyncTime != s.lastSyncTime {
+		return ""
+	}
+	return s.etag.value
+}
+
+func (s *store) setConfigSpecsETag(response *http.Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.etag = configSpecsETag{value: response.Header.Get("ETag"), lastSyncTime: s.lastSyncTime}
+}
+
+// Records a sync the server answered with http.StatusNotModified, which leaves the ruleset as is
+func (s *store) recordConfigSpecsNotModified() {
+	s.recordConfigSpecSync(0, 0, false)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.polling.recordSync(false)
+	s.initReason = reasonNetworkNotModified
+}
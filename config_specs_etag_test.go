@@ -0,0 +1,85 @@
+package statsig
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigSpecsETag(t *testing.T) {
+	rulesetAt := func(time int) string {
+		return fmt.Sprintf(`{"has_updates": true, "time": %d, "feature_gates": [
+			{"name": "gate", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": []}],
+			"dynamic_configs": [], "layer_configs": []}`, time)
+	}
+	var mu sync.Mutex
+	version := 1
+	var conditional []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if !strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write([]byte("{}"))
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		etag := fmt.Sprintf(`"v%d"`, version)
+		conditional = append(conditional, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == etag {
+			res.WriteHeader(http.StatusNotModified)
+			return
+		}
+		res.Header().Set("ETag", etag)
+		_, _ = res.Write([]byte(rulesetAt(version)))
+	}))
+	defer testServer.Close()
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		IDListSyncInterval:   time.Hour,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	takeConditional := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		taken := conditional
+		conditional = nil
+		return taken
+	}
+	if sent := takeConditional(); len(sent) != 1 || sent[0] != "" {
+		t.Errorf("Expected the initial sync not to be conditional, received %v", sent)
+	}
+
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	if sent := takeConditional(); len(sent) != 1 || sent[0] != `"v1"` {
+		t.Errorf("Expected the ETag of the served ruleset to be sent, received %v", sent)
+	}
+	metrics := c.GetConfigSpecSyncMetrics()
+	if metrics.SyncCount != 2 || metrics.LastSync.HasUpdates || metrics.LastSync.BytesDownloaded != 0 || metrics.LastUpdate.BytesDownloaded == 0 {
+		t.Errorf("Expected the unchanged ruleset to be neither downloaded nor applied, received %+v", metrics)
+	}
+	if reason := c.evaluator.store.initReason; reason != reasonNetworkNotModified {
+		t.Errorf("Expected evaluations to report the ruleset as not modified, received %s", reason)
+	}
+
+	mu.Lock()
+	version = 2
+	mu.Unlock()
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	if c.evaluator.store.lastSyncTime != 2 {
+		t.Errorf("Expected the changed ruleset to be applied, received time %d", c.evaluator.store.lastSyncTime)
+	}
+	takeConditional()
+
+	// A ruleset applied from another source no longer matches the ETag
+	c.evaluator.store.processConfigSpecs(rulesetAt(3), c.evaluator.store.addDiagnostics().downloadConfigSpecs())
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	if sent := takeConditional(); len(sent) != 1 || sent[0] != "" {
+		t.Errorf("Expected the ETag not to be sent once the ruleset changed, received %v", sent)
+	}
+}
@@ -0,0 +1,117 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// Decodes a download_config_specs payload one spec at a time, so only the decoded specs and the
+// decoder's small buffer are held in memory rather than the whole payload as well.
+// Returns the number of bytes read.
+func decodeConfigSpecsStream(reader io.Reader) (downloadConfigSpecResponse, int64, error) {
+	counter := &countingReader{reader: reader}
+	decoder := json.NewDecoder(counter)
+	specs := downloadConfigSpecResponse{}
+	if err := expectJSONDelim(decoder, '{'); err != nil {
+		return specs, counter.count, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return specs, counter.count, err
+		}
+		key, _ := token.(string)
+		switch key {
+		case "feature_gates":
+			specs.FeatureGates, err = decodeConfigSpecArray(decoder)
+		case "dynamic_configs":
+			specs.DynamicConfigs, err = decodeConfigSpecArray(decoder)
+		case "layer_configs":
+			specs.LayerConfigs, err = decodeConfigSpecArray(decoder)
+		default:
+			err = decodeConfigSpecsField(decoder, key, &specs)
+		}
+		if err != nil {
+			return specs, counter.count, fmt.Errorf("Failed to decode %s: %s", key, err.Error())
+		}
+	}
+	if err := expectJSONDelim(decoder, '}'); err != nil {
+		return specs, counter.count, err
+	}
+	return specs, counter.count, nil
+}
+
+func expectJSONDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("Expected %s, found %v", delim, token)
+	}
+	return nil
+}
+
+func decodeConfigSpecArray(decoder *json.Decoder) ([]configSpec, error) {
+	token, err := decoder.Token()
+	if err != nil || token == nil {
+		return nil, err
+	}
+	if token != json.Delim('[') {
+		return nil, fmt.Errorf("Expected [, found %v", token)
+	}
+	specs := make([]configSpec, 0)
+	for decoder.More() {
+		var spec configSpec
+		if err := decoder.Decode(&spec); err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, expectJSONDelim(decoder, ']')
+}
+
+// The remaining fields are small, so they are decoded whole using downloadConfigSpecResponse's json tags
+func decodeConfigSpecsField(decoder *json.Decoder, key string, specs *downloadConfigSpecResponse) error {
+	var value json.RawMessage
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	field, _ := json.Marshal(map[string]json.RawMessage{key: value})
+	return json.Unmarshal(field, specs)
+}
+
+// Passed to transport.get as the response body to decode network config specs, with decodeConfigSpecsStream when streaming
+type decodedConfigSpecs struct {
+	stream         bool
+	specs          downloadConfigSpecResponse
+	bytes          int64
+	decodeDuration time.Duration
+}
+
+func (d *decodedConfigSpecs) decodeFrom(reader io.Reader) error {
+	start := time.Now()
+	var err error
+	if d.stream {
+		d.specs, d.bytes, err = decodeConfigSpecsStream(reader)
+	} else {
+		counter := &countingReader{reader: reader}
+		err = json.NewDecoder(counter).Decode(&d.specs)
+		d.bytes = counter.count
+	}
+	d.decodeDuration = time.Since(start)
+	return err
+}
@@ -0,0 +1,89 @@
+package statsig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigSpecsStream(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+
+	t.Run("decodes the same specs as json.Unmarshal", func(t *testing.T) {
+		var expected downloadConfigSpecResponse
+		_ = json.Unmarshal(specs, &expected)
+		streamed, read, err := decodeConfigSpecsStream(bytes.NewReader(specs))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if !reflect.DeepEqual(streamed, expected) {
+			t.Errorf("Expected streamed specs to match json.Unmarshal")
+		}
+		if read != int64(len(specs)) {
+			t.Errorf("Expected %d bytes to be read, received %d", len(specs), read)
+		}
+		if _, _, err := decodeConfigSpecsStream(strings.NewReader(`{"feature_gates": {}}`)); err == nil {
+			t.Errorf("Expected an error for a malformed spec array")
+		}
+	})
+
+	t.Run("bootstraps from a reader", func(t *testing.T) {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		c := NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapReader:      bytes.NewReader(specs),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+		defer c.Shutdown()
+		if details := c.GetInitializeDetails(); details.Error != nil || details.Source != string(reasonBootstrap) {
+			t.Errorf("Expected a successful bootstrap, received %+v", details)
+		}
+		if !c.CheckGate(User{UserID: "a"}, "always_on_gate") {
+			t.Errorf("Expected gates from the reader to be evaluated")
+		}
+	})
+
+	t.Run("reports malformed readers as a BootstrapParseError", func(t *testing.T) {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		c := NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapReader:      strings.NewReader(`{"feature_gates": [{`),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+		defer c.Shutdown()
+		var parseErr *BootstrapParseError
+		if details := c.GetInitializeDetails(); !errors.As(details.Error, &parseErr) {
+			t.Errorf("Expected a BootstrapParseError, received %v", details.Error)
+		}
+	})
+
+	t.Run("streams config specs from the network", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if strings.Contains(req.URL.Path, "download_config_specs") {
+				_, _ = res.Write(specs)
+			} else if strings.Contains(req.URL.Path, "get_id_lists") {
+				_, _ = res.Write([]byte("{}"))
+			}
+		}))
+		defer testServer.Close()
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		c := NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			StreamConfigSpecs:    true,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+		defer c.Shutdown()
+		if details := c.GetInitializeDetails(); details.Source != string(reasonNetwork) {
+			t.Errorf("Expected specs from the network, received %+v", details)
+		}
+		if !c.CheckGate(User{UserID: "a"}, "always_on_gate") {
+			t.Errorf("Expected streamed gates to be evaluated")
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package statsig
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	configSyncStreamSpecsEvent      = "config_specs" // Data is a download_config_specs response, applied as is
+	configSyncStreamInvalidateEvent = "invalidate"   // The ruleset changed, download it with download_config_specs
+)
+
+var errConfigSyncStreamNotFound = errors.New("http response error code: 404")
+
+// A Server-Sent Events connection to ConfigSyncStreamOptions.URL, pushing ruleset changes as they happen.
+// Polling is paused while it is connected, and resumes until it reconnects.
+type configSyncStream struct {
+	url            *url.URL
+	client         *http.Client // Without a timeout, the connection is held open until idleTimeout passes without data
+	reconnectDelay time.Duration
+	idleTimeout    time.Duration
+	connected      int32
+	stopped        bool
+	cancel         context.CancelFunc
+	mu             sync.Mutex
+}
+
+func newConfigSyncStream(options *Options) *configSyncStream {
+	streamOptions := options.ConfigSyncStreamOptions
+	if streamOptions.URL == "" || options.LocalMode {
+		return nil
+	}
+	streamURL, err := url.Parse(streamOptions.URL)
+	if err != nil || streamURL.Host == "" {
+		Logger().LogError(fmt.Sprintf("Invalid config sync stream URL %q, polling for config specs instead\n", streamOptions.URL))
+		return nil
+	}
+	reconnectDelay := streamOptions.ReconnectDelay
+	if reconnectDelay <= 0 {
+		reconnectDelay = 5 * time.Second
+	}
+	idleTimeout := streamOptions.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = time.Minute
+	}
+	return &configSyncStream{url: streamURL, client: newHTTPClient(options, 0), reconnectDelay: reconnectDelay, idleTimeout: idleTimeout}
+}
+
+func (c *configSyncStream) isConnected() bool {
+	return c != nil && atomic.LoadInt32(&c.connected) == 1
+}
+
+func (c *configSyncStream) setConnected(connected bool) {
+	var value int32
+	if connected {
+		value = 1
+	}
+	atomic.StoreInt32(&c.connected, value)
+}
+
+// Returns a context cancelled by stop, or false once stopped
+func (c *configSyncStream) start() (context.Context, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return nil, false
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	return ctx, true
+}
+
+func (c *configSyncStream) stop() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// Keeps the stream connected until shutdown, waiting ReconnectDelay after each disconnection.
+// A URL that is not found is not retried, config specs are polled for instead
+func (s *store) streamConfigSpecs() {
+	defer s.pollers.Done()
+	for !s.isShutdown() {
+		ctx, ok := s.syncStream.start()
+		if !ok {
+			return
+		}
+		err := s.readConfigSyncStream(ctx)
+		s.syncStream.setConnected(false)
+		if s.isShutdown() {
+			return
+		}
+		if err == errConfigSyncStreamNotFound {
+			Logger().LogError(fmt.Sprintf("Config sync stream %s was not found, polling for config specs instead\n", s.syncStream.url.Redacted()))
+			return
+		}
+		if err == nil {
+			err = io.EOF
+		}
+		Logger().LogStep(StatsigProcessSync, fmt.Sprintf("Config sync stream disconnected, polling until it reconnects: %s", err.Error()))
+		if !s.waitForNextPoll(s.syncStream.reconnectDelay) {
+			return
+		}
+	}
+}
+
+func (s *store) readConfigSyncStream(ctx context.Context) error {
+	s.mu.RLock()
+	sinceTime := s.lastSyncTime
+	s.mu.RUnlock()
+	// Each line handled, keep-alive comments included, pushes back the idle deadline. Reaching it cancels
+	// the request, so a connection that silently stopped delivering data falls back to polling
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var idle int32
+	idleTimer := time.AfterFunc(s.syncStream.idleTimeout, func() {
+		atomic.StoreInt32(&idle, 1)
+		cancel()
+	})
+	defer idleTimer.Stop()
+	err := s.readConfigSyncStreamEvents(ctx, sinceTime, func() { idleTimer.Reset(s.syncStream.idleTimeout) })
+	if atomic.LoadInt32(&idle) == 1 {
+		return fmt.Errorf("No data received for %s", s.syncStream.idleTimeout)
+	}
+	return err
+}
+
+func (s *store) readConfigSyncStreamEvents(ctx context.Context, sinceTime int64, received func()) error {
+	req, err := s.transport.buildRequest(ctx, "GET", "", nil)
+	if req == nil || err != nil {
+		return err
+	}
+	// Sent with the SDK's headers, to the stream URL rather than API
+	streamURL := *s.syncStream.url
+	query := streamURL.Query()
+	query.Set("sinceTime", strconv.FormatInt(sinceTime, 10))
+	streamURL.RawQuery = query.Encode()
+	req.URL, req.Host = &streamURL, streamURL.Host
+	req.Header.Set("Accept", "text/event-stream")
+	// Events are read line by line as they arrive, without the decompression and size cap of other responses
+	req.Header.Set("Accept-Encoding", "identity")
+	res, err := s.syncStream.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return errConfigSyncStreamNotFound
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("http response error code: %d", res.StatusCode)
+	}
+	if encoding := strings.TrimSpace(res.Header.Get("Content-Encoding")); encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return fmt.Errorf("Unsupported content encoding %q in config sync stream", encoding)
+	}
+	s.syncStream.setConnected(true)
+	// Catch up on changes made while disconnected
+	s.fetchConfigSpecsFromServer(false)
+	received()
+
+	reader := bufio.NewReader(res.Body)
+	event, data := "", []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if len(data) > 0 || event != "" {
+				s.handleConfigSyncStreamEvent(event, strings.Join(data, "\n"))
+			}
+			event, data = "", data[:0]
+		case strings.HasPrefix(line, ":"):
+			// Comments keep the connection alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		received()
+	}
+}
+
+func (s *store) handleConfigSyncStreamEvent(event string, data string) {
+	switch event {
+	case configSyncStreamSpecsEvent:
+		parseStart := time.Now()
+		var specs downloadConfigSpecResponse
+		if err := json.Unmarshal([]byte(data), &specs); err != nil {
+			Logger().LogError(fmt.Errorf("Failed to parse config specs from the config sync stream, downloading them instead: %s", err.Error()))
+			s.fetchConfigSpecsFromServer(false)
+			return
+		}
+		s.applyNetworkConfigSpecs(specs, int64(len(data)), time.Since(parseStart))
+	case configSyncStreamInvalidateEvent:
+		s.fetchConfigSpecsFromServer(false)
+	}
+}
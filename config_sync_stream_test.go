@@ -0,0 +1,184 @@
+package statsig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConfigSyncStream(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	withAlwaysOnGate := func(enabled bool, time int64) string {
+		var parsed map[string]interface{}
+		_ = json.Unmarshal(specs, &parsed)
+		parsed["time"] = time
+		for _, gate := range parsed["feature_gates"].([]interface{}) {
+			if gate.(map[string]interface{})["name"] == "always_on_gate" {
+				gate.(map[string]interface{})["enabled"] = enabled
+			}
+		}
+		bytes, _ := json.Marshal(parsed)
+		return string(bytes)
+	}
+
+	type testStreamServer struct {
+		*httptest.Server
+		dcs         atomic.Value
+		dcsRequests int32
+		connections int32
+		events      chan string
+	}
+	newStreamServer := func(status int) *testStreamServer {
+		server := &testStreamServer{events: make(chan string, 10)}
+		server.dcs.Store(string(specs))
+		server.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			switch {
+			case strings.Contains(req.URL.Path, "config_specs_stream"):
+				atomic.AddInt32(&server.connections, 1)
+				if req.Header.Get("STATSIG-API-KEY") != "secret-key" || !strings.Contains(req.URL.RawQuery, "sinceTime=") {
+					t.Errorf("Expected an authenticated stream request, received %s", req.URL.String())
+				}
+				if encoding := req.Header.Get("Accept-Encoding"); encoding != "identity" {
+					t.Errorf("Expected the stream request not to accept compressed events, received %q", encoding)
+				}
+				if status != 0 {
+					res.WriteHeader(status)
+					return
+				}
+				res.Header().Set("Content-Type", "text/event-stream")
+				_, _ = res.Write([]byte(": connected\n\n"))
+				res.(http.Flusher).Flush()
+				for {
+					select {
+					case event := <-server.events:
+						_, _ = res.Write([]byte(event))
+						res.(http.Flusher).Flush()
+					case <-req.Context().Done():
+						return
+					}
+				}
+			case strings.Contains(req.URL.Path, "download_config_specs"):
+				atomic.AddInt32(&server.dcsRequests, 1)
+				_, _ = res.Write([]byte(server.dcs.Load().(string)))
+			default:
+				_, _ = res.Write([]byte("{}"))
+			}
+		}))
+		return server
+	}
+	newStreamClientWithOptions := func(server *testStreamServer, syncInterval time.Duration, streamOptions ConfigSyncStreamOptions) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		streamOptions.URL = server.URL + "/config_specs_stream"
+		return NewClientWithOptions("secret-key", &Options{
+			API:                     server.URL,
+			ConfigSyncInterval:      syncInterval,
+			StatsigLoggerOptions:    getStatsigLoggerOptionsForTest(t),
+			ConfigSyncStreamOptions: streamOptions,
+		})
+	}
+	newStreamClient := func(server *testStreamServer, syncInterval time.Duration) *Client {
+		return newStreamClientWithOptions(server, syncInterval, ConfigSyncStreamOptions{ReconnectDelay: 20 * time.Millisecond})
+	}
+	user := User{UserID: "123"}
+
+	t.Run("applies pushed config specs and invalidations", func(t *testing.T) {
+		server := newStreamServer(0)
+		defer server.Close()
+		c := newStreamClient(server, time.Hour)
+		defer c.Shutdown()
+		waitForConditionWithMessage(t, c.evaluator.store.syncStream.isConnected, "Expected the stream to connect")
+		if !c.CheckGate(user, "always_on_gate") {
+			t.Fatalf("Expected the initial ruleset to be used")
+		}
+
+		server.events <- fmt.Sprintf("event: config_specs\ndata: %s\n\n", withAlwaysOnGate(false, 1631638014812))
+		waitForConditionWithMessage(t, func() bool { return !c.CheckGate(user, "always_on_gate") }, "Expected the pushed ruleset to be applied")
+
+		server.dcs.Store(withAlwaysOnGate(true, 1631638014813))
+		server.events <- ": keepalive\n\nevent: invalidate\ndata: {}\n\n"
+		waitForConditionWithMessage(t, func() bool { return c.CheckGate(user, "always_on_gate") }, "Expected an invalidation to download the ruleset")
+		if c.GetInitializeDetails().Source != string(reasonNetwork) {
+			t.Errorf("Expected the ruleset to be reported as from the network")
+		}
+	})
+
+	t.Run("polls while the stream is unavailable and retries it", func(t *testing.T) {
+		server := newStreamServer(503)
+		defer server.Close()
+		c := newStreamClient(server, 20*time.Millisecond)
+		defer c.Shutdown()
+		waitForConditionWithMessage(t, func() bool { return atomic.LoadInt32(&server.connections) >= 3 }, "Expected the stream to reconnect")
+		server.dcs.Store(withAlwaysOnGate(false, 1631638014812))
+		waitForConditionWithMessage(t, func() bool { return !c.CheckGate(user, "always_on_gate") }, "Expected polling to continue while the stream is disconnected")
+	})
+
+	t.Run("pauses polling while connected", func(t *testing.T) {
+		server := newStreamServer(0)
+		defer server.Close()
+		c := newStreamClient(server, 20*time.Millisecond)
+		waitForConditionWithMessage(t, c.evaluator.store.syncStream.isConnected, "Expected the stream to connect")
+		time.Sleep(100 * time.Millisecond)
+		requests := atomic.LoadInt32(&server.dcsRequests)
+		time.Sleep(200 * time.Millisecond)
+		if after := atomic.LoadInt32(&server.dcsRequests); after != requests {
+			t.Errorf("Expected no polling while connected, received %d more requests", after-requests)
+		}
+		c.Shutdown()
+		waitForConditionWithMessage(t, func() bool { return !c.evaluator.store.syncStream.isConnected() }, "Expected Shutdown to close the stream")
+	})
+
+	t.Run("drops a stream that stops sending data", func(t *testing.T) {
+		server := newStreamServer(0)
+		defer server.Close()
+		// The server sends one keep-alive comment when connecting, then nothing
+		c := newStreamClientWithOptions(server, time.Hour, ConfigSyncStreamOptions{ReconnectDelay: 20 * time.Millisecond, IdleTimeout: 50 * time.Millisecond})
+		defer c.Shutdown()
+		waitForConditionWithMessage(t, func() bool { return atomic.LoadInt32(&server.connections) >= 3 }, "Expected idle streams to be dropped and reconnected")
+	})
+
+	t.Run("stops waiting to reconnect on shutdown", func(t *testing.T) {
+		server := newStreamServer(503)
+		defer server.Close()
+		c := newStreamClientWithOptions(server, time.Hour, ConfigSyncStreamOptions{ReconnectDelay: time.Hour})
+		waitForConditionWithMessage(t, func() bool { return atomic.LoadInt32(&server.connections) == 1 }, "Expected the stream to connect")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := c.ShutdownCtx(ctx); err != nil {
+			t.Errorf("Expected shutdown to interrupt the reconnect delay, received %s", err.Error())
+		}
+	})
+
+	t.Run("polls without retrying a stream that is not found", func(t *testing.T) {
+		server := newStreamServer(404)
+		defer server.Close()
+		c := newStreamClient(server, 20*time.Millisecond)
+		defer c.Shutdown()
+		waitForConditionWithMessage(t, func() bool { return atomic.LoadInt32(&server.connections) == 1 }, "Expected the stream to connect")
+		server.dcs.Store(withAlwaysOnGate(false, 1631638014812))
+		waitForConditionWithMessage(t, func() bool { return !c.CheckGate(user, "always_on_gate") }, "Expected config specs to be polled for")
+		// Retries would follow every ReconnectDelay
+		time.Sleep(100 * time.Millisecond)
+		if connections := atomic.LoadInt32(&server.connections); connections != 1 {
+			t.Errorf("Expected the stream not to be retried, received %d connections", connections)
+		}
+	})
+
+	t.Run("is disabled by default, in local mode and with an invalid URL", func(t *testing.T) {
+		if newConfigSyncStream(&Options{}) != nil {
+			t.Errorf("Expected no stream without a URL")
+		}
+		if newConfigSyncStream(&Options{LocalMode: true, ConfigSyncStreamOptions: ConfigSyncStreamOptions{URL: "http://localhost/config_specs_stream"}}) != nil {
+			t.Errorf("Expected no stream in local mode")
+		}
+		if newConfigSyncStream(&Options{ConfigSyncStreamOptions: ConfigSyncStreamOptions{URL: "config_specs_stream"}}) != nil {
+			t.Errorf("Expected no stream with an invalid URL")
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type configWatcher struct {
+	name     string
+	target   interface{}
+	onChange func(err error)
+	mu       sync.Mutex // Serializes decodes and swaps into target
+}
+
+type configWatchers struct {
+	watchers []*configWatcher
+	mu       sync.RWMutex
+}
+
+func (w *configWatchers) add(watcher *configWatcher) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watchers = append(w.watchers, watcher)
+}
+
+func (w *configWatchers) remove(watcher *configWatcher) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	watchers := make([]*configWatcher, 0, len(w.watchers))
+	for _, existing := range w.watchers {
+		if existing != watcher {
+			watchers = append(watchers, existing)
+		}
+	}
+	w.watchers = watchers
+}
+
+func (w *configWatchers) list() []*configWatcher {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.watchers
+}
+
+// Decodes the config's default value into a fresh value of the target's type, swaps it into the
+// watcher's target only if decoding succeeded, and reports the result to onChange
+func (s *store) decodeWatchedConfig(watcher *configWatcher) {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+	config, ok := s.getDynamicConfig(watcher.name)
+	target := reflect.ValueOf(watcher.target)
+	var err error
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		err = fmt.Errorf("WatchConfig target for %s must be a non-nil pointer", watcher.name)
+	} else if !ok {
+		err = fmt.Errorf("Config %s is not in the ruleset", watcher.name)
+	} else {
+		decoded := reflect.New(target.Elem().Type())
+		if err = json.Unmarshal(config.DefaultValue, decoded.Interface()); err != nil {
+			err = fmt.Errorf("Failed to decode config %s: %s", watcher.name, err.Error())
+		} else {
+			target.Elem().Set(decoded.Elem())
+		}
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			Logger().LogError(fmt.Sprintf("WatchConfig onChange for %s panicked: %s\n", watcher.name, toError(recovered).Error()))
+		}
+	}()
+	watcher.onChange(err)
+}
+
+// Called after new specs are applied with the spec hashes from before and after the update
+func (s *store) notifyConfigWatchers(previous map[string]uint64, current map[string]uint64) {
+	for _, watcher := range s.configWatchers.list() {
+		key := "config:" + watcher.name
+		previousHash, hadConfig := previous[key]
+		currentHash, hasConfig := current[key]
+		if hadConfig != hasConfig || previousHash != currentHash {
+			s.decodeWatchedConfig(watcher)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchConfig(t *testing.T) {
+	fixture, _ := os.ReadFile("download_config_specs.json")
+	var specs map[string]interface{}
+	_ = json.Unmarshal(fixture, &specs)
+	dcs, _ := json.Marshal(specs)
+	// Only test_config changes, the gate edit must not trigger a decode
+	specs["feature_gates"].([]interface{})[0].(map[string]interface{})["enabled"] = false
+	unrelatedDCS, _ := json.Marshal(specs)
+	specs["dynamic_configs"].([]interface{})[0].(map[string]interface{})["defaultValue"] = map[string]interface{}{
+		"number": 12, "string": "updated", "boolean": false,
+	}
+	modifiedDCS, _ := json.Marshal(specs)
+
+	var payload atomic.Value
+	payload.Store(dcs)
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write(payload.Load().([]byte))
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	type settings struct {
+		Number int    `json:"number"`
+		String string `json:"string"`
+	}
+	var mu sync.Mutex
+	var target settings
+	var results []error
+	unwatch := c.WatchConfig("test_config", &target, func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, err)
+	})
+	mu.Lock()
+	if len(results) != 1 || results[0] != nil || target.Number != 4 || target.String != "default" {
+		t.Errorf("Expected the initial decode, received %+v %v", target, results)
+	}
+	mu.Unlock()
+
+	payload.Store(unrelatedDCS)
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	payload.Store(modifiedDCS)
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	mu.Lock()
+	if len(results) != 2 || results[1] != nil || target.Number != 12 || target.String != "updated" {
+		t.Errorf("Expected one decode for the change, received %+v %v", target, results)
+	}
+	mu.Unlock()
+
+	// A failed decode leaves the previous value in place rather than a partial one
+	type mismatched struct {
+		Number  int  `json:"number"`
+		Boolean bool `json:"boolean"`
+		String  int  `json:"string"`
+	}
+	previous := mismatched{Number: 1, Boolean: true, String: 2}
+	decoded := previous
+	var decodeErr error
+	c.WatchConfig("test_config", &decoded, func(err error) { decodeErr = err })
+	if decodeErr == nil || decoded != previous {
+		t.Errorf("Expected a failed decode to leave the target unchanged, received %+v %v", decoded, decodeErr)
+	}
+
+	unwatch()
+	payload.Store(dcs)
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	mu.Lock()
+	if len(results) != 2 || target.Number != 12 {
+		t.Errorf("Expected no decodes after unwatching, received %+v %v", target, results)
+	}
+	mu.Unlock()
+
+	var missing settings
+	var missingErr error
+	c.WatchConfig("not_a_config", &missing, func(err error) { missingErr = err })
+	if missingErr == nil {
+		t.Errorf("Expected an error for a config not in the ruleset")
+	}
+}
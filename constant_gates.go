@@ -0,0 +1,55 @@
+package statsig
+
+import (
+	"strings"
+)
+
+// The result every user gets from a gate that does not depend on the user, computed when the
+// ruleset is ingested so checks skip the evaluator
+type constantGate struct {
+	result evalResult
+}
+
+// Detects gates whose result is the same for every user: disabled gates, gates without rules, and gates
+// whose first rule passes everyone, e.g. kill switches, with a pass percentage of 0 or 100 and no variants.
+// Gates keyed by a custom ID are excluded, as users lacking the ID get the default value.
+func compileConstantGate(spec configSpec) *constantGate {
+	if !spec.Enabled {
+		return &constantGate{result: evalResult{RuleID: RuleIDDisabled, IDType: spec.IDType, Variant: spec.DefaultVariant}}
+	}
+	if spec.IDType != "" && strings.ToLower(spec.IDType) != "userid" {
+		return nil
+	}
+	if len(spec.Rules) == 0 {
+		return &constantGate{result: evalResult{RuleID: RuleIDDefault, IDType: spec.IDType, Variant: spec.DefaultVariant}}
+	}
+	rule := spec.Rules[0]
+	if rule.ConfigDelegate != "" || len(rule.Variants) > 0 || (rule.PassPercentage != 0 && rule.PassPercentage != 100) {
+		return nil
+	}
+	for _, cond := range rule.Conditions {
+		if strings.ToLower(cond.Type) != "public" {
+			return nil
+		}
+	}
+	return &constantGate{
+		result: evalResult{
+			Pass:      rule.PassPercentage == 100,
+			RuleID:    rule.ID,
+			GroupName: rule.GroupName,
+			IDType:    spec.IDType,
+			Variant:   spec.DefaultVariant,
+		},
+	}
+}
+
+// A copy of the constant result, as callers append to exposures and may keep the result
+func (e *evaluator) evalConstantGate(gate *constantGate) *evalResult {
+	result := gate.result
+	result.SecondaryExposures = make([]map[string]string, 0)
+	e.store.mu.RLock()
+	reason := e.store.initReason
+	e.store.mu.RUnlock()
+	result.EvaluationDetails = e.createEvaluationDetails(reason)
+	return &result
+}
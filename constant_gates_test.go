@@ -0,0 +1,83 @@
+package statsig
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const constantGateSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [
+		{"name": "kill_switch", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "on", "id": "on_rule", "groupName": "everyone", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}]},
+		{"name": "rolled_back", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "off", "id": "off_rule", "salt": "s", "passPercentage": 0, "returnValue": true, "conditions": [{"type": "public"}]},
+			{"name": "emails", "id": "email_rule", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "user_field", "field": "email", "operator": "any", "targetValue": ["a@b.com"]}]}]},
+		{"name": "disabled", "type": "feature_gate", "salt": "s", "enabled": false, "defaultValue": false, "defaultVariant": "control", "rules": [
+			{"name": "on", "id": "on_rule", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}]},
+		{"name": "no_rules", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": []},
+		{"name": "partial_rollout", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "half", "id": "half_rule", "salt": "s", "passPercentage": 50, "returnValue": true, "conditions": [{"type": "public"}]}]},
+		{"name": "with_variants", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "on", "id": "on_rule", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}], "variants": [{"name": "a", "weight": 1}]}]},
+		{"name": "targeted", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "emails", "id": "email_rule", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "user_field", "field": "email", "operator": "any", "targetValue": ["a@b.com"]}]},
+			{"name": "on", "id": "on_rule", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}]},
+		{"name": "by_company", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "idType": "companyID", "rules": [
+			{"name": "on", "id": "on_rule", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}]}
+	],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+func TestConstantGates(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      constantGateSpecs,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	store := c.evaluator.store
+
+	constant := map[string]bool{"kill_switch": true, "rolled_back": true, "disabled": true, "no_rules": true}
+	users := []User{{UserID: "1"}, {UserID: "2", Email: "a@b.com"}, {CustomIDs: map[string]string{"companyID": "c"}}}
+	for _, name := range []string{"kill_switch", "rolled_back", "disabled", "no_rules", "partial_rollout", "with_variants", "targeted", "by_company"} {
+		gate, _ := store.getGate(name)
+		if (gate.constant != nil) != constant[name] {
+			t.Errorf("Expected %s to be detected as constant: %v", name, constant[name])
+		}
+		if gate.constant == nil {
+			continue
+		}
+		for _, user := range users {
+			cached, evaluated := c.evaluator.evalGate(user, name, 0), c.evaluator.eval(user, gate, 1)
+			if cached.Pass != evaluated.Pass || cached.RuleID != evaluated.RuleID || cached.GroupName != evaluated.GroupName ||
+				cached.Variant != evaluated.Variant || cached.IDType != evaluated.IDType ||
+				cached.EvaluationDetails == nil || evaluated.EvaluationDetails == nil || cached.EvaluationDetails.reason != evaluated.EvaluationDetails.reason {
+				t.Errorf("Expected the cached result of %s to match evaluation, received %+v and %+v", name, cached, evaluated)
+			}
+		}
+	}
+	gate := c.GetGate(User{UserID: "1"}, "kill_switch")
+	if !gate.Value || gate.RuleID != "on_rule" || gate.GroupName != "everyone" {
+		t.Errorf("Unexpected kill switch result %+v", gate)
+	}
+
+	c.OverrideGate("kill_switch", false)
+	if c.CheckGate(User{UserID: "1"}, "kill_switch") {
+		t.Errorf("Expected overrides to take precedence over the cached result")
+	}
+	c.RemoveGateOverride("kill_switch")
+
+	// Recomputed when the next ruleset is ingested
+	var specs downloadConfigSpecResponse
+	_ = json.Unmarshal([]byte(strings.Replace(constantGateSpecs, `"passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}]},`, `"passPercentage": 0, "returnValue": true, "conditions": [{"type": "public"}]}]},`, 1)), &specs)
+	specs.Time = 2
+	store.setConfigSpecs(specs)
+	if c.CheckGate(User{UserID: "1"}, "kill_switch") {
+		t.Errorf("Expected the kill switch to be off after the next sync")
+	}
+}
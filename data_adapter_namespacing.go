@@ -0,0 +1,191 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// Records which SDK key a namespace belongs to, so clients sharing a data adapter detect collisions
+const NAMESPACE_OWNER_KEY = "statsig.namespace_owner"
+
+// Isolates the keys of Options.DataAdapter per SDK key, for adapters shared by several projects
+type AdapterNamespaceOptions struct {
+	Enabled           bool
+	Namespace         string // Prefixed to every key. Derived from the SDK key when empty
+	MigrateLegacyKeys bool   // Copies values saved without a namespace into it on initialization, if they belong to this SDK key
+}
+
+// Passed to Options.OnSDKError when a data adapter namespace is owned by another SDK key.
+// The data adapter is then ignored, and config specs are only fetched from the network.
+type DataAdapterNamespaceError struct {
+	Namespace string
+}
+
+func (e *DataAdapterNamespaceError) Error() string {
+	return fmt.Sprintf("Data adapter namespace %s belongs to another SDK key. Ignoring the data adapter", e.Namespace)
+}
+
+// Keys the SDK writes without a namespace, which MigrateDataAdapterNamespace copies. Per list ID list keys are found through ID_LISTS_KEY.
+var legacyDataAdapterKeys = []string{CONFIG_SPECS_KEY, ID_LISTS_KEY, SYNC_METADATA_KEY, FAILSAFE_EVALUATIONS_KEY}
+
+type namespacedDataAdapter struct {
+	adapter      IDataAdapter
+	namespace    string
+	owner        string
+	hashedSDKKey string
+	options      *Options
+	collided     int32
+}
+
+func newNamespacedDataAdapter(adapter IDataAdapter, sdkKey string, options *Options) IDataAdapter {
+	if adapter == nil || !options.AdapterNamespaceOptions.Enabled {
+		return adapter
+	}
+	return &namespacedDataAdapter{
+		adapter:      adapter,
+		namespace:    dataAdapterNamespace(sdkKey, options.AdapterNamespaceOptions),
+		owner:        getHashBase64StringEncoding(sdkKey),
+		hashedSDKKey: getDJB2Hash(sdkKey),
+		options:      options,
+	}
+}
+
+func dataAdapterNamespace(sdkKey string, options AdapterNamespaceOptions) string {
+	if options.Namespace != "" {
+		return options.Namespace
+	}
+	return fmt.Sprintf("statsig.%s", getDJB2Hash(sdkKey))
+}
+
+func namespacedKey(namespace string, key string) string {
+	return fmt.Sprintf("%s::%s", namespace, key)
+}
+
+// Claims the namespace for this SDK key, then migrates legacy keys into it if enabled
+func (d *namespacedDataAdapter) Initialize() {
+	d.adapter.Initialize()
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("Error initializing data adapter namespace %s: %s\n", d.namespace, toError(err).Error()))
+		}
+	}()
+	if err := claimDataAdapterNamespace(d.adapter, d.namespace, d.owner); err != nil {
+		atomic.StoreInt32(&d.collided, 1)
+		Logger().LogError(err)
+		reportSDKError(d.options, err)
+		return
+	}
+	if d.options.AdapterNamespaceOptions.MigrateLegacyKeys {
+		if _, err := migrateDataAdapterKeys(d.adapter, d.namespace, d.hashedSDKKey); err != nil {
+			Logger().LogError(fmt.Sprintf("Failed to migrate data adapter keys into namespace %s: %s\n", d.namespace, err.Error()))
+		}
+	}
+}
+
+func (d *namespacedDataAdapter) Shutdown() {
+	d.adapter.Shutdown()
+}
+
+func (d *namespacedDataAdapter) Get(key string) string {
+	if d.isCollided() {
+		return ""
+	}
+	return d.adapter.Get(namespacedKey(d.namespace, key))
+}
+
+func (d *namespacedDataAdapter) Set(key string, value string) {
+	if d.isCollided() {
+		return
+	}
+	d.adapter.Set(namespacedKey(d.namespace, key), value)
+}
+
+// Asked with the key without its namespace, so adapters can keep comparing against CONFIG_SPECS_KEY and ID_LISTS_KEY
+func (d *namespacedDataAdapter) ShouldBeUsedForQueryingUpdates(key string) bool {
+	if d.isCollided() {
+		return false
+	}
+	return d.adapter.ShouldBeUsedForQueryingUpdates(key)
+}
+
+func (d *namespacedDataAdapter) isCollided() bool {
+	return atomic.LoadInt32(&d.collided) == 1
+}
+
+/**
+ * Optionally implemented by an IDataAdapter that can write a key only if it is unset, e.g. with
+ * Redis SETNX, so namespaces are claimed atomically. Returns false if the key was already set
+ */
+type IDataAdapterWithSetIfAbsent interface {
+	SetIfAbsent(key string, value string) bool
+}
+
+// Best effort unless the adapter implements IDataAdapterWithSetIfAbsent. Otherwise two SDK keys claiming
+// an unowned namespace at once can both find it empty, so the owner is read back after writing it and
+// the key that lost the race reports the collision. Writes interleaved between another key's write and
+// read back still go undetected until that key next initializes
+func claimDataAdapterNamespace(adapter IDataAdapter, namespace string, owner string) error {
+	key := namespacedKey(namespace, NAMESPACE_OWNER_KEY)
+	if atomicAdapter, ok := adapter.(IDataAdapterWithSetIfAbsent); ok && atomicAdapter.SetIfAbsent(key, owner) {
+		return nil
+	}
+	existing := adapter.Get(key)
+	if existing == "" {
+		adapter.Set(key, owner)
+		existing = adapter.Get(key)
+	}
+	if existing != owner {
+		return &DataAdapterNamespaceError{Namespace: namespace}
+	}
+	return nil
+}
+
+/**
+ * Copies the values an SDK without AdapterNamespaceOptions saved to adapter into the
+ * namespace of sdkKey, returning the number of keys copied. Values already in the namespace
+ * are kept. Config specs generated for another SDK key are refused, as are namespaces owned
+ * by another SDK key. Set AdapterNamespaceOptions.MigrateLegacyKeys to migrate on initialization instead.
+ */
+func MigrateDataAdapterNamespace(adapter IDataAdapter, sdkKey string, options AdapterNamespaceOptions) (int, error) {
+	namespace := dataAdapterNamespace(sdkKey, options)
+	if err := claimDataAdapterNamespace(adapter, namespace, getHashBase64StringEncoding(sdkKey)); err != nil {
+		return 0, err
+	}
+	return migrateDataAdapterKeys(adapter, namespace, getDJB2Hash(sdkKey))
+}
+
+func migrateDataAdapterKeys(adapter IDataAdapter, namespace string, hashedSDKKey string) (migrated int, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = toError(recovered)
+		}
+	}()
+	specs := adapter.Get(CONFIG_SPECS_KEY)
+	if specs != "" {
+		var parsed downloadConfigSpecResponse
+		if err := json.Unmarshal([]byte(specs), &parsed); err != nil {
+			return 0, err
+		}
+		if parsed.HashedSDKKeyUsed != "" && parsed.HashedSDKKeyUsed != hashedSDKKey {
+			return 0, fmt.Errorf("Config specs without a namespace were generated for another SDK key. Expected %s, got %s", hashedSDKKey, parsed.HashedSDKKeyUsed)
+		}
+	}
+	keys := append([]string{}, legacyDataAdapterKeys...)
+	var idLists map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(adapter.Get(ID_LISTS_KEY)), &idLists); err == nil {
+		for name := range idLists {
+			keys = append(keys, fmt.Sprintf("%s::%s", ID_LISTS_KEY, name))
+		}
+	}
+	for _, key := range keys {
+		value := adapter.Get(key)
+		target := namespacedKey(namespace, key)
+		if value == "" || adapter.Get(target) != "" {
+			continue
+		}
+		adapter.Set(target, value)
+		migrated++
+	}
+	return migrated, nil
+}
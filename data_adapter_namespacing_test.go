@@ -0,0 +1,177 @@
+package statsig
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDataAdapterNamespacing(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	idLists, _ := os.ReadFile("test_data/get_id_lists.json")
+	list, _ := os.ReadFile("test_data/list_1.txt")
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write(specs)
+			return
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	newClient := func(sdkKey string, adapter IDataAdapter, namespaceOptions AdapterNamespaceOptions, onSDKError func(err error)) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions(sdkKey, &Options{
+			API:                     testServer.URL,
+			DataAdapter:             adapter,
+			AdapterNamespaceOptions: namespaceOptions,
+			OnSDKError:              onSDKError,
+			StatsigLoggerOptions:    getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	user := User{UserID: "statsig_user", Email: "statsiguser@statsig.com"}
+
+	t.Run("saves each SDK key under its own namespace", func(t *testing.T) {
+		adapter := &dataAdapterExample{store: make(map[string]string)}
+		enabled := AdapterNamespaceOptions{Enabled: true}
+		first := newClient("secret-key", adapter, enabled, nil)
+		defer first.Shutdown()
+		second := newClient("secret-other-key", adapter, enabled, nil)
+		defer second.Shutdown()
+
+		if adapter.Get(CONFIG_SPECS_KEY) != "" {
+			t.Errorf("Expected no config specs to be saved without a namespace")
+		}
+		for _, sdkKey := range []string{"secret-key", "secret-other-key"} {
+			namespace := dataAdapterNamespace(sdkKey, enabled)
+			if adapter.Get(namespacedKey(namespace, CONFIG_SPECS_KEY)) == "" {
+				t.Errorf("Expected config specs to be saved in namespace %s", namespace)
+			}
+			if adapter.Get(namespacedKey(namespace, NAMESPACE_OWNER_KEY)) != getHashBase64StringEncoding(sdkKey) {
+				t.Errorf("Expected namespace %s to be owned by its SDK key", namespace)
+			}
+		}
+	})
+
+	t.Run("ignores a namespace owned by another SDK key", func(t *testing.T) {
+		adapter := &dataAdapterExample{store: make(map[string]string)}
+		shared := AdapterNamespaceOptions{Enabled: true, Namespace: "shared"}
+		first := newClient("secret-key", adapter, shared, nil)
+		first.Shutdown()
+		saved := adapter.Get(namespacedKey("shared", CONFIG_SPECS_KEY))
+		if saved == "" {
+			t.Fatalf("Expected config specs to be saved in the shared namespace")
+		}
+
+		var reported []error
+		second := newClient("secret-other-key", adapter, shared, func(err error) { reported = append(reported, err) })
+		defer second.Shutdown()
+		var namespaceErr *DataAdapterNamespaceError
+		if len(reported) != 1 || !errors.As(reported[0], &namespaceErr) || namespaceErr.Namespace != "shared" {
+			t.Errorf("Expected a namespace collision to be reported, received %v", reported)
+		}
+		if second.evaluator.store.initReason != reasonNetwork {
+			t.Errorf("Expected the colliding client to fetch config specs from the network, received %s", second.evaluator.store.initReason)
+		}
+		if adapter.Get(namespacedKey("shared", CONFIG_SPECS_KEY)) != saved {
+			t.Errorf("Expected the colliding client not to overwrite the shared namespace")
+		}
+		if !second.CheckGate(user, "always_on_gate") {
+			t.Errorf("Expected gates to evaluate from the network")
+		}
+	})
+
+	t.Run("migrates legacy keys belonging to the SDK key", func(t *testing.T) {
+		adapter := &dataAdapterExample{store: make(map[string]string)}
+		adapter.Set(CONFIG_SPECS_KEY, string(specs))
+		adapter.Set(ID_LISTS_KEY, string(idLists))
+		adapter.Set(fmt.Sprintf("%s::%s", ID_LISTS_KEY, "list_1"), string(list))
+		enabled := AdapterNamespaceOptions{Enabled: true}
+
+		migrated, err := MigrateDataAdapterNamespace(adapter, "secret-key", enabled)
+		if err != nil || migrated != 3 {
+			t.Errorf("Expected 3 keys to be migrated, received %d, %v", migrated, err)
+		}
+		namespace := dataAdapterNamespace("secret-key", enabled)
+		if adapter.Get(namespacedKey(namespace, fmt.Sprintf("%s::%s", ID_LISTS_KEY, "list_1"))) != string(list) {
+			t.Errorf("Expected ID lists to be migrated")
+		}
+		migrated, err = MigrateDataAdapterNamespace(adapter, "secret-key", enabled)
+		if err != nil || migrated != 0 {
+			t.Errorf("Expected migrated keys not to be copied again, received %d, %v", migrated, err)
+		}
+		if _, err := MigrateDataAdapterNamespace(adapter, "secret-other-key", AdapterNamespaceOptions{Namespace: namespace}); err == nil {
+			t.Errorf("Expected migrating into a namespace owned by another SDK key to fail")
+		}
+	})
+
+	t.Run("refuses to migrate config specs generated for another SDK key", func(t *testing.T) {
+		adapter := &dataAdapterExample{store: make(map[string]string)}
+		adapter.Set(CONFIG_SPECS_KEY, `{"has_updates":true,"time":1,"hashed_sdk_key_used":"12345"}`)
+		if migrated, err := MigrateDataAdapterNamespace(adapter, "secret-key", AdapterNamespaceOptions{}); err == nil || migrated != 0 {
+			t.Errorf("Expected the migration to be refused, received %d, %v", migrated, err)
+		}
+	})
+
+	t.Run("migrates on initialization when enabled", func(t *testing.T) {
+		adapter := &dataAdapterExample{store: make(map[string]string)}
+		adapter.Set(CONFIG_SPECS_KEY, string(specs))
+		c := newClient("secret-key", adapter, AdapterNamespaceOptions{Enabled: true, MigrateLegacyKeys: true}, nil)
+		defer c.Shutdown()
+		if c.evaluator.store.initReason != reasonDataAdapter {
+			t.Errorf("Expected the migrated config specs to be read from the data adapter, received %s", c.evaluator.store.initReason)
+		}
+	})
+}
+
+// Simulates another SDK key claiming the namespace between this key's read and write
+type racingNamespaceAdapter struct {
+	dataAdapterExample
+	rival string
+}
+
+func (d *racingNamespaceAdapter) Set(key string, value string) {
+	d.dataAdapterExample.Set(key, value)
+	if strings.HasSuffix(key, NAMESPACE_OWNER_KEY) {
+		d.dataAdapterExample.Set(key, d.rival)
+	}
+}
+
+type setIfAbsentAdapter struct {
+	dataAdapterExample
+	calls int
+}
+
+func (d *setIfAbsentAdapter) SetIfAbsent(key string, value string) bool {
+	d.calls++
+	if d.Get(key) != "" {
+		return false
+	}
+	d.Set(key, value)
+	return true
+}
+
+func TestClaimDataAdapterNamespace(t *testing.T) {
+	racing := &racingNamespaceAdapter{dataAdapterExample: dataAdapterExample{store: make(map[string]string)}, rival: "rival"}
+	var namespaceErr *DataAdapterNamespaceError
+	if err := claimDataAdapterNamespace(racing, "namespace", "owner"); !errors.As(err, &namespaceErr) {
+		t.Errorf("Expected a namespace claimed concurrently by another key to be a collision, received %v", err)
+	}
+
+	adapter := &setIfAbsentAdapter{dataAdapterExample: dataAdapterExample{store: make(map[string]string)}}
+	if err := claimDataAdapterNamespace(adapter, "namespace", "owner"); err != nil {
+		t.Errorf("Expected the namespace to be claimed, received %v", err)
+	}
+	if err := claimDataAdapterNamespace(adapter, "namespace", "owner"); err != nil {
+		t.Errorf("Expected the owner to reclaim its namespace, received %v", err)
+	}
+	if err := claimDataAdapterNamespace(adapter, "namespace", "rival"); !errors.As(err, &namespaceErr) {
+		t.Errorf("Expected another key to collide, received %v", err)
+	}
+	if adapter.calls != 3 || adapter.Get(namespacedKey("namespace", NAMESPACE_OWNER_KEY)) != "owner" {
+		t.Errorf("Expected the namespace to be claimed with SetIfAbsent")
+	}
+}
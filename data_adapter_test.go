@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -302,3 +303,54 @@ func contains_spec(specs []configSpec, name string, specType string) bool {
 	}
 	return false
 }
+
+func TestAdapterOnlyWithLogging(t *testing.T) {
+	var events []Event
+	var networkSyncRequests int32
+	dcs_bytes, _ := os.ReadFile("download_config_specs.json")
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") || strings.Contains(req.URL.Path, "get_id_lists") {
+			atomic.AddInt32(&networkSyncRequests, 1)
+		} else if strings.Contains(req.URL.Path, "log_event") {
+			input := &struct {
+				Events []Event `json:"events"`
+			}{}
+			defer req.Body.Close()
+			buf := new(bytes.Buffer)
+			_, _ = buf.ReadFrom(req.Body)
+			_ = json.Unmarshal(buf.Bytes(), &input)
+			events = input.Events
+		}
+	}))
+	defer testServer.Close()
+	dataAdapter := dataAdapterExample{store: make(map[string]string)}
+	options := &Options{
+		DataAdapter:              &dataAdapter,
+		DisableNetworkConfigSync: true,
+		API:                      testServer.URL,
+		ConfigSyncInterval:       100 * time.Millisecond,
+		IDListSyncInterval:       100 * time.Millisecond,
+		OutputLoggerOptions:      getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions:     getStatsigLoggerOptionsForTest(t),
+	}
+	InitializeWithOptions("secret-key", options)
+	user := User{UserID: "statsig_user"}
+	if CheckGate(user, "always_on_gate") {
+		t.Errorf("Expected gate to be false before the adapter has config specs")
+	}
+
+	dataAdapter.Set(CONFIG_SPECS_KEY, string(dcs_bytes))
+	time.Sleep(300 * time.Millisecond)
+	if !CheckGate(user, "always_on_gate") {
+		t.Errorf("Expected gate to be true after the adapter was updated")
+	}
+	ShutdownAndDangerouslyClearInstance()
+
+	if atomic.LoadInt32(&networkSyncRequests) != 0 {
+		t.Errorf("Expected no network config sync requests, received %d", networkSyncRequests)
+	}
+	if len(events) != 2 {
+		t.Errorf("Expected exposures to be logged to the network, received %d", len(events))
+	}
+}
@@ -0,0 +1,155 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const SYNC_METADATA_KEY = "statsig.sync_metadata"
+
+// Persisted with the config specs, so the sync state outlives the process
+type syncMetadata struct {
+	LastSyncTime    int64 `json:"lastSyncTime"`    // The sinceTime a restart resumes from, the time of the persisted config specs
+	InitialSyncTime int64 `json:"initialSyncTime"` // The time of the config specs the writing process initialized with
+	SavedAt         int64 `json:"savedAt"`
+}
+
+// Writes to the data adapter in the background, so a slow adapter does not hold up syncs or evaluations.
+// Only the latest value of each key is written.
+type adapterWriteBehind struct {
+	adapter IDataAdapter
+	pending map[string]interface{} // Strings are written as is, other values as JSON
+	order   []string
+	wake    chan struct{}
+	closed  bool
+	mu      sync.Mutex
+	writing sync.Mutex // Held while writing, so flush returns after in-progress writes
+}
+
+func newAdapterWriteBehind(adapter IDataAdapter) *adapterWriteBehind {
+	if adapter == nil {
+		return nil
+	}
+	w := &adapterWriteBehind{
+		adapter: adapter,
+		pending: make(map[string]interface{}),
+		wake:    make(chan struct{}, 1),
+	}
+	go w.backgroundWrite()
+	return w
+}
+
+func (w *adapterWriteBehind) set(key string, value interface{}) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	if _, ok := w.pending[key]; !ok {
+		w.order = append(w.order, key)
+	}
+	w.pending[key] = value
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *adapterWriteBehind) backgroundWrite() {
+	for range w.wake {
+		w.flush()
+	}
+}
+
+// Writes everything pending before returning
+func (w *adapterWriteBehind) flush() {
+	if w == nil {
+		return
+	}
+	w.writing.Lock()
+	defer w.writing.Unlock()
+	w.mu.Lock()
+	pending, order := w.pending, w.order
+	w.pending, w.order = make(map[string]interface{}), nil
+	w.mu.Unlock()
+	for _, key := range order {
+		w.write(key, pending[key])
+	}
+}
+
+func (w *adapterWriteBehind) write(key string, value interface{}) {
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("Error calling data adapter set: %s\n", toError(err).Error()))
+		}
+	}()
+	serialized, ok := value.(string)
+	if !ok {
+		bytes, err := json.Marshal(value)
+		if err != nil {
+			Logger().LogError(fmt.Sprintf("Failed to serialize %s for the data adapter: %s\n", key, err.Error()))
+			return
+		}
+		serialized = string(bytes)
+	}
+	w.adapter.Set(key, serialized)
+}
+
+// Flushes pending writes and stops the background writer. Called before the adapter is shut down
+func (w *adapterWriteBehind) close() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	close(w.wake)
+	w.mu.Unlock()
+	w.flush()
+}
+
+// Called with s.mu held after a network sync that changed the config specs. Clients that only read
+// the adapter leave the metadata to the instance that writes it
+func (s *store) saveSyncMetadataToAdapter() {
+	if !s.ownsDataAdapter() {
+		return
+	}
+	s.adapterWrites.set(SYNC_METADATA_KEY, syncMetadata{
+		LastSyncTime:    s.lastSyncTime,
+		InitialSyncTime: s.initialSyncTime,
+		SavedAt:         getUnixMilli(),
+	})
+}
+
+// Resumes from the adapter's config specs without a network sync on initialization, as without the
+// metadata, while it shows they were synced within the last sync interval, i.e. no later than the next
+// poll would sync them. Otherwise the owning client catches up from them on initialization
+func (s *store) resumeFromAdapterSyncMetadata() {
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("Error calling data adapter get: %s\n", toError(err).Error()))
+		}
+	}()
+	var metadata syncMetadata
+	if err := json.Unmarshal([]byte(s.dataAdapter.Get(SYNC_METADATA_KEY)), &metadata); err != nil || metadata.LastSyncTime == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if metadata.LastSyncTime > s.lastSyncTime {
+		Logger().LogError(fmt.Sprintf("The data adapter's config specs are from %d, but a sync at %d was persisted. "+
+			"Syncing from %d, check the adapter stores values the size of the config specs\n", s.lastSyncTime, metadata.LastSyncTime, s.lastSyncTime))
+		return
+	}
+	age := time.Duration(getUnixMilli()-metadata.SavedAt) * time.Millisecond
+	s.staleAdapterSync = metadata.LastSyncTime != s.lastSyncTime || age < 0 || age >= s.configSyncInterval
+}
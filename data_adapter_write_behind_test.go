@@ -0,0 +1,189 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingDataAdapter struct {
+	dataAdapterExample
+	release chan struct{}
+	writes  []string
+	writeMu sync.Mutex
+}
+
+func (d *blockingDataAdapter) Set(key string, value string) {
+	<-d.release
+	d.writeMu.Lock()
+	d.writes = append(d.writes, key+"="+value)
+	d.writeMu.Unlock()
+	d.dataAdapterExample.Set(key, value)
+}
+
+func TestAdapterWriteBehind(t *testing.T) {
+	t.Run("writes only the latest value of each key", func(t *testing.T) {
+		adapter := &blockingDataAdapter{dataAdapterExample: dataAdapterExample{store: make(map[string]string)}, release: make(chan struct{})}
+		w := newAdapterWriteBehind(adapter)
+		w.set("key", "1")
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			w.mu.Lock()
+			writing := len(w.pending) == 0
+			w.mu.Unlock()
+			if writing {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		w.set("key", "2")
+		w.set("other", map[string]int{"a": 1})
+		w.set("key", "3")
+		close(adapter.release)
+		w.close()
+		expected := []string{"key=1", "key=3", `other={"a":1}`}
+		if strings.Join(adapter.writes, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected writes %v, received %v", expected, adapter.writes)
+		}
+		w.set("key", "4")
+		if adapter.Get("key") != "3" {
+			t.Errorf("Expected no writes after close")
+		}
+	})
+
+	t.Run("restarts resume from the persisted sync", func(t *testing.T) {
+		specs, _ := os.ReadFile("download_config_specs.json")
+		var sinceTimes []string
+		var mu sync.Mutex
+		testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if strings.Contains(req.URL.Path, "download_config_specs") {
+				mu.Lock()
+				sinceTimes = append(sinceTimes, req.URL.Query().Get("sinceTime"))
+				mu.Unlock()
+				_, _ = res.Write(specs)
+				return
+			}
+			_, _ = res.Write([]byte("{}"))
+		}))
+		defer testServer.Close()
+		adapter := &dataAdapterExample{store: make(map[string]string)}
+		newClient := func() *Client {
+			InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+			return NewClientWithOptions("secret-key", &Options{
+				API:                  testServer.URL,
+				DataAdapter:          adapter,
+				StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			})
+		}
+
+		c := newClient()
+		var metadata syncMetadata
+		if err := json.Unmarshal([]byte(adapter.Get(SYNC_METADATA_KEY)), &metadata); err != nil {
+			t.Fatalf("Expected sync metadata to be persisted during initialization, received %v", err)
+		}
+		if metadata.LastSyncTime != configSyncTime || metadata.InitialSyncTime != configSyncTime || metadata.SavedAt == 0 {
+			t.Errorf("Expected the sync times to be persisted, received %+v", metadata)
+		}
+		if adapter.Get(CONFIG_SPECS_KEY) == "" {
+			t.Errorf("Expected the config specs to be persisted during initialization")
+		}
+		c.Shutdown()
+
+		c = newClient()
+		defer c.Shutdown()
+		c.evaluator.store.fetchConfigSpecsFromServer(false)
+		mu.Lock()
+		defer mu.Unlock()
+		if len(sinceTimes) != 2 || sinceTimes[0] != "0" || sinceTimes[1] != "1631638014811" {
+			t.Errorf("Expected the restart to sync from the persisted time, received %v", sinceTimes)
+		}
+	})
+	t.Run("persists metadata only when the owned specs change", func(t *testing.T) {
+		specs, _ := os.ReadFile("download_config_specs.json")
+		var sinceTimes []string
+		var mu sync.Mutex
+		testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if strings.Contains(req.URL.Path, "download_config_specs") {
+				mu.Lock()
+				sinceTimes = append(sinceTimes, req.URL.Query().Get("sinceTime"))
+				mu.Unlock()
+				if req.URL.Query().Get("sinceTime") != "0" {
+					res.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = res.Write(specs)
+				return
+			}
+			_, _ = res.Write([]byte("{}"))
+		}))
+		defer testServer.Close()
+		requests := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), sinceTimes...)
+		}
+		newClient := func(adapter IDataAdapter) *Client {
+			InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+			return NewClientWithOptions("secret-key", &Options{
+				API:                  testServer.URL,
+				DataAdapter:          adapter,
+				StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			})
+		}
+		adapter := &dataAdapterExample{store: make(map[string]string)}
+		c := newClient(adapter)
+		if adapter.Get(SYNC_METADATA_KEY) == "" {
+			t.Fatalf("Expected sync metadata to be persisted during initialization")
+		}
+		stale := `{"lastSyncTime":1631638014811,"initialSyncTime":1631638014811,"savedAt":1}`
+		adapter.Set(SYNC_METADATA_KEY, stale)
+		c.evaluator.store.fetchConfigSpecsFromServer(false)
+		c.evaluator.store.adapterWrites.flush()
+		if value := adapter.Get(SYNC_METADATA_KEY); value != stale {
+			t.Errorf("Expected a not modified sync to leave the metadata as is, received %s", value)
+		}
+		c.Shutdown()
+
+		// Stale metadata, so the restart catches up from the adapter's specs
+		c = newClient(adapter)
+		c.Shutdown()
+		if received := requests(); len(received) != 3 || received[2] != "1631638014811" {
+			t.Errorf("Expected the restart to catch up from the adapter's config specs, received %v", received)
+		}
+
+		// Fresh metadata, so the restart resumes without a network sync
+		adapter.Set(SYNC_METADATA_KEY, fmt.Sprintf(`{"lastSyncTime":1631638014811,"initialSyncTime":1631638014811,"savedAt":%d}`, getUnixMilli()))
+		c = newClient(adapter)
+		if c.evaluator.store.staleAdapterSync || !c.CheckGate(User{UserID: "a_user"}, "always_on_gate") {
+			t.Errorf("Expected the restart to resume from the adapter's config specs")
+		}
+		c.Shutdown()
+		if received := requests(); len(received) != 3 {
+			t.Errorf("Expected no network sync while the persisted sync is fresh, received %v", received)
+		}
+
+		// A client querying the adapter seeds an empty one from the network, but leaves the metadata to the owner
+		reader := &configSpecsReaderAdapter{dataAdapterExample: dataAdapterExample{store: make(map[string]string)}}
+		newClient(reader).Shutdown()
+		if reader.Get(CONFIG_SPECS_KEY) == "" {
+			t.Errorf("Expected a client querying an empty adapter to save the config specs it synced")
+		}
+		if value := reader.Get(SYNC_METADATA_KEY); value != "" {
+			t.Errorf("Expected a client querying the adapter not to write sync metadata, received %s", value)
+		}
+	})
+}
+
+type configSpecsReaderAdapter struct {
+	dataAdapterExample
+}
+
+func (d *configSpecsReaderAdapter) ShouldBeUsedForQueryingUpdates(key string) bool {
+	return key == CONFIG_SPECS_KEY
+}
@@ -0,0 +1,56 @@
+package statsig
+
+import (
+	"fmt"
+)
+
+// How conditions with list operators (any, none, str_contains_any, ...) treat an empty or missing target list
+type EmptyTargetListPolicy int
+
+const (
+	EmptyTargetListMatchesNothing EmptyTargetListPolicy = iota // No value is in the list, so "any" operators fail and "none" operators pass
+	EmptyTargetListFailsCondition                              // The condition fails for every operator, treating the empty list as a misconfiguration
+)
+
+var listOperators = map[string]bool{
+	"any":                 true,
+	"none":                true,
+	"any_case_sensitive":  true,
+	"none_case_sensitive": true,
+	"str_starts_with_any": true,
+	"str_ends_with_any":   true,
+	"str_contains_any":    true,
+	"str_contains_none":   true,
+}
+
+// Values that are not arrays never match, so they are treated like an empty list
+func isEmptyTargetList(operator string, targetValue interface{}) bool {
+	if !listOperators[operator] {
+		return false
+	}
+	array, ok := targetValue.([]interface{})
+	return !ok || len(array) == 0
+}
+
+func (e *evaluator) getEmptyTargetListPolicy() EmptyTargetListPolicy {
+	if e.options == nil {
+		return EmptyTargetListMatchesNothing
+	}
+	return e.options.EmptyTargetListPolicy
+}
+
+// Warns once per rule about conditions with an empty target list, which are usually a misconfiguration
+func (s *store) warnEmptyTargetLists(spec configSpec) {
+	for _, rule := range spec.Rules {
+		for _, cond := range rule.Conditions {
+			if !cond.getCompiled().emptyTargetList {
+				continue
+			}
+			key := spec.Name + ":" + rule.ID
+			if _, warned := s.targetListWarnings.LoadOrStore(key, true); !warned {
+				Logger().LogError(fmt.Sprintf("Rule %s of %s has a %s condition with an empty target list. "+
+					"It is evaluated according to Options.EmptyTargetListPolicy\n", rule.ID, spec.Name, cond.Operator))
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package statsig
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+const emptyTargetListSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [{
+		"name": "empty_any", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "r", "id": "empty_any_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "user_field", "field": "email", "operator": "any", "targetValue": []}]
+		}]
+	}, {
+		"name": "empty_none", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "r", "id": "empty_none_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "user_field", "field": "email", "operator": "str_contains_none", "targetValue": null}]
+		}]
+	}, {
+		"name": "populated_none", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "r", "id": "populated_none_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "user_field", "field": "email", "operator": "none", "targetValue": ["b@example.com"]}]
+		}]
+	}],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+func TestEmptyTargetListPolicy(t *testing.T) {
+	newPolicyClient := func(policy EmptyTargetListPolicy) (*Client, []string) {
+		var mu sync.Mutex
+		var warnings []string
+		InitializeGlobalOutputLogger(OutputLoggerOptions{LogCallback: func(message string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if strings.Contains(message, "empty target list") {
+				warnings = append(warnings, message)
+			}
+		}})
+		c := NewClientWithOptions("secret-key", &Options{
+			LocalMode:             true,
+			BootstrapValues:       emptyTargetListSpecs,
+			StatsigLoggerOptions:  getStatsigLoggerOptionsForTest(t),
+			EmptyTargetListPolicy: policy,
+		})
+		mu.Lock()
+		defer mu.Unlock()
+		return c, warnings
+	}
+	user := User{UserID: "123", Email: "a@example.com"}
+
+	t.Run("matches nothing by default", func(t *testing.T) {
+		c, warnings := newPolicyClient(EmptyTargetListMatchesNothing)
+		defer c.Shutdown()
+		if c.CheckGate(user, "empty_any") || !c.CheckGate(user, "empty_none") || !c.CheckGate(user, "populated_none") {
+			t.Errorf("Expected any to fail and none to pass for an empty list")
+		}
+		if len(warnings) != 2 || !strings.Contains(warnings[0], "empty_any_rule") || !strings.Contains(warnings[1], "empty_none_rule") {
+			t.Errorf("Expected a warning for each rule with an empty list, received %v", warnings)
+		}
+	})
+
+	t.Run("fails conditions with EmptyTargetListFailsCondition", func(t *testing.T) {
+		c, _ := newPolicyClient(EmptyTargetListFailsCondition)
+		defer c.Shutdown()
+		if c.CheckGate(user, "empty_any") || c.CheckGate(user, "empty_none") {
+			t.Errorf("Expected conditions with an empty list to fail")
+		}
+		if !c.CheckGate(user, "populated_none") {
+			t.Errorf("Expected conditions with targets to be unaffected")
+		}
+	})
+}
@@ -19,6 +19,7 @@ type errorBoundary struct {
 	seenLock    sync.RWMutex
 	diagnostics *diagnostics
 	options     *Options
+	instanceID  string
 }
 
 type logExceptionRequestBody struct {
@@ -45,7 +46,7 @@ func newErrorBoundary(sdkKey string, options *Options, diagnostics *diagnostics)
 		api:         ErrorBoundaryAPI,
 		endpoint:    ErrorBoundaryEndpoint,
 		sdkKey:      sdkKey,
-		client:      &http.Client{Timeout: time.Second * 3},
+		client:      newHTTPClient(options, time.Second*3),
 		seen:        make(map[string]bool),
 		diagnostics: diagnostics,
 		options:     options,
@@ -114,27 +115,34 @@ func (e *errorBoundary) captureVoid(task func()) {
 func (e *errorBoundary) ebRecover(recoverCallback func()) {
 	if err := recover(); err != nil {
 		e.logException(toError(err))
-		Logger().LogError(err)
+		// Structured loggers already received the exception
+		if !Logger().isStructured() {
+			Logger().LogError(err)
+		}
 		recoverCallback()
 	}
 }
 
+// Surfaces every exception to structured loggers, and reports each distinct one to Statsig once
 func (e *errorBoundary) logException(exception error) {
-	if e.options.StatsigLoggerOptions.DisableAllLogging {
-		return
-	}
 	var exceptionString string
 	if exception == nil {
 		exceptionString = "Unknown"
 	} else {
 		exceptionString = exception.Error()
 	}
+	stack := make([]byte, 1024)
+	n := runtime.Stack(stack, false)
+	Logger().logRecord(logLevelError, "Statsig SDK exception",
+		logAttr{"error", exceptionString}, logAttr{"stack", string(stack[:n])})
+	if e.options.StatsigLoggerOptions.DisableAllLogging || e.options.LocalMode {
+		return
+	}
 	if e.checkSeen(exceptionString) {
 		return
 	}
-	stack := make([]byte, 1024)
-	runtime.Stack(stack, false)
 	metadata := getStatsigMetadata()
+	metadata.InstanceID = e.instanceID
 	body := &logExceptionRequestBody{
 		Exception:       exceptionString,
 		Info:            string(stack),
@@ -155,6 +163,7 @@ func (e *errorBoundary) logException(exception error) {
 	req.Header.Add("STATSIG-SDK-TYPE", metadata.SDKType)
 	req.Header.Add("STATSIG-SDK-VERSION", metadata.SDKVersion)
 	req.Header.Add("STATSIG-SERVER-SESSION-ID", metadata.SessionID)
+	req.Header.Add("STATSIG-SDK-INSTANCE-ID", metadata.InstanceID)
 
 	_, _ = e.client.Do(req)
 }
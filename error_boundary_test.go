@@ -47,6 +47,22 @@ func TestLogException(t *testing.T) {
 	}
 }
 
+func TestLogExceptionInLocalMode(t *testing.T) {
+	hit := false
+	testServer := mock_server(t, nil, &hit)
+	defer testServer.Close()
+	opt := &Options{
+		API:       testServer.URL,
+		LocalMode: true,
+	}
+	diagnostics := newDiagnostics(opt)
+	errorBoundary := newErrorBoundary("client-key", opt, diagnostics)
+	errorBoundary.logException(errors.New("test error boundary in local mode"))
+	if hit {
+		t.Error("Expected sdk_exception endpoint not to be hit in local mode")
+	}
+}
+
 func TestDCSError(t *testing.T) {
 	hit := false
 	testServer := mock_server(t, nil, &hit)
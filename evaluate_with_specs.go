@@ -0,0 +1,92 @@
+package statsig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// The result of EvaluateWithSpecs
+type Result struct {
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`  // One of SpecTypeFeatureGate, SpecTypeDynamicConfig or SpecTypeLayer
+	Pass      bool                   `json:"pass"`  // Whether a gate passed, or the rule matched for configs and layers
+	Value     map[string]interface{} `json:"value"` // Empty for gates
+	RuleID    string                 `json:"ruleID"`
+	GroupName string                 `json:"groupName,omitempty"`
+}
+
+var (
+	statelessParsers   sync.Once
+	statelessUAParser  UserAgentParser
+	statelessCountries IPCountryLookup
+)
+
+// Evaluates the gate, config, experiment or layer named name for the user against a download_config_specs
+// snapshot, without a client or network requests. ID lists are not available, so in_segment_list conditions
+// never match, and no exposures are logged
+func EvaluateWithSpecs(specsJSON []byte, user User, name string) (Result, error) {
+	if user.UserID == "" && len(user.CustomIDs) == 0 {
+		return Result{}, errors.New(EmptyUserError)
+	}
+	var specs downloadConfigSpecResponse
+	if err := json.Unmarshal(specsJSON, &specs); err != nil {
+		return Result{}, fmt.Errorf("Failed to parse specs: %s", err.Error())
+	}
+	e := newStatelessEvaluator(specs)
+	user = normalizeUser(user, *e.options)
+	if _, ok := e.store.getGate(name); ok {
+		res := e.evalGate(user, name, 0)
+		return Result{Name: name, Type: SpecTypeFeatureGate, Pass: res.Pass, Value: map[string]interface{}{}, RuleID: res.RuleID, GroupName: res.GroupName}, nil
+	}
+	var res *evalResult
+	specType := SpecTypeDynamicConfig
+	if _, ok := e.store.getDynamicConfig(name); ok {
+		res = e.evalConfig(user, name, nil, 0)
+	} else if _, ok := e.store.getLayerConfig(name); ok {
+		specType = SpecTypeLayer
+		res = e.evalLayer(user, name, 0)
+	} else {
+		return Result{}, fmt.Errorf("No gate, config, experiment or layer named %s in the specs", name)
+	}
+	return Result{
+		Name:      name,
+		Type:      specType,
+		Pass:      res.Pass,
+		Value:     res.ConfigValue.Value,
+		RuleID:    res.RuleID,
+		GroupName: res.ConfigValue.GroupName,
+	}, nil
+}
+
+func newStatelessEvaluator(specs downloadConfigSpecResponse) *evaluator {
+	statelessParsers.Do(func() {
+		statelessUAParser = NewEmbeddedUserAgentParser()
+		statelessCountries = NewEmbeddedIPCountryLookup()
+	})
+	options := &Options{LocalMode: true}
+	diagnostics := newDiagnostics(options)
+	e := &evaluator{
+		store: &store{
+			idLists:       make(map[string]*idList),
+			initReason:    reasonBootstrap,
+			errorBoundary: newErrorBoundary("", options, diagnostics),
+			diagnostics:   diagnostics,
+			options:       options,
+		},
+		countryLookup:          statelessCountries,
+		uaParser:               statelessUAParser,
+		gateOverrides:          make(map[string]bool),
+		configOverrides:        make(map[string]map[string]interface{}),
+		layerOverrides:         make(map[string]map[string]interface{}),
+		precomputed:            make(map[string]*precomputedEvaluations),
+		persistentStorageUtils: newUserPersistentStorageUtils(options),
+		options:                options,
+	}
+	// Snapshots are not tied to an SDK key
+	specs.HashedSDKKeyUsed = ""
+	specs.HasUpdates = true
+	e.store.setConfigSpecs(specs)
+	return e
+}
@@ -0,0 +1,49 @@
+package statsig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEvaluateWithSpecs(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	user := User{UserID: "123", Email: "a@statsig.com"}
+
+	gate, err := EvaluateWithSpecs(specs, user, "always_on_gate")
+	if err != nil || !gate.Pass || gate.Type != SpecTypeFeatureGate || gate.RuleID != "6N6Z8ODekNYZ7F8gFdoLP5" {
+		t.Errorf("Expected always_on_gate to pass, received %+v and %v", gate, err)
+	}
+	config, err := EvaluateWithSpecs(specs, user, "test_config")
+	if err != nil || config.Type != SpecTypeDynamicConfig || config.Value["number"] != float64(7) {
+		t.Errorf("Expected test_config to be evaluated, received %+v and %v", config, err)
+	}
+	experiment, err := EvaluateWithSpecs(specs, user, "sample_experiment")
+	if err != nil || experiment.RuleID == "" || experiment.Value["experiment_param"] == nil {
+		t.Errorf("Expected sample_experiment to be evaluated, received %+v and %v", experiment, err)
+	}
+	layer, err := EvaluateWithSpecs(specs, user, "a_layer")
+	if err != nil || layer.Type != SpecTypeLayer || layer.RuleID != experiment.RuleID {
+		t.Errorf("Expected a_layer to be evaluated, received %+v and %v", layer, err)
+	}
+
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(specs),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	if c.GetExperiment(user, "sample_experiment").RuleID != experiment.RuleID || c.GetLayer(user, "a_layer").GroupName != layer.GroupName {
+		t.Errorf("Expected the same results as a client")
+	}
+
+	if _, err := EvaluateWithSpecs(specs, user, "not_a_spec"); err == nil {
+		t.Errorf("Expected an error for an unknown name")
+	}
+	if _, err := EvaluateWithSpecs([]byte("{"), user, "always_on_gate"); err == nil {
+		t.Errorf("Expected an error for invalid specs")
+	}
+	if _, err := EvaluateWithSpecs(specs, User{}, "always_on_gate"); err == nil {
+		t.Errorf("Expected an error for an empty user")
+	}
+}
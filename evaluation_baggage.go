@@ -0,0 +1,160 @@
+package statsig
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// W3C baggage member keys. Gate values are encoded as gate_a=1&gate_b=0, and the user as a short
+// hash of their unit IDs so downstream services only reuse decisions made for the same user
+const (
+	baggageGatesKey = "statsig.gates"
+	baggageUserKey  = "statsig.user"
+)
+
+// Gate results evaluated by an upstream service, to be propagated as OpenTelemetry baggage
+type EvaluationBaggage struct {
+	UserHash string
+	Gates    map[string]bool
+}
+
+type evaluationBaggageContextKey struct{}
+
+// The baggage members to propagate, e.g. with baggage.NewMember(key, value) from the OpenTelemetry API
+func (b EvaluationBaggage) Members() map[string]string {
+	gates := url.Values{}
+	for name, value := range b.Gates {
+		if value {
+			gates.Set(name, "1")
+		} else {
+			gates.Set(name, "0")
+		}
+	}
+	return map[string]string{
+		baggageGatesKey: gates.Encode(),
+		baggageUserKey:  b.UserHash,
+	}
+}
+
+// Encodes the baggage members as a W3C baggage header value
+func (b EvaluationBaggage) String() string {
+	members := b.Members()
+	return fmt.Sprintf("%s=%s,%s=%s", baggageUserKey, members[baggageUserKey], baggageGatesKey, members[baggageGatesKey])
+}
+
+// Reads EvaluationBaggage from a W3C baggage header value, ignoring unrelated members and properties
+func ParseEvaluationBaggage(header string) (EvaluationBaggage, error) {
+	baggage := EvaluationBaggage{Gates: make(map[string]bool)}
+	var foundGates bool
+	for _, member := range strings.Split(header, ",") {
+		member = strings.TrimSpace(strings.SplitN(member, ";", 2)[0])
+		parts := strings.SplitN(member, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		// Propagators may percent-encode the whole value
+		if unescaped, err := url.PathUnescape(value); err == nil && !strings.Contains(value, "=") {
+			value = unescaped
+		}
+		switch key {
+		case baggageUserKey:
+			baggage.UserHash = value
+		case baggageGatesKey:
+			foundGates = true
+			gates, err := url.ParseQuery(value)
+			if err != nil {
+				return EvaluationBaggage{}, fmt.Errorf("Failed to parse %s baggage: %s", baggageGatesKey, err.Error())
+			}
+			for name, values := range gates {
+				switch values[0] {
+				case "1":
+					baggage.Gates[name] = true
+				case "0":
+					baggage.Gates[name] = false
+				default:
+					return EvaluationBaggage{}, fmt.Errorf("Invalid value %q for gate %s in %s baggage", values[0], name, baggageGatesKey)
+				}
+			}
+		}
+	}
+	if !foundGates || baggage.UserHash == "" {
+		return EvaluationBaggage{}, fmt.Errorf("No %s and %s baggage members found", baggageGatesKey, baggageUserKey)
+	}
+	return baggage, nil
+}
+
+// Stores the baggage in ctx for propagation within the process
+func ContextWithEvaluationBaggage(ctx context.Context, baggage EvaluationBaggage) context.Context {
+	return context.WithValue(ctx, evaluationBaggageContextKey{}, baggage)
+}
+
+// Gets baggage stored with ContextWithEvaluationBaggage
+func EvaluationBaggageFromContext(ctx context.Context) (EvaluationBaggage, bool) {
+	baggage, ok := ctx.Value(evaluationBaggageContextKey{}).(EvaluationBaggage)
+	return baggage, ok
+}
+
+func evaluationBaggageUserHash(user User) string {
+	return getHashBase64StringEncoding(precomputedEvaluationsKey(user))[:12]
+}
+
+func (c *Client) getEvaluationBaggage(user User) EvaluationBaggage {
+	gates := c.options.EvaluationBaggageOptions.Gates
+	baggage := EvaluationBaggage{UserHash: evaluationBaggageUserHash(user), Gates: make(map[string]bool, len(gates))}
+	for _, gate := range gates {
+		baggage.Gates[gate] = c.checkGateImpl(user, gate, checkGateOptions{disableLogExposures: true}).Value
+	}
+	return baggage
+}
+
+// Upstream gate results verified against a user by LoadEvaluationBaggage, held only in the request's context
+type loadedEvaluationBaggage struct {
+	userKey string
+	gates   map[string]bool
+}
+
+type loadedEvaluationBaggageContextKey struct{}
+
+// Checks that the baggage was generated for the user and returns a context that serves its gates for that user
+func loadEvaluationBaggage(ctx context.Context, user User, baggage EvaluationBaggage) (context.Context, error) {
+	if baggage.UserHash != evaluationBaggageUserHash(user) {
+		return ctx, fmt.Errorf("Evaluation baggage was generated for a different user")
+	}
+	gates := make(map[string]bool, len(baggage.Gates))
+	for name, value := range baggage.Gates {
+		gates[name] = value
+	}
+	loaded := &loadedEvaluationBaggage{userKey: precomputedEvaluationsKey(user), gates: gates}
+	return context.WithValue(contextOrBackground(ctx), loadedEvaluationBaggageContextKey{}, loaded), nil
+}
+
+func loadedEvaluationBaggageFromContext(ctx context.Context) *loadedEvaluationBaggage {
+	if ctx == nil {
+		return nil
+	}
+	loaded, _ := ctx.Value(loadedEvaluationBaggageContextKey{}).(*loadedEvaluationBaggage)
+	return loaded
+}
+
+// Serves a gate from baggage loaded into ctx for the same user, unless the gate is overridden
+func (e *evaluator) getBaggageGate(ctx context.Context, user User, gateName string) (*evalResult, bool) {
+	loaded := loadedEvaluationBaggageFromContext(ctx)
+	if loaded == nil || e.reference || !e.inTenant(gateName) || loaded.userKey != precomputedEvaluationsKey(user) {
+		return nil, false
+	}
+	if _, hasOverride := e.getGateOverride(gateName); hasOverride {
+		return nil, false
+	}
+	value, ok := loaded.gates[gateName]
+	if !ok {
+		return nil, false
+	}
+	return &evalResult{
+		Pass:               value,
+		SecondaryExposures: make([]map[string]string, 0),
+		EvaluationDetails:  e.createEvaluationDetails(reasonPrecomputed),
+	}, true
+}
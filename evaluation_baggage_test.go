@@ -0,0 +1,95 @@
+package statsig
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestEvaluationBaggage(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	newBaggageClient := func(bootstrapValues string) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:                true,
+			BootstrapValues:          bootstrapValues,
+			StatsigLoggerOptions:     getStatsigLoggerOptionsForTest(t),
+			EvaluationBaggageOptions: EvaluationBaggageOptions{Gates: []string{"always_on_gate", "on_for_statsig_email"}},
+		})
+	}
+	user := User{UserID: "123", Email: "a@example.com"}
+
+	upstream := newBaggageClient(string(specs))
+	defer upstream.Shutdown()
+	baggage := upstream.GetEvaluationBaggage(user)
+	if !baggage.Gates["always_on_gate"] || baggage.Gates["on_for_statsig_email"] || len(baggage.Gates) != 2 {
+		t.Errorf("Expected the allow-listed gates to be evaluated, received %+v", baggage.Gates)
+	}
+	if len(upstream.logger.events) != 0 {
+		t.Errorf("Expected no exposures for baggage evaluations, received %d", len(upstream.logger.events))
+	}
+
+	t.Run("round trips through a baggage header", func(t *testing.T) {
+		header := "other=value;prop=1, " + baggage.String()
+		parsed, err := ParseEvaluationBaggage(header)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if parsed.UserHash != baggage.UserHash || !parsed.Gates["always_on_gate"] || parsed.Gates["on_for_statsig_email"] {
+			t.Errorf("Expected the baggage to round trip, received %+v", parsed)
+		}
+
+		members := baggage.Members()
+		encoded := "statsig.gates=" + url.PathEscape(members["statsig.gates"]) + ",statsig.user=" + url.PathEscape(members["statsig.user"])
+		if parsed, err := ParseEvaluationBaggage(encoded); err != nil || !parsed.Gates["always_on_gate"] {
+			t.Errorf("Expected percent-encoded members to be decoded, received %+v (%v)", parsed, err)
+		}
+		if _, err := ParseEvaluationBaggage("other=value"); err == nil {
+			t.Errorf("Expected an error without statsig members")
+		}
+		if _, err := ParseEvaluationBaggage("statsig.user=abc,statsig.gates=a=maybe"); err == nil {
+			t.Errorf("Expected an error for an invalid gate value")
+		}
+	})
+
+	t.Run("propagates within the process", func(t *testing.T) {
+		ctx := ContextWithEvaluationBaggage(context.Background(), baggage)
+		fromContext, ok := EvaluationBaggageFromContext(ctx)
+		if !ok || fromContext.UserHash != baggage.UserHash {
+			t.Errorf("Expected baggage to be stored in the context")
+		}
+		if _, ok := EvaluationBaggageFromContext(context.Background()); ok {
+			t.Errorf("Expected no baggage in an empty context")
+		}
+	})
+
+	t.Run("downstream services reuse upstream decisions", func(t *testing.T) {
+		// The downstream service has no ruleset, so the gates can only come from the baggage
+		downstream := newBaggageClient("")
+		defer downstream.Shutdown()
+		if ctx, err := downstream.LoadEvaluationBaggage(context.Background(), User{UserID: "456"}, baggage); err == nil || ctx != context.Background() {
+			t.Errorf("Expected an error and the unchanged context for a different user")
+		}
+		ctx, err := downstream.LoadEvaluationBaggage(context.Background(), user, baggage)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if !downstream.CheckGateCtx(ctx, user, "always_on_gate") || downstream.CheckGateCtx(ctx, user, "on_for_statsig_email") {
+			t.Errorf("Expected the upstream gate values")
+		}
+		exposure := downstream.logger.events[0].(ExposureEvent)
+		if exposure.Metadata["reason"] != string(reasonPrecomputed) {
+			t.Errorf("Expected exposures with reason Precomputed, received %+v", exposure.Metadata)
+		}
+		if downstream.CheckGateCtx(ctx, User{UserID: "456"}, "always_on_gate") {
+			t.Errorf("Expected the baggage to only apply to the user it was generated for")
+		}
+		if downstream.CheckGate(user, "always_on_gate") {
+			t.Errorf("Expected the baggage to only apply to evaluations with its context")
+		}
+		if len(downstream.evaluator.precomputed) != 0 {
+			t.Errorf("Expected no baggage in the evaluator's shared state")
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package statsig
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Shares one in-flight evaluation between goroutines evaluating the same spec for the same user,
+// e.g. fan-out handlers of a single request. The first caller evaluates and logs the exposure,
+// callers arriving before it returns wait for and receive its result without logging their own.
+type evaluationCoalescer struct {
+	inFlight map[string]*coalescedEvaluation
+	mu       sync.Mutex
+}
+
+type coalescedEvaluation struct {
+	done    chan struct{}
+	result  interface{} // Nil when the evaluation panicked
+	waiters int         // Guarded by evaluationCoalescer.mu
+}
+
+func newEvaluationCoalescer(options *Options) *evaluationCoalescer {
+	if !options.CoalesceEvaluations {
+		return nil
+	}
+	return &evaluationCoalescer{inFlight: make(map[string]*coalescedEvaluation)}
+}
+
+// Keyed by every attribute of the user, since any of them may be used by the spec's conditions
+func evaluationCoalescingKey(specType string, name string, variant string, user User) (string, bool) {
+	bytes, err := json.Marshal(user)
+	if err != nil {
+		return "", false
+	}
+	return specType + ":" + name + ":" + variant + ":" + getHashBase64StringEncoding(string(bytes)), true
+}
+
+// Runs evaluate, or waits for the identical evaluation already in flight. A waiter whose ctx is done
+// first, or whose leader panicked, evaluates on its own instead.
+func (c *evaluationCoalescer) do(ctx context.Context, specType string, name string, variant string, user User, evaluate func() interface{}) interface{} {
+	// Each caller awaiting an exposure ack must send its own exposure, and loaded baggage is specific to its request
+	if c == nil || exposureAckFromContext(ctx) != nil || loadedEvaluationBaggageFromContext(ctx) != nil {
+		return evaluate()
+	}
+	key, ok := evaluationCoalescingKey(specType, name, variant, user)
+	if !ok {
+		return evaluate()
+	}
+	c.mu.Lock()
+	if inFlight, exists := c.inFlight[key]; exists {
+		inFlight.waiters++
+		c.mu.Unlock()
+		select {
+		case <-inFlight.done:
+			if inFlight.result != nil {
+				return inFlight.result
+			}
+		case <-contextOrBackground(ctx).Done():
+		}
+		return evaluate()
+	}
+	inFlight := &coalescedEvaluation{done: make(chan struct{})}
+	c.inFlight[key] = inFlight
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.inFlight, key)
+		c.mu.Unlock()
+		close(inFlight.done)
+	}()
+	inFlight.result = evaluate()
+	return inFlight.result
+}
+
+func (c *evaluationCoalescer) waiting(specType string, name string, variant string, user User) int {
+	key, _ := evaluationCoalescingKey(specType, name, variant, user)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if inFlight, ok := c.inFlight[key]; ok {
+		return inFlight.waiters
+	}
+	return 0
+}
+
+func exposureVariant(disableLogExposures bool) string {
+	if disableLogExposures {
+		return "no_exposure"
+	}
+	return "exposure"
+}
@@ -0,0 +1,124 @@
+package statsig
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEvaluationCoalescing(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var evaluations, exposures int32
+	release := make(chan struct{})
+	newCoalescingClient := func(coalesce bool) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      string(specs),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			CoalesceEvaluations:  coalesce,
+			GateFallbacks: map[string]func(user User) bool{
+				"slow_gate": func(user User) bool {
+					atomic.AddInt32(&evaluations, 1)
+					<-release
+					return true
+				},
+			},
+			EvaluationCallbacks: EvaluationCallbacks{
+				GateEvaluationCallback: func(name string, result bool, exposure *ExposureEvent) {
+					if exposure != nil {
+						atomic.AddInt32(&exposures, 1)
+					}
+				},
+			},
+		})
+	}
+	t.Run("shares one evaluation and exposure between concurrent callers", func(t *testing.T) {
+		atomic.StoreInt32(&evaluations, 0)
+		atomic.StoreInt32(&exposures, 0)
+		release = make(chan struct{})
+		c := newCoalescingClient(true)
+		defer c.Shutdown()
+		user := User{UserID: "123", Email: "a@statsig.com"}
+		normalized := normalizeUser(user, *c.options)
+		callers := 5
+		results := make([]bool, callers)
+		wg := sync.WaitGroup{}
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = c.CheckGate(user, "slow_gate")
+			}(i)
+		}
+		waitForConditionWithMessage(t, func() bool { return c.coalescer.waiting("gate", "slow_gate", "exposure", normalized) == callers-1 }, "Expected the other callers to wait for the first")
+		close(release)
+		wg.Wait()
+		for i, result := range results {
+			if !result {
+				t.Errorf("Expected caller %d to receive the shared result", i)
+			}
+		}
+		if evaluations != 1 || exposures != 1 {
+			t.Errorf("Expected 1 evaluation and exposure, received %d and %d", evaluations, exposures)
+		}
+		c.CheckGate(user, "slow_gate")
+		if evaluations != 2 {
+			t.Errorf("Expected evaluations after the in-flight one returned to evaluate again")
+		}
+	})
+
+	t.Run("does not share between users or exposure logging", func(t *testing.T) {
+		atomic.StoreInt32(&evaluations, 0)
+		atomic.StoreInt32(&exposures, 0)
+		release = make(chan struct{})
+		c := newCoalescingClient(true)
+		defer c.Shutdown()
+		wg := sync.WaitGroup{}
+		check := func(f func()) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				f()
+			}()
+		}
+		check(func() { c.CheckGate(User{UserID: "123"}, "slow_gate") })
+		check(func() { c.CheckGate(User{UserID: "123", Country: "NZ"}, "slow_gate") })
+		check(func() { c.CheckGateWithExposureLoggingDisabled(User{UserID: "123"}, "slow_gate") })
+		waitForConditionWithMessage(t, func() bool { return atomic.LoadInt32(&evaluations) == 3 }, "Expected each caller to evaluate")
+		close(release)
+		wg.Wait()
+		if evaluations != 3 || exposures != 2 {
+			t.Errorf("Expected 3 evaluations and 2 exposures, received %d and %d", evaluations, exposures)
+		}
+	})
+
+	t.Run("evaluates each caller when disabled", func(t *testing.T) {
+		atomic.StoreInt32(&evaluations, 0)
+		release = make(chan struct{})
+		close(release)
+		c := newCoalescingClient(false)
+		defer c.Shutdown()
+		if c.coalescer != nil {
+			t.Errorf("Expected no coalescer unless enabled")
+		}
+		c.CheckGate(User{UserID: "123"}, "slow_gate")
+		c.CheckGate(User{UserID: "123"}, "slow_gate")
+		if evaluations != 2 {
+			t.Errorf("Expected 2 evaluations, received %d", evaluations)
+		}
+	})
+
+	t.Run("shares configs and layers", func(t *testing.T) {
+		c := newCoalescingClient(true)
+		defer c.Shutdown()
+		user := User{UserID: "123", Email: "a@statsig.com"}
+		if config := c.GetConfig(user, "test_config"); config.GetNumber("number", 0) != 7 {
+			t.Errorf("Expected the config to be evaluated, received %+v", config.Value)
+		}
+		if layer := c.GetLayer(user, "a_layer"); layer.Name != "a_layer" {
+			t.Errorf("Expected the layer to be evaluated, received %+v", layer)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package statsig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type evaluationContextKey struct{}
+
+func TestEvaluationContext(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var dcsCalls int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			// always_on_gate is only created after the initial sync, and fetching it is slow
+			if atomic.AddInt32(&dcsCalls, 1) == 1 {
+				_, _ = res.Write([]byte(strings.Replace(string(specs), `"always_on_gate"`, `"other_gate"`, 1)))
+				return
+			}
+			time.Sleep(200 * time.Millisecond)
+			_, _ = res.Write([]byte(strings.Replace(string(specs), `"time": 1631638014811`, `"time": 1631638014812`, 1)))
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                     testServer.URL,
+		ConfigSyncInterval:      time.Hour,
+		StatsigLoggerOptions:    getStatsigLoggerOptionsForTest(t),
+		UnknownSpecFetchOptions: UnknownSpecFetchOptions{Enabled: true},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if c.CheckGateCtx(ctx, user, "always_on_gate") {
+			t.Errorf("Expected the default value once the context is done")
+		}
+		if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+			t.Errorf("Expected the evaluation to return at the deadline, took %s", elapsed)
+		}
+
+		// The shared fetch is not cancelled, so the gate is served once it completes
+		time.Sleep(300 * time.Millisecond)
+		if !c.CheckGateCtx(context.Background(), user, "always_on_gate") {
+			t.Errorf("Expected the fetch to complete for other callers")
+		}
+		if calls := atomic.LoadInt32(&dcsCalls); calls != 2 {
+			t.Errorf("Expected a single on-demand fetch, received %d requests", calls)
+		}
+	})
+
+	t.Run("evaluates locally with a done context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if !c.CheckGateCtx(ctx, user, "always_on_gate") || c.GetConfigCtx(ctx, user, "test_config").RuleID == "" {
+			t.Errorf("Expected specs in the ruleset to be evaluated")
+		}
+		if c.GetExperimentCtx(ctx, user, "sample_experiment").Name != "sample_experiment" || c.GetLayerCtx(ctx, user, "a_layer").Name != "a_layer" {
+			t.Errorf("Expected experiments and layers to be evaluated")
+		}
+	})
+
+	t.Run("passes context values to requests", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), evaluationContextKey{}, "trace")
+		req, _ := c.transport.buildRequest(ctx, "POST", "/check_gate", nil)
+		if req.Context().Value(evaluationContextKey{}) != "trace" {
+			t.Errorf("Expected the request to carry the context's values")
+		}
+		detached := detachedContext{ctx}
+		if detached.Value(evaluationContextKey{}) != "trace" || detached.Done() != nil {
+			t.Errorf("Expected a detached context to keep values without cancellation")
+		}
+	})
+}
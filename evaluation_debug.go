@@ -0,0 +1,66 @@
+package statsig
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// The normalized user an exposure was evaluated against, delivered to EvaluationDebugOptions.Sink.
+// Unlike logged exposures, the user includes private attributes.
+type EvaluationInputSnapshot struct {
+	EventName ExposureEventName
+	Metadata  map[string]string
+	User      User
+	Time      int64
+}
+
+// Attached to sampled exposures. Private attributes are omitted unless EvaluationDebugOptions.PrivateAttributeHashKey
+// is set, in which case their values are replaced by a keyed hash that can be compared against expected values
+// by holders of the key, without low entropy values such as emails being reversible by dictionary lookup.
+type EvaluationInput struct {
+	User User `json:"user"`
+}
+
+// The keyed hash of a private attribute value attached to sampled exposures, for comparing against expected values
+func HashPrivateAttribute(key []byte, value interface{}) string {
+	return signEventBytes(key, []byte(fmt.Sprint(value)))
+}
+
+func (l *logger) shouldSampleEvaluationInput() bool {
+	rate := l.options.EvaluationDebugOptions.SampleRate
+	if rate <= 0 {
+		return false
+	}
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// Must be called before private attributes are stripped from the exposure user
+func (l *logger) sampleEvaluationInput(evt *ExposureEvent) {
+	if !l.shouldSampleEvaluationInput() {
+		return
+	}
+	if sink := l.options.EvaluationDebugOptions.Sink; sink != nil {
+		metadata := make(map[string]string, len(evt.Metadata))
+		for k, v := range evt.Metadata {
+			metadata[k] = v
+		}
+		sink(EvaluationInputSnapshot{
+			EventName: evt.EventName,
+			Metadata:  metadata,
+			User:      evt.User,
+			Time:      getUnixMilli(),
+		})
+		return
+	}
+	// Shipped with the exposure, so custom fields are filtered as on the exposure's user
+	user := l.loggedUser(evt.User)
+	key := l.options.EvaluationDebugOptions.PrivateAttributeHashKey
+	if len(evt.User.PrivateAttributes) > 0 && len(key) > 0 {
+		hashed := make(map[string]interface{}, len(evt.User.PrivateAttributes))
+		for k, v := range evt.User.PrivateAttributes {
+			hashed[k] = HashPrivateAttribute(key, v)
+		}
+		user.PrivateAttributes = hashed
+	}
+	evt.EvaluationInput = &EvaluationInput{User: user}
+}
@@ -0,0 +1,132 @@
+package statsig
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEvaluationInputSampling(t *testing.T) {
+	var events []ExposureEvent
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			bytes, _ := os.ReadFile("download_config_specs.json")
+			_, _ = res.Write(bytes)
+		} else if strings.Contains(req.URL.Path, "log_event") {
+			input := &struct {
+				Events []ExposureEvent `json:"events"`
+			}{}
+			defer req.Body.Close()
+			buf := new(bytes.Buffer)
+			_, _ = buf.ReadFrom(req.Body)
+			_ = json.Unmarshal(buf.Bytes(), &input)
+			events = input.Events
+		}
+	}))
+	defer testServer.Close()
+
+	user := User{
+		UserID:            "some_user_id",
+		Email:             "someuser@statsig.com",
+		PrivateAttributes: map[string]interface{}{"secret": "value"},
+	}
+
+	t.Run("attaches the evaluated user with hashed private attributes", func(t *testing.T) {
+		events = nil
+		key := []byte("deployment-secret")
+		c := NewClientWithOptions("secret-key", &Options{
+			API:                    testServer.URL,
+			Environment:            Environment{Tier: "test"},
+			OutputLoggerOptions:    getOutputLoggerOptionsForTest(t),
+			StatsigLoggerOptions:   getStatsigLoggerOptionsForTest(t),
+			EvaluationDebugOptions: EvaluationDebugOptions{SampleRate: 1, PrivateAttributeHashKey: key},
+		})
+		c.CheckGate(user, "always_on_gate")
+		c.Shutdown()
+
+		if len(events) != 1 || events[0].EvaluationInput == nil {
+			t.Fatalf("Expected a single exposure with an evaluation input, received %+v", events)
+		}
+		input := events[0].EvaluationInput.User
+		if input.UserID != "some_user_id" || input.StatsigEnvironment["tier"] != "test" {
+			t.Errorf("Expected the normalized user, received %+v", input)
+		}
+		if hashed := input.PrivateAttributes["secret"]; hashed != HashPrivateAttribute(key, "value") || hashed == getHashBase64StringEncoding("value") {
+			t.Errorf("Expected private attribute to be hashed with the key, received %+v", input.PrivateAttributes)
+		}
+		if events[0].User.PrivateAttributes != nil {
+			t.Errorf("Expected private attributes to be stripped from the exposure user")
+		}
+	})
+
+	t.Run("omits private attributes without a hash key", func(t *testing.T) {
+		events = nil
+		c := NewClientWithOptions("secret-key", &Options{
+			API:                    testServer.URL,
+			OutputLoggerOptions:    getOutputLoggerOptionsForTest(t),
+			StatsigLoggerOptions:   getStatsigLoggerOptionsForTest(t),
+			EvaluationDebugOptions: EvaluationDebugOptions{SampleRate: 1},
+		})
+		c.CheckGate(user, "always_on_gate")
+		c.Shutdown()
+
+		if len(events) != 1 || events[0].EvaluationInput == nil {
+			t.Fatalf("Expected a single exposure with an evaluation input, received %+v", events)
+		}
+		if input := events[0].EvaluationInput.User; input.PrivateAttributes != nil || input.Email != "someuser@statsig.com" {
+			t.Errorf("Expected only private attributes to be omitted, received %+v", input)
+		}
+	})
+
+	t.Run("delivers unhashed snapshots to the sink", func(t *testing.T) {
+		events = nil
+		var snapshots []EvaluationInputSnapshot
+		c := NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			EvaluationDebugOptions: EvaluationDebugOptions{
+				SampleRate: 1,
+				Sink: func(snapshot EvaluationInputSnapshot) {
+					snapshots = append(snapshots, snapshot)
+				},
+			},
+		})
+		c.GetConfig(user, "test_config")
+		c.Shutdown()
+
+		if len(snapshots) != 1 {
+			t.Fatalf("Expected 1 snapshot, received %d", len(snapshots))
+		}
+		if snapshots[0].EventName != ConfigExposureEventName || snapshots[0].Metadata["config"] != "test_config" {
+			t.Errorf("Unexpected snapshot %+v", snapshots[0])
+		}
+		if snapshots[0].User.PrivateAttributes["secret"] != "value" {
+			t.Errorf("Expected private attributes in the snapshot")
+		}
+		if len(events) != 1 || events[0].EvaluationInput != nil {
+			t.Errorf("Expected the exposure to be logged without an evaluation input")
+		}
+	})
+
+	t.Run("does not sample by default", func(t *testing.T) {
+		events = nil
+		c := NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+		c.CheckGate(user, "always_on_gate")
+		c.Shutdown()
+
+		if len(events) != 1 || events[0].EvaluationInput != nil {
+			t.Errorf("Expected the exposure to be logged without an evaluation input")
+		}
+	})
+}
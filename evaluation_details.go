@@ -11,6 +11,10 @@ const (
 	reasonDataAdapter        evaluationReason = "DataAdapter"
 	reasonNetworkNotModified evaluationReason = "NetworkNotModified"
 	reasonPersisted          evaluationReason = "Persisted"
+	reasonPrecomputed        evaluationReason = "Precomputed"
+	reasonHistorical         evaluationReason = "Historical"
+	reasonError              evaluationReason = "Error"
+	reasonFailsafe           evaluationReason = "Failsafe"
 )
 
 type evaluationDetails struct {
@@ -32,3 +36,49 @@ func newEvaluationDetails(
 		serverTime:     getUnixMilli(),
 	}
 }
+
+// How a result was evaluated, e.g. to detect results from a stale ruleset or defaults returned before initialization
+type EvaluationDetails struct {
+	Reason         string `json:"reason"` // Network, Bootstrap, DataAdapter, LocalOverride, Unrecognized, Uninitialized, Failsafe, or Error for invalid users
+	RuleID         string `json:"ruleID"`
+	ConfigSyncTime int64  `json:"configSyncTime"` // Unix milliseconds of the ruleset evaluated, 0 if none was loaded
+	InitTime       int64  `json:"initTime"`       // Unix milliseconds of the ruleset loaded during initialization
+	ServerTime     int64  `json:"serverTime"`     // Unix milliseconds of the evaluation
+}
+
+func exportEvaluationDetails(details *evaluationDetails, ruleID string) EvaluationDetails {
+	if details == nil {
+		return EvaluationDetails{Reason: string(reasonError), RuleID: ruleID, ServerTime: getUnixMilli()}
+	}
+	return EvaluationDetails{
+		Reason:         string(details.reason),
+		RuleID:         ruleID,
+		ConfigSyncTime: details.configSyncTime,
+		InitTime:       details.initTime,
+		ServerTime:     details.serverTime,
+	}
+}
+
+// Checks the value of a Feature Gate for the given user, with how it was evaluated
+func (c *Client) CheckGateWithDetails(user User, gate string) (bool, EvaluationDetails) {
+	res := c.checkGateImpl(user, gate, checkGateOptions{disableLogExposures: false})
+	return res.Value, exportEvaluationDetails(res.evaluationDetails, res.RuleID)
+}
+
+// Gets the DynamicConfig value for the given user, with how it was evaluated
+func (c *Client) GetConfigWithDetails(user User, config string) (DynamicConfig, EvaluationDetails) {
+	res := c.GetConfig(user, config)
+	return res, exportEvaluationDetails(res.EvaluationDetails, res.RuleID)
+}
+
+// Gets the DynamicConfig value of an Experiment for the given user, with how it was evaluated
+func (c *Client) GetExperimentWithDetails(user User, experiment string) (DynamicConfig, EvaluationDetails) {
+	res := c.GetExperiment(user, experiment)
+	return res, exportEvaluationDetails(res.EvaluationDetails, res.RuleID)
+}
+
+// Gets the Layer object for the given user, with how it was evaluated
+func (c *Client) GetLayerWithDetails(user User, layer string) (Layer, EvaluationDetails) {
+	res := c.GetLayer(user, layer)
+	return res, exportEvaluationDetails(res.EvaluationDetails, res.RuleID)
+}
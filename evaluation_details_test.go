@@ -220,3 +220,48 @@ func TestEvaluationDetails(t *testing.T) {
 		}, configSyncTime)
 	})
 }
+
+func TestEvaluationDetailsOnResults(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(specs),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	user := User{UserID: "some_user_id", Email: "a@statsig.com"}
+	expectDetails := func(name string, details EvaluationDetails, reason string, ruleID string, syncTime int64) {
+		if details.Reason != reason || details.RuleID != ruleID || details.ConfigSyncTime != syncTime || details.ServerTime == 0 {
+			t.Errorf("Expected %s to be evaluated with %s, %s and %d, received %+v", name, reason, ruleID, syncTime, details)
+		}
+	}
+
+	value, details := c.CheckGateWithDetails(user, "always_on_gate")
+	if !value {
+		t.Errorf("Expected always_on_gate to pass")
+	}
+	expectDetails("always_on_gate", details, "Bootstrap", "6N6Z8ODekNYZ7F8gFdoLP5", configSyncTime)
+	if details.InitTime != configSyncTime {
+		t.Errorf("Expected the init time of the bootstrapped ruleset, received %d", details.InitTime)
+	}
+	_, details = c.CheckGateWithDetails(User{UserID: "some_user_id"}, "on_for_statsig_email")
+	expectDetails("on_for_statsig_email", details, "Bootstrap", RuleIDDefault, configSyncTime)
+	config, details := c.GetConfigWithDetails(user, "test_config")
+	if config.GetNumber("number", 0) != 7 {
+		t.Errorf("Expected test_config to be evaluated, received %+v", config.Value)
+	}
+	expectDetails("test_config", details, "Bootstrap", config.RuleID, configSyncTime)
+	_, details = c.GetExperimentWithDetails(user, "sample_experiment")
+	expectDetails("sample_experiment", details, "Bootstrap", "2RamGsERWbWMIMnSfOlQuX", configSyncTime)
+	_, details = c.GetLayerWithDetails(user, "a_layer")
+	expectDetails("a_layer", details, "Bootstrap", "2RamGsERWbWMIMnSfOlQuX", configSyncTime)
+
+	_, details = c.CheckGateWithDetails(user, "not_a_gate")
+	expectDetails("not_a_gate", details, "Unrecognized", "", configSyncTime)
+	c.OverrideGate("always_on_gate", false)
+	_, details = c.CheckGateWithDetails(user, "always_on_gate")
+	expectDetails("overridden gate", details, "LocalOverride", RuleIDOverride, configSyncTime)
+	_, details = c.GetConfigWithDetails(User{}, "test_config")
+	expectDetails("invalid user", details, "Error", "", 0)
+}
@@ -0,0 +1,160 @@
+package statsig
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Pre-resolved form of a configCondition, built once when specs are ingested
+// so evaluation does not re-parse target values on every call
+type compiledCondition struct {
+	condType           string
+	operator           string
+	targetSet          map[string]bool // lowercased string forms of the target array, for any/none
+	targetSetExact     map[string]bool // string forms of the target array, for the case sensitive variants
+	targetLocales      map[string]bool // normalized target locales of any/none conditions on the locale, see LocaleMatchingPolicy
+	targetRegex        *regexp.Regexp
+	targetRegexInvalid bool
+	targetVersion      []int64
+	targetVersionValid bool
+	targetTime         time.Time
+	userBucketSalt     string
+	hasUserBucketSalt  bool
+	specName           string // Of the spec and rule the condition belongs to, which seed user_bucket salts with Options.TestHashingSeed
+	ruleName           string
+	userBucketCount    uint64
+	emptyTargetList    bool // A list operator with no targets, see EmptyTargetListPolicy
+}
+
+// Experiments default to 1000 buckets, which additionalValues.bucket_count overrides
+const defaultUserBucketCount = 1000
+
+func compileConfigSpec(spec *configSpec) {
+	for i := range spec.Rules {
+		for j := range spec.Rules[i].Conditions {
+			cond := &spec.Rules[i].Conditions[j]
+			cond.compiled = compileCondition(*cond)
+			cond.compiled.specName = spec.Name
+			cond.compiled.ruleName = spec.Rules[i].Name
+		}
+	}
+	if strings.ToLower(spec.Type) == "feature_gate" {
+		spec.constant = compileConstantGate(*spec)
+	}
+}
+
+func compileCondition(cond configCondition) *compiledCondition {
+	compiled := &compiledCondition{
+		condType: strings.ToLower(cond.Type),
+		operator: strings.ToLower(cond.Operator),
+	}
+	compiled.emptyTargetList = isEmptyTargetList(compiled.operator, cond.TargetValue)
+	switch compiled.operator {
+	case "any", "none":
+		compiled.targetSet = toStringSet(cond.TargetValue, true)
+		if isLocaleCondition(compiled.condType, cond.Field) {
+			compiled.targetLocales = toLocaleSet(cond.TargetValue)
+		}
+	case "any_case_sensitive", "none_case_sensitive":
+		compiled.targetSetExact = toStringSet(cond.TargetValue, false)
+	case "str_matches":
+		if cond.TargetValue != nil {
+			regex, err := regexp.Compile(toString(cond.TargetValue))
+			if err != nil {
+				compiled.targetRegexInvalid = true
+			} else {
+				compiled.targetRegex = regex
+			}
+		}
+	case "version_gt", "version_gte", "version_lt", "version_lte", "version_eq", "version_neq":
+		compiled.targetVersion, compiled.targetVersionValid = parseVersion(cond.TargetValue)
+	case "before", "after", "on":
+		compiled.targetTime = getTime(cond.TargetValue)
+	}
+	if compiled.condType == "user_bucket" {
+		if salt, ok := cond.AdditionalValues["salt"]; ok && salt != nil {
+			compiled.userBucketSalt = stringify(salt)
+			compiled.hasUserBucketSalt = true
+		}
+		compiled.userBucketCount = defaultUserBucketCount
+		if count, ok := getNumericValue(cond.AdditionalValues["bucket_count"]); ok && count >= 1 {
+			compiled.userBucketCount = uint64(count)
+		}
+	}
+	return compiled
+}
+
+// Specs that were not ingested through setConfigSpecs (e.g. constructed in tests) are compiled on demand
+func (c configCondition) getCompiled() *compiledCondition {
+	if c.compiled != nil {
+		return c.compiled
+	}
+	return compileCondition(c)
+}
+
+func toStringSet(arr interface{}, ignoreCase bool) map[string]bool {
+	set := make(map[string]bool)
+	array, ok := arr.([]interface{})
+	if !ok {
+		return set
+	}
+	for _, v := range array {
+		if v == nil {
+			continue
+		}
+		str := stringify(v)
+		if ignoreCase {
+			str = strings.ToLower(str)
+		}
+		set[str] = true
+	}
+	return set
+}
+
+func stringify(v interface{}) string {
+	if reflect.TypeOf(v).Kind() == reflect.String {
+		return toString(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func setContains(set map[string]bool, value interface{}, ignoreCase bool) bool {
+	if value == nil {
+		return false
+	}
+	str := stringify(value)
+	if ignoreCase {
+		str = strings.ToLower(str)
+	}
+	return set[str]
+}
+
+func parseVersion(v interface{}) ([]int64, bool) {
+	str, ok := v.(string)
+	if !ok {
+		return nil, false
+	}
+	version := strings.Split(str, "-")[0]
+	if len(version) == 0 {
+		return nil, false
+	}
+	parts, err := convertVersionStringToParts(version)
+	if err != nil {
+		return nil, false
+	}
+	return parts, true
+}
+
+func compareVersionToTarget(a interface{}, compiled *compiledCondition, fun func(x, y []int64) bool) bool {
+	if !compiled.targetVersionValid {
+		return false
+	}
+	parts, ok := parseVersion(a)
+	if !ok {
+		return false
+	}
+	return fun(parts, compiled.targetVersion)
+}
@@ -0,0 +1,83 @@
+package statsig
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestCompiledConditionsMatchUncompiled(t *testing.T) {
+	type testCase struct {
+		cond  configCondition
+		user  User
+		match bool
+	}
+	user := User{
+		UserID:     "123",
+		Email:      "Someone@Statsig.com",
+		AppVersion: "1.2.3-beta",
+		Custom:     map[string]interface{}{"level": float64(7), "joined": "2020-01-01T00:00:00Z"},
+	}
+	cases := []testCase{
+		{configCondition{Type: "user_field", Operator: "any", Field: "email", TargetValue: []interface{}{"someone@statsig.com"}}, user, true},
+		{configCondition{Type: "user_field", Operator: "any_case_sensitive", Field: "email", TargetValue: []interface{}{"someone@statsig.com"}}, user, false},
+		{configCondition{Type: "user_field", Operator: "none", Field: "level", TargetValue: []interface{}{7}}, user, false},
+		{configCondition{Type: "user_field", Operator: "none_case_sensitive", Field: "email", TargetValue: []interface{}{nil}}, user, true},
+		{configCondition{Type: "user_field", Operator: "str_matches", Field: "email", TargetValue: "^Some.*com$"}, user, true},
+		{configCondition{Type: "user_field", Operator: "str_matches", Field: "email", TargetValue: "(["}, user, false},
+		{configCondition{Type: "user_field", Operator: "version_gte", Field: "app_version", TargetValue: "1.2"}, user, true},
+		{configCondition{Type: "user_field", Operator: "version_lt", Field: "app_version", TargetValue: "1.2.3"}, user, false},
+		{configCondition{Type: "user_field", Operator: "version_eq", Field: "app_version", TargetValue: "bad.version"}, user, false},
+		{configCondition{Type: "user_field", Operator: "after", Field: "joined", TargetValue: float64(1500000000000)}, user, true},
+		{configCondition{Type: "user_field", Operator: "on", Field: "joined", TargetValue: "2020-01-01T10:00:00Z"}, user, true},
+		{configCondition{Type: "user_bucket", Operator: "lt", TargetValue: 1000, AdditionalValues: map[string]interface{}{"salt": "abc"}}, user, true},
+	}
+
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	e := &evaluator{store: &store{}}
+	for i, c := range cases {
+		uncompiled := e.evalCondition(c.user, c.cond, 0)
+		compiledCond := c.cond
+		compiledCond.compiled = compileCondition(c.cond)
+		compiled := e.evalCondition(c.user, compiledCond, 0)
+		if uncompiled.Pass != c.match || compiled.Pass != c.match {
+			t.Errorf("Case %d: expected %t, received uncompiled %t and compiled %t", i, c.match, uncompiled.Pass, compiled.Pass)
+		}
+	}
+}
+
+func TestSpecsAreCompiledOnIngest(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(bytes),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	gate, _ := c.evaluator.store.getGate("on_for_statsig_email")
+	if gate.Rules[0].Conditions[0].compiled == nil {
+		t.Errorf("Expected conditions to be compiled when specs are set")
+	}
+}
+
+func BenchmarkEvalConditionAny(b *testing.B) {
+	var targets []interface{}
+	_ = json.Unmarshal([]byte(`["a@statsig.com","b@statsig.com","c@statsig.com","d@statsig.com","e@statsig.com","f@statsig.com"]`), &targets)
+	cond := configCondition{Type: "user_field", Operator: "any", Field: "email", TargetValue: targets}
+	user := User{UserID: "123", Email: "f@statsig.com"}
+	e := &evaluator{store: &store{}}
+
+	b.Run("uncompiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			e.evalCondition(user, cond, 0)
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		compiledCond := cond
+		compiledCond.compiled = compileCondition(cond)
+		for i := 0; i < b.N; i++ {
+			e.evalCondition(user, compiledCond, 0)
+		}
+	})
+}
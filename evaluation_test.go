@@ -1,6 +1,7 @@
 package statsig
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -128,7 +129,7 @@ func test_helper(apiOverride string, t *testing.T) {
 	for _, entry := range d.Entries {
 		u := entry.User
 		for gate, serverResult := range entry.GatesV2 {
-			sdkResult := c.evaluator.checkGate(u, gate)
+			sdkResult := c.evaluator.checkGate(context.Background(), u, gate)
 			if sdkResult.Pass != serverResult.Value {
 				t.Errorf("Values are different for gate %s. SDK got %t but server is %t. User is %+v",
 					gate, sdkResult.Pass, serverResult.Value, u)
@@ -147,7 +148,7 @@ func test_helper(apiOverride string, t *testing.T) {
 		}
 
 		for config, serverResult := range entry.Configs {
-			sdkResult := c.evaluator.getConfig(u, config, nil)
+			sdkResult := c.evaluator.getConfig(context.Background(), u, config, nil)
 			if !reflect.DeepEqual(sdkResult.ConfigValue.Value, serverResult.Value) {
 				t.Errorf("Values are different for config %s. SDK got %s but server is %s. User is %+v",
 					config, sdkResult.ConfigValue.Value, serverResult.Value, u)
@@ -171,7 +172,7 @@ func test_helper(apiOverride string, t *testing.T) {
 		}
 
 		for layer, serverResult := range entry.Layers {
-			sdkResult := c.evaluator.getLayer(u, layer)
+			sdkResult := c.evaluator.getLayer(context.Background(), u, layer)
 			if !reflect.DeepEqual(sdkResult.ConfigValue.Value, serverResult.Value) {
 				t.Errorf("Values are different for layer %s. SDK got %s but server is %s. User is %+v",
 					layer, sdkResult.ConfigValue.Value, serverResult.Value, u)
@@ -1,20 +1,17 @@
 package statsig
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/statsig-io/ip3country-go/pkg/countrylookup"
-	"github.com/ua-parser/uap-go/uaparser"
 )
 
 type evalResult struct {
@@ -29,6 +26,8 @@ type evalResult struct {
 	ExplicitParameters            map[string]bool
 	EvaluationDetails             *evaluationDetails
 	IsExperimentGroup             *bool
+	IDType                        string
+	Variant                       string
 }
 
 func newEvalResultFromUserPersistedValues(configName string, persitedValues UserPersistedValues) *evalResult {
@@ -48,6 +47,7 @@ func newEvalResultFromMap(evalMap map[string]interface{}) *evalResult {
 		safeParseJSONint64(evalMap["initTime"]),
 	)
 	configValue := evalMap["ConfigValue"].(map[string]interface{})
+	isExperimentGroup := true
 	if secondaryExposures, ok = evalMap["SecondaryExposures"].([]map[string]string); !ok {
 		secondaryExposures = make([]map[string]string, 0)
 	}
@@ -58,7 +58,7 @@ func newEvalResultFromMap(evalMap map[string]interface{}) *evalResult {
 		GroupName:          evalMap["GroupName"].(string),
 		SecondaryExposures: secondaryExposures,
 		ConfigValue: DynamicConfig{
-			configBase{
+			configBase: configBase{
 				Name:              configValue["name"].(string),
 				Value:             configValue["value"].(map[string]interface{}),
 				RuleID:            configValue["rule_id"].(string),
@@ -66,6 +66,8 @@ func newEvalResultFromMap(evalMap map[string]interface{}) *evalResult {
 				EvaluationDetails: evaluationDetails,
 			},
 		},
+		// Only experiment group assignments are persisted
+		IsExperimentGroup: &isExperimentGroup,
 		EvaluationDetails: evaluationDetails,
 	}
 }
@@ -87,9 +89,16 @@ type evaluator struct {
 	gateOverrides          map[string]bool
 	configOverrides        map[string]map[string]interface{}
 	layerOverrides         map[string]map[string]interface{}
-	countryLookup          *countrylookup.CountryLookup
-	uaParser               *uaparser.Parser
+	countryLookup          IPCountryLookup
+	uaParser               UserAgentParser
 	persistentStorageUtils *userPersistentStorageUtils
+	options                *Options
+	missingUnitIDs         missingUnitIDCounter
+	precomputed            map[string]*precomputedEvaluations
+	parent                 *evaluator       // Set for tenant evaluators, which read overrides from their parent
+	tenant                 *tenantPartition // Restricts evaluation to a tenant's specs
+	shadow                 *shadowEvaluation
+	reference              bool // Set for the reference engine of ShadowEvaluationOptions, see forReference
 	mu                     sync.RWMutex
 }
 
@@ -104,8 +113,8 @@ func newEvaluator(
 	sdkKey string,
 ) *evaluator {
 	store := newStore(transport, errorBoundary, options, diagnostics, sdkKey)
-	parser := uaparser.NewFromSaved()
-	countryLookup := countrylookup.New()
+	parser := newUserAgentParser(options)
+	countryLookup := newIPCountryLookup(options)
 	defer func() {
 		if err := recover(); err != nil {
 			errorBoundary.logException(toError(err))
@@ -114,68 +123,105 @@ func newEvaluator(
 	}()
 	persistentStorageUtils := newUserPersistentStorageUtils(options)
 
-	return &evaluator{
+	e := &evaluator{
 		store:                  store,
 		countryLookup:          countryLookup,
 		uaParser:               parser,
 		gateOverrides:          make(map[string]bool),
 		configOverrides:        make(map[string]map[string]interface{}),
 		layerOverrides:         make(map[string]map[string]interface{}),
+		precomputed:            make(map[string]*precomputedEvaluations),
 		persistentStorageUtils: persistentStorageUtils,
+		options:                options,
 	}
+	e.shadow = newShadowEvaluation(e, options)
+	return e
 }
 
 func (e *evaluator) shutdown() {
 	if e.store.dataAdapter != nil {
+		e.store.adapterWrites.close()
 		e.store.dataAdapter.Shutdown()
 	}
 	e.store.stopPolling()
+	e.store.closeIDListFiles()
 }
 
 func (e *evaluator) createEvaluationDetails(reason evaluationReason) *evaluationDetails {
 	e.store.mu.RLock()
-	defer e.store.mu.RUnlock()
-	return newEvaluationDetails(reason, e.store.lastSyncTime, e.store.initialSyncTime)
+	details := newEvaluationDetails(reason, e.store.lastSyncTime, e.store.initialSyncTime)
+	e.store.mu.RUnlock()
+	details.serverTime = e.store.transport.eventTime()
+	return details
 }
 
-func (e *evaluator) checkGate(user User, gateName string) *evalResult {
-	return e.evalGate(user, gateName, 0)
+func (e *evaluator) checkGate(ctx context.Context, user User, gateName string) *evalResult {
+	if res, ok := e.getBaggageGate(ctx, user, gateName); ok {
+		return res
+	}
+	res := e.evalGate(user, gateName, 0)
+	if e.shouldFetchForUnknownSpec(gateName, res) && e.store.fetchForUnknownSpec(ctx) {
+		res = e.evalGate(user, gateName, 0)
+	}
+	e.shadow.compare(SpecTypeFeatureGate, gateName, user, res)
+	return res
 }
 
 func (e *evaluator) evalGate(user User, gateName string, depth int) *evalResult {
+	if !e.inTenant(gateName) {
+		return e.unrecognizedEvalResult()
+	}
 	if gateOverride, hasOverride := e.getGateOverride(gateName); hasOverride {
 		evalDetails := e.createEvaluationDetails(reasonLocalOverride)
 		return &evalResult{
 			Pass:               gateOverride,
-			RuleID:             "override",
+			RuleID:             RuleIDOverride,
 			EvaluationDetails:  evalDetails,
 			SecondaryExposures: make([]map[string]string, 0),
 		}
 	}
+	if depth == 0 && !e.reference {
+		if precomputed, ok := e.getPrecomputedGate(user, gateName); ok {
+			return precomputed
+		}
+	}
 	if gate, hasGate := e.store.getGate(gateName); hasGate {
+		if gate.constant != nil && !e.reference {
+			return e.evalConstantGate(gate.constant)
+		}
 		return e.eval(user, gate, depth+1)
 	}
-	emptyEvalResult := new(evalResult)
-	emptyEvalResult.EvaluationDetails = e.createEvaluationDetails(reasonUnrecognized)
-	emptyEvalResult.SecondaryExposures = make([]map[string]string, 0)
-	return emptyEvalResult
+	return e.unrecognizedEvalResult()
 }
 
-func (e *evaluator) getConfig(user User, configName string, persistedValues UserPersistedValues) *evalResult {
-	return e.evalConfig(user, configName, persistedValues, 0)
+func (e *evaluator) getConfig(ctx context.Context, user User, configName string, persistedValues UserPersistedValues) *evalResult {
+	res := e.evalConfig(user, configName, persistedValues, 0)
+	if e.shouldFetchForUnknownSpec(configName, res) && e.store.fetchForUnknownSpec(ctx) {
+		res = e.evalConfig(user, configName, persistedValues, 0)
+	}
+	e.shadow.compare(SpecTypeDynamicConfig, configName, user, res)
+	return res
 }
 
 func (e *evaluator) evalConfig(user User, configName string, persistedValues UserPersistedValues, depth int) *evalResult {
+	if !e.inTenant(configName) {
+		return e.unrecognizedEvalResult()
+	}
 	if configOverride, hasOverride := e.getConfigOverride(configName); hasOverride {
 		evalDetails := e.createEvaluationDetails(reasonLocalOverride)
 		return &evalResult{
 			Pass:               true,
-			ConfigValue:        *NewConfig(configName, configOverride, "override", "", evalDetails),
-			RuleID:             "override",
+			ConfigValue:        *NewConfig(configName, configOverride, RuleIDOverride, "", evalDetails),
+			RuleID:             RuleIDOverride,
 			EvaluationDetails:  evalDetails,
 			SecondaryExposures: make([]map[string]string, 0),
 		}
 	}
+	if depth == 0 {
+		if precomputed, ok := e.getPrecomputedConfig(user, configName); ok {
+			return precomputed
+		}
+	}
 	if config, hasConfig := e.store.getDynamicConfig(configName); hasConfig {
 		var evaluation *evalResult
 		if persistedValues != nil && config.IsActive != nil && *config.IsActive {
@@ -195,30 +241,44 @@ func (e *evaluator) evalConfig(user User, configName string, persistedValues Use
 		}
 		return evaluation
 	}
-	emptyEvalResult := new(evalResult)
-	emptyEvalResult.EvaluationDetails = e.createEvaluationDetails(reasonUnrecognized)
-	emptyEvalResult.SecondaryExposures = make([]map[string]string, 0)
-	return emptyEvalResult
+	return e.unrecognizedEvalResult()
 }
 
-func (e *evaluator) getLayer(user User, name string) *evalResult {
-	return e.evalLayer(user, name, 0)
+func (e *evaluator) getLayer(ctx context.Context, user User, name string) *evalResult {
+	res := e.evalLayer(user, name, 0)
+	if e.shouldFetchForUnknownSpec(name, res) && e.store.fetchForUnknownSpec(ctx) {
+		res = e.evalLayer(user, name, 0)
+	}
+	e.shadow.compare(SpecTypeLayer, name, user, res)
+	return res
 }
 
 func (e *evaluator) evalLayer(user User, name string, depth int) *evalResult {
+	if !e.inTenant(name) {
+		return e.unrecognizedEvalResult()
+	}
 	if layerOverride, hasOverride := e.getLayerOverride(name); hasOverride {
 		evalDetails := e.createEvaluationDetails(reasonLocalOverride)
 		return &evalResult{
 			Pass:               true,
-			ConfigValue:        *NewConfig(name, layerOverride, "override", "", evalDetails),
-			RuleID:             "override",
+			ConfigValue:        *NewConfig(name, layerOverride, RuleIDOverride, "", evalDetails),
+			RuleID:             RuleIDOverride,
 			EvaluationDetails:  evalDetails,
 			SecondaryExposures: make([]map[string]string, 0),
 		}
 	}
+	if depth == 0 {
+		if precomputed, ok := e.getPrecomputedLayer(user, name); ok {
+			return precomputed
+		}
+	}
 	if config, hasConfig := e.store.getLayerConfig(name); hasConfig {
 		return e.eval(user, config, depth+1)
 	}
+	return e.unrecognizedEvalResult()
+}
+
+func (e *evaluator) unrecognizedEvalResult() *evalResult {
 	emptyEvalResult := new(evalResult)
 	emptyEvalResult.EvaluationDetails = e.createEvaluationDetails(reasonUnrecognized)
 	emptyEvalResult.SecondaryExposures = make([]map[string]string, 0)
@@ -226,6 +286,9 @@ func (e *evaluator) evalLayer(user User, name string, depth int) *evalResult {
 }
 
 func (e *evaluator) getGateOverride(name string) (bool, bool) {
+	if e.parent != nil {
+		return e.parent.getGateOverride(name)
+	}
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	gate, ok := e.gateOverrides[name]
@@ -233,6 +296,9 @@ func (e *evaluator) getGateOverride(name string) (bool, bool) {
 }
 
 func (e *evaluator) getConfigOverride(name string) (map[string]interface{}, bool) {
+	if e.parent != nil {
+		return e.parent.getConfigOverride(name)
+	}
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	config, ok := e.configOverrides[name]
@@ -240,6 +306,9 @@ func (e *evaluator) getConfigOverride(name string) (map[string]interface{}, bool
 }
 
 func (e *evaluator) getLayerOverride(name string) (map[string]interface{}, bool) {
+	if e.parent != nil {
+		return e.parent.getLayerOverride(name)
+	}
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	layer, ok := e.layerOverrides[name]
@@ -267,6 +336,36 @@ func (e *evaluator) OverrideLayer(layer string, val map[string]interface{}) {
 	e.layerOverrides[layer] = val
 }
 
+// Remove the override of a Feature Gate, so it is evaluated normally again
+func (e *evaluator) RemoveGateOverride(gate string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.gateOverrides, gate)
+}
+
+// Remove the override of a DynamicConfig
+func (e *evaluator) RemoveConfigOverride(config string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.configOverrides, config)
+}
+
+// Remove the override of a Layer
+func (e *evaluator) RemoveLayerOverride(layer string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.layerOverrides, layer)
+}
+
+// Remove all gate, config and layer overrides
+func (e *evaluator) ClearOverrides() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.gateOverrides = make(map[string]bool)
+	e.configOverrides = make(map[string]map[string]interface{})
+	e.layerOverrides = make(map[string]map[string]interface{})
+}
+
 // Gets all evaluated values for the given user.
 // These values can then be given to a Statsig Client SDK via bootstrapping.
 func (e *evaluator) getClientInitializeResponse(user User, clientKey string) ClientInitializeResponse {
@@ -291,8 +390,10 @@ func (e *evaluator) eval(user User, spec configSpec, depth int) *evalResult {
 	}
 
 	var exposures = make([]map[string]string, 0)
-	defaultRuleID := "default"
-	if spec.Enabled {
+	defaultRuleID := RuleIDDefault
+	if !spec.Enabled {
+		defaultRuleID = RuleIDDisabled
+	} else if !e.isMissingUnitID(user, spec) {
 		for _, rule := range spec.Rules {
 			r := e.evalRule(user, rule, depth+1)
 			if r.FetchFromServer {
@@ -306,7 +407,7 @@ func (e *evaluator) eval(user User, spec configSpec, depth int) *evalResult {
 					return delegatedResult
 				}
 
-				pass := evalPassPercent(user, rule, spec)
+				pass := e.evalPassPercent(user, rule, spec)
 				if isDynamicConfig {
 					if pass {
 						var ruleConfigValue map[string]interface{}
@@ -324,24 +425,29 @@ func (e *evaluator) eval(user User, spec configSpec, depth int) *evalResult {
 						SecondaryExposures:            exposures,
 						UndelegatedSecondaryExposures: exposures,
 						EvaluationDetails:             evalDetails,
+						IDType:                        spec.IDType,
 					}
 					if rule.IsExperimentGroup != nil {
 						result.IsExperimentGroup = rule.IsExperimentGroup
 					}
 					return result
 				} else {
+					variant := spec.DefaultVariant
+					if pass {
+						variant = e.evalVariant(user, rule, spec)
+					}
 					return &evalResult{
 						Pass:               pass,
 						RuleID:             rule.ID,
 						GroupName:          rule.GroupName,
 						SecondaryExposures: exposures,
 						EvaluationDetails:  evalDetails,
+						IDType:             spec.IDType,
+						Variant:            variant,
 					}
 				}
 			}
 		}
-	} else {
-		defaultRuleID = "disabled"
 	}
 
 	if isDynamicConfig {
@@ -352,14 +458,15 @@ func (e *evaluator) eval(user User, spec configSpec, depth int) *evalResult {
 			SecondaryExposures:            exposures,
 			UndelegatedSecondaryExposures: exposures,
 			EvaluationDetails:             evalDetails,
+			IDType:                        spec.IDType,
 		}
 	}
-	return &evalResult{Pass: false, RuleID: defaultRuleID, SecondaryExposures: exposures}
+	return &evalResult{Pass: false, RuleID: defaultRuleID, SecondaryExposures: exposures, EvaluationDetails: evalDetails, IDType: spec.IDType, Variant: spec.DefaultVariant}
 }
 
 func (e *evaluator) evalDelegate(user User, rule configRule, exposures []map[string]string, depth int) *evalResult {
 	config, hasConfig := e.store.getDynamicConfig(rule.ConfigDelegate)
-	if !hasConfig {
+	if !hasConfig || !e.inTenant(rule.ConfigDelegate) {
 		return nil
 	}
 
@@ -376,12 +483,8 @@ func (e *evaluator) evalDelegate(user User, rule configRule, exposures []map[str
 	return result
 }
 
-func evalPassPercent(user User, rule configRule, spec configSpec) bool {
-	ruleSalt := rule.Salt
-	if ruleSalt == "" {
-		ruleSalt = rule.ID
-	}
-	hash := getHashUint64Encoding(spec.Salt + "." + ruleSalt + "." + getUnitID(user, rule.IDType))
+func (e *evaluator) evalPassPercent(user User, rule configRule, spec configSpec) bool {
+	hash := getHashUint64Encoding(e.getRuleHashSalt(rule, spec) + "." + getUnitID(user, rule.IDType))
 
 	return float64(hash%10000) < (rule.PassPercentage * 100)
 }
@@ -418,8 +521,9 @@ func (e *evaluator) evalRule(user User, rule configRule, depth int) *evalResult
 
 func (e *evaluator) evalCondition(user User, cond configCondition, depth int) *evalResult {
 	var value interface{}
-	condType := strings.ToLower(cond.Type)
-	op := strings.ToLower(cond.Operator)
+	compiled := e.compiledCondition(cond)
+	condType := compiled.condType
+	op := compiled.operator
 	switch condType {
 	case "public":
 		return &evalResult{Pass: true}
@@ -444,24 +548,24 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int) *e
 			return &evalResult{Pass: !result.Pass, SecondaryExposures: allExposures}
 		}
 	case "ip_based":
-		value = getFromUser(user, cond.Field)
+		value = getFromUser(user, cond.Field, e.getAttributePrecedence())
 		if value == nil || value == "" {
-			value = getFromIP(user, cond.Field, e.countryLookup)
+			value = getFromIP(user, cond.Field, e.countryLookup, e.getAttributePrecedence())
 		}
 	case "ua_based":
-		value = getFromUser(user, cond.Field)
+		value = getFromUser(user, cond.Field, e.getAttributePrecedence())
 		if value == nil || value == "" {
-			value = getFromUserAgent(user, cond.Field, e.uaParser)
+			value = getFromUserAgent(user, cond.Field, e.uaParser, e.getAttributePrecedence())
 		}
 	case "user_field":
-		value = getFromUser(user, cond.Field)
+		value = getFromUser(user, cond.Field, e.getAttributePrecedence())
 	case "environment_field":
 		value = getFromEnvironment(user, cond.Field)
 	case "current_time":
 		value = time.Now().Unix() // time in seconds
 	case "user_bucket":
-		if salt, ok := cond.AdditionalValues["salt"]; ok {
-			value = int64(getHashUint64Encoding(fmt.Sprintf("%s.%s", salt, getUnitID(user, cond.IDType))) % 1000)
+		if compiled.hasUserBucketSalt {
+			value = int64(getHashUint64Encoding(e.getUserBucketHashSalt(compiled)+"."+getUnitID(user, cond.IDType)) % compiled.userBucketCount)
 		}
 	case "unit_id":
 		value = getUnitID(user, cond.IDType)
@@ -481,35 +585,32 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int) *e
 	case "lte":
 		pass = compareNumbers(value, cond.TargetValue, func(x, y float64) bool { return x <= y })
 	case "version_gt":
-		pass = compareVersions(value, cond.TargetValue, func(x, y []int64) bool { return compareVersionsHelper(x, y) > 0 })
+		pass = compareVersionToTarget(value, compiled, func(x, y []int64) bool { return compareVersionsHelper(x, y) > 0 })
 	case "version_gte":
-		pass = compareVersions(value, cond.TargetValue, func(x, y []int64) bool { return compareVersionsHelper(x, y) >= 0 })
+		pass = compareVersionToTarget(value, compiled, func(x, y []int64) bool { return compareVersionsHelper(x, y) >= 0 })
 	case "version_lt":
-		pass = compareVersions(value, cond.TargetValue, func(x, y []int64) bool { return compareVersionsHelper(x, y) < 0 })
+		pass = compareVersionToTarget(value, compiled, func(x, y []int64) bool { return compareVersionsHelper(x, y) < 0 })
 	case "version_lte":
-		pass = compareVersions(value, cond.TargetValue, func(x, y []int64) bool { return compareVersionsHelper(x, y) <= 0 })
+		pass = compareVersionToTarget(value, compiled, func(x, y []int64) bool { return compareVersionsHelper(x, y) <= 0 })
 	case "version_eq":
-		pass = compareVersions(value, cond.TargetValue, func(x, y []int64) bool { return compareVersionsHelper(x, y) == 0 })
+		pass = compareVersionToTarget(value, compiled, func(x, y []int64) bool { return compareVersionsHelper(x, y) == 0 })
 	case "version_neq":
-		pass = compareVersions(value, cond.TargetValue, func(x, y []int64) bool { return compareVersionsHelper(x, y) != 0 })
+		pass = compareVersionToTarget(value, compiled, func(x, y []int64) bool { return compareVersionsHelper(x, y) != 0 })
 
 	// array operations
-	case "any":
-		pass = arrayAny(cond.TargetValue, value, func(x, y interface{}) bool {
-			return compareStrings(x, y, true, func(s1, s2 string) bool { return s1 == s2 })
-		})
-	case "none":
-		pass = !arrayAny(cond.TargetValue, value, func(x, y interface{}) bool {
-			return compareStrings(x, y, true, func(s1, s2 string) bool { return s1 == s2 })
-		})
+	case "any", "none":
+		if compiled.targetLocales != nil && e.getLocaleMatchingPolicy() == LocaleMatchNormalized {
+			pass = localeInSet(compiled.targetLocales, value)
+		} else {
+			pass = setContains(compiled.targetSet, value, true)
+		}
+		if op == "none" {
+			pass = !pass
+		}
 	case "any_case_sensitive":
-		pass = arrayAny(cond.TargetValue, value, func(x, y interface{}) bool {
-			return compareStrings(x, y, false, func(s1, s2 string) bool { return s1 == s2 })
-		})
+		pass = setContains(compiled.targetSetExact, value, false)
 	case "none_case_sensitive":
-		pass = !arrayAny(cond.TargetValue, value, func(x, y interface{}) bool {
-			return compareStrings(x, y, false, func(s1, s2 string) bool { return s1 == s2 })
-		})
+		pass = !setContains(compiled.targetSetExact, value, false)
 
 	// string operations
 	case "str_starts_with_any":
@@ -531,9 +632,8 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int) *e
 	case "str_matches":
 		if cond.TargetValue == nil || value == nil {
 			pass = cond.TargetValue == nil && value == nil
-		} else {
-			matched, _ := regexp.MatchString(toString(cond.TargetValue), toString(value))
-			pass = matched
+		} else if !compiled.targetRegexInvalid {
+			pass = compiled.targetRegex.MatchString(toString(value))
 		}
 
 	// strict equality
@@ -553,20 +653,20 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int) *e
 
 	// time
 	case "before":
-		pass = getTime(value).Before(getTime(cond.TargetValue))
+		pass = getTime(value).Before(compiled.targetTime)
 	case "after":
-		pass = getTime(value).After(getTime(cond.TargetValue))
+		pass = getTime(value).After(compiled.targetTime)
 	case "on":
 		y1, m1, d1 := getTime(value).Date()
-		y2, m2, d2 := getTime(cond.TargetValue).Date()
+		y2, m2, d2 := compiled.targetTime.Date()
 		pass = (y1 == y2 && m1 == m2 && d1 == d2)
 	case "in_segment_list", "not_in_segment_list":
 		inlist := false
 		if reflect.TypeOf(cond.TargetValue).String() == "string" && reflect.TypeOf(value).String() == "string" {
-			list := e.store.getIDList(toString(cond.TargetValue))
+			list := e.store.getIDListForCondition(toString(cond.TargetValue))
 			if list != nil {
 				h := sha256.Sum256([]byte(toString(value)))
-				_, inlist = list.ids.Load(base64.StdEncoding.EncodeToString(h[:])[:8])
+				inlist = list.contains(base64.StdEncoding.EncodeToString(h[:])[:8])
 			}
 		}
 		if op == "in_segment_list" {
@@ -578,45 +678,70 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int) *e
 		pass = false
 		server = true
 	}
+	if compiled.emptyTargetList && e.getEmptyTargetListPolicy() == EmptyTargetListFailsCondition {
+		pass = false
+	}
 	return &evalResult{Pass: pass, FetchFromServer: server}
 }
 
-func getFromUser(user User, field string) interface{} {
-	var value interface{}
-	// 1. Try to get from top level user field first
+func getFromUser(user User, field string, precedence AttributePrecedence) interface{} {
+	topLevelValue := getTopLevelUserField(user, field)
+	if precedence == AttributePrecedencePrivateFirst {
+		if privateValue, ok := getUserAttribute(user.PrivateAttributes, field); ok {
+			return privateValue
+		}
+		if customValue, ok := getUserAttribute(user.Custom, field); ok {
+			return customValue
+		}
+		return topLevelValue
+	}
+	if topLevelValue != "" && topLevelValue != nil {
+		return topLevelValue
+	}
+	if customValue, ok := getUserAttribute(user.Custom, field); ok {
+		return customValue
+	}
+	if privateValue, ok := getUserAttribute(user.PrivateAttributes, field); ok {
+		return privateValue
+	}
+	return topLevelValue
+}
+
+func getTopLevelUserField(user User, field string) interface{} {
 	switch strings.ToLower(field) {
 	case "userid", "user_id":
-		value = user.UserID
+		return user.UserID
 	case "email":
-		value = user.Email
+		return user.Email
 	case "ip", "ipaddress", "ip_address":
-		value = user.IpAddress
+		return user.IpAddress
 	case "useragent", "user_agent":
 		if user.UserAgent != "" { // UserAgent cannot be empty string
-			value = user.UserAgent
+			return user.UserAgent
 		}
 	case "country":
-		value = user.Country
+		return user.Country
 	case "locale":
-		value = user.Locale
+		return user.Locale
 	case "appversion", "app_version":
-		value = user.AppVersion
+		return user.AppVersion
 	}
+	return nil
+}
 
-	// 2. Check custom user attributes and then private attributes next
-	if value == "" || value == nil {
-		if customValue, ok := user.Custom[field]; ok {
-			value = customValue
-		} else if customValue, ok := user.Custom[strings.ToLower(field)]; ok {
-			value = customValue
-		} else if privateValue, ok := user.PrivateAttributes[field]; ok {
-			value = privateValue
-		} else if privateValue, ok := user.PrivateAttributes[strings.ToLower(field)]; ok {
-			value = privateValue
-		}
+func getUserAttribute(attributes map[string]interface{}, field string) (interface{}, bool) {
+	if value, ok := attributes[field]; ok {
+		return value, true
 	}
+	value, ok := attributes[strings.ToLower(field)]
+	return value, ok
+}
 
-	return value
+func (e *evaluator) getAttributePrecedence() AttributePrecedence {
+	if e.options == nil {
+		return AttributePrecedenceTopLevelFirst
+	}
+	return e.options.AttributePrecedence
 }
 
 func getFromEnvironment(user User, field string) string {
@@ -630,34 +755,34 @@ func getFromEnvironment(user User, field string) string {
 	return value
 }
 
-func getFromUserAgent(user User, field string, parser *uaparser.Parser) string {
-	ua := getFromUser(user, "useragent")
+func getFromUserAgent(user User, field string, parser UserAgentParser, precedence AttributePrecedence) string {
+	ua := getFromUser(user, "useragent", precedence)
 	uaStr, ok := ua.(string)
 	if !ok {
 		return ""
 	}
-	client := parser.Parse(uaStr)
+	info := parseUserAgent(parser, uaStr)
 	switch strings.ToLower(field) {
 	case "os_name", "osname":
-		return client.Os.Family
+		return info.OSName
 	case "os_version", "osversion":
-		return strings.Join(removeEmptyStrings([]string{client.Os.Major, client.Os.Minor, client.Os.Patch, client.Os.PatchMinor}), ".")
+		return info.OSVersion
 	case "browser_name", "browsername":
-		return client.UserAgent.Family
+		return info.BrowserName
 	case "browser_version", "browserversion":
-		return strings.Join(removeEmptyStrings([]string{client.UserAgent.Major, client.UserAgent.Minor, client.UserAgent.Patch}), ".")
+		return info.BrowserVersion
 	}
 	return ""
 }
 
-func getFromIP(user User, field string, lookup *countrylookup.CountryLookup) string {
+func getFromIP(user User, field string, lookup IPCountryLookup, precedence AttributePrecedence) string {
 	if strings.ToLower(field) != "country" {
 		return ""
 	}
 
-	ip := getFromUser(user, "ip")
+	ip := getFromUser(user, "ip", precedence)
 	if ipStr, ok := ip.(string); ok {
-		if res, lookupOK := lookup.LookupIp(ipStr); lookupOK {
+		if res, lookupOK := lookupCountry(lookup, ipStr); lookupOK {
 			return res
 		}
 	}
@@ -779,26 +904,6 @@ func compareVersionsHelper(v1 []int64, v2 []int64) int {
 	return 0
 }
 
-func compareVersions(a, b interface{}, fun func(x, y []int64) bool) bool {
-	strA, okA := a.(string)
-	strB, okB := b.(string)
-	if !okA || !okB {
-		return false
-	}
-	v1 := strings.Split(strA, "-")[0]
-	v2 := strings.Split(strB, "-")[0]
-	if len(v1) == 0 || len(v2) == 0 {
-		return false
-	}
-
-	v1Parts, e1 := convertVersionStringToParts(v1)
-	v2Parts, e2 := convertVersionStringToParts(v2)
-	if e1 != nil || e2 != nil {
-		return false
-	}
-	return fun(v1Parts, v2Parts)
-}
-
 func maxInt(x, y int) int {
 	if x > y {
 		return x
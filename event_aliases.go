@@ -0,0 +1,65 @@
+package statsig
+
+import "sync"
+
+// Derives a Statsig metric event from a logged event, see Client.RegisterEventAlias
+type EventAlias struct {
+	MetricEventName string
+	Value           func(event Event) (value string, ok bool) // Transforms the value, skipping the metric event when ok is false. Keeps the original value when nil
+	DropOriginal    bool                                      // Logs only the metric event, not the original
+}
+
+type eventAliasRegistry struct {
+	aliases map[string][]EventAlias
+	mu      sync.RWMutex
+}
+
+func (r *eventAliasRegistry) register(eventName string, alias EventAlias) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.aliases == nil {
+		r.aliases = make(map[string][]EventAlias)
+	}
+	r.aliases[eventName] = append(r.aliases[eventName], alias)
+}
+
+func (r *eventAliasRegistry) get(eventName string) []EventAlias {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.aliases[eventName]
+}
+
+// Returns the events to log in place of event: the metric events derived from its aliases,
+// preceded by the event itself unless an alias drops it
+func (r *eventAliasRegistry) apply(event Event) []Event {
+	aliases := r.get(event.EventName)
+	if len(aliases) == 0 {
+		return []Event{event}
+	}
+	events := make([]Event, 1, len(aliases)+1)
+	events[0] = event
+	keepOriginal := true
+	for _, alias := range aliases {
+		keepOriginal = keepOriginal && !alias.DropOriginal
+		derived := event
+		derived.EventName = alias.MetricEventName
+		if alias.Value != nil {
+			value, ok := alias.Value(event)
+			if !ok {
+				continue
+			}
+			derived.Value = value
+		}
+		if event.Metadata != nil {
+			derived.Metadata = make(map[string]string, len(event.Metadata))
+			for key, value := range event.Metadata {
+				derived.Metadata[key] = value
+			}
+		}
+		events = append(events, derived)
+	}
+	if !keepOriginal {
+		return events[1:]
+	}
+	return events
+}
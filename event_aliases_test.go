@@ -0,0 +1,64 @@
+package statsig
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestEventAliases(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a_user"}
+
+	// Cents to dollars, skipping events without a valid amount
+	c.RegisterEventAlias("checkout_completed", EventAlias{
+		MetricEventName: "purchase",
+		Value: func(event Event) (string, bool) {
+			cents, err := strconv.Atoi(event.Value)
+			if err != nil {
+				return "", false
+			}
+			return strconv.FormatFloat(float64(cents)/100, 'f', 2, 64), true
+		},
+	})
+	c.RegisterEventAlias("legacy_signup", EventAlias{MetricEventName: "signup", DropOriginal: true})
+
+	events := func() []Event {
+		logged := make([]Event, 0)
+		for _, event := range c.logger.events {
+			if custom, ok := event.(Event); ok {
+				logged = append(logged, custom)
+			}
+		}
+		c.logger.events = make([]interface{}, 0)
+		return logged
+	}
+
+	c.LogEvent(Event{EventName: "checkout_completed", User: user, Value: "1999", Metadata: map[string]string{"sku": "a"}})
+	logged := events()
+	if len(logged) != 2 || logged[0].EventName != "checkout_completed" || logged[0].Value != "1999" {
+		t.Fatalf("Expected the original event followed by the metric event, received %+v", logged)
+	}
+	if logged[1].EventName != "purchase" || logged[1].Value != "19.99" || logged[1].Metadata["sku"] != "a" || logged[1].User.UserID != "a_user" {
+		t.Errorf("Unexpected metric event %+v", logged[1])
+	}
+
+	c.LogEvent(Event{EventName: "checkout_completed", User: user, Value: "unknown"})
+	if logged = events(); len(logged) != 1 {
+		t.Errorf("Expected the metric event to be skipped, received %+v", logged)
+	}
+
+	c.LogEvent(Event{EventName: "legacy_signup", User: user, Value: "web"})
+	if logged = events(); len(logged) != 1 || logged[0].EventName != "signup" || logged[0].Value != "web" {
+		t.Errorf("Expected only the metric event, received %+v", logged)
+	}
+
+	c.LogEvent(Event{EventName: "page_view", User: user})
+	if logged = events(); len(logged) != 1 || logged[0].EventName != "page_view" {
+		t.Errorf("Expected events without aliases to be unchanged, received %+v", logged)
+	}
+}
@@ -0,0 +1,19 @@
+package statsig
+
+import "github.com/google/uuid"
+
+// Identifies a batch of events across every attempt to send it, including transport retries and
+// replays from the event spool, so ingestion counts a batch that was received but not acknowledged once.
+// Events requeued by EventQueueOptions are resent together with newer events, in a new batch
+const eventBatchIDHeader = "STATSIG-EVENT-BATCH-ID"
+
+func newEventBatchID() string {
+	return uuid.NewString()
+}
+
+func eventBatchHeaders(batchID string) map[string]string {
+	if batchID == "" {
+		return nil
+	}
+	return map[string]string{eventBatchIDHeader: batchID}
+}
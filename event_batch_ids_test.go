@@ -0,0 +1,83 @@
+package statsig
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEventBatchIDs(t *testing.T) {
+	var failures int32
+	var mu sync.Mutex
+	var batchIDs []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "log_event") {
+			mu.Lock()
+			batchIDs = append(batchIDs, req.Header.Get(eventBatchIDHeader))
+			mu.Unlock()
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				res.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	received := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), batchIDs...)
+	}
+	dir, _ := ioutil.TempDir("", "event_batch_ids")
+	defer os.RemoveAll(dir)
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	sink := &testEventSink{}
+	options := &Options{
+		API:                  testServer.URL,
+		LoggingInterval:      time.Hour,
+		RetryOptions:         RetryOptions{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		EventSinkOptions:     EventSinkOptions{Sink: sink},
+		EventSpoolOptions:    EventSpoolOptions{Directory: dir},
+	}
+	l := newLogger(newTransport("secret-key", options), options, newDiagnostics(options))
+	defer l.flush(true)
+
+	// Retries resend the batch ID of the first attempt
+	atomic.StoreInt32(&failures, 1)
+	l.sendEvents([]interface{}{Event{EventName: "a"}})
+	ids := received()
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("Expected both attempts to send the same batch ID, received %v", ids)
+	}
+	if len(sink.writes) != 1 || sink.writes[0][0].BatchID != ids[0] {
+		t.Errorf("Expected sink events to carry the batch ID %s, received %+v", ids[0], sink.writes)
+	}
+
+	// Spooled batches are replayed under their original batch ID
+	atomic.StoreInt32(&failures, 2)
+	l.sendEvents([]interface{}{Event{EventName: "b"}})
+	spooled := received()[2]
+	if len(l.spool.batches) != 1 || l.spool.batches[0].batchID != spooled {
+		t.Fatalf("Expected the failed batch to be spooled with batch ID %s, received %+v", spooled, l.spool.batches)
+	}
+	l.sendEvents([]interface{}{Event{EventName: "c"}})
+	deadline := time.Now().Add(time.Second)
+	for len(received()) < 6 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ids = received()
+	if len(ids) != 6 || ids[5] != spooled || ids[4] == spooled {
+		t.Errorf("Expected the spooled batch to be replayed with batch ID %s, received %v", spooled, ids)
+	}
+
+	if id := spooledBatchID("00000000000000000001-1.json"); id != "" {
+		t.Errorf("Expected batches spooled without a batch ID to have none, received %s", id)
+	}
+}
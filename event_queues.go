@@ -0,0 +1,242 @@
+package statsig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Which events a full partitioned event queue discards
+type EventDropPolicy int
+
+const (
+	DropOldestEvents EventDropPolicy = iota // Discard the oldest queued events to make room for new ones
+	DropNewestEvents                        // Keep the queued events and discard new ones
+)
+
+const (
+	exposureEventQueue = "exposures"
+	customEventQueue   = "custom"
+)
+
+// A queue of one kind of event, flushed on its own schedule. Events from failed flushes are kept
+// for the next flush, up to MaxPendingEvents
+type eventQueue struct {
+	name    string
+	policy  EventQueuePolicy
+	events  []interface{}
+	retry   []interface{}
+	dropped int64
+	mu      sync.Mutex
+	tick    *time.Ticker
+	logger  *logger
+}
+
+func newEventQueue(name string, policy EventQueuePolicy, l *logger, loggingInterval time.Duration) *eventQueue {
+	if policy.FlushInterval <= 0 {
+		policy.FlushInterval = loggingInterval
+	}
+	if policy.MaxBatchSize <= 0 {
+		policy.MaxBatchSize = l.maxEvents
+	}
+	q := &eventQueue{
+		name:   name,
+		policy: policy,
+		events: make([]interface{}, 0),
+		tick:   time.NewTicker(policy.FlushInterval),
+		logger: l,
+	}
+	go func() {
+		for range q.tick.C {
+			q.flush(false)
+		}
+	}()
+	return q
+}
+
+func (q *eventQueue) enqueue(evt interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if max := q.policy.MaxPendingEvents; max > 0 && len(q.retry)+len(q.events) >= max {
+		q.dropped++
+		q.logger.metrics.eventsDropped(q.name, 1)
+		if q.policy.DropPolicy == DropNewestEvents {
+			return
+		}
+		if len(q.retry) > 0 {
+			q.retry = q.retry[1:]
+		} else {
+			q.events = q.events[1:]
+		}
+	}
+	q.events = append(q.events, evt)
+	if len(q.events) >= q.policy.MaxBatchSize {
+		q.flushLocked()
+	}
+}
+
+// The batch taken while closing is sent after q.mu is released, as failures are spooled to disk
+func (q *eventQueue) flush(closing bool) {
+	q.mu.Lock()
+	if !closing {
+		q.flushLocked()
+		q.mu.Unlock()
+		return
+	}
+	q.tick.Stop()
+	send := q.takeBatch(context.Background(), true)
+	q.mu.Unlock()
+	if send != nil {
+		_ = send()
+	}
+}
+
+func (q *eventQueue) flushLocked() {
+	if send := q.takeBatch(context.Background(), false); send != nil {
+		go send()
+	}
+}
+
+// Sends queued events synchronously without stopping the flush ticker
+func (q *eventQueue) flushPending() {
+	_ = q.flushPendingCtx(context.Background())
+}
+
+// Failed events are requeued, as after a scheduled flush
+func (q *eventQueue) flushPendingCtx(ctx context.Context) error {
+	q.mu.Lock()
+	send := q.takeBatch(ctx, false)
+	q.mu.Unlock()
+	if send == nil {
+		return nil
+	}
+	return send()
+}
+
+// Empties the queue, returning a function that sends the taken events and requeues them on failure
+func (q *eventQueue) takeBatch(ctx context.Context, closing bool) func() error {
+	if len(q.events) == 0 && len(q.retry) == 0 {
+		return nil
+	}
+	// The logger lifts any pause as it closes, so held events are sent then
+	if q.logger.isLoggingPaused() {
+		return nil
+	}
+	fresh, batch := q.events, append(q.retry, q.events...)
+	q.events = make([]interface{}, 0)
+	q.retry = nil
+	return func() error {
+		batchID := newEventBatchID()
+		// Retried events were already written to the event sink on their first attempt
+		q.logger.writeToEventSink(fresh, batchID)
+		err := q.logger.postEventsCtx(ctx, batch, batchID)
+		if err != nil && !closing {
+			q.requeue(batch, batchID)
+		} else if err != nil {
+			q.spillOrDrop(batch, batchID)
+		}
+		return err
+	}
+}
+
+func (q *eventQueue) requeue(batch []interface{}, batchID string) {
+	max := q.policy.MaxPendingEvents
+	if max <= 0 {
+		q.spillOrDrop(batch, batchID)
+		return
+	}
+	q.mu.Lock()
+	retry := append(batch, q.retry...)
+	var overflow []interface{}
+	if excess := len(retry) + len(q.events) - max; excess > 0 {
+		if q.policy.DropPolicy == DropNewestEvents {
+			// The failed batch is older than anything queued since, so newer events go first
+			keep := max - len(retry)
+			if keep < 0 {
+				overflow = append(overflow, retry[max:]...)
+				retry = retry[:max]
+				keep = 0
+			}
+			overflow = append(overflow, q.events[keep:]...)
+			q.events = q.events[:keep]
+		} else if excess <= len(retry) {
+			overflow = append(overflow, retry[:excess]...)
+			retry = retry[excess:]
+		} else {
+			overflow = append(append(overflow, retry...), q.events[:excess-len(retry)]...)
+			q.events = q.events[excess-len(retry):]
+			retry = nil
+		}
+	}
+	q.retry = retry
+	q.mu.Unlock()
+	if len(overflow) > 0 && !q.logger.spillEvents(overflow, newEventBatchID()) {
+		q.recordDropped(int64(len(overflow)))
+		Logger().LogError(fmt.Sprintf("The %s event queue is full, dropped %d events\n", q.name, len(overflow)))
+	}
+}
+
+// Spooling writes to disk, so it happens without q.mu held to keep logging unblocked
+func (q *eventQueue) spillOrDrop(events []interface{}, batchID string) {
+	if !q.logger.spillEvents(events, batchID) {
+		q.recordDropped(int64(len(events)))
+	}
+}
+
+func (q *eventQueue) recordDropped(count int64) {
+	q.mu.Lock()
+	q.dropped += count
+	q.mu.Unlock()
+	q.logger.metrics.eventsDropped(q.name, count)
+}
+
+func (q *eventQueue) depth() (int, int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.retry) + len(q.events), q.dropped
+}
+
+type eventQueues struct {
+	exposures *eventQueue
+	custom    *eventQueue
+}
+
+func newEventQueues(options *Options, l *logger, loggingInterval time.Duration) *eventQueues {
+	if !options.EventQueueOptions.Partitioned {
+		return nil
+	}
+	return &eventQueues{
+		exposures: newEventQueue(exposureEventQueue, options.EventQueueOptions.Exposures, l, loggingInterval),
+		custom:    newEventQueue(customEventQueue, options.EventQueueOptions.Custom, l, loggingInterval),
+	}
+}
+
+// Custom events, including metric events derived with RegisterEventAlias, go to the custom queue.
+// Exposures and SDK diagnostics go to the exposure queue
+func (qs *eventQueues) queueFor(evt interface{}) *eventQueue {
+	if _, ok := evt.(Event); ok {
+		return qs.custom
+	}
+	return qs.exposures
+}
+
+func (qs *eventQueues) flush(closing bool) {
+	qs.exposures.flush(closing)
+	qs.custom.flush(closing)
+}
+
+func (qs *eventQueues) flushPending() {
+	qs.exposures.flushPending()
+	qs.custom.flushPending()
+}
+
+func (qs *eventQueues) flushPendingCtx(ctx context.Context) []error {
+	var errs []error
+	for _, q := range []*eventQueue{qs.exposures, qs.custom} {
+		if err := q.flushPendingCtx(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s queue: %s", q.name, err.Error()))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,124 @@
+package statsig
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPartitionedEventQueues(t *testing.T) {
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	var failExposures int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			bytes, _ := os.ReadFile("download_config_specs.json")
+			_, _ = res.Write(bytes)
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		} else if strings.Contains(req.URL.Path, "log_event") {
+			input := &struct {
+				Events []map[string]interface{} `json:"events"`
+			}{}
+			buf := new(bytes.Buffer)
+			_, _ = buf.ReadFrom(req.Body)
+			_ = json.Unmarshal(buf.Bytes(), &input)
+			if atomic.LoadInt32(&failExposures) == 1 && strings.HasPrefix(input.Events[0]["eventName"].(string), "statsig::") {
+				res.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			received = append(received, input.Events...)
+			mu.Unlock()
+		}
+	}))
+	defer testServer.Close()
+
+	receivedNames := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		names := make([]string, 0, len(received))
+		for _, event := range received {
+			name := event["eventName"].(string)
+			if metadata, ok := event["metadata"].(map[string]interface{}); ok && metadata["gate"] != nil {
+				name = metadata["gate"].(string)
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		EventQueueOptions: EventQueueOptions{
+			Partitioned: true,
+			Exposures:   EventQueuePolicy{FlushInterval: time.Hour, MaxPendingEvents: 3},
+			Custom:      EventQueuePolicy{FlushInterval: 10 * time.Millisecond},
+		},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	t.Run("flushes custom events on their own interval", func(t *testing.T) {
+		c.CheckGate(user, "always_on_gate")
+		c.LogEvent(Event{EventName: "purchase", User: user})
+		deadline := time.Now().Add(2 * time.Second)
+		for len(receivedNames()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if names := receivedNames(); len(names) != 1 || names[0] != "purchase" {
+			t.Errorf("Expected only the custom event to be flushed, received %v", names)
+		}
+		if depth, _ := c.logger.queues.exposures.depth(); depth != 1 {
+			t.Errorf("Expected the exposure to stay queued, received depth %d", depth)
+		}
+	})
+
+	t.Run("retains exposures during an outage with a drop policy", func(t *testing.T) {
+		atomic.StoreInt32(&failExposures, 1)
+		c.logger.queues.exposures.flushPending()
+		if depth, _ := c.logger.queues.exposures.depth(); depth != 1 {
+			t.Errorf("Expected the failed exposure to be kept for retry, received depth %d", depth)
+		}
+		for _, gate := range []string{"on_for_statsig_email", "on_for_id_list", "fractional_gate"} {
+			c.CheckGate(user, gate)
+		}
+		stats := c.GetSDKStats()
+		if stats.DroppedEventCounts[exposureEventQueue] != 1 || stats.EventQueueDepth != 3 {
+			t.Errorf("Expected the oldest exposure to be dropped, received %+v", stats)
+		}
+
+		c.LogEvent(Event{EventName: "signup", User: user})
+		c.logger.queues.custom.flushPending()
+		if names := receivedNames(); len(names) != 2 || names[1] != "signup" {
+			t.Errorf("Expected custom events to flush during the outage, received %v", names)
+		}
+
+		atomic.StoreInt32(&failExposures, 0)
+		c.logger.queues.exposures.flushPending()
+		names := receivedNames()
+		if strings.Join(names[2:], ",") != "on_for_statsig_email,on_for_id_list,fractional_gate" {
+			t.Errorf("Expected the retained exposures to be sent in order, received %v", names)
+		}
+	})
+
+	t.Run("drops new events with DropNewestEvents", func(t *testing.T) {
+		q := &eventQueue{name: "test", policy: EventQueuePolicy{MaxBatchSize: 10, MaxPendingEvents: 2, DropPolicy: DropNewestEvents}, logger: c.logger}
+		q.enqueue(Event{EventName: "a"})
+		q.enqueue(Event{EventName: "b"})
+		q.enqueue(Event{EventName: "c"})
+		q.requeue([]interface{}{Event{EventName: "failed"}}, newEventBatchID())
+		if len(q.retry) != 1 || len(q.events) != 1 || q.events[0].(Event).EventName != "a" || q.dropped != 2 {
+			t.Errorf("Expected the oldest events to be kept, received %+v %+v", q.retry, q.events)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package statsig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+)
+
+const (
+	eventSignatureHeader      = "STATSIG-EVENT-SIGNATURE"
+	eventSignatureKeyIDHeader = "STATSIG-EVENT-SIGNATURE-KEY-ID"
+)
+
+// Implemented by event sinks that verify the batches they receive, e.g. a relay reading them back from an
+// intermediate queue. Used in place of WriteEvents when EventSigningOptions.Key is set
+type SignedEventSink interface {
+	EventSink
+	WriteSignedEvents(events []SinkEvent, signature EventBatchSignature) error
+}
+
+type EventBatchSignature struct {
+	KeyID     string // EventSigningOptions.KeyID, to select the key when keys are rotated
+	Signature string // See SignSinkEvents
+}
+
+func signEventBytes(key []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(payload)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Base64 encoded HMAC-SHA256 of the events' payloads, in order and separated by newlines, so removing,
+// reordering or altering any event invalidates the signature
+func SignSinkEvents(key []byte, events []SinkEvent) string {
+	mac := hmac.New(sha256.New, key)
+	for i, event := range events {
+		if i > 0 {
+			_, _ = mac.Write([]byte{'\n'})
+		}
+		_, _ = mac.Write(event.Payload)
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Reports whether the signature was created by SignSinkEvents with the key for exactly these events
+func VerifySinkEvents(key []byte, events []SinkEvent, signature string) bool {
+	return hmac.Equal([]byte(SignSinkEvents(key, events)), []byte(signature))
+}
+
+// Reports whether the signature in the STATSIG-EVENT-SIGNATURE header of a log_event request was created with the key for this body.
+// Pass the decompressed body of requests sent with Content-Encoding: gzip
+func VerifyEventRequestBody(key []byte, body []byte, signature string) bool {
+	return hmac.Equal([]byte(signEventBytes(key, body)), []byte(signature))
+}
+
+func (transport *transport) signEventRequest(req *http.Request, body []byte) {
+	signing := transport.options.EventSigningOptions
+	if len(signing.Key) == 0 {
+		return
+	}
+	req.Header.Set(eventSignatureHeader, signEventBytes(signing.Key, body))
+	if signing.KeyID != "" {
+		req.Header.Set(eventSignatureKeyIDHeader, signing.KeyID)
+	}
+}
+
+func (l *logger) writeSinkEvents(sink EventSink, events []SinkEvent) error {
+	signing := l.options.EventSigningOptions
+	if signed, ok := sink.(SignedEventSink); ok && len(signing.Key) != 0 {
+		return signed.WriteSignedEvents(events, EventBatchSignature{KeyID: signing.KeyID, Signature: SignSinkEvents(signing.Key, events)})
+	}
+	return sink.WriteEvents(events)
+}
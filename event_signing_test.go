@@ -0,0 +1,103 @@
+package statsig
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testSignedEventSink struct {
+	testEventSink
+	signatures []EventBatchSignature
+}
+
+func (s *testSignedEventSink) WriteSignedEvents(events []SinkEvent, signature EventBatchSignature) error {
+	s.mu.Lock()
+	s.signatures = append(s.signatures, signature)
+	s.mu.Unlock()
+	return s.WriteEvents(events)
+}
+
+func TestEventSigning(t *testing.T) {
+	key := []byte("relay-key")
+	var mu sync.Mutex
+	var bodies [][]byte
+	var signatures, keyIDs []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "log_event") {
+			body, _ := io.ReadAll(req.Body)
+			mu.Lock()
+			bodies = append(bodies, body)
+			signatures = append(signatures, req.Header.Get("STATSIG-EVENT-SIGNATURE"))
+			keyIDs = append(keyIDs, req.Header.Get("STATSIG-EVENT-SIGNATURE-KEY-ID"))
+			mu.Unlock()
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	newSigningClient := func(signing EventSigningOptions, sink EventSink) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			StatsigLoggerOptions: StatsigLoggerOptions{DisableInitDiagnostics: true, DisableSyncDiagnostics: true, DisableApiDiagnostics: true},
+			EventSinkOptions:     EventSinkOptions{Sink: sink},
+			EventSigningOptions:  signing,
+		})
+	}
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		bodies, signatures, keyIDs = nil, nil, nil
+	}
+
+	t.Run("signs log_event requests and sink batches", func(t *testing.T) {
+		reset()
+		sink := &testSignedEventSink{}
+		c := newSigningClient(EventSigningOptions{Key: key, KeyID: "2024-01"}, sink)
+		c.LogEvent(Event{EventName: "purchase", User: User{UserID: "a_user"}})
+		c.LogEvent(Event{EventName: "refund", User: User{UserID: "a_user"}})
+		c.Shutdown()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(bodies) != 1 || !VerifyEventRequestBody(key, bodies[0], signatures[0]) || keyIDs[0] != "2024-01" {
+			t.Fatalf("Expected a verifiable log_event request, received %d requests, signatures %v", len(bodies), signatures)
+		}
+		if VerifyEventRequestBody([]byte("other-key"), bodies[0], signatures[0]) {
+			t.Errorf("Expected verification with another key to fail")
+		}
+		if len(sink.writes) != 1 || len(sink.signatures) != 1 || sink.signatures[0].KeyID != "2024-01" {
+			t.Fatalf("Expected one signed sink batch, received %+v", sink.signatures)
+		}
+		events := sink.writes[0]
+		if !VerifySinkEvents(key, events, sink.signatures[0].Signature) {
+			t.Errorf("Expected the sink batch to verify")
+		}
+		if VerifySinkEvents(key, events[:1], sink.signatures[0].Signature) {
+			t.Errorf("Expected a truncated batch not to verify")
+		}
+		if VerifySinkEvents(key, []SinkEvent{events[1], events[0]}, sink.signatures[0].Signature) {
+			t.Errorf("Expected a reordered batch not to verify")
+		}
+	})
+
+	t.Run("does not sign without a key", func(t *testing.T) {
+		reset()
+		sink := &testSignedEventSink{}
+		c := newSigningClient(EventSigningOptions{}, sink)
+		c.LogEvent(Event{EventName: "purchase", User: User{UserID: "a_user"}})
+		c.Shutdown()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(bodies) != 1 || signatures[0] != "" {
+			t.Errorf("Expected an unsigned log_event request, received %v", signatures)
+		}
+		if len(sink.writes) != 1 || len(sink.signatures) != 0 {
+			t.Errorf("Expected WriteEvents to be used, received %d signed writes", len(sink.signatures))
+		}
+	})
+}
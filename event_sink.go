@@ -0,0 +1,89 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+const defaultEventSinkMaxPendingEvents = 10000
+
+// A copy of a logged event delivered to an EventSink
+type SinkEvent struct {
+	EventName string
+	UserID    string // Empty for events without a user, e.g. diagnostics
+	Time      int64
+	Payload   []byte // The event as JSON, in the format sent to log_event
+	BatchID   string // The log_event batch the event was sent in, kept when the sink retries it
+}
+
+// Receives every batch of events the SDK flushes, in addition to Statsig's log_event.
+// Relays can deduplicate on SinkEvent.BatchID, which is unique to each batch the SDK sends.
+// See the kafkasink package for a Kafka implementation.
+type EventSink interface {
+	// Returning an error keeps the batch, which is written again together with the next
+	// flushed batch. Sinks therefore receive events at least once.
+	WriteEvents(events []SinkEvent) error
+}
+
+// Events that could not be written to the sink, retried on the next flush
+type eventSinkBuffer struct {
+	pending []SinkEvent
+	mu      sync.Mutex
+}
+
+func newSinkEvent(event interface{}, batchID string) (SinkEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return SinkEvent{}, err
+	}
+	sinkEvent := SinkEvent{Payload: payload, BatchID: batchID}
+	switch e := event.(type) {
+	case ExposureEvent:
+		sinkEvent.EventName, sinkEvent.UserID, sinkEvent.Time = string(e.EventName), e.User.UserID, e.Time
+	case Event:
+		sinkEvent.EventName, sinkEvent.UserID, sinkEvent.Time = e.EventName, e.User.UserID, e.Time
+	case diagnosticsEvent:
+		sinkEvent.EventName, sinkEvent.Time = e.EventName, e.Time
+	}
+	return sinkEvent, nil
+}
+
+func (l *logger) writeToEventSink(events []interface{}, batchID string) {
+	sink := l.options.EventSinkOptions.Sink
+	if sink == nil {
+		return
+	}
+	maxPending := l.options.EventSinkOptions.MaxPendingEvents
+	if maxPending <= 0 {
+		maxPending = defaultEventSinkMaxPendingEvents
+	}
+	l.sinkBuffer.mu.Lock()
+	defer l.sinkBuffer.mu.Unlock()
+	for _, event := range events {
+		sinkEvent, err := newSinkEvent(event, batchID)
+		if err != nil {
+			Logger().LogError(fmt.Sprintf("Failed to serialize event for the event sink: %s\n", err.Error()))
+			continue
+		}
+		l.sinkBuffer.pending = append(l.sinkBuffer.pending, sinkEvent)
+	}
+	if dropped := len(l.sinkBuffer.pending) - maxPending; dropped > 0 {
+		Logger().LogError(fmt.Sprintf("Event sink is failing, dropped the %d oldest pending events\n", dropped))
+		l.sinkBuffer.pending = append([]SinkEvent(nil), l.sinkBuffer.pending[dropped:]...)
+	}
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = toError(recovered)
+			}
+		}()
+		return l.writeSinkEvents(sink, l.sinkBuffer.pending)
+	}()
+	if err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to write %d events to the event sink, retrying on the next flush: %s\n",
+			len(l.sinkBuffer.pending), err.Error()))
+		return
+	}
+	l.sinkBuffer.pending = nil
+}
@@ -0,0 +1,59 @@
+package statsig
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+)
+
+type testEventSink struct {
+	failures int
+	writes   [][]SinkEvent
+	mu       sync.Mutex
+}
+
+func (s *testEventSink) WriteEvents(events []SinkEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, append([]SinkEvent(nil), events...))
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	return nil
+}
+
+func TestEventSink(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	sink := &testEventSink{failures: 1}
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(bytes),
+		StatsigLoggerOptions: StatsigLoggerOptions{DisableInitDiagnostics: true, DisableSyncDiagnostics: true, DisableApiDiagnostics: true},
+		EventSinkOptions:     EventSinkOptions{Sink: sink, MaxPendingEvents: 3},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a_user"}
+
+	c.CheckGate(user, "always_on_gate")
+	c.LogEvent(Event{EventName: "purchase", User: user})
+	c.logger.flush(true)
+	c.LogEvent(Event{EventName: "refund", User: user})
+	c.LogEvent(Event{EventName: "refund", User: user})
+	c.logger.flush(true)
+
+	if len(sink.writes) != 2 {
+		t.Fatalf("Expected 2 writes, received %d", len(sink.writes))
+	}
+	first := sink.writes[0]
+	if len(first) != 2 || first[0].EventName != "statsig::gate_exposure" || first[0].UserID != "a_user" || len(first[0].Payload) == 0 {
+		t.Errorf("Unexpected first batch %+v", first)
+	}
+	// The failed batch is retried with the next one, dropping the oldest beyond MaxPendingEvents
+	retried := sink.writes[1]
+	if len(retried) != 3 || retried[0].EventName != "purchase" || retried[2].EventName != "refund" {
+		t.Errorf("Expected the failed batch to be retried, received %+v", retried)
+	}
+}
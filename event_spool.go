@@ -0,0 +1,228 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultEventSpoolMaxBytes = 256 << 20
+	eventSpoolQueue           = "spool"
+	eventSpoolFileFormat      = "%020d-%d-%s.json" // Sequence number, event count and batch ID
+)
+
+// Writes batches of events that failed to send to disk instead of dropping them, e.g. during a log_event
+// outage, and replays them oldest first once a flush succeeds again. Batches left by a previous process
+// are replayed on startup.
+type EventSpoolOptions struct {
+	Directory string // Spooling is disabled unless this is set. Must not be shared with another client
+	MaxBytes  int64  // Size of all spooled batches, beyond which the oldest are deleted. Defaults to 256MB
+}
+
+type spooledBatch struct {
+	name    string
+	size    int64
+	events  int64
+	batchID string
+}
+
+type eventSpool struct {
+	dir        string
+	maxBytes   int64
+	batches    []spooledBatch // Oldest first
+	totalBytes int64
+	seq        int64
+	replaying  int32 // Set while a replay is running, read atomically
+	closed     bool
+	mu         sync.Mutex
+}
+
+func newEventSpool(options *Options) *eventSpool {
+	spoolOptions := options.EventSpoolOptions
+	// Nothing is sent in local mode, so replayed batches would be lost
+	if spoolOptions.Directory == "" || options.LocalMode || options.StatsigLoggerOptions.DisableAllLogging {
+		return nil
+	}
+	maxBytes := spoolOptions.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultEventSpoolMaxBytes
+	}
+	s := &eventSpool{dir: spoolOptions.Directory, maxBytes: maxBytes}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to create event spool directory %s: %s\n", s.dir, err.Error()))
+		return nil
+	}
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to read event spool directory %s: %s\n", s.dir, err.Error()))
+		return nil
+	}
+	for _, file := range files {
+		var seq, events int64
+		// Batches spooled before batch IDs were recorded are named without one
+		if _, err := fmt.Sscanf(file.Name(), "%d-%d", &seq, &events); err != nil || file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		s.batches = append(s.batches, spooledBatch{name: file.Name(), size: file.Size(), events: events, batchID: spooledBatchID(file.Name())})
+		s.totalBytes += file.Size()
+		if seq >= s.seq {
+			s.seq = seq + 1
+		}
+	}
+	sort.Slice(s.batches, func(i, j int) bool { return s.batches[i].name < s.batches[j].name })
+	return s
+}
+
+// Batches spooled before batch IDs were recorded have none, and are replayed under a new one
+func spooledBatchID(name string) string {
+	parts := strings.SplitN(strings.TrimSuffix(name, ".json"), "-", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+// Returns the number of events in older batches deleted to stay within MaxBytes
+func (s *eventSpool) write(events []interface{}, batchID string) (int64, error) {
+	serialized, err := json.Marshal(events)
+	if err != nil {
+		return 0, err
+	}
+	size := int64(len(serialized))
+	if size > s.maxBytes {
+		return 0, fmt.Errorf("batch of %d bytes exceeds the spool limit of %d bytes", size, s.maxBytes)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch := spooledBatch{
+		name:    fmt.Sprintf(eventSpoolFileFormat, s.seq, len(events), batchID),
+		size:    size,
+		events:  int64(len(events)),
+		batchID: batchID,
+	}
+	s.seq++
+	// Written under a temporary name, so a crash mid-write leaves no partial batch to replay
+	path := filepath.Join(s.dir, batch.name)
+	if err = ioutil.WriteFile(path+".tmp", serialized, 0o600); err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		_ = os.Remove(path + ".tmp")
+		return 0, err
+	}
+	s.batches = append(s.batches, batch)
+	s.totalBytes += size
+	var evicted int64
+	for s.totalBytes > s.maxBytes {
+		evicted += s.batches[0].events
+		s.removeLocked(s.batches[0])
+	}
+	return evicted, nil
+}
+
+func (s *eventSpool) oldest() (spooledBatch, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || len(s.batches) == 0 {
+		return spooledBatch{}, false
+	}
+	return s.batches[0], true
+}
+
+func (s *eventSpool) read(batch spooledBatch) ([]interface{}, error) {
+	serialized, err := ioutil.ReadFile(filepath.Join(s.dir, batch.name))
+	if err != nil {
+		return nil, err
+	}
+	var events []interface{}
+	err = json.Unmarshal(serialized, &events)
+	return events, err
+}
+
+func (s *eventSpool) remove(batch spooledBatch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(batch)
+}
+
+func (s *eventSpool) removeLocked(batch spooledBatch) {
+	for i, spooled := range s.batches {
+		if spooled.name == batch.name {
+			s.batches = append(s.batches[:i], s.batches[i+1:]...)
+			s.totalBytes -= spooled.size
+			break
+		}
+	}
+	if err := os.Remove(filepath.Join(s.dir, batch.name)); err != nil && !os.IsNotExist(err) {
+		Logger().LogError(fmt.Sprintf("Failed to delete spooled events %s: %s\n", batch.name, err.Error()))
+	}
+}
+
+// Spooled batches are kept for the next process
+func (s *eventSpool) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
+// Returns false if the events could not be spooled and should be counted as dropped
+// Spooled events are replayed under batchID
+func (l *logger) spillEvents(events []interface{}, batchID string) bool {
+	if l.spool == nil || len(events) == 0 {
+		return false
+	}
+	evicted, err := l.spool.write(events, batchID)
+	if err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to spool %d events: %s\n", len(events), err.Error()))
+		return false
+	}
+	if evicted > 0 {
+		l.metrics.eventsDropped(eventSpoolQueue, evicted)
+		Logger().LogError(fmt.Sprintf("The event spool is full, deleted %d of the oldest spooled events\n", evicted))
+	}
+	Logger().logRecord(logLevelDebug, "Spooled events", logAttr{"event_count", len(events)})
+	return true
+}
+
+// Sends spooled batches in the background, oldest first, stopping at the first failure
+func (l *logger) replaySpool() {
+	if l.spool == nil || l.isLoggingPaused() || !atomic.CompareAndSwapInt32(&l.spool.replaying, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&l.spool.replaying, 0)
+		for !l.isLoggingPaused() {
+			batch, ok := l.spool.oldest()
+			if !ok {
+				return
+			}
+			events, err := l.spool.read(batch)
+			if os.IsNotExist(err) {
+				// Evicted since it was listed, or deleted by hand
+				l.spool.remove(batch)
+				continue
+			}
+			if err != nil {
+				Logger().LogError(fmt.Sprintf("Discarding unreadable spooled events %s: %s\n", batch.name, err.Error()))
+				l.metrics.eventsDropped(eventSpoolQueue, batch.events)
+				l.spool.remove(batch)
+				continue
+			}
+			batchID := batch.batchID
+			if batchID == "" {
+				batchID = newEventBatchID()
+			}
+			if err = l.postEvents(events, batchID); err != nil {
+				return
+			}
+			l.spool.remove(batch)
+		}
+	}()
+}
@@ -0,0 +1,138 @@
+package statsig
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEventSpool(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var failLogging int32
+	var mu sync.Mutex
+	var logged []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			_, _ = res.Write(specs)
+		case strings.Contains(req.URL.Path, "log_event"):
+			if atomic.LoadInt32(&failLogging) == 1 {
+				res.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			var input struct {
+				Events []map[string]interface{} `json:"events"`
+			}
+			_ = json.NewDecoder(req.Body).Decode(&input)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, evt := range input.Events {
+				if name, _ := evt["eventName"].(string); !strings.HasPrefix(name, "statsig::") {
+					logged = append(logged, name)
+				}
+			}
+			_, _ = res.Write([]byte("{}"))
+		default:
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+	dir, _ := ioutil.TempDir("", "event_spool")
+	defer os.RemoveAll(dir)
+	newSpoolClient := func(partitioned bool, maxBytes int64) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:               testServer.URL,
+			LoggingInterval:   time.Hour,
+			RetryOptions:      RetryOptions{MaxAttempts: 1},
+			EventQueueOptions: EventQueueOptions{Partitioned: partitioned},
+			EventSpoolOptions: EventSpoolOptions{Directory: dir, MaxBytes: maxBytes},
+		})
+	}
+	spooled := func() int {
+		files, _ := ioutil.ReadDir(dir)
+		return len(files)
+	}
+	waitForLogged := func(expected []string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			received := strings.Join(logged, ",")
+			mu.Unlock()
+			if received == strings.Join(expected, ",") && spooled() == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %v to be sent and the spool emptied, received %s with %d spooled batches", expected, received, spooled())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	for _, partitioned := range []bool{false, true} {
+		logged = nil
+		atomic.StoreInt32(&failLogging, 1)
+		c := newSpoolClient(partitioned, 0)
+		c.LogEvent(Event{EventName: "a", User: User{UserID: "1"}})
+		_ = c.Flush(context.Background())
+		c.LogEvent(Event{EventName: "b", User: User{UserID: "1"}})
+		_ = c.Flush(context.Background())
+		// Partitioned clients also spool the exposure queue's diagnostics
+		if spooled() < 2 {
+			t.Errorf("Expected failed batches to be spooled, partitioned %v, received %d", partitioned, spooled())
+		}
+
+		// Replayed oldest first once a flush succeeds
+		atomic.StoreInt32(&failLogging, 0)
+		c.LogEvent(Event{EventName: "c", User: User{UserID: "1"}})
+		if err := c.Flush(context.Background()); err != nil {
+			t.Errorf("Expected the flush to succeed, received %v", err)
+		}
+		waitForLogged([]string{"c", "a", "b"})
+		c.Shutdown()
+	}
+
+	// Batches that fail on Shutdown are replayed by the next client
+	logged = nil
+	atomic.StoreInt32(&failLogging, 1)
+	c := newSpoolClient(false, 0)
+	c.LogEvent(Event{EventName: "d", User: User{UserID: "1"}})
+	c.Shutdown()
+	if spooled() != 1 {
+		t.Fatalf("Expected the batch failing on shutdown to be spooled, received %d", spooled())
+	}
+	if files, _ := ioutil.ReadDir(dir); runtime.GOOS != "windows" && files[0].Mode().Perm() != 0o600 {
+		t.Errorf("Expected spooled events to be readable only by their owner, received %v", files[0].Mode().Perm())
+	}
+	atomic.StoreInt32(&failLogging, 0)
+	c = newSpoolClient(false, 0)
+	waitForLogged([]string{"d"})
+	c.Shutdown()
+
+	// The oldest batches are deleted to stay within MaxBytes
+	spool := newEventSpool(&Options{EventSpoolOptions: EventSpoolOptions{Directory: dir, MaxBytes: 50}})
+	batch := []interface{}{strings.Repeat("x", 18)} // 22 bytes serialized
+	for i := 0; i < 3; i++ {
+		if evicted, err := spool.write(batch, "batch"); err != nil || evicted != int64(i/2) {
+			t.Errorf("Unexpected eviction of %d events, error %v", evicted, err)
+		}
+	}
+	if spooled() != 2 || spool.totalBytes != 44 || spool.batches[0].name != "00000000000000000001-1-batch.json" {
+		t.Errorf("Expected the oldest batch to be deleted, received %+v", spool.batches)
+	}
+	if _, err := spool.write([]interface{}{strings.Repeat("x", 60)}, "batch"); err == nil {
+		t.Errorf("Expected batches larger than MaxBytes to be rejected")
+	}
+	if reopened := newEventSpool(&Options{EventSpoolOptions: EventSpoolOptions{Directory: dir}}); len(reopened.batches) != 2 || reopened.seq != 3 {
+		t.Errorf("Expected spooled batches to be listed on startup, received %+v", reopened.batches)
+	}
+}
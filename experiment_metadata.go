@@ -0,0 +1,44 @@
+package statsig
+
+// The assignment context of an experiment evaluation, e.g. for analytics pipelines recording which
+// users were allocated to which group
+type ExperimentMetadata struct {
+	GroupName          string `json:"groupName"`          // The assigned group, empty when the user is not in the experiment
+	RuleID             string `json:"ruleID"`             // The assigned rule, or one of the well-known rule IDs, e.g. RuleIDPrestart
+	IsUserInExperiment bool   `json:"isUserInExperiment"` // Whether the user was allocated to an experiment group rather than a targeting or default rule
+	IsExperimentActive bool   `json:"isExperimentActive"` // Whether the experiment is started and not yet concluded
+}
+
+type experimentAssignment struct {
+	isUserInExperiment bool
+	isExperimentActive bool
+}
+
+// Gets the DynamicConfig value of an Experiment for the given user, with the user's assignment
+func (c *Client) GetExperimentWithMetadata(user User, experiment string) (DynamicConfig, ExperimentMetadata) {
+	res := c.GetExperiment(user, experiment)
+	return res, exportExperimentMetadata(res)
+}
+
+// Gets the DynamicConfig value of an Experiment for the given user with configurable options, with the user's assignment
+func (c *Client) GetExperimentWithMetadataAndOptions(user User, experiment string, options *GetExperimentOptions) (DynamicConfig, ExperimentMetadata) {
+	res := c.GetExperimentWithOptions(user, experiment, options)
+	return res, exportExperimentMetadata(res)
+}
+
+func exportExperimentMetadata(config DynamicConfig) ExperimentMetadata {
+	return ExperimentMetadata{
+		GroupName:          config.GroupName,
+		RuleID:             config.RuleID,
+		IsUserInExperiment: config.experiment.isUserInExperiment,
+		IsExperimentActive: config.experiment.isExperimentActive,
+	}
+}
+
+func (c *Client) experimentAssignment(experiment string, res *evalResult) experimentAssignment {
+	spec, ok := c.evaluator.store.getDynamicConfig(experiment)
+	return experimentAssignment{
+		isUserInExperiment: res.IsExperimentGroup != nil && *res.IsExperimentGroup,
+		isExperimentActive: ok && spec.IsActive != nil && *spec.IsActive,
+	}
+}
@@ -0,0 +1,59 @@
+package statsig
+
+import (
+	"testing"
+)
+
+const experimentMetadataSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [],
+	"dynamic_configs": [
+		{"name": "checkout", "type": "dynamic_config", "entity": "experiment", "idType": "userID", "salt": "s", "enabled": true, "isActive": true, "defaultValue": {"color": "grey"}, "rules": [
+			{"name": "qa", "id": "qa_rule", "groupName": "QA", "salt": "s", "passPercentage": 100, "returnValue": {"color": "red"},
+				"conditions": [{"type": "user_field", "field": "email", "operator": "any", "targetValue": ["qa@b.com"]}]},
+			{"name": "test", "id": "test_rule", "groupName": "Test", "salt": "s", "passPercentage": 100, "isExperimentGroup": true, "returnValue": {"color": "blue"},
+				"conditions": [{"type": "public"}]}]},
+		{"name": "concluded", "type": "dynamic_config", "entity": "experiment", "idType": "userID", "salt": "s", "enabled": true, "isActive": false, "defaultValue": {}, "rules": []}
+	],
+	"layer_configs": []
+}`
+
+func TestExperimentMetadata(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:             true,
+		BootstrapValues:       experimentMetadataSpecs,
+		UserPersistentStorage: &userPersistentStorageExample{store: make(map[string]string)},
+		StatsigLoggerOptions:  getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	config, metadata := c.GetExperimentWithMetadata(User{UserID: "a"}, "checkout")
+	expected := ExperimentMetadata{GroupName: "Test", RuleID: "test_rule", IsUserInExperiment: true, IsExperimentActive: true}
+	if metadata != expected || config.GetString("color", "") != "blue" {
+		t.Errorf("Expected %+v, received %+v", expected, metadata)
+	}
+	_, metadata = c.GetExperimentWithMetadataAndOptions(User{UserID: "a", Email: "qa@b.com"}, "checkout", &GetExperimentOptions{DisableLogExposures: true})
+	expected = ExperimentMetadata{GroupName: "QA", RuleID: "qa_rule", IsUserInExperiment: false, IsExperimentActive: true}
+	if metadata != expected {
+		t.Errorf("Expected targeted users not to be in the experiment, received %+v", metadata)
+	}
+	_, metadata = c.GetExperimentWithMetadata(User{UserID: "a"}, "concluded")
+	if metadata.IsExperimentActive || metadata.IsUserInExperiment || metadata.RuleID != RuleIDDefault {
+		t.Errorf("Unexpected metadata for a concluded experiment %+v", metadata)
+	}
+	_, metadata = c.GetExperimentWithMetadata(User{UserID: "a"}, "unknown")
+	if metadata.IsExperimentActive || metadata.IsUserInExperiment {
+		t.Errorf("Unexpected metadata for an unknown experiment %+v", metadata)
+	}
+
+	// Sticky assignments are only persisted for users in an experiment group
+	persisted := c.GetUserPersistedValues(User{UserID: "a"}, "userID")
+	c.GetExperimentWithOptions(User{UserID: "a"}, "checkout", &GetExperimentOptions{PersistedValues: persisted})
+	persisted = c.GetUserPersistedValues(User{UserID: "a"}, "userID")
+	config, metadata = c.GetExperimentWithMetadataAndOptions(User{UserID: "a"}, "checkout", &GetExperimentOptions{PersistedValues: persisted})
+	if config.EvaluationDetails.reason != reasonPersisted || !metadata.IsUserInExperiment || metadata.GroupName != "Test" {
+		t.Errorf("Expected sticky assignments to be in the experiment, received %+v", metadata)
+	}
+}
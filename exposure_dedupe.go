@@ -0,0 +1,130 @@
+package statsig
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Exposures remembered for deduplication across all shards. A shard that fills up starts a new
+	// generation early, forgetting its oldest exposures
+	maxDedupedExposures = 100000
+	// Recording locks only the shard an exposure falls in, so concurrent evaluations rarely contend
+	exposureDedupeShards = 16
+)
+
+// Remembers exposures logged within Options.ExposureDedupeWindow, so repeated evaluations of the same spec
+// for the same user, e.g. along one request path, log a single exposure. Forgotten when the ruleset changes
+type exposureDedupe struct {
+	window time.Duration
+	shards [exposureDedupeShards]exposureDedupeShard
+}
+
+// Exposures are kept in two generations, each spanning one window. Once the current generation is a window
+// old, it replaces the previous one, which is dropped whole, so expired exposures are evicted in O(1)
+type exposureDedupeShard struct {
+	current   map[string]time.Time // When each exposure was last logged
+	previous  map[string]time.Time
+	rotatedAt time.Time
+	capacity  int
+	syncTime  int64 // Ruleset the remembered exposures were evaluated against
+	mu        sync.Mutex
+}
+
+func newExposureDedupe(options *Options) *exposureDedupe {
+	if options.ExposureDedupeWindow <= 0 {
+		return nil
+	}
+	d := &exposureDedupe{window: options.ExposureDedupeWindow}
+	now := time.Now()
+	for i := range d.shards {
+		d.shards[i] = exposureDedupeShard{
+			current:   make(map[string]time.Time),
+			rotatedAt: now,
+			// Each generation holds half of the shard's share
+			capacity: maxDedupedExposures / exposureDedupeShards / 2,
+		}
+	}
+	return d
+}
+
+// Keyed by the user's IDs and the exposure metadata set before evaluation details are added,
+// i.e. the spec, rule, value and, for layers, the parameter
+func exposureDedupeKey(evt *ExposureEvent) (string, bool) {
+	ids, err := json.Marshal(struct {
+		UserID    string
+		CustomIDs map[string]string
+	}{evt.User.UserID, evt.User.CustomIDs})
+	if err != nil {
+		return "", false
+	}
+	fields := make([]string, 0, len(evt.Metadata))
+	for k, v := range evt.Metadata {
+		fields = append(fields, k+"="+v)
+	}
+	sort.Strings(fields)
+	return string(evt.EventName) + ":" + getHashBase64StringEncoding(string(ids)+strings.Join(fields, "&")), true
+}
+
+// Returns false if an identical exposure was logged within the window
+func (d *exposureDedupe) shouldLog(evt *ExposureEvent, syncTime int64) bool {
+	if d == nil || evt.Metadata["isManualExposure"] == "true" {
+		return true
+	}
+	key, ok := exposureDedupeKey(evt)
+	if !ok {
+		return true
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(key))
+	shard := &d.shards[hasher.Sum32()%exposureDedupeShards]
+	now := time.Now()
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if syncTime > shard.syncTime {
+		shard.current, shard.previous, shard.rotatedAt = make(map[string]time.Time), nil, now
+		shard.syncTime = syncTime
+	}
+	if now.Sub(shard.rotatedAt) >= d.window {
+		shard.rotateLocked(now, d.window)
+	}
+	if logged, exists := shard.current[key]; exists && now.Sub(logged) < d.window {
+		return false
+	}
+	if logged, exists := shard.previous[key]; exists && now.Sub(logged) < d.window {
+		return false
+	}
+	if len(shard.current) >= shard.capacity {
+		shard.rotateLocked(now, d.window)
+	}
+	shard.current[key] = now
+	return true
+}
+
+// Starts a new generation. The current one becomes the previous, unless it is two windows old and
+// nothing in it can still deduplicate an exposure
+func (s *exposureDedupeShard) rotateLocked(now time.Time, window time.Duration) {
+	if now.Sub(s.rotatedAt) >= 2*window {
+		s.previous = nil
+	} else {
+		s.previous = s.current
+	}
+	s.current = make(map[string]time.Time)
+	s.rotatedAt = now
+}
+
+// Exposures awaited with WithExposureAck are always logged
+func (l *logger) dedupeExposure(evt *ExposureEvent, evalDetails *evaluationDetails, context *logContext) bool {
+	if context != nil && exposureAckFromContext(context.ctx) != nil {
+		return true
+	}
+	var syncTime int64
+	if evalDetails != nil {
+		syncTime = evalDetails.configSyncTime
+	}
+	return l.dedupe.shouldLog(evt, syncTime)
+}
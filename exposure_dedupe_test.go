@@ -0,0 +1,116 @@
+package statsig
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExposureDedupe(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(specs),
+		LoggingInterval:      time.Hour,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		ExposureDedupeWindow: time.Minute,
+	})
+	defer c.Shutdown()
+	exposureCount := func() int {
+		c.logger.mu.Lock()
+		defer c.logger.mu.Unlock()
+		count := 0
+		for _, evt := range c.logger.events {
+			if _, ok := evt.(ExposureEvent); ok {
+				count++
+			}
+		}
+		return count
+	}
+	user := User{UserID: "a_user"}
+
+	for i := 0; i < 3; i++ {
+		c.CheckGate(user, "always_on_gate")
+		c.GetConfig(user, "test_config")
+	}
+	if count := exposureCount(); count != 2 {
+		t.Errorf("Expected one exposure per spec, received %d", count)
+	}
+	c.CheckGate(User{UserID: "another_user"}, "always_on_gate")
+	c.CheckGate(User{UserID: "a_user", Email: "a@statsig.com"}, "always_on_gate")
+	if count := exposureCount(); count != 3 {
+		t.Errorf("Expected only exposures for other user IDs to be logged, received %d", count)
+	}
+	c.ManuallyLogGateExposure(user, "always_on_gate")
+	if count := exposureCount(); count != 4 {
+		t.Errorf("Expected manual exposures to be logged, received %d", count)
+	}
+
+	// Exposures are remembered only until the ruleset changes
+	updated := strings.Replace(string(specs), `"time": 1631638014811`, `"time": 1631638014812`, 1)
+	if parsed, changed := c.evaluator.store.processConfigSpecs(updated, c.evaluator.store.addDiagnostics().downloadConfigSpecs()); !parsed || !changed {
+		t.Fatalf("Expected the updated ruleset to be applied")
+	}
+	c.CheckGate(user, "always_on_gate")
+	if count := exposureCount(); count != 5 {
+		t.Errorf("Expected the exposure to be logged again after the ruleset changed, received %d", count)
+	}
+
+	for i := range c.logger.dedupe.shards {
+		shard := &c.logger.dedupe.shards[i]
+		shard.mu.Lock()
+		for key := range shard.current {
+			shard.current[key] = time.Now().Add(-time.Minute)
+		}
+		shard.mu.Unlock()
+	}
+	c.CheckGate(user, "always_on_gate")
+	if count := exposureCount(); count != 6 {
+		t.Errorf("Expected the exposure to be logged again after the window, received %d", count)
+	}
+}
+
+func TestExposureDedupeEviction(t *testing.T) {
+	d := newExposureDedupe(&Options{ExposureDedupeWindow: time.Minute})
+	exposure := func(userID string) *ExposureEvent {
+		return &ExposureEvent{EventName: GateExposureEventName, User: User{UserID: userID}, Metadata: map[string]string{"gate": "a_gate"}}
+	}
+	for i := 0; i < 2*maxDedupedExposures; i++ {
+		if !d.shouldLog(exposure(strconv.Itoa(i)), 1) {
+			t.Fatalf("Expected the first exposure for each user to be logged")
+		}
+	}
+	remembered := 0
+	for i := range d.shards {
+		remembered += len(d.shards[i].current) + len(d.shards[i].previous)
+	}
+	if remembered > maxDedupedExposures {
+		t.Errorf("Expected at most %d remembered exposures, received %d", maxDedupedExposures, remembered)
+	}
+	if d.shouldLog(exposure(strconv.Itoa(2*maxDedupedExposures-1)), 1) {
+		t.Errorf("Expected the latest exposures to still be deduplicated")
+	}
+
+	// A generation a window old moves to the previous one and is dropped after another window
+	shard := &d.shards[0]
+	shard.mu.Lock()
+	shard.rotatedAt = time.Now().Add(-time.Minute)
+	var key string
+	for key = range shard.current {
+		shard.current[key] = time.Now().Add(-30 * time.Second)
+		break
+	}
+	shard.rotateLocked(time.Now(), time.Minute)
+	if _, ok := shard.previous[key]; !ok || len(shard.current) != 0 {
+		t.Errorf("Expected the current generation to become the previous one")
+	}
+	shard.rotatedAt = time.Now().Add(-2 * time.Minute)
+	shard.rotateLocked(time.Now(), time.Minute)
+	if len(shard.previous) != 0 || len(shard.current) != 0 {
+		t.Errorf("Expected generations two windows old to be dropped")
+	}
+	shard.mu.Unlock()
+}
@@ -0,0 +1,66 @@
+package statsig
+
+import (
+	"fmt"
+	"time"
+)
+
+// Attribute keys follow the OpenTelemetry semantic conventions for feature flags
+const (
+	exposureRecordEventName         = "feature_flag.evaluation"
+	exposureAttributeKey            = "feature_flag.key"
+	exposureAttributeProviderName   = "feature_flag.provider_name"
+	exposureAttributeVariant        = "feature_flag.variant"
+	exposureAttributeContextID      = "feature_flag.context.id"
+	exposureAttributeMetadataPrefix = "statsig."
+	exposureProviderName            = "Statsig"
+)
+
+// An exposure in the shape of an OpenTelemetry log record or span event, e.g. for
+// logger.Emit with the log bridge API or span.AddEvent(record.EventName, ...)
+type ExposureLogRecord struct {
+	Timestamp  time.Time
+	EventName  string            // Always feature_flag.evaluation
+	Attributes map[string]string // feature_flag.* attributes, plus the exposure metadata prefixed with statsig.
+}
+
+func newExposureLogRecord(evt ExposureEvent) ExposureLogRecord {
+	attributes := map[string]string{
+		exposureAttributeProviderName:                       exposureProviderName,
+		exposureAttributeContextID:                          evt.User.UserID,
+		exposureAttributeMetadataPrefix + "exposure_type":   string(evt.EventName),
+		exposureAttributeMetadataPrefix + "time_since_init": fmt.Sprint(evt.TimeSinceInit),
+	}
+	for key, value := range evt.Metadata {
+		attributes[exposureAttributeMetadataPrefix+key] = value
+	}
+	switch evt.EventName {
+	case GateExposureEventName:
+		attributes[exposureAttributeKey] = evt.Metadata["gate"]
+		attributes[exposureAttributeVariant] = evt.Metadata["gateValue"]
+	default:
+		attributes[exposureAttributeKey] = evt.Metadata["config"]
+		attributes[exposureAttributeVariant] = evt.Metadata["ruleID"]
+	}
+	if unitID, ok := evt.Metadata["unitID"]; ok {
+		attributes[exposureAttributeContextID] = unitID
+	}
+	return ExposureLogRecord{
+		Timestamp:  time.Unix(0, evt.Time*int64(time.Millisecond)),
+		EventName:  exposureRecordEventName,
+		Attributes: attributes,
+	}
+}
+
+func (l *logger) exportExposure(evt ExposureEvent) {
+	export := l.options.ExposureExportOptions.Exporter
+	if export == nil {
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("Exposure exporter panicked: %s\n", toError(err).Error()))
+		}
+	}()
+	export(newExposureLogRecord(evt))
+}
@@ -0,0 +1,66 @@
+package statsig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestExposureExport(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	user := User{UserID: "a_user", Email: "a_user@statsig.com"}
+
+	var records []ExposureLogRecord
+	newTestClient := func(disableStatsigLogging bool) *Client {
+		records = make([]ExposureLogRecord, 0)
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      string(bytes),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			ExposureExportOptions: ExposureExportOptions{
+				Exporter:                      func(record ExposureLogRecord) { records = append(records, record) },
+				DisableStatsigExposureLogging: disableStatsigLogging,
+			},
+		})
+	}
+
+	t.Run("exports exposures alongside log_event", func(t *testing.T) {
+		c := newTestClient(false)
+		defer c.Shutdown()
+		c.CheckGate(user, "on_for_statsig_email")
+		c.GetConfig(user, "test_config")
+		if len(records) != 2 || len(c.logger.events) != 2 {
+			t.Fatalf("Expected 2 exported and 2 logged exposures, received %d and %d", len(records), len(c.logger.events))
+		}
+		gate := records[0]
+		expected := map[string]string{
+			"feature_flag.key":           "on_for_statsig_email",
+			"feature_flag.variant":       "true",
+			"feature_flag.provider_name": "Statsig",
+			"feature_flag.context.id":    "a_user",
+			"statsig.exposure_type":      "statsig::gate_exposure",
+			"statsig.ruleID":             "7w9rbTSffLT89pxqpyhuqK",
+		}
+		for key, value := range expected {
+			if gate.Attributes[key] != value {
+				t.Errorf("Expected %s to be %s, received %s", key, value, gate.Attributes[key])
+			}
+		}
+		if gate.EventName != "feature_flag.evaluation" || gate.Timestamp.IsZero() {
+			t.Errorf("Unexpected record %+v", gate)
+		}
+		if records[1].Attributes["feature_flag.key"] != "test_config" || records[1].Attributes["feature_flag.variant"] != "1kNmlB23wylPFZi1M0Divl" {
+			t.Errorf("Unexpected config record %+v", records[1].Attributes)
+		}
+	})
+
+	t.Run("exports exposures instead of log_event", func(t *testing.T) {
+		c := newTestClient(true)
+		defer c.Shutdown()
+		c.CheckGate(user, "always_on_gate")
+		c.LogEvent(Event{EventName: "custom", User: user})
+		if len(records) != 1 || len(c.logger.events) != 1 {
+			t.Errorf("Expected only the custom event to be logged, received %d records and %d events", len(records), len(c.logger.events))
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package statsig
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Logs a fraction of exposures and caps how many are logged per second, so very hot gates do not send
+// millions of identical exposures. Exposures kept by sampling record their rate as samplingRate metadata,
+// so counts can be scaled back up. Manual exposures and those awaited with WithExposureAck are always logged
+type ExposureSamplingOptions struct {
+	SampleRate            float64            // Fraction of exposures logged, between 0 and 1. Defaults to 1
+	SpecSampleRates       map[string]float64 // Rates for individual gates, configs and layers, overriding SampleRate. 0 logs none of their exposures
+	MaxExposuresPerSecond float64            // Exposures logged per second across all specs, beyond which they are dropped. Unlimited when 0
+	Burst                 int                // Exposures logged at once before MaxExposuresPerSecond applies. Defaults to MaxExposuresPerSecond
+}
+
+// A token bucket shared by every exposure, refilled at MaxExposuresPerSecond
+type exposureRateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+func newExposureRateLimiter(options ExposureSamplingOptions) *exposureRateLimiter {
+	if options.MaxExposuresPerSecond <= 0 {
+		return nil
+	}
+	burst := float64(options.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, options.MaxExposuresPerSecond)
+	}
+	return &exposureRateLimiter{rate: options.MaxExposuresPerSecond, burst: burst, tokens: burst, last: time.Now()}
+}
+
+func (r *exposureRateLimiter) allow() bool {
+	if r == nil {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+func (l *logger) exposureSampleRate(evt *ExposureEvent) float64 {
+	sampling := l.options.ExposureSamplingOptions
+	name := evt.Metadata["gate"]
+	if name == "" {
+		name = evt.Metadata["config"]
+	}
+	if rate, ok := sampling.SpecSampleRates[name]; ok {
+		return rate
+	}
+	if sampling.SampleRate <= 0 {
+		return 1
+	}
+	return sampling.SampleRate
+}
+
+// Returns false if the exposure should not be logged
+func (l *logger) sampleExposure(evt *ExposureEvent) bool {
+	if evt.Metadata["isManualExposure"] == "true" {
+		return true
+	}
+	rate := l.exposureSampleRate(evt)
+	if rate < 1 && (rate <= 0 || rand.Float64() >= rate) {
+		l.metrics.exposureSkipped("sampled")
+		return false
+	}
+	if !l.exposureLimit.allow() {
+		l.metrics.exposureSkipped("rate_limited")
+		return false
+	}
+	if rate < 1 {
+		evt.Metadata["samplingRate"] = strconv.FormatFloat(rate, 'f', -1, 64)
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package statsig
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExposureSampling(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	newSamplingClient := func(sampling ExposureSamplingOptions) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:               true,
+			BootstrapValues:         string(bytes),
+			LoggingInterval:         time.Hour,
+			StatsigLoggerOptions:    getStatsigLoggerOptionsForTest(t),
+			ExposureSamplingOptions: sampling,
+		})
+	}
+	exposures := func(c *Client) []ExposureEvent {
+		c.logger.mu.Lock()
+		defer c.logger.mu.Unlock()
+		var logged []ExposureEvent
+		for _, evt := range c.logger.events {
+			if exposure, ok := evt.(ExposureEvent); ok {
+				logged = append(logged, exposure)
+			}
+		}
+		return logged
+	}
+	user := User{UserID: "a_user"}
+
+	t.Run("samples per spec and records the rate", func(t *testing.T) {
+		c := newSamplingClient(ExposureSamplingOptions{
+			SampleRate:      0.5,
+			SpecSampleRates: map[string]float64{"always_on_gate": 0, "test_config": 1},
+		})
+		defer c.Shutdown()
+		for i := 0; i < 200; i++ {
+			c.CheckGate(user, "always_on_gate")
+			c.CheckGate(user, "on_for_statsig_email")
+		}
+		c.GetConfig(user, "test_config")
+		c.ManuallyLogGateExposure(user, "always_on_gate")
+		var gate, manual, config, sampled int
+		for _, evt := range exposures(c) {
+			switch {
+			case evt.Metadata["isManualExposure"] == "true":
+				manual++
+			case evt.Metadata["gate"] == "always_on_gate":
+				gate++
+			case evt.Metadata["config"] == "test_config":
+				config++
+				if evt.Metadata["samplingRate"] != "" {
+					t.Errorf("Expected unsampled exposures to have no sampling rate, received %+v", evt.Metadata)
+				}
+			case evt.Metadata["samplingRate"] == "0.5":
+				sampled++
+			}
+		}
+		if gate != 0 || manual != 1 || config != 1 {
+			t.Errorf("Expected only the manual always_on_gate exposure and the config exposure, received %d, %d and %d", gate, manual, config)
+		}
+		if sampled < 50 || sampled > 150 {
+			t.Errorf("Expected about half of the exposures to be sampled, received %d of 200", sampled)
+		}
+	})
+
+	t.Run("caps exposures per second", func(t *testing.T) {
+		c := newSamplingClient(ExposureSamplingOptions{MaxExposuresPerSecond: 1, Burst: 2})
+		defer c.Shutdown()
+		for i := 0; i < 5; i++ {
+			c.CheckGate(user, "always_on_gate")
+		}
+		if logged := len(exposures(c)); logged != 2 {
+			t.Errorf("Expected the burst of 2 exposures to be logged, received %d", logged)
+		}
+		c.logger.exposureLimit.last = c.logger.exposureLimit.last.Add(-time.Second)
+		c.CheckGate(user, "always_on_gate")
+		if logged := len(exposures(c)); logged != 3 {
+			t.Errorf("Expected an exposure to be logged once the bucket refilled, received %d", logged)
+		}
+	})
+}
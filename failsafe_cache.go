@@ -0,0 +1,301 @@
+package statsig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const FAILSAFE_EVALUATIONS_KEY = "statsig.failsafe_evaluations"
+
+const (
+	defaultFailsafePersistInterval = time.Minute
+	// Users tracked per cached user, so IDs that become hot are counted before they reach the top
+	failsafeTrackingFactor = 10
+	// Recording locks only the shard a unit falls in, so concurrent evaluations rarely contend
+	failsafeShards = 16
+)
+
+// Persists evaluation results for the most frequently evaluated users to Options.DataAdapter, and
+// serves them while no ruleset is loaded, e.g. after a restart until the first sync succeeds.
+// Results are cached by the unit ID of each spec's ID type, which is hashed before it is persisted.
+type FailsafeCacheOptions struct {
+	Size            int           // Number of users whose results are persisted. Disabled when 0
+	PersistInterval time.Duration // How often results are written to the adapter, and again on Shutdown. Defaults to one minute
+}
+
+type failsafeResult struct {
+	Pass                          bool                   `json:"pass,omitempty"`
+	Value                         map[string]interface{} `json:"value,omitempty"`
+	RuleID                        string                 `json:"ruleID"`
+	GroupName                     string                 `json:"groupName,omitempty"`
+	IDType                        string                 `json:"idType,omitempty"`
+	Variant                       string                 `json:"variant,omitempty"`
+	IsExperimentGroup             *bool                  `json:"isExperimentGroup,omitempty"`
+	ConfigDelegate                string                 `json:"configDelegate,omitempty"`
+	ExplicitParameters            map[string]bool        `json:"explicitParameters,omitempty"`
+	SecondaryExposures            []map[string]string    `json:"secondaryExposures,omitempty"`
+	UndelegatedSecondaryExposures []map[string]string    `json:"undelegatedSecondaryExposures,omitempty"`
+	ConfigSyncTime                int64                  `json:"configSyncTime"` // The ruleset version the result was evaluated against
+}
+
+type failsafeSnapshot struct {
+	RulesetVersion int64                                `json:"rulesetVersion"`
+	SavedAt        int64                                `json:"savedAt"`
+	Units          map[string]map[string]failsafeResult `json:"units"` // Keyed by failsafeUnitKey
+}
+
+type failsafeUnit struct {
+	count   int64
+	results map[string]failsafeResult
+}
+
+type failsafeShard struct {
+	units map[string]*failsafeUnit
+	mu    sync.Mutex
+}
+
+type failsafeCache struct {
+	store         *store
+	size          int
+	shardCapacity int                                  // Units tracked per shard before counts decay
+	loaded        map[string]map[string]failsafeResult // Read from the adapter once, before any evaluation
+	loadedIDTypes map[string]string                    // The ID type each persisted spec was evaluated for
+	shards        [failsafeShards]failsafeShard
+	tick          *time.Ticker
+	done          chan bool
+	once          sync.Once
+}
+
+func newFailsafeCache(store *store, options *Options) *failsafeCache {
+	if options.FailsafeCacheOptions.Size <= 0 || options.DataAdapter == nil {
+		return nil
+	}
+	interval := options.FailsafeCacheOptions.PersistInterval
+	if interval <= 0 {
+		interval = defaultFailsafePersistInterval
+	}
+	size := options.FailsafeCacheOptions.Size
+	c := &failsafeCache{
+		store:         store,
+		size:          size,
+		shardCapacity: (size*failsafeTrackingFactor + failsafeShards - 1) / failsafeShards,
+		tick:          time.NewTicker(interval),
+		done:          make(chan bool),
+	}
+	for i := range c.shards {
+		c.shards[i].units = make(map[string]*failsafeUnit)
+	}
+	c.load()
+	go c.backgroundPersist()
+	return c
+}
+
+func (c *failsafeCache) load() {
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("Error calling data adapter get: %s\n", toError(err).Error()))
+		}
+	}()
+	content := c.store.dataAdapter.Get(FAILSAFE_EVALUATIONS_KEY)
+	if content == "" {
+		return
+	}
+	var snapshot failsafeSnapshot
+	if err := json.Unmarshal([]byte(content), &snapshot); err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to parse failsafe evaluations from the data adapter: %s", err.Error()))
+		return
+	}
+	c.loaded = snapshot.Units
+	c.loadedIDTypes = make(map[string]string)
+	for _, results := range snapshot.Units {
+		for key, result := range results {
+			c.loadedIDTypes[key] = result.IDType
+		}
+	}
+}
+
+func failsafeKey(kind string, name string) string {
+	return kind + ":" + name
+}
+
+// Hashes the unit ID, so IDs are not stored in the adapter, and picks the shard it is recorded in
+func failsafeUnitKey(idType string, unitID string) (string, int) {
+	idType = strings.ToLower(idType)
+	if idType == "" {
+		idType = "userid"
+	}
+	hash := getHash(idType + ":" + unitID)
+	return base64.StdEncoding.EncodeToString(hash), int(hash[0]) % failsafeShards
+}
+
+// Serves the persisted result in place of one evaluated without a ruleset, and otherwise records
+// the result for the user
+func (c *failsafeCache) resolve(user User, kind string, name string, res *evalResult) *evalResult {
+	if c == nil || res.FetchFromServer || res.EvaluationDetails == nil {
+		return res
+	}
+	switch res.EvaluationDetails.reason {
+	case reasonUnrecognized:
+		if c.store.getInitReason() == reasonUninitialized {
+			return c.serve(user, kind, name, res)
+		}
+	case reasonUninitialized, reasonLocalOverride, reasonFailsafe:
+	default:
+		c.record(user, kind, name, res)
+	}
+	return res
+}
+
+func (c *failsafeCache) serve(user User, kind string, name string, res *evalResult) *evalResult {
+	key := failsafeKey(kind, name)
+	idType, ok := c.loadedIDTypes[key]
+	if !ok {
+		return res
+	}
+	unitID := getUnitID(user, idType)
+	if unitID == "" {
+		return res
+	}
+	unitKey, _ := failsafeUnitKey(idType, unitID)
+	cached, ok := c.loaded[unitKey][key]
+	if !ok {
+		return res
+	}
+	return &evalResult{
+		Pass:                          cached.Pass,
+		ConfigValue:                   *NewConfig(name, cached.Value, cached.RuleID, cached.GroupName, nil),
+		RuleID:                        cached.RuleID,
+		GroupName:                     cached.GroupName,
+		IDType:                        cached.IDType,
+		Variant:                       cached.Variant,
+		IsExperimentGroup:             cached.IsExperimentGroup,
+		ConfigDelegate:                cached.ConfigDelegate,
+		ExplicitParameters:            cached.ExplicitParameters,
+		SecondaryExposures:            cached.SecondaryExposures,
+		UndelegatedSecondaryExposures: cached.UndelegatedSecondaryExposures,
+		EvaluationDetails:             newEvaluationDetails(reasonFailsafe, cached.ConfigSyncTime, res.EvaluationDetails.initTime),
+	}
+}
+
+func (c *failsafeCache) record(user User, kind string, name string, res *evalResult) {
+	unitID := getUnitID(user, res.IDType)
+	if unitID == "" {
+		return
+	}
+	unitKey, index := failsafeUnitKey(res.IDType, unitID)
+	shard := &c.shards[index]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	entry, ok := shard.units[unitKey]
+	if !ok {
+		if len(shard.units) >= c.shardCapacity {
+			shard.decayLocked()
+		}
+		entry = &failsafeUnit{results: make(map[string]failsafeResult)}
+		shard.units[unitKey] = entry
+	}
+	entry.count++
+	entry.results[failsafeKey(kind, name)] = failsafeResult{
+		Pass:                          res.Pass,
+		Value:                         res.ConfigValue.Value,
+		RuleID:                        res.RuleID,
+		GroupName:                     res.GroupName,
+		IDType:                        res.IDType,
+		Variant:                       res.Variant,
+		IsExperimentGroup:             res.IsExperimentGroup,
+		ConfigDelegate:                res.ConfigDelegate,
+		ExplicitParameters:            res.ExplicitParameters,
+		SecondaryExposures:            res.SecondaryExposures,
+		UndelegatedSecondaryExposures: res.UndelegatedSecondaryExposures,
+		ConfigSyncTime:                res.EvaluationDetails.configSyncTime,
+	}
+}
+
+// Halves every count in the shard, forgetting units evaluated once since the last decay, so the
+// tracked units stay bounded and favor those evaluated recently
+func (s *failsafeShard) decayLocked() {
+	for unitKey, entry := range s.units {
+		entry.count /= 2
+		if entry.count == 0 {
+			delete(s.units, unitKey)
+		}
+	}
+}
+
+func (c *failsafeCache) snapshot() *failsafeSnapshot {
+	type counted struct {
+		key     string
+		count   int64
+		results map[string]failsafeResult
+	}
+	var units []counted
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.mu.Lock()
+		for unitKey, entry := range shard.units {
+			results := make(map[string]failsafeResult, len(entry.results))
+			for key, result := range entry.results {
+				results[key] = result
+			}
+			units = append(units, counted{key: unitKey, count: entry.count, results: results})
+		}
+		shard.mu.Unlock()
+	}
+	if len(units) == 0 {
+		return nil
+	}
+	sort.Slice(units, func(i, j int) bool {
+		if units[i].count != units[j].count {
+			return units[i].count > units[j].count
+		}
+		return units[i].key < units[j].key
+	})
+	if len(units) > c.size {
+		units = units[:c.size]
+	}
+	snapshot := &failsafeSnapshot{
+		SavedAt: getUnixMilli(),
+		Units:   make(map[string]map[string]failsafeResult, len(units)),
+	}
+	for _, unit := range units {
+		snapshot.Units[unit.key] = unit.results
+	}
+	c.store.mu.RLock()
+	snapshot.RulesetVersion = c.store.lastSyncTime
+	c.store.mu.RUnlock()
+	return snapshot
+}
+
+func (c *failsafeCache) persist() {
+	if snapshot := c.snapshot(); snapshot != nil {
+		c.store.adapterWrites.set(FAILSAFE_EVALUATIONS_KEY, snapshot)
+	}
+}
+
+func (c *failsafeCache) backgroundPersist() {
+	for {
+		select {
+		case <-c.tick.C:
+			c.persist()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Persists the latest results, which the adapter writer flushes as it closes on shutdown
+func (c *failsafeCache) stop() {
+	if c == nil {
+		return
+	}
+	c.once.Do(func() {
+		c.tick.Stop()
+		close(c.done)
+		c.persist()
+	})
+}
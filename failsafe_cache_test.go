@@ -0,0 +1,99 @@
+package statsig
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFailsafeCache(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	newFailsafeClient := func(adapter *dataAdapterExample) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			DataAdapter:          adapter,
+			FailsafeCacheOptions: FailsafeCacheOptions{Size: 1},
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	hot := User{UserID: "hot", Email: "hot@statsig.com"}
+	cold := User{UserID: "cold", Email: "cold@statsig.com"}
+
+	adapter := &dataAdapterExample{store: map[string]string{CONFIG_SPECS_KEY: string(specs)}}
+	c := newFailsafeClient(adapter)
+	for i := 0; i < 3; i++ {
+		c.CheckGate(hot, "always_on_gate")
+	}
+	expectedConfig := c.GetConfig(hot, "test_config")
+	expectedLayer := c.GetLayer(hot, "a_layer")
+	c.CheckGate(cold, "always_on_gate")
+	c.Shutdown()
+
+	var snapshot failsafeSnapshot
+	if err := json.Unmarshal([]byte(adapter.Get(FAILSAFE_EVALUATIONS_KEY)), &snapshot); err != nil {
+		t.Fatalf("Expected failsafe evaluations to be persisted, received %v", err)
+	}
+	hotKey, _ := failsafeUnitKey("userID", "hot")
+	if len(snapshot.Units) != 1 || snapshot.Units[hotKey] == nil || snapshot.RulesetVersion != configSyncTime {
+		t.Errorf("Expected only the most evaluated user to be persisted, received %+v", snapshot)
+	}
+	if strings.Contains(adapter.Get(FAILSAFE_EVALUATIONS_KEY), `"hot"`) {
+		t.Errorf("Expected unit IDs to be hashed before they are persisted")
+	}
+
+	// Restarted without config specs, so nothing is loaded until the first sync succeeds
+	restarted := newFailsafeClient(&dataAdapterExample{store: map[string]string{
+		FAILSAFE_EVALUATIONS_KEY: adapter.Get(FAILSAFE_EVALUATIONS_KEY),
+	}})
+	defer restarted.Shutdown()
+	pass, details := restarted.CheckGateWithDetails(hot, "always_on_gate")
+	if !pass || details.Reason != "Failsafe" || details.RuleID != "6N6Z8ODekNYZ7F8gFdoLP5" || details.ConfigSyncTime != configSyncTime {
+		t.Errorf("Expected the persisted gate result, received %v %+v", pass, details)
+	}
+	config, details := restarted.GetConfigWithDetails(hot, "test_config")
+	if !reflect.DeepEqual(config.Value, expectedConfig.Value) || config.RuleID != expectedConfig.RuleID || details.Reason != "Failsafe" {
+		t.Errorf("Expected the persisted config result, received %+v %+v", config, details)
+	}
+	layer := restarted.GetLayer(hot, "a_layer")
+	if !reflect.DeepEqual(layer.Value, expectedLayer.Value) || layer.RuleID != expectedLayer.RuleID {
+		t.Errorf("Expected the persisted layer result, received %+v", layer)
+	}
+	if _, details = restarted.CheckGateWithDetails(cold, "always_on_gate"); details.Reason != "Unrecognized" {
+		t.Errorf("Expected users outside the top N to be evaluated without a ruleset, received %+v", details)
+	}
+	if _, details = restarted.GetConfigWithDetails(hot, "sample_experiment"); details.Reason != "Unrecognized" {
+		t.Errorf("Expected specs the user was not evaluated for to be evaluated without a ruleset, received %+v", details)
+	}
+}
+
+func TestFailsafeCacheIDTypes(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	adapter := &dataAdapterExample{store: make(map[string]string)}
+	opt := &Options{LocalMode: true, DataAdapter: adapter, FailsafeCacheOptions: FailsafeCacheOptions{Size: 2}}
+	c := newFailsafeCache(&store{dataAdapter: adapter}, opt)
+	defer c.stop()
+	user := User{UserID: "user", CustomIDs: map[string]string{"companyID": "acme"}}
+	res := &evalResult{Pass: true, RuleID: "rule", IDType: "companyID", EvaluationDetails: newEvaluationDetails(reasonNetwork, 1, 1)}
+	c.resolve(user, "gate", "company_gate", res)
+	c.resolve(User{UserID: "other_user", CustomIDs: map[string]string{"companyID": "acme"}}, "gate", "company_gate", res)
+	c.resolve(User{UserID: "no_company"}, "gate", "company_gate", res)
+
+	snapshot := c.snapshot()
+	companyKey, _ := failsafeUnitKey("companyID", "acme")
+	if len(snapshot.Units) != 1 || snapshot.Units[companyKey]["gate:company_gate"].RuleID != "rule" {
+		t.Fatalf("Expected results to be recorded once per unit of the spec's ID type, received %+v", snapshot.Units)
+	}
+
+	c.loaded = snapshot.Units
+	c.loadedIDTypes = map[string]string{"gate:company_gate": "companyID"}
+	unrecognized := &evalResult{EvaluationDetails: newEvaluationDetails(reasonUnrecognized, 0, 1)}
+	if served := c.serve(User{CustomIDs: map[string]string{"companyID": "acme"}}, "gate", "company_gate", unrecognized); !served.Pass || served.RuleID != "rule" {
+		t.Errorf("Expected the result persisted for the unit to be served, received %+v", served)
+	}
+	if served := c.serve(User{UserID: "acme"}, "gate", "company_gate", unrecognized); served != unrecognized {
+		t.Errorf("Expected users without the unit ID not to be served the persisted result")
+	}
+}
@@ -0,0 +1,52 @@
+package statsig
+
+import (
+	"fmt"
+	"time"
+)
+
+// Upper bound on how long a panicking goroutine waits for queued events to be sent
+const flushOnPanicTimeout = 5 * time.Second
+
+// Sends queued events synchronously without stopping the flush ticker, so the logger keeps
+// working if the panic is recovered further up the stack. Nothing is sent while logging is paused
+func (l *logger) flushPending() {
+	if l.isLoggingPaused() {
+		return
+	}
+	l.mu.Lock()
+	events := l.events
+	l.events = make([]interface{}, 0)
+	l.mu.Unlock()
+	if len(events) > 0 {
+		l.sendEvents(events)
+	}
+	if l.queues != nil {
+		l.queues.flushPending()
+	}
+}
+
+func (c *Client) flushBeforePanic(recovered interface{}) {
+	Logger().LogError(fmt.Sprintf("Flushing events before panic: %v", recovered))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { _ = recover() }()
+		c.logger.flushPending()
+	}()
+	select {
+	case <-done:
+	case <-time.After(flushOnPanicTimeout):
+		Logger().LogError("Timed out flushing events before panic")
+	}
+}
+
+// Flushes queued events if the calling goroutine is panicking, then re-panics with the same value.
+// Must be deferred directly, e.g. `defer client.FlushOnPanic()`, as the first defer of a goroutine
+// or handler so it runs before any recover middleware further up the stack.
+func (c *Client) FlushOnPanic() {
+	if recovered := recover(); recovered != nil {
+		c.flushBeforePanic(recovered)
+		panic(recovered)
+	}
+}
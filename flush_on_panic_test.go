@@ -0,0 +1,99 @@
+package statsig
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFlushOnPanic(t *testing.T) {
+	var mu sync.Mutex
+	events := []Event{}
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			bytes, _ := os.ReadFile("download_config_specs.json")
+			_, _ = res.Write(bytes)
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		} else if strings.Contains(req.URL.Path, "log_event") {
+			input := &struct {
+				Events []Event `json:"events"`
+			}{}
+			defer req.Body.Close()
+			buf := new(bytes.Buffer)
+			_, _ = buf.ReadFrom(req.Body)
+			_ = json.Unmarshal(buf.Bytes(), &input)
+			mu.Lock()
+			events = append(events, input.Events...)
+			mu.Unlock()
+		}
+	}))
+	defer testServer.Close()
+
+	opt := &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	}
+	receivedEvents := func() []Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Event{}, events...)
+	}
+
+	InitializeWithOptions("secret-key", opt)
+	defer ShutdownAndDangerouslyClearInstance()
+
+	t.Run("flushes queued events and re-panics", func(t *testing.T) {
+		var recovered interface{}
+		func() {
+			defer func() { recovered = recover() }()
+			defer FlushOnPanic()
+			LogEvent(Event{EventName: "last_words", User: User{UserID: "123"}})
+			panic("boom")
+		}()
+		if recovered != "boom" {
+			t.Errorf("Expected the original panic value to be re-raised, received %v", recovered)
+		}
+		received := receivedEvents()
+		if len(received) != 1 || received[0].EventName != "last_words" {
+			t.Errorf("Expected the queued event to be flushed, received %+v", received)
+		}
+	})
+
+	t.Run("does nothing without a panic", func(t *testing.T) {
+		before := len(receivedEvents())
+		func() {
+			defer instance.FlushOnPanic()
+			LogEvent(Event{EventName: "not_flushed", User: User{UserID: "123"}})
+		}()
+		if len(receivedEvents()) != before {
+			t.Errorf("Expected events to stay queued")
+		}
+	})
+
+	t.Run("keeps logging after a recovered panic", func(t *testing.T) {
+		func() {
+			defer func() { _ = recover() }()
+			defer instance.FlushOnPanic()
+			panic("recovered upstream")
+		}()
+		LogEvent(Event{EventName: "after_panic", User: User{UserID: "123"}})
+		instance.logger.flushPending()
+		found := false
+		for _, event := range receivedEvents() {
+			if event.EventName == "after_panic" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected events logged after the recovered panic to be sent")
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package statsig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlush(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var logged, failLogging int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			_, _ = res.Write(specs)
+		case strings.Contains(req.URL.Path, "log_event"):
+			if atomic.LoadInt32(&failLogging) == 1 {
+				res.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var input logEventInput
+			_ = json.NewDecoder(req.Body).Decode(&input)
+			atomic.AddInt32(&logged, int32(len(input.Events)))
+			_, _ = res.Write([]byte("{}"))
+		default:
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+	newFlushClient := func(partitioned bool) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			LoggingInterval:      time.Hour,
+			LoggingMaxBufferSize: 1000,
+			EventQueueOptions: EventQueueOptions{
+				Partitioned: partitioned,
+				Custom:      EventQueuePolicy{MaxPendingEvents: 100},
+			},
+		})
+	}
+
+	for _, partitioned := range []bool{false, true} {
+		atomic.StoreInt32(&logged, 0)
+		atomic.StoreInt32(&failLogging, 0)
+		c := newFlushClient(partitioned)
+		c.LogEvent(Event{EventName: "a", User: User{UserID: "123"}})
+		c.CheckGate(User{UserID: "123"}, "always_on_gate")
+		if err := c.Flush(context.Background()); err != nil {
+			t.Errorf("Expected the flush to succeed, received %v", err)
+		}
+		if atomic.LoadInt32(&logged) < 2 {
+			t.Errorf("Expected the buffered events to be sent by Flush, partitioned %v, received %d", partitioned, logged)
+		}
+
+		atomic.StoreInt32(&failLogging, 1)
+		c.LogEvent(Event{EventName: "b", User: User{UserID: "123"}})
+		if err := c.Flush(context.Background()); err == nil {
+			t.Errorf("Expected an error when events fail to send, partitioned %v", partitioned)
+		}
+		c.LogEvent(Event{EventName: "c", User: User{UserID: "123"}})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := c.Flush(ctx); err == nil {
+			t.Errorf("Expected an error when ctx is done, partitioned %v", partitioned)
+		}
+		if err := c.Flush(context.Background()); partitioned == (err == nil) {
+			t.Errorf("Expected failed events to be retried only with partitioned queues, received %v", err)
+		}
+		atomic.StoreInt32(&failLogging, 0)
+		c.Shutdown()
+	}
+}
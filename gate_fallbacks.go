@@ -0,0 +1,39 @@
+package statsig
+
+import (
+	"fmt"
+)
+
+// Replaces the result for a gate that is not in the ruleset, including when no ruleset has been
+// loaded yet, with the gate's fallback from Options.GateFallbacks. A fallback that panics fails closed.
+func (c *Client) applyGateFallback(user User, gate string, res *evalResult) *evalResult {
+	fallback, ok := c.options.GateFallbacks[gate]
+	if !ok || fallback == nil || res.EvaluationDetails == nil || res.EvaluationDetails.reason != reasonUnrecognized {
+		return res
+	}
+	reason := reasonUnrecognized
+	if c.evaluator.store.getInitReason() == reasonUninitialized {
+		reason = reasonUninitialized
+	}
+	pass := func() (pass bool) {
+		defer func() {
+			if err := recover(); err != nil {
+				Logger().LogError(fmt.Sprintf("Fallback for gate %s panicked: %s", gate, toError(err).Error()))
+				pass = false
+			}
+		}()
+		return fallback(user)
+	}()
+	return &evalResult{
+		Pass:               pass,
+		RuleID:             RuleIDFallback,
+		EvaluationDetails:  c.evaluator.createEvaluationDetails(reason),
+		SecondaryExposures: make([]map[string]string, 0),
+	}
+}
+
+func (s *store) getInitReason() evaluationReason {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.initReason
+}
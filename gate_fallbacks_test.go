@@ -0,0 +1,79 @@
+package statsig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGateFallbacks(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var fallbackUsers []User
+	fallbacks := map[string]func(user User) bool{
+		"legacy_region_gate": func(user User) bool {
+			fallbackUsers = append(fallbackUsers, user)
+			return user.Country == "NZ"
+		},
+		"always_on_gate": func(user User) bool { return false },
+		"panicking_gate": func(user User) bool { panic("legacy system unavailable") },
+	}
+	newFallbackClient := func(bootstrapValues string) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      bootstrapValues,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			GateFallbacks:        fallbacks,
+		})
+	}
+
+	t.Run("falls back for gates missing from the ruleset", func(t *testing.T) {
+		fallbackUsers = nil
+		c := newFallbackClient(string(specs))
+		defer c.Shutdown()
+		gate := c.GetGate(User{UserID: "123", Country: "NZ"}, "legacy_region_gate")
+		if !gate.Value || gate.RuleID != RuleIDFallback {
+			t.Errorf("Expected the fallback result, received %+v", gate)
+		}
+		if c.CheckGate(User{UserID: "123", Country: "US"}, "legacy_region_gate") {
+			t.Errorf("Expected the fallback to be evaluated per user")
+		}
+		if len(fallbackUsers) != 2 || fallbackUsers[0].UserID != "123" {
+			t.Errorf("Expected the fallback to receive the user, received %+v", fallbackUsers)
+		}
+		exposure := c.logger.events[0].(ExposureEvent)
+		if exposure.Metadata["gateValue"] != "true" || exposure.Metadata["ruleID"] != RuleIDFallback || exposure.Metadata["reason"] != "Unrecognized" {
+			t.Errorf("Expected the exposure to record the fallback, received %+v", exposure.Metadata)
+		}
+	})
+
+	t.Run("ignores fallbacks for gates in the ruleset", func(t *testing.T) {
+		c := newFallbackClient(string(specs))
+		defer c.Shutdown()
+		if gate := c.GetGate(User{UserID: "123"}, "always_on_gate"); !gate.Value || gate.RuleID == RuleIDFallback {
+			t.Errorf("Expected the ruleset to be used, received %+v", gate)
+		}
+	})
+
+	t.Run("falls back while uninitialized", func(t *testing.T) {
+		c := newFallbackClient("")
+		defer c.Shutdown()
+		if !c.CheckGate(User{UserID: "123", Country: "NZ"}, "legacy_region_gate") {
+			t.Errorf("Expected the fallback result")
+		}
+		if gate := c.GetGate(User{UserID: "123"}, "always_on_gate"); gate.Value || gate.RuleID != RuleIDFallback {
+			t.Errorf("Expected the fallback for a gate missing before the first sync, received %+v", gate)
+		}
+		exposure := c.logger.events[0].(ExposureEvent)
+		if exposure.Metadata["reason"] != "Uninitialized" {
+			t.Errorf("Expected the exposure reason to be Uninitialized, received %+v", exposure.Metadata)
+		}
+	})
+
+	t.Run("fails closed when a fallback panics", func(t *testing.T) {
+		c := newFallbackClient(string(specs))
+		defer c.Shutdown()
+		if gate := c.GetGate(User{UserID: "123"}, "panicking_gate"); gate.Value || gate.RuleID != RuleIDFallback {
+			t.Errorf("Expected a false fallback result, received %+v", gate)
+		}
+	})
+}
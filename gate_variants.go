@@ -0,0 +1,33 @@
+package statsig
+
+// One of the string variants a gate's rule assigns to the users passing it, in proportion to its weight
+type gateVariant struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// Assigns a variant of the rule by hashing the user's unit ID with the rule's salt, so the assignment is
+// stable across evaluations. The spec's default variant is returned for rules without variants.
+func (e *evaluator) evalVariant(user User, rule configRule, spec configSpec) string {
+	total := 0.0
+	for _, variant := range rule.Variants {
+		if variant.Weight > 0 {
+			total += variant.Weight
+		}
+	}
+	if total <= 0 {
+		return spec.DefaultVariant
+	}
+	hash := getHashUint64Encoding(e.getRuleHashSalt(rule, spec) + ".variant." + getUnitID(user, rule.IDType))
+	bucket := float64(hash%10000) / 10000 * total
+	for _, variant := range rule.Variants {
+		if variant.Weight <= 0 {
+			continue
+		}
+		if bucket < variant.Weight {
+			return variant.Name
+		}
+		bucket -= variant.Weight
+	}
+	return rule.Variants[len(rule.Variants)-1].Name
+}
@@ -0,0 +1,99 @@
+package statsig
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+const gateVariantSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [{
+		"name": "checkout_color", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "defaultVariant": "control",
+		"rules": [{
+			"name": "employees", "id": "employees_rule", "salt": "e", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "user_field", "field": "email", "operator": "str_contains_any", "targetValue": ["@statsig.com"]}],
+			"variants": [{"name": "gold", "weight": 1}]
+		}, {
+			"name": "everyone", "id": "everyone_rule", "salt": "v", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "user_field", "field": "country", "operator": "any", "targetValue": ["US"]}],
+			"variants": [{"name": "red", "weight": 50}, {"name": "green", "weight": 30}, {"name": "blue", "weight": 20}, {"name": "unused", "weight": 0}]
+		}]
+	}, {
+		"name": "plain_gate", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "r", "id": "plain_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "public"}]
+		}]
+	}],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+func TestGateVariants(t *testing.T) {
+	newVariantClient := func() *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      gateVariantSpecs,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+
+	t.Run("assigns variants in proportion to their weights", func(t *testing.T) {
+		c := newVariantClient()
+		defer c.Shutdown()
+		counts := map[string]int{}
+		users := 10000
+		for i := 0; i < users; i++ {
+			user := User{UserID: fmt.Sprintf("user_%d", i), Country: "US"}
+			variant := c.GetVariant(user, "checkout_color")
+			if c.GetVariant(user, "checkout_color") != variant {
+				t.Fatalf("Expected the variant to be stable for %s", user.UserID)
+			}
+			counts[variant]++
+		}
+		expected := map[string]float64{"red": 0.5, "green": 0.3, "blue": 0.2}
+		for variant, share := range expected {
+			if math.Abs(float64(counts[variant])/float64(users)-share) > 0.03 {
+				t.Errorf("Expected about %.0f%% %s, received %d of %d", share*100, variant, counts[variant], users)
+			}
+		}
+		if counts["unused"] != 0 || len(counts) != 3 {
+			t.Errorf("Expected only weighted variants to be assigned, received %v", counts)
+		}
+	})
+
+	t.Run("uses the first passing rule and the default variant", func(t *testing.T) {
+		c := newVariantClient()
+		defer c.Shutdown()
+		if variant := c.GetVariant(User{UserID: "123", Email: "a@statsig.com", Country: "US"}, "checkout_color"); variant != "gold" {
+			t.Errorf("Expected the first passing rule's variant, received %s", variant)
+		}
+		gate := c.GetGate(User{UserID: "123", Country: "NZ"}, "checkout_color")
+		if gate.Value || gate.Variant != "control" {
+			t.Errorf("Expected the default variant for users failing the gate, received %+v", gate)
+		}
+		if variant := c.GetVariant(User{UserID: "123"}, "plain_gate"); variant != "" {
+			t.Errorf("Expected no variant for gates without variants, received %s", variant)
+		}
+		if variant := c.GetVariant(User{UserID: "123"}, "missing_gate"); variant != "" {
+			t.Errorf("Expected no variant for unrecognized gates, received %s", variant)
+		}
+	})
+
+	t.Run("logs the variant with the exposure", func(t *testing.T) {
+		c := newVariantClient()
+		defer c.Shutdown()
+		c.GetVariant(User{UserID: "123", Email: "a@statsig.com"}, "checkout_color")
+		c.CheckGate(User{UserID: "123"}, "plain_gate")
+		exposure := c.logger.events[0].(ExposureEvent)
+		if exposure.Metadata["variant"] != "gold" || exposure.Metadata["ruleID"] != "employees_rule" {
+			t.Errorf("Expected the exposure to record the variant, received %+v", exposure.Metadata)
+		}
+		if _, ok := c.logger.events[1].(ExposureEvent).Metadata["variant"]; ok {
+			t.Errorf("Expected no variant in exposures of gates without variants")
+		}
+	})
+}
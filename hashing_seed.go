@@ -0,0 +1,30 @@
+package statsig
+
+// Salts prefixed to unit IDs when bucketing. Options.TestHashingSeed replaces the console-generated
+// spec, rule and user_bucket salts with ones derived from the seed and spec and rule names, which
+// are the same in every environment.
+func (e *evaluator) getRuleHashSalt(rule configRule, spec configSpec) string {
+	if seed := e.getTestHashingSeed(); seed != "" {
+		return seed + "." + spec.Name + "." + rule.Name
+	}
+	ruleSalt := rule.Salt
+	if ruleSalt == "" {
+		ruleSalt = rule.ID
+	}
+	return spec.Salt + "." + ruleSalt
+}
+
+// Seeded salts are suffixed so a rule's user_bucket conditions do not follow its pass percentage
+func (e *evaluator) getUserBucketHashSalt(compiled *compiledCondition) string {
+	if seed := e.getTestHashingSeed(); seed != "" {
+		return seed + "." + compiled.specName + "." + compiled.ruleName + ".user_bucket"
+	}
+	return compiled.userBucketSalt
+}
+
+func (e *evaluator) getTestHashingSeed() string {
+	if e.options == nil {
+		return ""
+	}
+	return e.options.TestHashingSeed
+}
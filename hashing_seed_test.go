@@ -0,0 +1,105 @@
+package statsig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// The same gates as exported from two environments, which differ only in their generated salts
+const hashingSeedSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [{
+		"name": "half_rollout", "type": "feature_gate", "salt": "SPEC_SALT", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "rollout", "id": "RULE_ID", "salt": "RULE_SALT", "passPercentage": 50, "returnValue": true,
+			"conditions": [{"type": "public"}]
+		}]
+	}, {
+		"name": "bucketed", "type": "feature_gate", "salt": "SPEC_SALT", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "low_buckets", "id": "RULE_ID", "salt": "RULE_SALT", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "user_bucket", "operator": "lt", "targetValue": 500, "additionalValues": {"salt": "BUCKET_SALT"}}]
+		}]
+	}, {
+		"name": "other_bucketed", "type": "feature_gate", "salt": "SPEC_SALT", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "low_buckets", "id": "RULE_ID", "salt": "RULE_SALT", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "user_bucket", "operator": "lt", "targetValue": 500, "additionalValues": {"salt": "BUCKET_SALT"}}]
+		}]
+	}],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+func TestTestHashingSeed(t *testing.T) {
+	newEnvironmentClient := func(environment string, seed string) *Client {
+		specs := strings.NewReplacer(
+			"SPEC_SALT", environment+"_spec_salt",
+			"RULE_SALT", environment+"_rule_salt",
+			"RULE_ID", environment+"_rule_id",
+			"BUCKET_SALT", environment+"_bucket_salt",
+		).Replace(hashingSeedSpecs)
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      specs,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			TestHashingSeed:      seed,
+		})
+	}
+	assignments := func(c *Client, gate string) (string, int) {
+		var b strings.Builder
+		passed := 0
+		for i := 0; i < 200; i++ {
+			pass := c.CheckGateWithExposureLoggingDisabled(User{UserID: fmt.Sprint(i)}, gate)
+			if pass {
+				passed++
+			}
+			fmt.Fprintf(&b, "%t,", pass)
+		}
+		return b.String(), passed
+	}
+
+	for _, gate := range []string{"half_rollout", "bucketed"} {
+		t.Run(gate, func(t *testing.T) {
+			staging, production := newEnvironmentClient("staging", ""), newEnvironmentClient("production", "")
+			defer staging.Shutdown()
+			defer production.Shutdown()
+			stagingAssignments, _ := assignments(staging, gate)
+			productionAssignments, _ := assignments(production, gate)
+			if stagingAssignments == productionAssignments {
+				t.Errorf("Expected different salts to assign users differently")
+			}
+
+			seededStaging, seededProduction := newEnvironmentClient("staging", "seed"), newEnvironmentClient("production", "seed")
+			defer seededStaging.Shutdown()
+			defer seededProduction.Shutdown()
+			seededStagingAssignments, passed := assignments(seededStaging, gate)
+			seededProductionAssignments, _ := assignments(seededProduction, gate)
+			if seededStagingAssignments != seededProductionAssignments {
+				t.Errorf("Expected a seed to assign users the same way in every environment")
+			}
+			if passed < 60 || passed > 140 {
+				t.Errorf("Expected roughly half of users to pass, received %d of 200", passed)
+			}
+
+			otherSeed := newEnvironmentClient("staging", "other_seed")
+			defer otherSeed.Shutdown()
+			if otherSeedAssignments, _ := assignments(otherSeed, gate); otherSeedAssignments == seededStagingAssignments {
+				t.Errorf("Expected different seeds to assign users differently")
+			}
+		})
+	}
+
+	t.Run("seeds user_bucket conditions per spec", func(t *testing.T) {
+		seeded := newEnvironmentClient("staging", "seed")
+		defer seeded.Shutdown()
+		bucketed, _ := assignments(seeded, "bucketed")
+		otherBucketed, _ := assignments(seeded, "other_bucketed")
+		if bucketed == otherBucketed {
+			t.Errorf("Expected user_bucket conditions of different specs to bucket users differently")
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package statsig
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultBloomFilterFalsePositiveRate = 0.001
+	minBloomFilterEntries               = 1000
+)
+
+// A bloom filter of the IDs in a list, using about 1.8 bytes per ID at the default false positive rate.
+// Bloom filters do not support removals, so the filter is rebuilt from the whole list on every sync that
+// changes it. Lookups can return false positives but never false negatives.
+type bloomFilter struct {
+	falsePositiveRate float64
+	bits              []uint64
+	numBits           uint64
+	numHashes         uint64
+	count             int64
+	mu                sync.RWMutex
+}
+
+func newBloomFilter(falsePositiveRate float64) *bloomFilter {
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = defaultBloomFilterFalsePositiveRate
+	}
+	b := &bloomFilter{falsePositiveRate: falsePositiveRate}
+	b.bits, b.numBits, b.numHashes = b.allocate(0)
+	return b
+}
+
+// Sized for the expected number of entries at the configured false positive rate
+func (b *bloomFilter) allocate(expectedEntries int) ([]uint64, uint64, uint64) {
+	if expectedEntries < minBloomFilterEntries {
+		expectedEntries = minBloomFilterEntries
+	}
+	n := float64(expectedEntries)
+	m := math.Ceil(-n * math.Log(b.falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/n*math.Ln2))
+	numBits := uint64(m)
+	return make([]uint64, (numBits+63)/64), numBits, uint64(k)
+}
+
+func bloomFilterIndexes(id string, numBits uint64, numHashes uint64, visit func(i uint64) bool) bool {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(id))
+	sum := hasher.Sum64()
+	h1, h2 := sum&0xffffffff, sum>>32
+	for i := uint64(0); i < numHashes; i++ {
+		if !visit((h1 + i*h2) % numBits) {
+			return false
+		}
+	}
+	return true
+}
+
+func setBloomFilterBits(bits []uint64, numBits uint64, numHashes uint64, id string) {
+	bloomFilterIndexes(id, numBits, numHashes, func(i uint64) bool {
+		bits[i/64] |= 1 << (i % 64)
+		return true
+	})
+}
+
+// Replaces the filter with one built from the full contents of an ID list, applying
+// its additions and removals in order. Only IDs that were ever removed are tracked individually.
+func (b *bloomFilter) rebuild(content string) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	removed := make(map[string]bool)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if len(line) > 1 && line[0] == '-' {
+			removed[line[1:]] = false
+		}
+	}
+	ids := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if len(line) <= 1 {
+			continue
+		}
+		id := line[1:]
+		if _, ok := removed[id]; ok {
+			removed[id] = line[0] == '+'
+		} else if line[0] == '+' {
+			ids = append(ids, id)
+		}
+	}
+	for id, present := range removed {
+		if present {
+			ids = append(ids, id)
+		}
+	}
+	// IDs can be added more than once, which would inflate the count the filter is sized by
+	sort.Strings(ids)
+	unique := ids[:0]
+	for i, id := range ids {
+		if i == 0 || id != ids[i-1] {
+			unique = append(unique, id)
+		}
+	}
+	ids = unique
+
+	bits, numBits, numHashes := b.allocate(len(ids))
+	for _, id := range ids {
+		setBloomFilterBits(bits, numBits, numHashes, id)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bits, b.numBits, b.numHashes, b.count = bits, numBits, numHashes, int64(len(ids))
+}
+
+func (b *bloomFilter) contains(id string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return bloomFilterIndexes(id, b.numBits, b.numHashes, func(i uint64) bool {
+		return b.bits[i/64]&(1<<(i%64)) != 0
+	})
+}
+
+func (b *bloomFilter) entryCount() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.count
+}
+
+// Estimates the false positive rate from the number of entries the filter was built with
+func (b *bloomFilter) estimatedFalsePositiveRate() float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	k := float64(b.numHashes)
+	m := float64(b.numBits)
+	return math.Pow(1-math.Exp(-k*float64(b.count)/m), k)
+}
@@ -0,0 +1,159 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBloomFilter(t *testing.T) {
+	filter := newBloomFilter(0.01)
+	var content strings.Builder
+	for i := 0; i < 10000; i++ {
+		content.WriteString(fmt.Sprintf("+id_%d\n", i))
+	}
+	filter.rebuild(content.String())
+	for i := 0; i < 10000; i++ {
+		if !filter.contains(fmt.Sprintf("id_%d", i)) {
+			t.Fatalf("Expected no false negatives")
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if filter.contains(fmt.Sprintf("other_%d", i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 200 {
+		t.Errorf("Expected roughly 1%% false positives, received %d in 10000", falsePositives)
+	}
+	estimate := filter.estimatedFalsePositiveRate()
+	if estimate < 0.005 || estimate > 0.02 {
+		t.Errorf("Expected estimated false positive rate near 0.01, received %f", estimate)
+	}
+	if bytesPerEntry := float64(len(filter.bits)*8) / 10000; bytesPerEntry > 1.5 {
+		t.Errorf("Expected about 1.2 bytes per entry at a 1%% false positive rate, received %f", bytesPerEntry)
+	}
+
+	// Removing an ID that is only a false positive must not affect other IDs
+	content.WriteString("-id_1\n+id_2\n-id_3\n+id_3\n-never_added\n")
+	filter.rebuild(content.String())
+	if filter.entryCount() != 9999 {
+		t.Errorf("Expected 9999 entries, received %d", filter.entryCount())
+	}
+	for i := 0; i < 10000; i++ {
+		if i != 1 && !filter.contains(fmt.Sprintf("id_%d", i)) {
+			t.Fatalf("Expected no false negatives after removals")
+		}
+	}
+}
+
+func TestIDListBloomFilterMode(t *testing.T) {
+	var ranges sync.Map
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "get_id_lists") {
+			baseURL := "http://" + req.Host
+			r := map[string]idList{
+				"bloom_list": {Name: "bloom_list", Size: 20, URL: baseURL + "/bloom_list", CreationTime: 1, FileID: "file_1"},
+				"exact_list": {Name: "exact_list", Size: 20, URL: baseURL + "/exact_list", CreationTime: 1, FileID: "file_2"},
+			}
+			v, _ := json.Marshal(r)
+			_, _ = res.Write(v)
+		} else if strings.Contains(req.URL.Path, "_list") {
+			ranges.Store(req.URL.Path, req.Header.Get("Range"))
+			_, _ = res.Write([]byte("+a\n+b\n-a\n"))
+		}
+	}))
+	defer testServer.Close()
+	opt := &Options{
+		API: testServer.URL,
+		IDListBloomFilterOptions: IDListBloomFilterOptions{
+			Lists:             []string{"bloom_list"},
+			FalsePositiveRate: 0.0001,
+		},
+	}
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	n := newTransport("secret-123", opt)
+	d := newDiagnostics(opt)
+	e := newErrorBoundary("client-key", opt, d)
+	s := newStoreInternal(n, time.Minute, time.Minute, "", nil, e, nil, d, "secret-123", opt)
+	defer s.stopPolling()
+
+	bloomList := s.getIDList("bloom_list")
+	if bloomList == nil || bloomList.bloom == nil {
+		t.Fatalf("Expected bloom_list to be stored as a bloom filter")
+	}
+	if !bloomList.contains("b") || bloomList.contains("a") {
+		t.Errorf("Expected bloom_list to contain only b")
+	}
+	exactList := s.getIDList("exact_list")
+	if exactList == nil || exactList.bloom != nil {
+		t.Fatalf("Expected exact_list to be stored as a set")
+	}
+	if !exactList.contains("b") || exactList.contains("a") {
+		t.Errorf("Expected exact_list to contain only b")
+	}
+
+	s.fetchIDListsFromServer()
+	if bloomRange, _ := ranges.Load("/bloom_list"); bloomRange != "bytes=0-" {
+		t.Errorf("Expected bloom_list to be downloaded in full to rebuild the filter, received %v", bloomRange)
+	}
+	if exactRange, _ := ranges.Load("/exact_list"); exactRange != "bytes=9-" {
+		t.Errorf("Expected exact_list to be downloaded incrementally, received %v", exactRange)
+	}
+	if !bloomList.contains("b") || bloomList.contains("a") {
+		t.Errorf("Expected the rebuilt bloom_list to contain only b")
+	}
+}
+
+type idListReaderAdapter struct {
+	*dataAdapterExample
+}
+
+func (d idListReaderAdapter) ShouldBeUsedForQueryingUpdates(key string) bool {
+	return key == ID_LISTS_KEY
+}
+
+func TestIDListBloomFilterAdapterReader(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "get_id_lists") {
+			r := map[string]idList{
+				"bloom_list": {Name: "bloom_list", Size: 9, URL: "http://" + req.Host + "/bloom_list", CreationTime: 1, FileID: "file_1"},
+			}
+			v, _ := json.Marshal(r)
+			_, _ = res.Write(v)
+		} else if strings.Contains(req.URL.Path, "bloom_list") {
+			_, _ = res.Write([]byte("+a\n+b\n-a\n"))
+		}
+	}))
+	defer testServer.Close()
+	adapter := &dataAdapterExample{store: make(map[string]string)}
+	newStore := func(dataAdapter IDataAdapter, opt *Options) *store {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		n := newTransport("secret-123", opt)
+		d := newDiagnostics(opt)
+		e := newErrorBoundary("client-key", opt, d)
+		return newStoreInternal(n, time.Minute, time.Minute, "", nil, e, dataAdapter, d, "secret-123", opt)
+	}
+	writer := newStore(adapter, &Options{
+		API:                      testServer.URL,
+		IDListBloomFilterOptions: IDListBloomFilterOptions{Lists: []string{"bloom_list"}},
+	})
+	defer writer.stopPolling()
+	writer.fetchIDListsFromServer()
+
+	reader := newStore(idListReaderAdapter{adapter}, &Options{API: testServer.URL})
+	defer reader.stopPolling()
+	list := reader.getIDList("bloom_list")
+	if list == nil || !list.contains("b") || list.contains("a") {
+		t.Errorf("Expected a client reading ID lists from the adapter to receive the bloom filter list's IDs")
+	}
+}
@@ -0,0 +1,41 @@
+package statsig
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// How an ID list manifest with an older CreationTime than the loaded list is handled,
+// e.g. after the list was rebuilt on a server with a skewed clock
+type IDListSkewPolicy int
+
+const (
+	IDListIgnoreSkewed      IDListSkewPolicy = iota // The manifest entry is ignored until its CreationTime catches up
+	IDListResetOnFileChange                         // The list is reset and downloaded again whenever its FileID changes, regardless of CreationTime
+)
+
+// Counts the skew on the loaded list, reported in IDListStats.SkewCount, and logs it once per file.
+// Only the last skewed file of each list is remembered, so rebuilt lists do not accumulate entries
+func (s *store) recordIDListSkew(localList *idList, serverList idList) {
+	atomic.AddInt64(&localList.skewCount, 1)
+	if logged, ok := s.idListSkewWarnings.Load(localList.Name); ok && logged == serverList.FileID {
+		return
+	}
+	s.idListSkewWarnings.Store(localList.Name, serverList.FileID)
+	message := fmt.Sprintf("ID list %s file %s was created at %d, before the loaded file %s created at %d",
+		localList.Name, serverList.FileID, serverList.CreationTime, localList.FileID, localList.CreationTime)
+	if s.options.IDListSkewPolicy == IDListResetOnFileChange && serverList.FileID != localList.FileID {
+		Logger().Log(message+", resetting the list\n", nil)
+	} else {
+		Logger().LogError(message + ". Set Options.IDListSkewPolicy to IDListResetOnFileChange to reset lists regardless of CreationTime\n")
+	}
+}
+
+// Whether the manifest entry should be ignored because it is older than the loaded list
+func (s *store) ignoreSkewedIDList(localList *idList, serverList idList) bool {
+	if serverList.CreationTime >= localList.CreationTime {
+		return false
+	}
+	s.recordIDListSkew(localList, serverList)
+	return s.options.IDListSkewPolicy != IDListResetOnFileChange || serverList.FileID == localList.FileID
+}
@@ -0,0 +1,99 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestIDListClockSkew(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var mu sync.Mutex
+	manifest := idList{Name: "list", CreationTime: 10, FileID: "file_1"}
+	content := map[string]string{"file_1": "+1\n", "file_2": "+2\n"}
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			_, _ = res.Write(specs)
+		case strings.Contains(req.URL.Path, "get_id_lists"):
+			list := manifest
+			list.URL = "http://" + req.Host + "/" + list.FileID
+			list.Size = int64(len(content[list.FileID]))
+			bytes, _ := json.Marshal(map[string]idList{"list": list})
+			_, _ = res.Write(bytes)
+		default:
+			_, _ = res.Write([]byte(content[strings.TrimPrefix(req.URL.Path, "/")]))
+		}
+	}))
+	defer testServer.Close()
+	rebuild := func(c *Client) *idList {
+		mu.Lock()
+		manifest = idList{Name: "list", CreationTime: 5, FileID: "file_2"}
+		mu.Unlock()
+		c.evaluator.store.fetchIDListsFromServer()
+		return c.evaluator.store.getIDList("list")
+	}
+	newClient := func(policy IDListSkewPolicy) *Client {
+		mu.Lock()
+		manifest = idList{Name: "list", CreationTime: 10, FileID: "file_1"}
+		mu.Unlock()
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			IDListSkewPolicy:     policy,
+		})
+	}
+
+	t.Run("ignores older manifests by default", func(t *testing.T) {
+		c := newClient(IDListIgnoreSkewed)
+		defer c.Shutdown()
+		list := rebuild(c)
+		if list.FileID != "file_1" || !list.contains("1") {
+			t.Errorf("Expected the loaded file to be kept, received %+v", list.stats())
+		}
+		if stats := c.GetSDKStats(); stats.IDListSkewCount != 1 || stats.IDLists[0].SkewCount != 1 {
+			t.Errorf("Expected the skew to be counted, received %+v", stats.IDLists)
+		}
+	})
+
+	t.Run("resets on a FileID change regardless of CreationTime", func(t *testing.T) {
+		c := newClient(IDListResetOnFileChange)
+		defer c.Shutdown()
+		list := rebuild(c)
+		if list.FileID != "file_2" || list.CreationTime != 5 || !list.contains("2") || list.contains("1") {
+			t.Errorf("Expected the list to be reset to the new file, received %+v", list.stats())
+		}
+		if list.stats().SkewCount != 1 {
+			t.Errorf("Expected the skew count to carry over, received %+v", list.stats())
+		}
+		c.evaluator.store.fetchIDListsFromServer()
+		if list := c.evaluator.store.getIDList("list"); list.FileID != "file_2" || list.stats().SkewCount != 1 {
+			t.Errorf("Expected no further skew once reset, received %+v", list.stats())
+		}
+	})
+
+	t.Run("remembers only the last skewed file of each list", func(t *testing.T) {
+		c := newClient(IDListIgnoreSkewed)
+		defer c.Shutdown()
+		rebuild(c)
+		mu.Lock()
+		manifest = idList{Name: "list", CreationTime: 4, FileID: "file_3"}
+		mu.Unlock()
+		c.evaluator.store.fetchIDListsFromServer()
+		entries := map[interface{}]interface{}{}
+		c.evaluator.store.idListSkewWarnings.Range(func(key, value interface{}) bool {
+			entries[key] = value
+			return true
+		})
+		if len(entries) != 1 || entries["list"] != "file_3" {
+			t.Errorf("Expected one entry for the list's last skewed file, received %v", entries)
+		}
+	})
+}
@@ -0,0 +1,571 @@
+package statsig
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultIDListFileCompactionThreshold = 1000000
+	idListFileIndexEntrySize             = 8
+	// IDs sorted in memory at a time while building a list's first files from its download
+	defaultIDListFileSortRunSize = 1000000
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// An ID list kept in a memory-mapped file of sorted, newline separated IDs, with an index file of
+// the offset of each ID for binary search. The first files are built from the list's full download
+// with an external sort. Changes from the list's incremental updates are held in memory until there
+// are enough of them to compact into a new pair of files in the background.
+type idListFile struct {
+	path                string // Without extension, files are named path.<generation>.ids and path.<generation>.idx
+	generation          int
+	data                []byte
+	index               []byte
+	unmapData           func() error
+	unmapIndex          func() error
+	added               map[string]bool
+	removed             map[string]bool
+	compactionThreshold int
+	sortRunSize         int
+	closed              bool
+	compacting          int32
+	compactions         sync.WaitGroup // Waited on before the mapped files are closed
+	mu                  sync.RWMutex
+}
+
+func newIDListFile(options IDListFileOptions, name string, fileID string) *idListFile {
+	threshold := options.CompactionThreshold
+	if threshold <= 0 {
+		threshold = defaultIDListFileCompactionThreshold
+	}
+	base := unsafeFileNameChars.ReplaceAllString(name, "_") + "-" + unsafeFileNameChars.ReplaceAllString(fileID, "_")
+	return &idListFile{
+		path:                filepath.Join(options.Directory, base),
+		added:               make(map[string]bool),
+		removed:             make(map[string]bool),
+		compactionThreshold: threshold,
+		sortRunSize:         defaultIDListFileSortRunSize,
+	}
+}
+
+func (f *idListFile) count() int {
+	return len(f.index) / idListFileIndexEntrySize
+}
+
+func (f *idListFile) at(i int) []byte {
+	start := binary.LittleEndian.Uint64(f.index[i*idListFileIndexEntrySize:])
+	end := uint64(len(f.data))
+	if i+1 < f.count() {
+		end = binary.LittleEndian.Uint64(f.index[(i+1)*idListFileIndexEntrySize:])
+	}
+	// Strip the newline terminating each ID
+	return f.data[start : end-1]
+}
+
+func (f *idListFile) mappedContains(id string) bool {
+	target := []byte(id)
+	n := f.count()
+	i := sort.Search(n, func(i int) bool { return bytes.Compare(f.at(i), target) >= 0 })
+	return i < n && bytes.Equal(f.at(i), target)
+}
+
+func (f *idListFile) contains(id string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed || f.removed[id] {
+		return false
+	}
+	return f.added[id] || f.mappedContains(id)
+}
+
+func (f *idListFile) add(id string) {
+	f.mu.Lock()
+	delete(f.removed, id)
+	f.added[id] = true
+	f.compactIfNeeded()
+	f.mu.Unlock()
+}
+
+func (f *idListFile) remove(id string) {
+	f.mu.Lock()
+	delete(f.added, id)
+	f.removed[id] = true
+	f.compactIfNeeded()
+	f.mu.Unlock()
+}
+
+// Called with f.mu held. Starts a compaction in the background, so the callers
+// adding and removing IDs never wait on the disk
+func (f *idListFile) compactIfNeeded() {
+	if f.closed || len(f.added)+len(f.removed) < f.compactionThreshold || !atomic.CompareAndSwapInt32(&f.compacting, 0, 1) {
+		return
+	}
+	f.compactions.Add(1)
+	go func() {
+		defer f.compactions.Done()
+		defer atomic.StoreInt32(&f.compacting, 0)
+		f.compact()
+	}()
+}
+
+// Number of IDs in the list, counting those not yet compacted
+func (f *idListFile) entryCount() int64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	count := int64(f.count())
+	for id := range f.added {
+		if !f.mappedContains(id) {
+			count++
+		}
+	}
+	for id := range f.removed {
+		if f.mappedContains(id) {
+			count--
+		}
+	}
+	return count
+}
+
+func (f *idListFile) fileNames(generation int) (string, string) {
+	return fmt.Sprintf("%s.%d.ids", f.path, generation), fmt.Sprintf("%s.%d.idx", f.path, generation)
+}
+
+// Merges the pending changes with the mapped IDs into the next generation of files, then maps them
+// in place of the current ones. Only one compaction runs at a time, and the mapped files are only
+// replaced by it or unmapped by close after it finishes, so the new files are written without f.mu.
+func (f *idListFile) compact() {
+	f.mu.RLock()
+	if f.closed {
+		f.mu.RUnlock()
+		return
+	}
+	added := make([]string, 0, len(f.added))
+	for id := range f.added {
+		if !f.mappedContains(id) {
+			added = append(added, id)
+		}
+	}
+	sort.Strings(added)
+	removed := make(map[string]bool, len(f.removed))
+	for id := range f.removed {
+		removed[id] = true
+	}
+	generation := f.generation + 1
+	f.mu.RUnlock()
+	dataPath, indexPath := f.fileNames(generation)
+	if err := f.writeMerged(dataPath, indexPath, added, removed); err != nil {
+		f.removeFiles(generation)
+		Logger().LogError(fmt.Errorf("Failed to compact ID list file %s, keeping changes in memory: %s", f.path, err.Error()))
+		return
+	}
+
+	data, index, unmapData, unmapIndex, err := f.mapGeneration(generation)
+	if err != nil {
+		Logger().LogError(fmt.Errorf("Failed to map ID list file %s, keeping changes in memory: %s", f.path, err.Error()))
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		_ = unmapData()
+		_ = unmapIndex()
+		f.removeFiles(generation)
+		return
+	}
+	f.unmap()
+	previousGeneration := f.generation
+	f.data, f.index, f.unmapData, f.unmapIndex, f.generation = data, index, unmapData, unmapIndex, generation
+	// Changes made while compacting are kept, the rest are now in the files
+	for _, id := range added {
+		delete(f.added, id)
+	}
+	for id := range f.added {
+		if f.mappedContains(id) {
+			delete(f.added, id)
+		}
+	}
+	for id := range removed {
+		if !f.added[id] {
+			delete(f.removed, id)
+		}
+	}
+	f.removeFiles(previousGeneration)
+}
+
+// Writes sorted IDs with their index entries
+type idListFileWriter struct {
+	dataFile    *os.File
+	indexFile   *os.File
+	dataWriter  *bufio.Writer
+	indexWriter *bufio.Writer
+	offset      uint64
+	entry       []byte
+}
+
+func newIDListFileWriter(dataPath string, indexPath string) (*idListFileWriter, error) {
+	dataFile, err := os.Create(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	indexFile, err := os.Create(indexPath)
+	if err != nil {
+		dataFile.Close()
+		return nil, err
+	}
+	return &idListFileWriter{
+		dataFile:    dataFile,
+		indexFile:   indexFile,
+		dataWriter:  bufio.NewWriter(dataFile),
+		indexWriter: bufio.NewWriter(indexFile),
+		entry:       make([]byte, idListFileIndexEntrySize),
+	}, nil
+}
+
+func (w *idListFileWriter) write(id []byte) {
+	binary.LittleEndian.PutUint64(w.entry, w.offset)
+	_, _ = w.indexWriter.Write(w.entry)
+	_, _ = w.dataWriter.Write(id)
+	_ = w.dataWriter.WriteByte('\n')
+	w.offset += uint64(len(id) + 1)
+}
+
+func (w *idListFileWriter) close() error {
+	err := w.dataWriter.Flush()
+	if indexErr := w.indexWriter.Flush(); err == nil {
+		err = indexErr
+	}
+	w.dataFile.Close()
+	w.indexFile.Close()
+	return err
+}
+
+// Streams the merge of the mapped IDs, minus removed, with the sorted added IDs
+func (f *idListFile) writeMerged(dataPath string, indexPath string, added []string, removed map[string]bool) error {
+	w, err := newIDListFileWriter(dataPath, indexPath)
+	if err != nil {
+		return err
+	}
+	i, n := 0, f.count()
+	for _, id := range added {
+		for ; i < n && bytes.Compare(f.at(i), []byte(id)) < 0; i++ {
+			if !removed[string(f.at(i))] {
+				w.write(f.at(i))
+			}
+		}
+		if !removed[id] {
+			w.write([]byte(id))
+		}
+	}
+	for ; i < n; i++ {
+		if !removed[string(f.at(i))] {
+			w.write(f.at(i))
+		}
+	}
+	return w.close()
+}
+
+// Maps the files of a generation in place of the current ones, deleting the files on failure
+func (f *idListFile) mapGeneration(generation int) ([]byte, []byte, func() error, func() error, error) {
+	dataPath, indexPath := f.fileNames(generation)
+	data, unmapData, err := mapIDListFile(dataPath)
+	if err != nil {
+		f.removeFiles(generation)
+		return nil, nil, nil, nil, err
+	}
+	index, unmapIndex, err := mapIDListFile(indexPath)
+	if err != nil {
+		_ = unmapData()
+		f.removeFiles(generation)
+		return nil, nil, nil, nil, err
+	}
+	return data, index, unmapData, unmapIndex, nil
+}
+
+// Applies the +id and -id lines of a download, returning the number of bytes read. The first download
+// of a list is sorted straight into files, later ones are incremental and held in memory until compacted
+func (f *idListFile) load(reader io.Reader) (int64, error) {
+	counter := &countingReader{reader: reader}
+	f.mu.RLock()
+	initial := f.generation == 0 && len(f.added) == 0 && len(f.removed) == 0
+	f.mu.RUnlock()
+	var err error
+	if initial {
+		err = f.build(counter)
+	} else {
+		err = scanIDListLines(counter, func(add bool, id string) error {
+			if add {
+				f.add(id)
+			} else {
+				f.remove(id)
+			}
+			return nil
+		})
+	}
+	return counter.count, err
+}
+
+func scanIDListLines(reader io.Reader, visit func(add bool, id string) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) <= 1 || (line[0] != '+' && line[0] != '-') {
+			continue
+		}
+		if err := visit(line[0] == '+', string(line[1:])); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+type idListOp struct {
+	id  string
+	add bool
+}
+
+// Sorts the download in runs of sortRunSize IDs, each written to a temporary file holding the last
+// operation on each of its IDs, then merges the runs into the first generation of files. Where runs
+// disagree, the later one wins, as it holds the later line of the download
+func (f *idListFile) build(reader io.Reader) error {
+	var runs []string
+	defer func() {
+		for _, run := range runs {
+			_ = os.Remove(run)
+		}
+	}()
+	ops := make([]idListOp, 0, f.sortRunSize)
+	flush := func() error {
+		if len(ops) == 0 {
+			return nil
+		}
+		run := fmt.Sprintf("%s.run%d", f.path, len(runs))
+		runs = append(runs, run)
+		err := writeIDListRun(run, ops)
+		ops = ops[:0]
+		return err
+	}
+	err := scanIDListLines(reader, func(add bool, id string) error {
+		ops = append(ops, idListOp{id: id, add: add})
+		if len(ops) >= f.sortRunSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return err
+	}
+	ops = nil
+
+	generation := 1
+	dataPath, indexPath := f.fileNames(generation)
+	if err := mergeIDListRuns(runs, dataPath, indexPath); err != nil {
+		f.removeFiles(generation)
+		return err
+	}
+	data, index, unmapData, unmapIndex, err := f.mapGeneration(generation)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		_ = unmapData()
+		_ = unmapIndex()
+		f.removeFiles(generation)
+		return nil
+	}
+	f.data, f.index, f.unmapData, f.unmapIndex, f.generation = data, index, unmapData, unmapIndex, generation
+	return nil
+}
+
+func writeIDListRun(path string, ops []idListOp) error {
+	// Stable, so the operations on each ID stay in download order and the last one is kept
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].id < ops[j].id })
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	for i, op := range ops {
+		if i+1 < len(ops) && ops[i+1].id == op.id {
+			continue
+		}
+		if op.add {
+			_ = writer.WriteByte('+')
+		} else {
+			_ = writer.WriteByte('-')
+		}
+		_, _ = writer.WriteString(op.id)
+		_ = writer.WriteByte('\n')
+	}
+	return writer.Flush()
+}
+
+type idListRunCursor struct {
+	scanner *bufio.Scanner
+	run     int
+	op      idListOp
+}
+
+type idListRunHeap []*idListRunCursor
+
+func (h idListRunHeap) Len() int { return len(h) }
+func (h idListRunHeap) Less(i, j int) bool {
+	if h[i].op.id != h[j].op.id {
+		return h[i].op.id < h[j].op.id
+	}
+	return h[i].run < h[j].run
+}
+func (h idListRunHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *idListRunHeap) Push(x interface{}) { *h = append(*h, x.(*idListRunCursor)) }
+func (h *idListRunHeap) Pop() interface{} {
+	old := *h
+	cursor := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return cursor
+}
+
+func (c *idListRunCursor) next() bool {
+	if !c.scanner.Scan() {
+		return false
+	}
+	line := c.scanner.Text()
+	c.op = idListOp{id: line[1:], add: line[0] == '+'}
+	return true
+}
+
+func mergeIDListRuns(runs []string, dataPath string, indexPath string) error {
+	h := make(idListRunHeap, 0, len(runs))
+	for i, run := range runs {
+		file, err := os.Open(run)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		cursor := &idListRunCursor{scanner: scanner, run: i}
+		if cursor.next() {
+			h = append(h, cursor)
+		} else if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+	heap.Init(&h)
+	w, err := newIDListFileWriter(dataPath, indexPath)
+	if err != nil {
+		return err
+	}
+	advance := func(cursor *idListRunCursor) error {
+		if cursor.next() {
+			heap.Push(&h, cursor)
+			return nil
+		}
+		return cursor.scanner.Err()
+	}
+	for h.Len() > 0 && err == nil {
+		// Runs are popped in order for each ID, so the last holds its final operation
+		cursor := heap.Pop(&h).(*idListRunCursor)
+		for h.Len() > 0 && h[0].op.id == cursor.op.id && err == nil {
+			err = advance(cursor)
+			cursor = heap.Pop(&h).(*idListRunCursor)
+		}
+		if cursor.op.add {
+			w.write([]byte(cursor.op.id))
+		}
+		if err == nil {
+			err = advance(cursor)
+		}
+	}
+	if closeErr := w.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (f *idListFile) unmap() {
+	if f.unmapData != nil {
+		_ = f.unmapData()
+	}
+	if f.unmapIndex != nil {
+		_ = f.unmapIndex()
+	}
+	f.data, f.index, f.unmapData, f.unmapIndex = nil, nil, nil, nil
+}
+
+func (f *idListFile) removeFiles(generation int) {
+	dataPath, indexPath := f.fileNames(generation)
+	_ = os.Remove(dataPath)
+	_ = os.Remove(indexPath)
+}
+
+// Unmaps and deletes the files, after which the list contains no IDs
+func (f *idListFile) close() {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return
+	}
+	f.closed = true
+	f.mu.Unlock()
+	// A running compaction still reads the mapped files
+	f.compactions.Wait()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unmap()
+	f.removeFiles(f.generation)
+	f.added, f.removed = nil, nil
+}
+
+func (s *store) useIDListFile(name string) bool {
+	if s.options == nil || s.options.IDListFileOptions.Directory == "" {
+		return false
+	}
+	lists := s.options.IDListFileOptions.Lists
+	if len(lists) == 0 {
+		return true
+	}
+	for _, list := range lists {
+		if s.idListKey(list) == s.idListKey(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Closes the files of ID lists that are no longer used
+func closeIDListFile(list *idList) {
+	if list != nil && list.file != nil {
+		list.file.close()
+	}
+}
+
+func (s *store) closeIDListFiles() {
+	s.mu.RLock()
+	lists := make([]*idList, 0, len(s.idLists))
+	for _, list := range s.idLists {
+		lists = append(lists, list)
+	}
+	s.mu.RUnlock()
+	for _, list := range lists {
+		closeIDListFile(list)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package statsig
+
+import (
+	"os"
+)
+
+// Reads the file into memory, since it cannot be mapped on this platform
+func mapIDListFile(path string) ([]byte, func() error, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contents, func() error { return nil }, nil
+}
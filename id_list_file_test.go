@@ -0,0 +1,165 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIDListFile(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	dir := t.TempDir()
+	f := newIDListFile(IDListFileOptions{Directory: dir, CompactionThreshold: 10}, "list/1", "file_1")
+	for i := 99; i >= 0; i-- {
+		f.add(fmt.Sprintf("id_%02d", i))
+	}
+	for i := 0; i < 100; i += 3 {
+		f.remove(fmt.Sprintf("id_%02d", i))
+	}
+	f.add("id_00")
+	f.compactions.Wait()
+
+	if f.generation == 0 || f.count() == 0 {
+		t.Fatalf("Expected changes to be compacted into files")
+	}
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("id_%02d", i)
+		if expected := i%3 != 0 || i == 0; f.contains(id) != expected {
+			t.Errorf("Expected contains(%s) to be %t", id, expected)
+		}
+	}
+	if f.contains("id_100") || f.contains("") {
+		t.Errorf("Expected IDs that were never added to be missing")
+	}
+	if count := f.entryCount(); count != 67 {
+		t.Errorf("Expected 67 entries, received %d", count)
+	}
+	for i := 1; i < f.count(); i++ {
+		if string(f.at(i-1)) >= string(f.at(i)) {
+			t.Fatalf("Expected mapped IDs to be sorted and unique, received %s before %s", f.at(i-1), f.at(i))
+		}
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "list_1-file_1.*")); len(files) != 2 {
+		t.Errorf("Expected only the latest generation of files to be kept, received %v", files)
+	}
+
+	f.close()
+	if f.contains("id_01") {
+		t.Errorf("Expected a closed list to contain no IDs")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected files to be deleted when closed, received %d", len(files))
+	}
+}
+
+func TestIDListFileBuild(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	dir := t.TempDir()
+	f := newIDListFile(IDListFileOptions{Directory: dir}, "list", "file_1")
+	f.sortRunSize = 7
+	var content strings.Builder
+	for i := 99; i >= 0; i-- {
+		content.WriteString(fmt.Sprintf("+id_%02d\n", i))
+	}
+	// Removals and re-additions land in later sort runs than the additions they override
+	for i := 0; i < 100; i += 3 {
+		content.WriteString(fmt.Sprintf("-id_%02d\n", i))
+	}
+	content.WriteString("+id_00\n+id_01\n")
+	read, err := f.load(strings.NewReader(content.String()))
+	if err != nil || read != int64(content.Len()) {
+		t.Fatalf("Expected the download to be read in full, received %d bytes and %v", read, err)
+	}
+	if f.generation != 1 || len(f.added) != 0 || len(f.removed) != 0 {
+		t.Errorf("Expected the first download to be written to files without holding IDs in memory")
+	}
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("id_%02d", i)
+		if expected := i%3 != 0 || i == 0; f.contains(id) != expected {
+			t.Errorf("Expected contains(%s) to be %t", id, expected)
+		}
+	}
+	if count := f.entryCount(); count != 67 {
+		t.Errorf("Expected 67 entries, received %d", count)
+	}
+	for i := 1; i < f.count(); i++ {
+		if string(f.at(i-1)) >= string(f.at(i)) {
+			t.Fatalf("Expected mapped IDs to be sorted and unique, received %s before %s", f.at(i-1), f.at(i))
+		}
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 2 {
+		t.Errorf("Expected the sort runs to be deleted, received %v", files)
+	}
+
+	// Later downloads are incremental changes
+	if _, err := f.load(strings.NewReader("+id_03\n-id_01\n")); err != nil {
+		t.Fatalf("Expected the changes to load, received %v", err)
+	}
+	if !f.contains("id_03") || f.contains("id_01") || len(f.added) != 1 || len(f.removed) != 1 {
+		t.Errorf("Expected incremental changes to be held until compacted")
+	}
+	f.close()
+}
+
+func TestIDListFileMode(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "get_id_lists") {
+			baseURL := "http://" + req.Host
+			r := map[string]idList{
+				"file_list":  {Name: "file_list", Size: 20, URL: baseURL + "/file_list", CreationTime: 1, FileID: "file_1"},
+				"exact_list": {Name: "exact_list", Size: 20, URL: baseURL + "/exact_list", CreationTime: 1, FileID: "file_2"},
+			}
+			v, _ := json.Marshal(r)
+			_, _ = res.Write(v)
+		} else if strings.Contains(req.URL.Path, "_list") {
+			_, _ = res.Write([]byte("+a\n+b\n+c\n-a\n"))
+		}
+	}))
+	defer testServer.Close()
+	dir := t.TempDir()
+	opt := &Options{
+		API:               testServer.URL,
+		IDListFileOptions: IDListFileOptions{Directory: dir, Lists: []string{"file_list"}, CompactionThreshold: 2},
+	}
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	n := newTransport("secret-123", opt)
+	d := newDiagnostics(opt)
+	e := newErrorBoundary("client-key", opt, d)
+	s := newStoreInternal(n, time.Minute, time.Minute, "", nil, e, nil, d, "secret-123", opt)
+	defer s.stopPolling()
+
+	fileList := s.getIDList("file_list")
+	if fileList == nil || fileList.file == nil {
+		t.Fatalf("Expected file_list to be stored in a file")
+	}
+	if !fileList.contains("b") || !fileList.contains("c") || fileList.contains("a") {
+		t.Errorf("Expected file_list to contain only b and c")
+	}
+	if fileList.file.generation != 1 || len(fileList.file.added) != 0 {
+		t.Errorf("Expected file_list to be downloaded straight into its files")
+	}
+	adapter := &dataAdapterExample{store: make(map[string]string)}
+	s.dataAdapter = adapter
+	s.saveIDListsToAdapter(s.idLists)
+	var manifest map[string]idList
+	_ = json.Unmarshal([]byte(adapter.Get(ID_LISTS_KEY)), &manifest)
+	if _, ok := manifest["file_list"]; ok || manifest["exact_list"].Name != "exact_list" {
+		t.Errorf("Expected only lists kept in memory to be saved to the adapter, received %v", manifest)
+	}
+	s.dataAdapter = nil
+	if exactList := s.getIDList("exact_list"); exactList == nil || exactList.file != nil {
+		t.Errorf("Expected exact_list to be kept in memory")
+	}
+
+	s.closeIDListFiles()
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected files to be deleted on shutdown, received %d", len(files))
+	}
+}
@@ -0,0 +1,91 @@
+package statsig
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Which ID lists are skipped once Options.IDListMemoryLimit is reached
+type IDListMemoryPolicy int
+
+const (
+	SkipNewIDLists      IDListMemoryPolicy = iota // Lists already in memory are kept, and lists that no longer fit are skipped
+	EvictLargestIDLists                           // The largest lists are skipped, evicting them from memory if needed, so the most lists fit
+)
+
+type IDListMemoryOptions struct {
+	Policy        IDListMemoryPolicy
+	OnListSkipped func(name string, size int64) // Called when a list is skipped or evicted. Skipped lists contain no IDs until they fit again
+}
+
+const idListSkippedError = "Exceeds IDListMemoryLimit"
+
+// Only lists held in a map count towards the limit, bloom filters and files are bounded separately
+func (s *store) countsTowardsIDListMemory(name string) bool {
+	if _, ok := s.getBloomFilterFalsePositiveRate(name); ok {
+		return false
+	}
+	return !s.useIDListFile(name)
+}
+
+// Returns the lists that fit within IDListMemoryLimit, or nil when unlimited
+func (s *store) admitIDLists(idLists map[string]idList) map[string]bool {
+	limit := s.options.IDListMemoryLimit
+	if limit <= 0 {
+		return nil
+	}
+	names := make([]string, 0, len(idLists))
+	admitted := make(map[string]bool, len(idLists))
+	for name := range idLists {
+		if s.countsTowardsIDListMemory(name) {
+			names = append(names, name)
+		} else {
+			admitted[name] = true
+		}
+	}
+	loaded := func(name string) bool {
+		local := s.getIDList(name)
+		return local != nil && !local.skipped && local.FileID == idLists[name].FileID
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if s.options.IDListMemoryOptions.Policy == EvictLargestIDLists {
+			if sizeI, sizeJ := idLists[names[i]].Size, idLists[names[j]].Size; sizeI != sizeJ {
+				return sizeI < sizeJ
+			}
+		} else if loadedI, loadedJ := loaded(names[i]), loaded(names[j]); loadedI != loadedJ {
+			return loadedI
+		}
+		return names[i] < names[j]
+	})
+	var used int64
+	for _, name := range names {
+		if size := idLists[name].Size; used+size <= limit {
+			used += size
+			admitted[name] = true
+		}
+	}
+	return admitted
+}
+
+// Replaces the list with an empty one, freeing its IDs. The callback is called once each time a list is skipped
+func (s *store) skipIDList(name string, serverList idList) {
+	if local := s.getIDList(name); local != nil && local.skipped {
+		return
+	}
+	skipped := &idList{Name: name, CreationTime: serverList.CreationTime, ids: &sync.Map{}, skipped: true}
+	skipped.lastError.Store(idListSkippedError)
+	closeIDListFile(s.getIDList(name))
+	s.setIDList(name, skipped)
+	Logger().Log(fmt.Sprintf("ID list %s of %d bytes exceeds IDListMemoryLimit and is skipped, it contains no IDs until it fits\n", name, serverList.Size), nil)
+	if callback := s.options.IDListMemoryOptions.OnListSkipped; callback != nil {
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					Logger().LogError(fmt.Sprintf("IDListMemoryOptions.OnListSkipped panicked: %s\n", toError(err).Error()))
+				}
+			}()
+			callback(name, serverList.Size)
+		}()
+	}
+}
@@ -0,0 +1,102 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIDListMemoryLimit(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	content := map[string]string{"list_a": "+1\n+2\n", "list_b": "+3\n+4\n", "list_c": "+5\n+6\n+7\n"}
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			_, _ = res.Write(specs)
+		case strings.Contains(req.URL.Path, "get_id_lists"):
+			lists := make(map[string]idList)
+			for name, ids := range content {
+				lists[name] = idList{Name: name, Size: int64(len(ids)), URL: "http://" + req.Host + "/" + name, CreationTime: 1, FileID: name + "_file"}
+			}
+			bytes, _ := json.Marshal(lists)
+			_, _ = res.Write(bytes)
+		default:
+			_, _ = res.Write([]byte(content[strings.TrimPrefix(req.URL.Path, "/")]))
+		}
+	}))
+	defer testServer.Close()
+	newClient := func(limit int64, policy IDListMemoryPolicy, skipped map[string]int64) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			IDListMemoryLimit:    limit,
+			IDListMemoryOptions: IDListMemoryOptions{
+				Policy: policy,
+				OnListSkipped: func(name string, size int64) {
+					skipped[name] = size
+				},
+			},
+		})
+	}
+	storage := func(c *Client) map[string]string {
+		result := make(map[string]string)
+		for _, stats := range c.GetIDListStats() {
+			result[stats.Name] = stats.Storage
+		}
+		return result
+	}
+
+	t.Run("skips lists that do not fit", func(t *testing.T) {
+		skipped := make(map[string]int64)
+		c := newClient(12, SkipNewIDLists, skipped)
+		defer c.Shutdown()
+		if got := storage(c); got["list_a"] != "map" || got["list_b"] != "map" || got["list_c"] != "skipped" {
+			t.Errorf("Expected list_c to be skipped, received %+v", got)
+		}
+		if len(skipped) != 1 || skipped["list_c"] != 9 {
+			t.Errorf("Expected the callback for list_c, received %+v", skipped)
+		}
+		if list := c.evaluator.store.getIDList("list_c"); list.entryCount() != 0 || list.stats().LastError != idListSkippedError {
+			t.Errorf("Expected the skipped list to be empty, received %+v", list.stats())
+		}
+
+		c.evaluator.store.fetchIDListsFromServer()
+		if len(skipped) != 1 {
+			t.Errorf("Expected the callback once per skip, received %+v", skipped)
+		}
+		c.evaluator.store.options.IDListMemoryLimit = 21
+		c.evaluator.store.fetchIDListsFromServer()
+		if list := c.evaluator.store.getIDList("list_c"); list.skipped || list.entryCount() != 3 {
+			t.Errorf("Expected list_c to be downloaded once it fits, received %+v", list.stats())
+		}
+	})
+
+	t.Run("keeps lists already in memory", func(t *testing.T) {
+		skipped := make(map[string]int64)
+		c := newClient(0, SkipNewIDLists, skipped)
+		defer c.Shutdown()
+		c.evaluator.store.options.IDListMemoryLimit = 9
+		c.evaluator.store.fetchIDListsFromServer()
+		if got := storage(c); got["list_a"] != "map" || got["list_b"] != "skipped" || got["list_c"] != "skipped" {
+			t.Errorf("Expected lists beyond the limit to be skipped, received %+v", got)
+		}
+	})
+
+	t.Run("evicts the largest lists", func(t *testing.T) {
+		skipped := make(map[string]int64)
+		c := newClient(0, EvictLargestIDLists, skipped)
+		defer c.Shutdown()
+		c.evaluator.store.options.IDListMemoryLimit = 12
+		c.evaluator.store.fetchIDListsFromServer()
+		if got := storage(c); got["list_a"] != "map" || got["list_b"] != "map" || got["list_c"] != "skipped" {
+			t.Errorf("Expected the largest list to be evicted, received %+v", got)
+		}
+		if len(skipped) != 1 || skipped["list_c"] != 9 {
+			t.Errorf("Expected the callback for the evicted list, received %+v", skipped)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package statsig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// How ID list names from the get_id_lists manifest are matched against in_segment_list conditions
+type IDListNameCasePolicy int
+
+const (
+	IDListNameExact           IDListNameCasePolicy = iota // Names must match exactly
+	IDListNameCaseInsensitive                             // Names are matched ignoring case
+)
+
+func (s *store) idListNamesCaseInsensitive() bool {
+	return s.options != nil && s.options.IDListNameCasePolicy == IDListNameCaseInsensitive
+}
+
+// The key ID lists are stored under
+func (s *store) idListKey(name string) string {
+	if s.idListNamesCaseInsensitive() {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// Warns about manifest names that differ only in case. With IDListNameCaseInsensitive they would
+// share a key, so only the first in sorted order is kept.
+func (s *store) resolveIDListNames(idLists map[string]idList) map[string]idList {
+	names := make([]string, 0, len(idLists))
+	for name := range idLists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	groups := make(map[string][]string)
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		groups[lower] = append(groups[lower], name)
+	}
+	skipped := make(map[string]bool)
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		s.warnIDListNameOnce(strings.Join(group, ","), fmt.Sprintf(
+			"ID lists %s have names that differ only in case\n", strings.Join(group, ", ")))
+		if s.idListNamesCaseInsensitive() {
+			for _, name := range group[1:] {
+				skipped[name] = true
+			}
+		}
+	}
+	if len(skipped) == 0 {
+		return idLists
+	}
+	resolved := make(map[string]idList, len(idLists))
+	for name, list := range idLists {
+		if !skipped[name] {
+			resolved[name] = list
+		}
+	}
+	return resolved
+}
+
+// Looks up the list for an in_segment_list condition. With IDListNameExact, a miss that
+// only differs in case from a loaded list is reported, as conditions will never match it.
+func (s *store) getIDListForCondition(name string) *idList {
+	list := s.getIDList(name)
+	if list != nil || s.idListNamesCaseInsensitive() {
+		return list
+	}
+	s.mu.RLock()
+	var match string
+	for loaded := range s.idLists {
+		if strings.EqualFold(loaded, name) {
+			match = loaded
+			break
+		}
+	}
+	s.mu.RUnlock()
+	if match != "" {
+		s.warnIDListNameOnce(name, fmt.Sprintf("Condition references ID list %s, but only %s is loaded. "+
+			"Set Options.IDListNameCasePolicy to IDListNameCaseInsensitive to match names ignoring case\n", name, match))
+	}
+	return nil
+}
+
+func (s *store) warnIDListNameOnce(key string, message string) {
+	if _, warned := s.idListNameWarnings.LoadOrStore(key, true); !warned {
+		Logger().LogError(message)
+	}
+}
@@ -0,0 +1,98 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIDListNameCasePolicy(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "get_id_lists") {
+			baseURL := "http://" + req.Host
+			r := map[string]idList{
+				"Employees": {Name: "Employees", Size: 3, URL: baseURL + "/list/Employees", CreationTime: 1, FileID: "file_1"},
+				"employees": {Name: "employees", Size: 3, URL: baseURL + "/list/employees", CreationTime: 1, FileID: "file_2"},
+				"Admins":    {Name: "Admins", Size: 3, URL: baseURL + "/list/Admins", CreationTime: 1, FileID: "file_3"},
+			}
+			v, _ := json.Marshal(r)
+			_, _ = res.Write(v)
+		} else if strings.HasSuffix(req.URL.Path, "/list/Employees") {
+			_, _ = res.Write([]byte("+a\n"))
+		} else if strings.HasSuffix(req.URL.Path, "/list/employees") {
+			_, _ = res.Write([]byte("+b\n"))
+		} else if strings.HasSuffix(req.URL.Path, "/list/Admins") {
+			_, _ = res.Write([]byte("+c\n"))
+		}
+	}))
+	defer testServer.Close()
+
+	newTestStore := func(policy IDListNameCasePolicy) (*store, *[]string) {
+		var mu sync.Mutex
+		warnings := make([]string, 0)
+		opt := &Options{API: testServer.URL, IDListNameCasePolicy: policy}
+		InitializeGlobalOutputLogger(OutputLoggerOptions{LogCallback: func(message string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			warnings = append(warnings, message)
+		}})
+		n := newTransport("secret-123", opt)
+		d := newDiagnostics(opt)
+		e := newErrorBoundary("client-key", opt, d)
+		s := newStoreInternal(n, time.Minute, time.Minute, "", nil, e, nil, d, "secret-123", opt)
+		return s, &warnings
+	}
+	countWarnings := func(warnings []string, substring string) int {
+		count := 0
+		for _, warning := range warnings {
+			if strings.Contains(warning, substring) {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("exact", func(t *testing.T) {
+		s, warnings := newTestStore(IDListNameExact)
+		defer s.stopPolling()
+		if !s.getIDList("Employees").contains("a") || !s.getIDList("employees").contains("b") {
+			t.Errorf("Expected both lists to be loaded under their own names")
+		}
+		// Looked up twice, the mismatch should only be reported once
+		if s.getIDListForCondition("ADMINS") != nil || s.getIDListForCondition("ADMINS") != nil {
+			t.Errorf("Expected names to match exactly")
+		}
+		if countWarnings(*warnings, "differ only in case") != 1 {
+			t.Errorf("Expected one near-duplicate warning, received %v", *warnings)
+		}
+		if countWarnings(*warnings, "Condition references ID list ADMINS, but only Admins is loaded") != 1 {
+			t.Errorf("Expected one mismatch warning, received %v", *warnings)
+		}
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		s, warnings := newTestStore(IDListNameCaseInsensitive)
+		defer s.stopPolling()
+		list := s.getIDListForCondition("EMPLOYEES")
+		if list == nil || list.Name != "Employees" || !list.contains("a") || list.contains("b") {
+			t.Errorf("Expected the first list in sorted order to be kept, received %+v", list)
+		}
+		if admins := s.getIDListForCondition("admins"); admins == nil || !admins.contains("c") {
+			t.Errorf("Expected names to match ignoring case")
+		}
+		if countWarnings(*warnings, "differ only in case") != 1 {
+			t.Errorf("Expected one near-duplicate warning, received %v", *warnings)
+		}
+
+		// Lists dropped from the manifest are deleted regardless of key normalization
+		s.processIDLists(map[string]idList{"Admins": *s.getIDList("Admins")}, NetworkDataSource)
+		if s.getIDList("employees") != nil || s.getIDList("ADMINS") == nil {
+			t.Errorf("Expected only Admins to remain")
+		}
+	})
+}
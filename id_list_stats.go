@@ -0,0 +1,103 @@
+package statsig
+
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+)
+
+// The size and sync state of one ID list, e.g. to find the list responsible for memory growth
+type IDListStats struct {
+	Name            string `json:"name"`
+	FileID          string `json:"fileID"`
+	Storage         string `json:"storage"` // map, bloom_filter, file or skipped. See IDListBloomFilterOptions, IDListFileOptions and IDListMemoryLimit
+	EntryCount      int64  `json:"entryCount"`
+	Size            int64  `json:"size"`                   // Bytes of the list's current file applied so far
+	BytesDownloaded int64  `json:"bytesDownloaded"`        // Read from the network or DataAdapter since the current file was created
+	LastSyncTime    int64  `json:"lastSyncTime,omitempty"` // Unix milliseconds of the last successful sync
+	LastError       string `json:"lastError,omitempty"`    // From the last sync, cleared by the next successful one
+	SkewCount       int64  `json:"skewCount,omitempty"`    // Syncs whose manifest had an older CreationTime than the loaded list. See IDListSkewPolicy
+}
+
+func (l *idList) recordSync(bytes int) {
+	atomic.AddInt64(&l.bytesDownloaded, int64(bytes))
+	atomic.StoreInt64(&l.lastSyncTime, getUnixMilli())
+	l.lastError.Store("")
+	l.markSynced()
+}
+
+func (l *idList) markSynced() {
+	atomic.StoreInt32(&l.synced, 1)
+}
+
+func (l *idList) isSynced() bool {
+	return atomic.LoadInt32(&l.synced) == 1
+}
+
+func (l *idList) recordSyncError(err error) {
+	if err == nil {
+		err = errors.New("No response")
+	}
+	l.lastError.Store(err.Error())
+}
+
+func (l *idList) entryCount() int64 {
+	if l.bloom != nil {
+		return l.bloom.entryCount()
+	}
+	if l.file != nil {
+		return l.file.entryCount()
+	}
+	var count int64
+	if l.ids != nil {
+		l.ids.Range(func(key, value interface{}) bool {
+			count++
+			return true
+		})
+	}
+	return count
+}
+
+func (l *idList) stats() IDListStats {
+	storage := "map"
+	if l.skipped {
+		storage = "skipped"
+	} else if l.bloom != nil {
+		storage = "bloom_filter"
+	} else if l.file != nil {
+		storage = "file"
+	}
+	lastError, _ := l.lastError.Load().(string)
+	return IDListStats{
+		Name:            l.Name,
+		FileID:          l.FileID,
+		Storage:         storage,
+		EntryCount:      l.entryCount(),
+		Size:            atomic.LoadInt64(&l.Size),
+		BytesDownloaded: atomic.LoadInt64(&l.bytesDownloaded),
+		LastSyncTime:    atomic.LoadInt64(&l.lastSyncTime),
+		LastError:       lastError,
+		SkewCount:       atomic.LoadInt64(&l.skewCount),
+	}
+}
+
+func (s *store) getIDLists() []*idList {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lists := make([]*idList, 0, len(s.idLists))
+	for _, list := range s.idLists {
+		lists = append(lists, list)
+	}
+	return lists
+}
+
+// Sorted by name
+func (s *store) getIDListStats() []IDListStats {
+	lists := s.getIDLists()
+	stats := make([]IDListStats, 0, len(lists))
+	for _, list := range lists {
+		stats = append(stats, list.stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
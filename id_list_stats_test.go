@@ -0,0 +1,62 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIDListStats(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	content := "+7/rrkvF6\n+1\n+2\n-2\n"
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			_, _ = res.Write(specs)
+		case strings.Contains(req.URL.Path, "get_id_lists"):
+			baseURL := "http://" + req.Host
+			lists, _ := json.Marshal(map[string]idList{
+				"list_1": {Name: "list_1", Size: int64(len(content)), URL: baseURL + "/list_1", CreationTime: 1, FileID: "file_1"},
+				"list_2": {Name: "list_2", Size: 10, URL: baseURL + "/list_2", CreationTime: 1, FileID: "file_2"},
+			})
+			_, _ = res.Write(lists)
+		case strings.Contains(req.URL.Path, "list_1"):
+			_, _ = res.Write([]byte(content))
+		case strings.Contains(req.URL.Path, "list_2"):
+			res.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	stats := c.GetIDListStats()
+	if len(stats) != 2 || stats[0].Name != "list_1" || stats[1].Name != "list_2" {
+		t.Fatalf("Expected stats for both lists sorted by name, received %+v", stats)
+	}
+	synced := stats[0]
+	if synced.FileID != "file_1" || synced.Storage != "map" || synced.EntryCount != 2 {
+		t.Errorf("Expected the synced list's file ID, storage and entries, received %+v", synced)
+	}
+	if synced.Size != int64(len(content)) || synced.BytesDownloaded != int64(len(content)) || synced.LastSyncTime == 0 || synced.LastError != "" {
+		t.Errorf("Expected the synced list's sync state, received %+v", synced)
+	}
+	failed := stats[1]
+	if failed.LastSyncTime != 0 || failed.BytesDownloaded != 0 || failed.LastError == "" {
+		t.Errorf("Expected the failed list's error, received %+v", failed)
+	}
+
+	sdkStats := c.GetSDKStats()
+	if len(sdkStats.IDLists) != 2 || sdkStats.IDListCount != 2 || sdkStats.IDListEntryCount != 2 {
+		t.Errorf("Expected the per list stats in SDK stats, received %+v", sdkStats)
+	}
+}
@@ -0,0 +1,45 @@
+package statsig
+
+import (
+	"fmt"
+
+	"github.com/statsig-io/ip3country-go/pkg/countrylookup"
+)
+
+// Resolves the country of User.IpAddress for ip_based conditions on the country field, used when the
+// user has no Country set. Called concurrently from evaluations.
+type IPCountryLookup interface {
+	LookupIP(ip string) (country string, ok bool) // ISO 3166-1 alpha-2 code, e.g. "US"
+}
+
+type embeddedIPCountryLookup struct {
+	lookup *countrylookup.CountryLookup
+}
+
+// The IPv4 country table bundled with the SDK, used unless Options.IPCountryLookup is set. Useful as
+// a fallback when wrapping another lookup, e.g. one that also resolves IPv6 addresses.
+func NewEmbeddedIPCountryLookup() IPCountryLookup {
+	return &embeddedIPCountryLookup{lookup: countrylookup.New()}
+}
+
+func (l *embeddedIPCountryLookup) LookupIP(ip string) (string, bool) {
+	return l.lookup.LookupIp(ip)
+}
+
+func newIPCountryLookup(options *Options) IPCountryLookup {
+	if options.IPCountryLookup != nil {
+		return options.IPCountryLookup
+	}
+	return NewEmbeddedIPCountryLookup()
+}
+
+// A lookup that panics resolves no country, so the condition fails as it would for an unknown IP
+func lookupCountry(lookup IPCountryLookup, ip string) (country string, ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("IPCountryLookup panicked: %s", toError(err).Error()))
+			country, ok = "", false
+		}
+	}()
+	return lookup.LookupIP(ip)
+}
@@ -0,0 +1,70 @@
+package statsig
+
+import (
+	"testing"
+)
+
+const ipCountrySpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [{
+		"name": "nz_only", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "nz", "id": "nz_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "ip_based", "field": "country", "operator": "any", "targetValue": ["NZ"]}]
+		}]
+	}],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+type mapIPCountryLookup map[string]string
+
+func (m mapIPCountryLookup) LookupIP(ip string) (string, bool) {
+	if ip == "panic" {
+		panic("lookup failed")
+	}
+	country, ok := m[ip]
+	return country, ok
+}
+
+func TestIPCountryLookup(t *testing.T) {
+	newLookupClient := func(lookup IPCountryLookup) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      ipCountrySpecs,
+			IPCountryLookup:      lookup,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+
+	c := newLookupClient(mapIPCountryLookup{"10.0.0.1": "NZ", "10.0.0.2": "US"})
+	defer c.Shutdown()
+	if !c.CheckGate(User{UserID: "a", IpAddress: "10.0.0.1"}, "nz_only") {
+		t.Errorf("Expected the country to be resolved by the custom lookup")
+	}
+	if c.CheckGate(User{UserID: "a", IpAddress: "10.0.0.2"}, "nz_only") {
+		t.Errorf("Expected users resolved to another country to fail")
+	}
+	if c.CheckGate(User{UserID: "a", IpAddress: "10.0.0.1", Country: "US"}, "nz_only") {
+		t.Errorf("Expected User.Country to take precedence over the lookup")
+	}
+	if c.CheckGate(User{UserID: "a", IpAddress: "10.0.0.3"}, "nz_only") || c.CheckGate(User{UserID: "a", IpAddress: "panic"}, "nz_only") {
+		t.Errorf("Expected unresolved IPs and panicking lookups to fail the condition")
+	}
+
+	embedded := NewEmbeddedIPCountryLookup()
+	country, ok := embedded.LookupIP("8.8.8.8")
+	if !ok || country == "" {
+		t.Fatalf("Expected the embedded table to resolve 8.8.8.8")
+	}
+	if _, ok = embedded.LookupIP("not an ip"); ok {
+		t.Errorf("Expected invalid IPs not to resolve")
+	}
+	d := newLookupClient(nil)
+	defer d.Shutdown()
+	if _, ok := d.evaluator.countryLookup.(*embeddedIPCountryLookup); !ok {
+		t.Errorf("Expected the embedded table to be used by default")
+	}
+}
@@ -0,0 +1,67 @@
+// Package kafkasink writes events flushed by the Statsig SDK to a Kafka topic.
+//
+// The package does not depend on a Kafka client. Wrap the client you already use in a
+// Producer, e.g. a sarama.SyncProducer with RequiredAcks set to WaitForAll, or a kafka-go
+// Writer with RequiredAcks set to RequireAll and the default hash balancer.
+package kafkasink
+
+import (
+	statsig "github.com/statsig-io/go-sdk"
+)
+
+type Message struct {
+	Topic string
+	Key   []byte // The user ID, so a key hashing partitioner keeps each user's events in one partition
+	Value []byte // The event as JSON
+	// The SDK batch the event was flushed in, e.g. for a message header. Retried events keep their
+	// batch ID, so consumers can deduplicate on it together with the event
+	BatchID string
+}
+
+type Producer interface {
+	// Produces the messages, returning only once every message has been acknowledged
+	Produce(messages []Message) error
+}
+
+type Config struct {
+	Topic         string
+	ExposuresOnly bool // Only write gate, config and layer exposures
+}
+
+// An EventSink writing one message per event. Failed batches are retried by the SDK on the next
+// flush, so events are delivered at least once and consumers should deduplicate on Message.BatchID if needed.
+type Sink struct {
+	producer Producer
+	config   Config
+}
+
+func New(producer Producer, config Config) *Sink {
+	return &Sink{producer: producer, config: config}
+}
+
+func (s *Sink) WriteEvents(events []statsig.SinkEvent) error {
+	messages := make([]Message, 0, len(events))
+	for _, event := range events {
+		if s.config.ExposuresOnly && !isExposure(event.EventName) {
+			continue
+		}
+		var key []byte
+		if event.UserID != "" {
+			key = []byte(event.UserID)
+		}
+		messages = append(messages, Message{Topic: s.config.Topic, Key: key, Value: event.Payload, BatchID: event.BatchID})
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return s.producer.Produce(messages)
+}
+
+func isExposure(eventName string) bool {
+	switch statsig.ExposureEventName(eventName) {
+	case statsig.GateExposureEventName, statsig.ConfigExposureEventName, statsig.LayerExposureEventName:
+		return true
+	default:
+		return false
+	}
+}
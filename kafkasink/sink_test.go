@@ -0,0 +1,61 @@
+package kafkasink
+
+import (
+	"errors"
+	"testing"
+
+	statsig "github.com/statsig-io/go-sdk"
+)
+
+type fakeProducer struct {
+	fail     bool
+	messages []Message
+}
+
+func (p *fakeProducer) Produce(messages []Message) error {
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	events := []statsig.SinkEvent{
+		{EventName: "statsig::gate_exposure", UserID: "user_1", Payload: []byte(`{"eventName":"statsig::gate_exposure"}`), BatchID: "batch_1"},
+		{EventName: "purchase", UserID: "user_2", Payload: []byte(`{"eventName":"purchase"}`)},
+		{EventName: "statsig::diagnostics", Payload: []byte(`{"eventName":"statsig::diagnostics"}`)},
+	}
+
+	t.Run("keys messages by user ID", func(t *testing.T) {
+		producer := &fakeProducer{}
+		if err := New(producer, Config{Topic: "statsig_events"}).WriteEvents(events); err != nil {
+			t.Fatalf("Expected the write to succeed, received %s", err.Error())
+		}
+		if len(producer.messages) != 3 {
+			t.Fatalf("Expected 3 messages, received %d", len(producer.messages))
+		}
+		first := producer.messages[0]
+		if first.Topic != "statsig_events" || string(first.Key) != "user_1" || string(first.Value) != `{"eventName":"statsig::gate_exposure"}` ||
+			first.BatchID != "batch_1" {
+			t.Errorf("Unexpected message %+v", first)
+		}
+		if producer.messages[2].Key != nil {
+			t.Errorf("Expected events without a user to have no key")
+		}
+	})
+
+	t.Run("filters to exposures", func(t *testing.T) {
+		producer := &fakeProducer{}
+		_ = New(producer, Config{Topic: "exposures", ExposuresOnly: true}).WriteEvents(events)
+		if len(producer.messages) != 1 || string(producer.messages[0].Key) != "user_1" {
+			t.Errorf("Expected only the exposure, received %+v", producer.messages)
+		}
+	})
+
+	t.Run("returns producer errors for the SDK to retry", func(t *testing.T) {
+		if err := New(&fakeProducer{fail: true}, Config{Topic: "statsig_events"}).WriteEvents(events); err == nil {
+			t.Errorf("Expected the producer error")
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package statsig
+
+import (
+	"strings"
+)
+
+// How any and none conditions on the user's locale compare it to the target list
+type LocaleMatchingPolicy int
+
+const (
+	LocaleMatchExact      LocaleMatchingPolicy = iota // Locales are compared as strings, ignoring case
+	LocaleMatchNormalized                             // en_US, en-us and en-US.UTF-8 are the same locale, and a language or script target such as "en" or "zh-Hant" matches every region of it
+)
+
+func (e *evaluator) getLocaleMatchingPolicy() LocaleMatchingPolicy {
+	if e.options == nil {
+		return LocaleMatchExact
+	}
+	return e.options.LocaleMatchingPolicy
+}
+
+func isLocaleCondition(condType string, field string) bool {
+	return condType == "user_field" && strings.ToLower(field) == "locale"
+}
+
+// Lowercases the tag and separates subtags with hyphens, dropping any encoding or modifier,
+// e.g. "en_US.UTF-8" and "en-us" are both "en-us"
+func normalizeLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+func toLocaleSet(targets interface{}) map[string]bool {
+	targetSet := make(map[string]bool)
+	for target := range toStringSet(targets, false) {
+		if normalized := normalizeLocale(target); normalized != "" {
+			targetSet[normalized] = true
+		}
+	}
+	return targetSet
+}
+
+// Tries the locale, then falls back to it without its trailing subtags, e.g. zh-hant-tw, zh-hant, then zh
+func localeInSet(targetSet map[string]bool, value interface{}) bool {
+	locale, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for locale = normalizeLocale(locale); locale != ""; {
+		if targetSet[locale] {
+			return true
+		}
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return false
+}
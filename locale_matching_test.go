@@ -0,0 +1,69 @@
+package statsig
+
+import (
+	"testing"
+)
+
+const localeSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [
+		{"name": "rollout", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "locales", "id": "locale_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+				"conditions": [{"type": "user_field", "field": "locale", "operator": "any", "targetValue": ["en", "pt_BR", "zh-Hant"]}]}]},
+		{"name": "not_french", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "locales", "id": "locale_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+				"conditions": [{"type": "user_field", "field": "locale", "operator": "none", "targetValue": ["fr"]}]}]}
+	],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+func TestLocaleMatching(t *testing.T) {
+	newLocaleClient := func(policy LocaleMatchingPolicy) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      localeSpecs,
+			LocaleMatchingPolicy: policy,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	exact, normalized := newLocaleClient(LocaleMatchExact), newLocaleClient(LocaleMatchNormalized)
+	defer exact.Shutdown()
+	defer normalized.Shutdown()
+
+	tests := []struct {
+		locale     string
+		exact      bool
+		normalized bool
+	}{
+		{"en", true, true},
+		{"EN", true, true},
+		{"en-US", false, true},
+		{"en_gb.UTF-8", false, true},
+		{"pt-br", false, true},
+		{"pt_BR", true, true},
+		{"pt", false, false},
+		{"pt-PT", false, false},
+		{"zh-Hant-TW", false, true},
+		{"zh-Hans-CN", false, false},
+		{"english", false, false},
+		{"", false, false},
+	}
+	for _, test := range tests {
+		user := User{UserID: "a", Locale: test.locale}
+		if pass := exact.CheckGate(user, "rollout"); pass != test.exact {
+			t.Errorf("Expected %q to pass %v with exact matching, received %v", test.locale, test.exact, pass)
+		}
+		if pass := normalized.CheckGate(user, "rollout"); pass != test.normalized {
+			t.Errorf("Expected %q to pass %v with normalized matching, received %v", test.locale, test.normalized, pass)
+		}
+	}
+	if normalized.CheckGate(User{UserID: "a", Locale: "fr_CA"}, "not_french") || !exact.CheckGate(User{UserID: "a", Locale: "fr_CA"}, "not_french") {
+		t.Errorf("Expected none conditions to exclude every region of a language only with normalized matching")
+	}
+	if !normalized.CheckGate(User{UserID: "a", Locale: "de-DE"}, "not_french") {
+		t.Errorf("Expected other languages to pass none conditions")
+	}
+}
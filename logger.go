@@ -1,8 +1,10 @@
 package statsig
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -22,6 +24,8 @@ type ExposureEvent struct {
 	Metadata           map[string]string   `json:"metadata"`
 	SecondaryExposures []map[string]string `json:"secondaryExposures"`
 	Time               int64               `json:"time"`
+	TimeSinceInit      int64               `json:"timeSinceInit,omitempty"`
+	EvaluationInput    *EvaluationInput    `json:"evaluationInput,omitempty"`
 }
 
 const diagnosticsEventName = "statsig::diagnostics"
@@ -41,17 +45,32 @@ type logEventResponse struct{}
 
 type logContext struct {
 	isManualExposure bool
+	unitIDType       string
+	variant          string
+	ctx              context.Context // The evaluation's context, which may carry an ExposureAck
 }
 
 type logger struct {
-	events      []interface{}
-	transport   *transport
-	tick        *time.Ticker
-	mu          sync.Mutex
-	maxEvents   int
-	disabled    bool
-	diagnostics *diagnostics
-	options     *Options
+	events        []interface{}
+	transport     *transport
+	tick          *time.Ticker
+	mu            sync.Mutex
+	maxEvents     int
+	disabled      bool
+	diagnostics   *diagnostics
+	options       *Options
+	initTime      time.Time
+	sinkBuffer    eventSinkBuffer
+	aliases       eventAliasRegistry
+	queues        *eventQueues
+	flushing      adaptiveFlush
+	customKeys    map[string]bool // From Options.LoggedCustomFields, nil to log all custom fields
+	metrics       *metricsReporter
+	paused        int32 // Set while PauseLogging is in effect, read atomically
+	pause         loggingPause
+	spool         *eventSpool
+	exposureLimit *exposureRateLimiter // From Options.ExposureSamplingOptions, nil when unlimited
+	dedupe        *exposureDedupe
 }
 
 func newLogger(transport *transport, options *Options, diagnostics *diagnostics) *logger {
@@ -65,56 +84,113 @@ func newLogger(transport *transport, options *Options, diagnostics *diagnostics)
 	}
 	disabled := options.StatsigLoggerOptions.DisableAllLogging
 	log := &logger{
-		events:      make([]interface{}, 0),
-		transport:   transport,
-		tick:        time.NewTicker(loggingInterval),
-		maxEvents:   maxEvents,
-		disabled:    disabled,
-		diagnostics: diagnostics,
-		options:     options,
+		events:        make([]interface{}, 0),
+		transport:     transport,
+		tick:          time.NewTicker(loggingInterval),
+		maxEvents:     maxEvents,
+		disabled:      disabled,
+		diagnostics:   diagnostics,
+		options:       options,
+		initTime:      time.Now(),
+		flushing:      newAdaptiveFlush(loggingInterval, maxEvents, options.AdaptiveFlushOptions),
+		metrics:       newMetricsReporter(options),
+		spool:         newEventSpool(options),
+		exposureLimit: newExposureRateLimiter(options.ExposureSamplingOptions),
+		dedupe:        newExposureDedupe(options),
 	}
+	if options.LoggedCustomFields != nil {
+		log.customKeys = make(map[string]bool, len(options.LoggedCustomFields))
+		for _, field := range options.LoggedCustomFields {
+			log.customKeys[field] = true
+		}
+	}
+	log.queues = newEventQueues(options, log, loggingInterval)
 
 	go log.backgroundFlush()
+	log.replaySpool()
 
 	return log
 }
 
 func (l *logger) backgroundFlush() {
 	for range l.tick.C {
+		l.mu.Lock()
+		l.recordFlush(len(l.events))
+		l.mu.Unlock()
 		l.flush(false)
 	}
 }
 
 func (l *logger) logCustom(evt Event) {
-	evt.User.PrivateAttributes = nil
+	evt.User = l.loggedUser(evt.User)
 	if evt.Time == 0 {
-		evt.Time = getUnixMilli()
+		evt.Time = l.transport.eventTime()
+	}
+	evt.TimeSinceInit = l.getTimeSinceInit()
+	for _, aliased := range l.aliases.apply(evt) {
+		l.logInternal(aliased)
 	}
-	l.logInternal(evt)
 }
 
 func (l *logger) logExposureWithEvaluationDetails(
 	evt *ExposureEvent,
 	evalDetails *evaluationDetails,
+	context *logContext,
 ) {
+	if !l.dedupeExposure(evt, evalDetails, context) {
+		return
+	}
 	if evalDetails != nil {
 		evt.Metadata["reason"] = string(evalDetails.reason)
 		evt.Metadata["configSyncTime"] = fmt.Sprint(evalDetails.configSyncTime)
 		evt.Metadata["initTime"] = fmt.Sprint(evalDetails.initTime)
 		evt.Metadata["serverTime"] = fmt.Sprint(evalDetails.serverTime)
 	}
+	l.sampleEvaluationInput(evt)
+	if context != nil {
+		if ack := exposureAckFromContext(context.ctx); ack != nil {
+			ack.record(l.logExposureSync(context.ctx, *evt))
+			return
+		}
+	}
 	l.logExposure(*evt)
-
 }
 
 func (l *logger) logExposure(evt ExposureEvent) {
-	evt.User.PrivateAttributes = nil
+	evt.User = l.loggedUser(evt.User)
 	if evt.Time == 0 {
-		evt.Time = getUnixMilli()
+		evt.Time = l.transport.eventTime()
+	}
+	evt.TimeSinceInit = l.getTimeSinceInit()
+	l.exportExposure(evt)
+	if l.options.ExposureExportOptions.DisableStatsigExposureLogging || !l.sampleExposure(&evt) {
+		return
 	}
 	l.logInternal(evt)
 }
 
+// Private attributes are never logged, and custom fields only if in Options.LoggedCustomFields when set.
+// Evaluations still use every attribute
+func (l *logger) loggedUser(user User) User {
+	user.PrivateAttributes = nil
+	if l.customKeys == nil || user.Custom == nil {
+		return user
+	}
+	custom := make(map[string]interface{}, len(l.customKeys))
+	for key, value := range user.Custom {
+		if l.customKeys[key] {
+			custom[key] = value
+		}
+	}
+	user.Custom = custom
+	return user
+}
+
+// time.Since uses the monotonic clock reading captured in initTime
+func (l *logger) getTimeSinceInit() int64 {
+	return int64(time.Since(l.initTime) / time.Millisecond)
+}
+
 func (l *logger) logInternal(evt interface{}) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -122,9 +198,20 @@ func (l *logger) logInternal(evt interface{}) {
 	if l.disabled {
 		return
 	}
+	if l.queues != nil {
+		l.queues.queueFor(evt).enqueue(evt)
+		return
+	}
+	if l.isLoggingPaused() {
+		if l.holdWhilePaused() {
+			l.events = append(l.events, evt)
+		}
+		return
+	}
 
 	l.events = append(l.events, evt)
-	if len(l.events) >= l.maxEvents {
+	if len(l.events) >= l.flushing.threshold {
+		l.recordFlush(len(l.events))
 		l.flushInternal(false)
 	}
 }
@@ -146,13 +233,17 @@ func (l *logger) logGateExposure(
 	if context != nil && context.isManualExposure {
 		metadata["isManualExposure"] = "true"
 	}
+	if context != nil && context.variant != "" {
+		metadata["variant"] = context.variant
+	}
+	addUnitIDMetadata(metadata, user, context)
 	evt := &ExposureEvent{
 		User:               user,
 		EventName:          GateExposureEventName,
 		Metadata:           metadata,
 		SecondaryExposures: exposures,
 	}
-	l.logExposureWithEvaluationDetails(evt, evalDetails)
+	l.logExposureWithEvaluationDetails(evt, evalDetails, context)
 	return evt
 }
 
@@ -171,13 +262,14 @@ func (l *logger) logConfigExposure(
 	if context != nil && context.isManualExposure {
 		metadata["isManualExposure"] = "true"
 	}
+	addUnitIDMetadata(metadata, user, context)
 	evt := &ExposureEvent{
 		User:               user,
 		EventName:          ConfigExposureEventName,
 		Metadata:           metadata,
 		SecondaryExposures: exposures,
 	}
-	l.logExposureWithEvaluationDetails(evt, evalDetails)
+	l.logExposureWithEvaluationDetails(evt, evalDetails, context)
 	return evt
 }
 
@@ -207,6 +299,7 @@ func (l *logger) logLayerExposure(
 	if context != nil && context.isManualExposure {
 		metadata["isManualExposure"] = "true"
 	}
+	addUnitIDMetadata(metadata, user, context)
 
 	evt := &ExposureEvent{
 		User:               user,
@@ -214,21 +307,67 @@ func (l *logger) logLayerExposure(
 		Metadata:           metadata,
 		SecondaryExposures: exposures,
 	}
-	l.logExposureWithEvaluationDetails(evt, evalDetails)
+	l.logExposureWithEvaluationDetails(evt, evalDetails, context)
 	return evt
 }
 
+// Exposures for specs keyed by a non-user unit (e.g. stableID, sessionID) record which unit was allocated
+func addUnitIDMetadata(metadata map[string]string, user User, context *logContext) {
+	if context == nil || context.unitIDType == "" || strings.ToLower(context.unitIDType) == "userid" {
+		return
+	}
+	metadata["unitIDType"] = context.unitIDType
+	metadata["unitID"] = getUnitID(user, context.unitIDType)
+}
+
 func (l *logger) flush(closing bool) {
 	l.logDiagnosticsEvents(l.diagnostics)
 	l.mu.Lock()
-	defer l.mu.Unlock()
-
 	l.flushInternal(closing)
+	l.mu.Unlock()
+	if l.queues != nil {
+		l.queues.flush(closing)
+	}
+}
+
+// Sends buffered events synchronously without stopping the flush ticker, bound to ctx
+func (l *logger) flushCtx(ctx context.Context) error {
+	if l.isLoggingPaused() {
+		return errLoggingPaused
+	}
+	l.logDiagnosticsEvents(l.diagnostics)
+	l.mu.Lock()
+	events := l.events
+	l.events = make([]interface{}, 0)
+	l.mu.Unlock()
+	var errs []string
+	if len(events) > 0 {
+		if err := l.sendEventsCtx(ctx, events); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if l.queues != nil {
+		for _, err := range l.queues.flushPendingCtx(ctx) {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Failed to flush events: %s", strings.Join(errs, "; "))
+	}
+	return nil
 }
 
 func (l *logger) flushInternal(closing bool) {
 	if closing {
+		l.flushing.closed = true
 		l.tick.Stop()
+		if l.spool != nil {
+			l.spool.close()
+		}
+		l.liftPauseLocked()
+	}
+	if l.isLoggingPaused() {
+		return
 	}
 	if len(l.events) == 0 {
 		return
@@ -244,12 +383,49 @@ func (l *logger) flushInternal(closing bool) {
 }
 
 func (l *logger) sendEvents(events []interface{}) {
+	_ = l.sendEventsCtx(context.Background(), events)
+}
+
+// Failed events are spooled if Options.EventSpoolOptions is set, and dropped otherwise
+func (l *logger) sendEventsCtx(ctx context.Context, events []interface{}) error {
+	batchID := newEventBatchID()
+	l.writeToEventSink(events, batchID)
+	err := l.postEventsCtx(ctx, events, batchID)
+	if err != nil && !l.spillEvents(events, batchID) {
+		l.metrics.eventsDropped("default", int64(len(events)))
+	}
+	return err
+}
+
+func (l *logger) postEvents(events []interface{}, batchID string) error {
+	return l.postEventsCtx(context.Background(), events, batchID)
+}
+
+// Every attempt, including retries, is sent with the same batch ID
+func (l *logger) postEventsCtx(ctx context.Context, events []interface{}, batchID string) error {
 	input := &logEventInput{
 		Events:          events,
 		StatsigMetadata: l.transport.metadata,
 	}
 	var res logEventResponse
-	_, _ = l.transport.post("/log_event", input, &res, RequestOptions{retries: maxRetries})
+	start := time.Now()
+	_, err := l.transport.post("/log_event", input, &res, RequestOptions{
+		retries:         maxRetries,
+		ctx:             ctx,
+		headers:         eventBatchHeaders(batchID),
+		useRetryOptions: true,
+	})
+	l.metrics.eventFlush(len(events), err == nil)
+	if err != nil {
+		Logger().logRecord(logLevelWarn, "Failed to flush events", logAttr{"event_count", len(events)},
+			logAttr{"batch_id", batchID}, logAttr{"duration", time.Since(start)}, logAttr{"error", err.Error()})
+		return err
+	}
+	Logger().logRecord(logLevelDebug, "Flushed events",
+		logAttr{"event_count", len(events)}, logAttr{"batch_id", batchID}, logAttr{"duration", time.Since(start)})
+	// The endpoint has recovered
+	l.replaySpool()
+	return nil
 }
 
 func (l *logger) logDiagnosticsEvents(d *diagnostics) {
@@ -274,7 +450,7 @@ func (l *logger) logDiagnosticsEvent(d *diagnosticsBase) {
 	}
 	event := diagnosticsEvent{
 		EventName: diagnosticsEventName,
-		Time:      getUnixMilli(),
+		Time:      l.transport.eventTime(),
 		Metadata:  serialized,
 	}
 	l.logInternal(event)
@@ -42,7 +42,7 @@ func TestLog(t *testing.T) {
 	customEventNoPrivate := Event{
 		EventName: "test_event",
 		User:      privateUser, Value: "3",
-		Time: evt1.Time,
+		Time: evt1.Time, TimeSinceInit: evt1.TimeSinceInit,
 	}
 
 	if !reflect.DeepEqual(evt1, customEventNoPrivate) {
@@ -64,7 +64,7 @@ func TestLog(t *testing.T) {
 		"gate":      "test_gate",
 		"gateValue": strconv.FormatBool(true),
 		"ruleID":    "rule_id",
-	}, SecondaryExposures: exposures, Time: evt2.Time}
+	}, SecondaryExposures: exposures, Time: evt2.Time, TimeSinceInit: evt2.TimeSinceInit}
 
 	if !reflect.DeepEqual(evt2, gateExposureEvent) {
 		t.Errorf("Gate exposure not logged correctly.")
@@ -84,7 +84,7 @@ func TestLog(t *testing.T) {
 	configExposureEvent := ExposureEvent{EventName: ConfigExposureEventName, User: privateUser, Metadata: map[string]string{
 		"config": "test_config",
 		"ruleID": "rule_id_config",
-	}, SecondaryExposures: exposures, Time: evt3.Time}
+	}, SecondaryExposures: exposures, Time: evt3.Time, TimeSinceInit: evt3.TimeSinceInit}
 
 	if !reflect.DeepEqual(evt3, configExposureEvent) {
 		t.Errorf("Config exposure not logged correctly.")
@@ -93,3 +93,76 @@ func TestLog(t *testing.T) {
 		t.Errorf("Config exposure event time not set correctly.")
 	}
 }
+
+func TestLogTimeSinceInit(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+	defer testServer.Close()
+	opt := &Options{
+		API: testServer.URL,
+	}
+	transport := newTransport("secret", opt)
+	logger := newLogger(transport, opt, newDiagnostics(opt))
+	defer logger.flush(true)
+	user := User{UserID: "123"}
+
+	time.Sleep(20 * time.Millisecond)
+	logger.logCustom(Event{EventName: "first", User: user})
+	time.Sleep(20 * time.Millisecond)
+	logger.logGateExposure(user, "test_gate", true, "rule_id", nil, nil, nil)
+
+	first := logger.events[0].(Event)
+	second := logger.events[1].(ExposureEvent)
+	if first.TimeSinceInit < 20 {
+		t.Errorf("Expected time since init to be at least 20ms, received %d", first.TimeSinceInit)
+	}
+	if second.TimeSinceInit < first.TimeSinceInit+20 {
+		t.Errorf("Expected time since init to increase monotonically, received %d then %d", first.TimeSinceInit, second.TimeSinceInit)
+	}
+}
+
+func TestLoggedCustomFields(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+	defer testServer.Close()
+	newTestLogger := func(fields []string) *logger {
+		opt := &Options{
+			API:                    testServer.URL,
+			LoggedCustomFields:     fields,
+			EvaluationDebugOptions: EvaluationDebugOptions{SampleRate: 1},
+		}
+		logger := newLogger(newTransport("secret", opt), opt, newDiagnostics(opt))
+		t.Cleanup(func() { logger.flush(true) })
+		return logger
+	}
+	custom := map[string]interface{}{"plan": "pro", "company": "acme", "ssn": "123"}
+	user := User{UserID: "123", Custom: custom}
+
+	logger := newTestLogger([]string{"plan", "company"})
+	logger.logCustom(Event{EventName: "test_event", User: user})
+	logger.logExposureWithEvaluationDetails(&ExposureEvent{EventName: GateExposureEventName, User: user, Metadata: map[string]string{}}, nil, nil)
+	expected := map[string]interface{}{"plan": "pro", "company": "acme"}
+	if logged := logger.events[0].(Event).User.Custom; !reflect.DeepEqual(logged, expected) {
+		t.Errorf("Expected only allow-listed fields on the custom event, received %v", logged)
+	}
+	exposure := logger.events[1].(ExposureEvent)
+	if logged := exposure.User.Custom; !reflect.DeepEqual(logged, expected) {
+		t.Errorf("Expected only allow-listed fields on the exposure, received %v", logged)
+	}
+	if exposure.EvaluationInput == nil || !reflect.DeepEqual(exposure.EvaluationInput.User.Custom, expected) {
+		t.Errorf("Expected only allow-listed fields on the sampled evaluation input, received %+v", exposure.EvaluationInput)
+	}
+	if len(custom) != 3 {
+		t.Errorf("Expected the caller's custom fields to be unmodified, received %v", custom)
+	}
+
+	logger = newTestLogger([]string{})
+	logger.logCustom(Event{EventName: "test_event", User: user})
+	if logged := logger.events[0].(Event).User.Custom; len(logged) != 0 {
+		t.Errorf("Expected no custom fields with an empty allow-list, received %v", logged)
+	}
+
+	logger = newTestLogger(nil)
+	logger.logCustom(Event{EventName: "test_event", User: user})
+	if logged := logger.events[0].(Event).User.Custom; !reflect.DeepEqual(logged, custom) {
+		t.Errorf("Expected every custom field without an allow-list, received %v", logged)
+	}
+}
@@ -0,0 +1,97 @@
+package statsig
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxLoggingPauseDuration = time.Hour
+	// Events held in the default queue while paused, beyond which new events are dropped
+	maxPausedEvents = 10000
+)
+
+var errLoggingPaused = errors.New("Event logging is paused")
+
+// The state of an event logging pause started with PauseLogging
+type LoggingPauseStatus struct {
+	Paused        bool
+	PausedAt      time.Time
+	ExpiresAt     time.Time // Logging resumes automatically after Options.MaxLoggingPauseDuration
+	DroppedEvents int64     // Events dropped because the default queue was full while paused
+}
+
+type loggingPause struct {
+	pausedAt time.Time
+	expires  *time.Timer
+	maxPause time.Duration
+	dropped  int64
+}
+
+func (l *logger) pauseLogging() {
+	maxPause := l.options.MaxLoggingPauseDuration
+	if maxPause <= 0 {
+		maxPause = defaultMaxLoggingPauseDuration
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.flushing.closed || !atomic.CompareAndSwapInt32(&l.paused, 0, 1) {
+		return
+	}
+	l.pause = loggingPause{pausedAt: time.Now(), maxPause: maxPause}
+	l.pause.expires = time.AfterFunc(maxPause, func() {
+		Logger().LogError("Event logging pause exceeded its maximum duration and was lifted\n")
+		l.resumeLogging()
+	})
+}
+
+// Sends the events held while paused, without waiting for the next flush
+func (l *logger) resumeLogging() {
+	l.mu.Lock()
+	lifted := l.liftPauseLocked()
+	l.mu.Unlock()
+	if lifted {
+		l.flush(false)
+	}
+}
+
+// Called with l.mu held. Also called as the logger closes, so the events held while paused are sent
+// rather than lost. Returns false if logging was not paused
+func (l *logger) liftPauseLocked() bool {
+	if !atomic.CompareAndSwapInt32(&l.paused, 1, 0) {
+		return false
+	}
+	l.pause.expires.Stop()
+	l.pause = loggingPause{}
+	return true
+}
+
+// Read without l.mu, as event queues check it while holding their own lock
+func (l *logger) isLoggingPaused() bool {
+	return atomic.LoadInt32(&l.paused) == 1
+}
+
+// Called with l.mu held. Returns false if the event should be dropped
+func (l *logger) holdWhilePaused() bool {
+	if len(l.events) < maxPausedEvents {
+		return true
+	}
+	l.pause.dropped++
+	l.metrics.eventsDropped("default", 1)
+	return false
+}
+
+func (l *logger) getLoggingPauseStatus() LoggingPauseStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.isLoggingPaused() {
+		return LoggingPauseStatus{}
+	}
+	return LoggingPauseStatus{
+		Paused:        true,
+		PausedAt:      l.pause.pausedAt,
+		ExpiresAt:     l.pause.pausedAt.Add(l.pause.maxPause),
+		DroppedEvents: l.pause.dropped,
+	}
+}
@@ -0,0 +1,119 @@
+package statsig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoggingPause(t *testing.T) {
+	var logged int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "log_event") {
+			var input logEventInput
+			_ = json.NewDecoder(req.Body).Decode(&input)
+			for _, event := range input.Events {
+				if event.(map[string]interface{})["eventName"] == "paused_event" {
+					atomic.AddInt32(&logged, 1)
+				}
+			}
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	user := User{UserID: "a_user"}
+
+	newClient := func(maxPause time.Duration, partitioned bool) *Client {
+		atomic.StoreInt32(&logged, 0)
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                     testServer.URL,
+			LoggingInterval:         20 * time.Millisecond,
+			MaxLoggingPauseDuration: maxPause,
+			EventQueueOptions:       EventQueueOptions{Partitioned: partitioned},
+			StatsigLoggerOptions:    getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	waitForLogged := func(expected int32) bool {
+		for i := 0; i < 100 && atomic.LoadInt32(&logged) < expected; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return atomic.LoadInt32(&logged) == expected
+	}
+
+	for _, partitioned := range []bool{false, true} {
+		c := newClient(0, partitioned)
+		c.PauseLogging()
+		status := c.GetLoggingPauseStatus()
+		if !status.Paused || status.ExpiresAt.Sub(status.PausedAt) != defaultMaxLoggingPauseDuration {
+			t.Errorf("Unexpected pause status %+v", status)
+		}
+		for i := 0; i < 3; i++ {
+			c.LogEvent(Event{EventName: "paused_event", User: user})
+		}
+		time.Sleep(100 * time.Millisecond)
+		if atomic.LoadInt32(&logged) != 0 {
+			t.Errorf("Expected no events to be sent while paused, partitioned %v", partitioned)
+		}
+		if err := c.Flush(context.Background()); err != errLoggingPaused {
+			t.Errorf("Expected Flush to report that logging is paused")
+		}
+		c.ResumeLogging()
+		if c.GetLoggingPauseStatus().Paused {
+			t.Errorf("Expected logging to be resumed")
+		}
+		if !waitForLogged(3) {
+			t.Errorf("Expected the held events to be sent on resume, partitioned %v, received %d", partitioned, logged)
+		}
+		c.Shutdown()
+	}
+
+	t.Run("resumes after the maximum duration", func(t *testing.T) {
+		c := newClient(50*time.Millisecond, false)
+		defer c.Shutdown()
+		c.PauseLogging()
+		c.LogEvent(Event{EventName: "paused_event", User: user})
+		if !waitForLogged(1) || c.GetLoggingPauseStatus().Paused {
+			t.Errorf("Expected logging to resume once the pause expired")
+		}
+	})
+
+	t.Run("sends held events on shutdown", func(t *testing.T) {
+		for _, partitioned := range []bool{false, true} {
+			c := newClient(0, partitioned)
+			c.PauseLogging()
+			c.LogEvent(Event{EventName: "paused_event", User: user})
+			c.LogEvent(Event{EventName: "paused_event", User: user})
+			c.Shutdown()
+			if sent := atomic.LoadInt32(&logged); sent != 2 {
+				t.Errorf("Expected the 2 held events to be sent on shutdown, partitioned %v, received %d", partitioned, sent)
+			}
+			if c.GetLoggingPauseStatus().Paused {
+				t.Errorf("Expected the pause to be lifted on shutdown")
+			}
+		}
+	})
+
+	t.Run("drops events beyond the limit", func(t *testing.T) {
+		c := newClient(0, false)
+		defer c.Shutdown()
+		c.PauseLogging()
+		for i := 0; i < maxPausedEvents+5; i++ {
+			c.LogEvent(Event{EventName: "paused_event", User: user})
+		}
+		if dropped := c.GetLoggingPauseStatus().DroppedEvents; dropped != 5 {
+			t.Errorf("Expected 5 dropped events, received %d", dropped)
+		}
+		c.logger.mu.Lock()
+		held := len(c.logger.events)
+		c.logger.mu.Unlock()
+		if held != maxPausedEvents {
+			t.Errorf("Expected %d held events, received %d", maxPausedEvents, held)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package statsig
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// How a Metric's value is aggregated, matching Prometheus metric types
+type MetricKind int
+
+const (
+	MetricCounter   MetricKind = iota // Value is added to the running total
+	MetricGauge                       // Value replaces the current value
+	MetricHistogram                   // Value is one observation, in seconds for durations
+)
+
+// Names of the metrics reported to MetricsOptions.Hook
+const (
+	MetricConfigSyncDuration = "statsig_config_sync_duration_seconds" // Histogram of network config spec syncs, successful or not
+	MetricConfigSyncFailures = "statsig_config_sync_failures_total"
+	MetricEventFlushes       = "statsig_event_flushes_total"     // Labeled success=true|false
+	MetricEventsFlushed      = "statsig_events_flushed_total"    // Labeled success=true|false
+	MetricEventsDropped      = "statsig_events_dropped_total"    // Labeled queue=default|exposures|custom
+	MetricIDListEntries      = "statsig_id_list_entries"         // Gauge labeled list=<name>, updated after each ID list sync
+	MetricIDListBytes        = "statsig_id_list_bytes"           // Gauge labeled list=<name>, updated after each ID list sync
+	MetricEvaluations        = "statsig_evaluations_total"       // Labeled type=gate|config|experiment|layer
+	MetricSpecAnomalies      = "statsig_spec_anomalies_total"    // Labeled kind=<SpecAnomalyError.Kind> and type=<SpecAnomalyError.SpecType>
+	MetricExposuresSkipped   = "statsig_exposures_skipped_total" // Labeled reason=sampled|rate_limited, see ExposureSamplingOptions
+)
+
+type Metric struct {
+	Name   string
+	Kind   MetricKind
+	Value  float64
+	Labels map[string]string
+}
+
+// Reports SDK health metrics, e.g. to update Prometheus collectors. Hook is called synchronously on SDK goroutines,
+// including those evaluating, so it must be safe for concurrent use and return quickly
+type MetricsOptions struct {
+	Hook func(metric Metric)
+}
+
+type metricsReporter struct {
+	hook func(metric Metric)
+}
+
+func newMetricsReporter(options *Options) *metricsReporter {
+	if options == nil || options.MetricsOptions.Hook == nil {
+		return nil
+	}
+	return &metricsReporter{hook: options.MetricsOptions.Hook}
+}
+
+func (m *metricsReporter) report(name string, kind MetricKind, value float64, labels map[string]string) {
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("MetricsOptions.Hook panicked: %s\n", toError(err).Error()))
+		}
+	}()
+	m.hook(Metric{Name: name, Kind: kind, Value: value, Labels: labels})
+}
+
+func (m *metricsReporter) configSync(duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.report(MetricConfigSyncDuration, MetricHistogram, duration.Seconds(), nil)
+}
+
+func (m *metricsReporter) configSyncFailure() {
+	if m == nil {
+		return
+	}
+	m.report(MetricConfigSyncFailures, MetricCounter, 1, nil)
+}
+
+func (m *metricsReporter) eventFlush(events int, success bool) {
+	if m == nil {
+		return
+	}
+	labels := map[string]string{"success": strconv.FormatBool(success)}
+	m.report(MetricEventFlushes, MetricCounter, 1, labels)
+	m.report(MetricEventsFlushed, MetricCounter, float64(events), labels)
+}
+
+func (m *metricsReporter) eventsDropped(queue string, events int64) {
+	if m == nil || events <= 0 {
+		return
+	}
+	m.report(MetricEventsDropped, MetricCounter, float64(events), map[string]string{"queue": queue})
+}
+
+func (m *metricsReporter) idLists(stats []IDListStats) {
+	if m == nil {
+		return
+	}
+	for _, list := range stats {
+		labels := map[string]string{"list": list.Name}
+		m.report(MetricIDListEntries, MetricGauge, float64(list.EntryCount), labels)
+		m.report(MetricIDListBytes, MetricGauge, float64(list.Size), labels)
+	}
+}
+
+func (m *metricsReporter) evaluation(specType string) {
+	if m == nil {
+		return
+	}
+	m.report(MetricEvaluations, MetricCounter, 1, map[string]string{"type": specType})
+}
+
+func (m *metricsReporter) specAnomaly(anomaly *SpecAnomalyError) {
+	if m == nil {
+		return
+	}
+	m.report(MetricSpecAnomalies, MetricCounter, 1, map[string]string{"kind": anomaly.Kind, "type": anomaly.SpecType})
+}
+
+func (m *metricsReporter) exposureSkipped(reason string) {
+	if m == nil {
+		return
+	}
+	m.report(MetricExposuresSkipped, MetricCounter, 1, map[string]string{"reason": reason})
+}
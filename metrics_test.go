@@ -0,0 +1,128 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMetricsHook(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	content := "+7/rrkvF6\n+1\n"
+	var failSync int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			if atomic.LoadInt32(&failSync) == 1 {
+				res.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = res.Write(specs)
+		case strings.Contains(req.URL.Path, "get_id_lists"):
+			lists, _ := json.Marshal(map[string]idList{
+				"list_1": {Name: "list_1", Size: int64(len(content)), URL: "http://" + req.Host + "/list_1", CreationTime: 1, FileID: "file_1"},
+			})
+			_, _ = res.Write(lists)
+		case strings.Contains(req.URL.Path, "list_1"):
+			_, _ = res.Write([]byte(content))
+		default:
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	var mu sync.Mutex
+	counters := make(map[string]float64)
+	gauges := make(map[string]float64)
+	histograms := make(map[string]int)
+	key := func(metric Metric) string {
+		for label, value := range metric.Labels {
+			return metric.Name + "{" + label + "=" + value + "}"
+		}
+		return metric.Name
+	}
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		MetricsOptions: MetricsOptions{
+			Hook: func(metric Metric) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch metric.Kind {
+				case MetricCounter:
+					counters[key(metric)] += metric.Value
+				case MetricGauge:
+					gauges[key(metric)] = metric.Value
+				case MetricHistogram:
+					histograms[key(metric)]++
+				}
+			},
+		},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	c.CheckGate(user, "always_on_gate")
+	c.CheckGate(user, "always_on_gate")
+	c.GetConfig(user, "test_config")
+	c.GetExperiment(user, "sample_experiment")
+	c.GetLayer(user, "a_layer")
+	c.logger.flush(true)
+	atomic.StoreInt32(&failSync, 1)
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+
+	mu.Lock()
+	defer mu.Unlock()
+	expectedCounters := map[string]float64{
+		"statsig_evaluations_total{type=gate}":       2,
+		"statsig_evaluations_total{type=config}":     1,
+		"statsig_evaluations_total{type=experiment}": 1,
+		"statsig_evaluations_total{type=layer}":      1,
+		"statsig_event_flushes_total{success=true}":  1,
+		"statsig_config_sync_failures_total":         1,
+	}
+	for name, value := range expectedCounters {
+		if counters[name] != value {
+			t.Errorf("Expected %s to be %v, received %v", name, value, counters[name])
+		}
+	}
+	if counters["statsig_events_flushed_total{success=true}"] < 4 {
+		t.Errorf("Expected the flushed exposures to be counted, received %v", counters)
+	}
+	if histograms[MetricConfigSyncDuration] != 2 {
+		t.Errorf("Expected a duration for the initial and failed syncs, received %v", histograms)
+	}
+	if gauges["statsig_id_list_entries{list=list_1}"] != 2 || gauges["statsig_id_list_bytes{list=list_1}"] != float64(len(content)) {
+		t.Errorf("Expected the ID list size, received %v", gauges)
+	}
+}
+
+func TestMetricsHookDroppedEvents(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusBadRequest)
+	}))
+	defer testServer.Close()
+	var dropped float64
+	opt := &Options{
+		API: testServer.URL,
+		MetricsOptions: MetricsOptions{Hook: func(metric Metric) {
+			if metric.Name == MetricEventsDropped && metric.Labels["queue"] == "default" {
+				dropped += metric.Value
+			}
+		}},
+	}
+	logger := newLogger(newTransport("secret", opt), opt, newDiagnostics(opt))
+	defer logger.flush(true)
+	logger.logCustom(Event{EventName: "a", User: User{UserID: "123"}})
+	logger.logCustom(Event{EventName: "b", User: User{UserID: "123"}})
+	logger.sendEvents(logger.events)
+	if dropped != 2 {
+		t.Errorf("Expected the failed events to be counted as dropped, received %v", dropped)
+	}
+}
@@ -0,0 +1,48 @@
+package statsig
+
+import (
+	"strings"
+	"sync"
+)
+
+// Counts evaluations of specs keyed by a custom ID type the user did not provide, by ID type
+type missingUnitIDCounter struct {
+	counts map[string]int64
+	mu     sync.Mutex
+}
+
+func (m *missingUnitIDCounter) increment(idType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts == nil {
+		m.counts = make(map[string]int64)
+	}
+	m.counts[idType]++
+}
+
+func (m *missingUnitIDCounter) snapshot() map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int64, len(m.counts))
+	for idType, count := range m.counts {
+		counts[idType] = count
+	}
+	return counts
+}
+
+// Specs keyed by a custom ID the user lacks evaluate to their default value rather than
+// bucketing every such user on an empty unit ID
+func (e *evaluator) isMissingUnitID(user User, spec configSpec) bool {
+	if spec.IDType == "" || strings.ToLower(spec.IDType) == "userid" || getUnitID(user, spec.IDType) != "" {
+		return false
+	}
+	counter := &e.missingUnitIDs
+	if e.parent != nil {
+		counter = &e.parent.missingUnitIDs
+	}
+	counter.increment(spec.IDType)
+	if e.options != nil && e.options.OnMissingUnitID != nil {
+		e.options.OnMissingUnitID(spec.Name, spec.IDType, user)
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package statsig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// Each gate in test_data/operator_conformance.json has a single rule built from its conditions.
+// To cover a new operator, add a gate with the condition under test and the expected result per user.
+type conformanceFixture struct {
+	Gates []conformanceGate `json:"gates"`
+}
+
+type conformanceGate struct {
+	Name           string            `json:"name"`
+	Enabled        bool              `json:"enabled"`
+	PassPercentage float64           `json:"passPercentage"`
+	IDType         string            `json:"idType"`
+	Conditions     []configCondition `json:"conditions"`
+	Cases          []conformanceCase `json:"cases"`
+}
+
+type conformanceCase struct {
+	User     User   `json:"user"`
+	Expected bool   `json:"expected"`
+	RuleID   string `json:"rule_id"`
+}
+
+func (g conformanceGate) toSpec() configSpec {
+	return configSpec{
+		Name:    g.Name,
+		Type:    "feature_gate",
+		Salt:    g.Name + "_salt",
+		Enabled: g.Enabled,
+		IDType:  g.IDType,
+		Rules: []configRule{{
+			Name:           g.Name + "_rule",
+			ID:             g.Name + "_rule",
+			Salt:           g.Name + "_rule_salt",
+			PassPercentage: g.PassPercentage,
+			Conditions:     g.Conditions,
+			IDType:         g.IDType,
+		}},
+	}
+}
+
+func TestOperatorConformance(t *testing.T) {
+	bytes, err := os.ReadFile("test_data/operator_conformance.json")
+	if err != nil {
+		t.Fatalf("Could not read conformance fixture: %s", err.Error())
+	}
+	var fixture conformanceFixture
+	if err := json.Unmarshal(bytes, &fixture); err != nil {
+		t.Fatalf("Could not parse conformance fixture: %s", err.Error())
+	}
+
+	specs := downloadConfigSpecResponse{HasUpdates: true, Time: 1}
+	for _, gate := range fixture.Gates {
+		specs.FeatureGates = append(specs.FeatureGates, gate.toSpec())
+	}
+	bootstrap, _ := json.Marshal(specs)
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(bootstrap),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	for _, gate := range fixture.Gates {
+		for i, testCase := range gate.Cases {
+			t.Run(fmt.Sprintf("%s/%d", gate.Name, i), func(t *testing.T) {
+				res := c.evaluator.checkGate(context.Background(), testCase.User, gate.Name)
+				if res.FetchFromServer {
+					t.Fatalf("Expected the condition to be evaluated locally")
+				}
+				if res.Pass != testCase.Expected {
+					t.Errorf("Expected %t but received %t for user %+v", testCase.Expected, res.Pass, testCase.User)
+				}
+				expectedRuleID := testCase.RuleID
+				if expectedRuleID == "" && testCase.Expected {
+					expectedRuleID = gate.Name + "_rule"
+				} else if expectedRuleID == "" {
+					expectedRuleID = RuleIDDefault
+				}
+				if res.RuleID != expectedRuleID {
+					t.Errorf("Expected rule ID %s but received %s", expectedRuleID, res.RuleID)
+				}
+			})
+		}
+	}
+}
+
+func TestAttributePrecedence(t *testing.T) {
+	user := User{
+		UserID:            "a_user",
+		Email:             "top@statsig.com",
+		Custom:            map[string]interface{}{"email": "custom@statsig.com", "plan": "custom"},
+		PrivateAttributes: map[string]interface{}{"email": "private@statsig.com", "plan": "private"},
+	}
+	tests := []struct {
+		precedence AttributePrecedence
+		field      string
+		expected   interface{}
+	}{
+		{AttributePrecedencePrivateFirst, "email", "private@statsig.com"},
+		{AttributePrecedencePrivateFirst, "plan", "private"},
+		{AttributePrecedencePrivateFirst, "userID", "a_user"},
+		{AttributePrecedenceTopLevelFirst, "email", "top@statsig.com"},
+		{AttributePrecedenceTopLevelFirst, "plan", "custom"},
+		{AttributePrecedenceTopLevelFirst, "missing", nil},
+	}
+	for _, test := range tests {
+		if value := getFromUser(user, test.field, test.precedence); value != test.expected {
+			t.Errorf("Expected %v for %s with precedence %d, received %v", test.expected, test.field, test.precedence, value)
+		}
+	}
+
+	user.Email = ""
+	if value := getFromUser(user, "email", AttributePrecedenceTopLevelFirst); value != "custom@statsig.com" {
+		t.Errorf("Expected empty top-level fields to fall through, received %v", value)
+	}
+}
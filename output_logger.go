@@ -3,6 +3,7 @@ package statsig
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -13,11 +14,45 @@ const (
 	StatsigProcessSync       StatsigProcess = "Sync"
 )
 
+type logLevel int
+
+const (
+	logLevelDebug logLevel = iota
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+)
+
+// A structured field of an SDK log record, only emitted through OutputLoggerOptions.Logger or Slog
+type logAttr struct {
+	key   string
+	value interface{}
+}
+
+// Receives SDK logs at their level with structured fields, e.g. to adapt zap, zerolog or logrus
+type StructuredLogger interface {
+	Debug(msg string, fields map[string]interface{})
+	Info(msg string, fields map[string]interface{})
+	Warn(msg string, fields map[string]interface{})
+	Error(msg string, fields map[string]interface{})
+}
+
 type OutputLogger struct {
 	options OutputLoggerOptions
 }
 
 func (o *OutputLogger) Log(msg string, err error) {
+	level := logLevelInfo
+	if err != nil {
+		level = logLevelError
+	}
+	o.log(level, msg, err)
+}
+
+func (o *OutputLogger) log(level logLevel, msg string, err error) {
+	if o.isInitialized() && o.emit(level, msg, err, nil) {
+		return
+	}
 	if o.isInitialized() && o.options.LogCallback != nil {
 		o.options.LogCallback(msg, err)
 	} else {
@@ -41,7 +76,7 @@ func (o *OutputLogger) Debug(any interface{}) {
 }
 
 func (o *OutputLogger) LogStep(process StatsigProcess, msg string) {
-	if !o.isInitialized() || !o.options.EnableDebug {
+	if !o.isInitialized() {
 		return
 	}
 	if o.options.DisableInitDiagnostics && process == StatsigProcessInitialize {
@@ -50,6 +85,10 @@ func (o *OutputLogger) LogStep(process StatsigProcess, msg string) {
 	if o.options.DisableSyncDiagnostics && process == StatsigProcessSync {
 		return
 	}
+	// slog handlers decide whether debug records are emitted
+	if o.emit(logLevelDebug, msg, nil, []logAttr{{"process", string(process)}}) || !o.options.EnableDebug {
+		return
+	}
 	timestamp := time.Now().Format(time.RFC3339)
 	o.Log(fmt.Sprintf("[%s][Statsig] %s: %s\n", timestamp, process, msg), nil)
 }
@@ -57,12 +96,65 @@ func (o *OutputLogger) LogStep(process StatsigProcess, msg string) {
 func (o *OutputLogger) LogError(err interface{}) {
 	switch errTyped := err.(type) {
 	case string:
-		o.Log(errTyped, nil)
+		o.log(logLevelError, errTyped, nil)
 	case error:
-		o.Log("", errTyped)
+		o.log(logLevelError, "", errTyped)
+	default:
+		if !o.isInitialized() || !o.emit(logLevelError, fmt.Sprint(err), nil, nil) {
+			fmt.Print(err)
+		}
+	}
+}
+
+// Emits an SDK event, e.g. a config sync or event flush, with structured attributes.
+// Only loggers set with OutputLoggerOptions.Logger or Slog receive these.
+func (o *OutputLogger) logRecord(level logLevel, msg string, attrs ...logAttr) {
+	if o.isInitialized() {
+		o.emit(level, msg, nil, attrs)
+	}
+}
+
+// Whether records reach a structured logger, which also receives those only emitted by logRecord
+func (o *OutputLogger) isStructured() bool {
+	return o.isInitialized() && (o.options.Logger != nil || o.options.Slog != nil)
+}
+
+// Returns false if neither OutputLoggerOptions.Logger nor Slog is set
+func (o *OutputLogger) emit(level logLevel, msg string, err error, attrs []logAttr) bool {
+	if o.logToStructuredLogger(level, msg, err, attrs) {
+		return true
+	}
+	return o.logToSlog(level, msg, err, attrs)
+}
+
+func (o *OutputLogger) logToStructuredLogger(level logLevel, msg string, err error, attrs []logAttr) bool {
+	logger := o.options.Logger
+	if logger == nil {
+		return false
+	}
+	fields := make(map[string]interface{}, len(attrs)+2)
+	fields["sdk"] = "statsig"
+	for _, attr := range attrs {
+		fields[attr.key] = attr.value
+	}
+	msg = strings.TrimSpace(msg)
+	if err != nil {
+		fields["error"] = err.Error()
+		if msg == "" {
+			msg = "Statsig SDK error"
+		}
+	}
+	switch level {
+	case logLevelDebug:
+		logger.Debug(msg, fields)
+	case logLevelInfo:
+		logger.Info(msg, fields)
+	case logLevelWarn:
+		logger.Warn(msg, fields)
 	default:
-		fmt.Print(err)
+		logger.Error(msg, fields)
 	}
+	return true
 }
 
 func (o *OutputLogger) isInitialized() bool {
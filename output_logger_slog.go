@@ -0,0 +1,41 @@
+//go:build go1.21
+// +build go1.21
+
+package statsig
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// The logger accepted by OutputLoggerOptions.Slog
+type SlogLogger = *slog.Logger
+
+var slogLevels = map[logLevel]slog.Level{
+	logLevelDebug: slog.LevelDebug,
+	logLevelInfo:  slog.LevelInfo,
+	logLevelWarn:  slog.LevelWarn,
+	logLevelError: slog.LevelError,
+}
+
+func (o *OutputLogger) logToSlog(level logLevel, msg string, err error, attrs []logAttr) bool {
+	logger := o.options.Slog
+	if logger == nil {
+		return false
+	}
+	slogAttrs := make([]slog.Attr, 0, len(attrs)+2)
+	slogAttrs = append(slogAttrs, slog.String("sdk", "statsig"))
+	for _, attr := range attrs {
+		slogAttrs = append(slogAttrs, slog.Any(attr.key, attr.value))
+	}
+	msg = strings.TrimSpace(msg)
+	if err != nil {
+		slogAttrs = append(slogAttrs, slog.String("error", err.Error()))
+		if msg == "" {
+			msg = "Statsig SDK error"
+		}
+	}
+	logger.LogAttrs(context.Background(), slogLevels[level], msg, slogAttrs...)
+	return true
+}
@@ -0,0 +1,11 @@
+//go:build !go1.21
+// +build !go1.21
+
+package statsig
+
+// log/slog requires Go 1.21, OutputLoggerOptions.Slog is ignored on older versions
+type SlogLogger = interface{}
+
+func (o *OutputLogger) logToSlog(level logLevel, msg string, err error, attrs []logAttr) bool {
+	return false
+}
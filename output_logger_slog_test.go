@@ -0,0 +1,99 @@
+//go:build go1.21
+// +build go1.21
+
+package statsig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type lockedBuffer struct {
+	buffer bytes.Buffer
+	mu     sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *lockedBuffer) records() []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records := make([]map[string]interface{}, 0)
+	for _, line := range strings.Split(strings.TrimSpace(b.buffer.String()), "\n") {
+		var record map[string]interface{}
+		if json.Unmarshal([]byte(line), &record) == nil {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func findRecord(records []map[string]interface{}, msg string) map[string]interface{} {
+	for _, record := range records {
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestSlogOutputLogger(t *testing.T) {
+	dcs, _ := os.ReadFile("download_config_specs.json")
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write(dcs)
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		} else if strings.Contains(req.URL.Path, "log_event") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	output := &lockedBuffer{}
+	var callbackMessages int
+	outputLoggerOptions := OutputLoggerOptions{
+		Slog:        slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		LogCallback: func(message string, err error) { callbackMessages++ },
+	}
+	InitializeGlobalOutputLogger(outputLoggerOptions)
+	defer InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  outputLoggerOptions,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	c.LogEvent(Event{EventName: "purchase", User: User{UserID: "a_user"}})
+	Logger().LogError(errors.New("something failed"))
+	c.Shutdown()
+
+	records := output.records()
+	sync := findRecord(records, "Synced config specs")
+	if sync == nil || sync["level"] != "INFO" || sync["has_updates"] != true || sync["specs_added"] != float64(9) || sync["sdk"] != "statsig" {
+		t.Errorf("Expected a structured sync record, received %+v", sync)
+	}
+	flush := findRecord(records, "Flushed events")
+	if flush == nil || flush["level"] != "DEBUG" || flush["event_count"] == nil {
+		t.Errorf("Expected a structured flush record, received %+v", flush)
+	}
+	failure := findRecord(records, "Statsig SDK error")
+	if failure == nil || failure["level"] != "ERROR" || failure["error"] != "something failed" {
+		t.Errorf("Expected a structured error record, received %+v", failure)
+	}
+	if callbackMessages != 0 {
+		t.Errorf("Expected slog to take precedence over LogCallback, received %d messages", callbackMessages)
+	}
+}
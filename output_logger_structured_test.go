@@ -0,0 +1,107 @@
+package statsig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type structuredRecord struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+type recordingStructuredLogger struct {
+	records []structuredRecord
+	mu      sync.Mutex
+}
+
+func (l *recordingStructuredLogger) record(level string, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, structuredRecord{level: level, msg: msg, fields: fields})
+}
+
+func (l *recordingStructuredLogger) Debug(msg string, fields map[string]interface{}) {
+	l.record("debug", msg, fields)
+}
+func (l *recordingStructuredLogger) Info(msg string, fields map[string]interface{}) {
+	l.record("info", msg, fields)
+}
+func (l *recordingStructuredLogger) Warn(msg string, fields map[string]interface{}) {
+	l.record("warn", msg, fields)
+}
+func (l *recordingStructuredLogger) Error(msg string, fields map[string]interface{}) {
+	l.record("error", msg, fields)
+}
+
+func (l *recordingStructuredLogger) find(msg string) (structuredRecord, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var found structuredRecord
+	count := 0
+	for _, record := range l.records {
+		if record.msg == msg {
+			found = record
+			count++
+		}
+	}
+	return found, count
+}
+
+func TestStructuredOutputLogger(t *testing.T) {
+	dcs, _ := os.ReadFile("download_config_specs.json")
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write(dcs)
+		} else {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+	logger := &recordingStructuredLogger{}
+	var callbackMessages []string
+	options := &Options{
+		API:                testServer.URL,
+		ConfigSyncInterval: time.Hour,
+		IDListSyncInterval: time.Hour,
+		OutputLoggerOptions: OutputLoggerOptions{
+			Logger:      logger,
+			LogCallback: func(message string, err error) { callbackMessages = append(callbackMessages, message) },
+		},
+	}
+	InitializeGlobalOutputLogger(options.OutputLoggerOptions)
+	defer InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", options)
+	defer c.Shutdown()
+
+	if record, count := logger.find("Synced config specs"); count == 0 || record.level != "info" || record.fields["has_updates"] != true || record.fields["sdk"] != "statsig" {
+		t.Errorf("Expected sync stats as an info record with fields, received %+v", record)
+	}
+
+	Logger().LogError(errors.New("boom"))
+	if record, _ := logger.find("Statsig SDK error"); record.level != "error" || record.fields["error"] != "boom" {
+		t.Errorf("Expected errors as error records, received %+v", record)
+	}
+
+	// Exceptions caught by the error boundary are surfaced on every occurrence
+	for i := 0; i < 2; i++ {
+		c.errorBoundary.captureVoid(func() { panic(errors.New("internal failure")) })
+	}
+	record, count := logger.find("Statsig SDK exception")
+	if count != 2 || record.level != "error" || record.fields["error"] != "internal failure" {
+		t.Errorf("Expected each exception as an error record, received %d of %+v", count, record)
+	}
+	if stack, _ := record.fields["stack"].(string); !strings.Contains(stack, "goroutine") {
+		t.Errorf("Expected the exception to include its stack, received %v", record.fields["stack"])
+	}
+	if len(callbackMessages) != 0 {
+		t.Errorf("Expected the structured logger to take precedence over LogCallback, received %v", callbackMessages)
+	}
+}
@@ -75,3 +75,48 @@ func TestOverrides(t *testing.T) {
 		t.Errorf("Failed to get override value for a layer when in LocalMode")
 	}
 }
+
+func TestRemoveOverrides(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions(secret, &Options{
+		LocalMode: true,
+	})
+	defer c.Shutdown()
+
+	user := User{UserID: "123"}
+	value := map[string]interface{}{"test": 123}
+	override := func() {
+		c.OverrideGate("any_gate", true)
+		c.OverrideConfig("any_config", value)
+		c.OverrideLayer("any_layer", value)
+	}
+
+	override()
+	c.RemoveGateOverride("any_gate")
+	if c.CheckGate(user, "any_gate") {
+		t.Errorf("Expected the gate override to be removed")
+	}
+	if !reflect.DeepEqual(c.GetConfig(user, "any_config").Value, value) || !reflect.DeepEqual(c.GetLayer(user, "any_layer").Value, value) {
+		t.Errorf("Expected the other overrides to remain")
+	}
+
+	c.RemoveConfigOverride("any_config")
+	if len(c.GetConfig(user, "any_config").Value) != 0 {
+		t.Errorf("Expected the config override to be removed")
+	}
+	c.RemoveLayerOverride("any_layer")
+	if len(c.GetLayer(user, "any_layer").Value) != 0 {
+		t.Errorf("Expected the layer override to be removed")
+	}
+	c.RemoveGateOverride("never_overridden")
+
+	override()
+	c.ClearOverrides()
+	if c.CheckGate(user, "any_gate") || len(c.GetConfig(user, "any_config").Value) != 0 || len(c.GetLayer(user, "any_layer").Value) != 0 {
+		t.Errorf("Expected all overrides to be cleared")
+	}
+	override()
+	if !c.CheckGate(user, "any_gate") {
+		t.Errorf("Expected overrides to be settable after clearing")
+	}
+}
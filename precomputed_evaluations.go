@@ -0,0 +1,155 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A ClientInitializeResponse produced for a single user, e.g. by an edge service
+type precomputedEvaluations struct {
+	ClientInitializeResponse
+	HashUsed string `json:"hash_used"`
+}
+
+func parsePrecomputedEvaluations(user User, payload string) (*precomputedEvaluations, error) {
+	var evaluations precomputedEvaluations
+	if err := json.Unmarshal([]byte(payload), &evaluations); err != nil {
+		return nil, fmt.Errorf("Failed to parse precomputed evaluations: %s", err.Error())
+	}
+	if evaluatedUserID, ok := evaluations.EvaluatedKeys["userID"].(string); ok && evaluatedUserID != user.UserID {
+		return nil, fmt.Errorf("Precomputed evaluations were generated for userID %q, not %q", evaluatedUserID, user.UserID)
+	}
+	return &evaluations, nil
+}
+
+// Spec names are keyed the same way as GetClientInitializeResponse, by their base64 SHA-256 unless
+// the payload says otherwise
+func (p *precomputedEvaluations) key(name string) string {
+	switch p.HashUsed {
+	case "none":
+		return name
+	case "djb2":
+		return getDJB2Hash(name)
+	default:
+		return getHashBase64StringEncoding(name)
+	}
+}
+
+// Precomputed evaluations are matched on the unit IDs they were computed for
+func precomputedEvaluationsKey(user User) string {
+	customIDs := make([]string, 0, len(user.CustomIDs))
+	for idType, id := range user.CustomIDs {
+		customIDs = append(customIDs, idType+"="+id)
+	}
+	sort.Strings(customIDs)
+	return user.UserID + "|" + strings.Join(customIDs, "|")
+}
+
+func (e *evaluator) loadPrecomputedEvaluations(user User, payload string) error {
+	evaluations, err := parsePrecomputedEvaluations(user, payload)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.precomputed[precomputedEvaluationsKey(user)] = evaluations
+	return nil
+}
+
+func (e *evaluator) clearPrecomputedEvaluations(user User) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.precomputed, precomputedEvaluationsKey(user))
+}
+
+func (e *evaluator) getPrecomputedEvaluations(user User) (*precomputedEvaluations, bool) {
+	if e.parent != nil {
+		return e.parent.getPrecomputedEvaluations(user)
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if len(e.precomputed) == 0 {
+		return nil, false
+	}
+	evaluations, ok := e.precomputed[precomputedEvaluationsKey(user)]
+	return evaluations, ok
+}
+
+func precomputedIDType(isDeviceBased bool) string {
+	if isDeviceBased {
+		return "stableID"
+	}
+	return ""
+}
+
+func (e *evaluator) getPrecomputedGate(user User, name string) (*evalResult, bool) {
+	evaluations, ok := e.getPrecomputedEvaluations(user)
+	if !ok {
+		return nil, false
+	}
+	gate, ok := evaluations.FeatureGates[evaluations.key(name)]
+	if !ok {
+		return nil, false
+	}
+	return &evalResult{
+		Pass:               gate.Value,
+		RuleID:             gate.RuleID,
+		SecondaryExposures: gate.SecondaryExposures,
+		EvaluationDetails:  e.createEvaluationDetails(reasonPrecomputed),
+	}, true
+}
+
+func (e *evaluator) getPrecomputedConfig(user User, name string) (*evalResult, bool) {
+	evaluations, ok := e.getPrecomputedEvaluations(user)
+	if !ok {
+		return nil, false
+	}
+	config, ok := evaluations.DynamicConfigs[evaluations.key(name)]
+	if !ok {
+		return nil, false
+	}
+	evalDetails := e.createEvaluationDetails(reasonPrecomputed)
+	return &evalResult{
+		Pass:               true,
+		ConfigValue:        *NewConfig(name, config.Value, config.RuleID, config.Group, evalDetails),
+		RuleID:             config.RuleID,
+		GroupName:          config.Group,
+		SecondaryExposures: config.SecondaryExposures,
+		EvaluationDetails:  evalDetails,
+		IsExperimentGroup:  config.IsUserInExperiment,
+		IDType:             precomputedIDType(config.IsDeviceBased),
+	}, true
+}
+
+func (e *evaluator) getPrecomputedLayer(user User, name string) (*evalResult, bool) {
+	evaluations, ok := e.getPrecomputedEvaluations(user)
+	if !ok {
+		return nil, false
+	}
+	layer, ok := evaluations.LayerConfigs[evaluations.key(name)]
+	if !ok {
+		return nil, false
+	}
+	explicitParameters := make(map[string]bool)
+	if layer.ExplicitParameters != nil {
+		for _, parameter := range *layer.ExplicitParameters {
+			explicitParameters[parameter] = true
+		}
+	}
+	evalDetails := e.createEvaluationDetails(reasonPrecomputed)
+	return &evalResult{
+		Pass:                          true,
+		ConfigValue:                   *NewConfig(name, layer.Value, layer.RuleID, layer.Group, evalDetails),
+		RuleID:                        layer.RuleID,
+		GroupName:                     layer.Group,
+		SecondaryExposures:            layer.SecondaryExposures,
+		UndelegatedSecondaryExposures: layer.UndelegatedSecondaryExposures,
+		ConfigDelegate:                layer.AllocatedExperimentName,
+		ExplicitParameters:            explicitParameters,
+		EvaluationDetails:             evalDetails,
+		IsExperimentGroup:             layer.IsUserInExperiment,
+		IDType:                        precomputedIDType(layer.IsDeviceBased),
+	}, true
+}
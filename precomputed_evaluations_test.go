@@ -0,0 +1,81 @@
+package statsig
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestPrecomputedEvaluations(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	user := User{UserID: "a_user", Email: "a_user@statsig.com"}
+
+	// Stands in for the edge service producing the payload
+	edge := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(bytes),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer edge.Shutdown()
+	payload, _ := json.Marshal(edge.GetClientInitializeResponse(user, ""))
+
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	if err := c.LoadPrecomputedEvaluations(user, string(payload)); err != nil {
+		t.Fatalf("Expected the payload to load, received %s", err.Error())
+	}
+
+	t.Run("serves the user from the payload", func(t *testing.T) {
+		c.logger.events = make([]interface{}, 0)
+		if !c.CheckGate(user, "on_for_statsig_email") {
+			t.Errorf("Expected the precomputed gate value")
+		}
+		config := c.GetConfig(user, "test_config")
+		if config.GetNumber("number", 0) != 7 || config.RuleID != "1kNmlB23wylPFZi1M0Divl" {
+			t.Errorf("Expected the precomputed config value, received %+v", config)
+		}
+		layer := c.GetLayer(user, "c_layer_with_holdout")
+		if layer.RuleID != "7d2E854TtGmfETdmJFip1L" {
+			t.Errorf("Expected the precomputed layer, received %+v", layer)
+		}
+		if len(c.logger.events) != 2 {
+			t.Fatalf("Expected 2 exposures, received %d", len(c.logger.events))
+		}
+		gateExposure := c.logger.events[0].(ExposureEvent)
+		if gateExposure.Metadata["reason"] != "Precomputed" || gateExposure.Metadata["gateValue"] != "true" {
+			t.Errorf("Unexpected gate exposure %+v", gateExposure.Metadata)
+		}
+		configExposure := c.logger.events[1].(ExposureEvent)
+		if configExposure.Metadata["reason"] != "Precomputed" || configExposure.Metadata["ruleID"] != "1kNmlB23wylPFZi1M0Divl" {
+			t.Errorf("Unexpected config exposure %+v", configExposure.Metadata)
+		}
+	})
+
+	t.Run("does not serve other users from the payload", func(t *testing.T) {
+		other := User{UserID: "other_user", Email: "a_user@statsig.com"}
+		if c.CheckGate(other, "on_for_statsig_email") {
+			t.Errorf("Expected other users to be evaluated from the ruleset")
+		}
+	})
+
+	t.Run("rejects payloads generated for another user", func(t *testing.T) {
+		if err := c.LoadPrecomputedEvaluations(User{UserID: "other_user"}, string(payload)); err == nil {
+			t.Errorf("Expected a userID mismatch error")
+		}
+		if err := c.LoadPrecomputedEvaluations(user, "{"); err == nil {
+			t.Errorf("Expected a parse error")
+		}
+	})
+
+	t.Run("clears the payload", func(t *testing.T) {
+		c.ClearPrecomputedEvaluations(user)
+		if c.CheckGate(user, "on_for_statsig_email") {
+			t.Errorf("Expected the precomputed evaluations to be cleared")
+		}
+	})
+}
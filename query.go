@@ -0,0 +1,245 @@
+package statsig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The outcome of Client.Query, with the user it was evaluated for
+type QueryResult struct {
+	Kind        string                 // gate, config, experiment or layer
+	Name        string                 // Name of the evaluated spec
+	User        User                   // The user described by the query
+	Pass        bool                   // Whether a gate passed, or a config's rule passed its pass percentage
+	Value       map[string]interface{} // Value of a config, experiment or layer. Nil for gates
+	RuleID      string                 // ID of the rule the user matched, or "default" or "disabled"
+	GroupName   string                 // Experiment group the user was assigned to, if any
+	Reason      string                 // Source of the evaluated spec, e.g. "Network" or "LocalOverride"
+	Explanation []string               // How the rules were evaluated, one step per line
+}
+
+const (
+	queryKindGate       = "gate"
+	queryKindConfig     = "config"
+	queryKindExperiment = "experiment"
+	queryKindLayer      = "layer"
+)
+
+// Parses a query such as `gate:new_checkout user.userID=42 user.country=DE user.custom.plan=pro`.
+// Exactly one of gate:, config:, experiment: or layer: names the spec to evaluate. User fields are
+// userID, email, ip, userAgent, country, locale and appVersion, and custom.<key>,
+// customIDs.<idType>, privateAttributes.<key> and environment.<key> set map entries. Values
+// containing spaces can be double quoted, and custom values are strings.
+func parseQuery(query string) (kind string, name string, user User, err error) {
+	tokens, err := splitQuery(query)
+	if err != nil {
+		return "", "", User{}, err
+	}
+	for _, token := range tokens {
+		if key, value, ok := cutQueryToken(token, "="); ok {
+			if err := setQueryUserField(&user, key, value); err != nil {
+				return "", "", User{}, err
+			}
+			continue
+		}
+		tokenKind, tokenName, ok := cutQueryToken(token, ":")
+		switch tokenKind = strings.ToLower(tokenKind); {
+		case !ok || tokenName == "":
+			return "", "", User{}, fmt.Errorf("expected kind:name or user.field=value, got %q", token)
+		case tokenKind != queryKindGate && tokenKind != queryKindConfig && tokenKind != queryKindExperiment && tokenKind != queryKindLayer:
+			return "", "", User{}, fmt.Errorf("unknown kind %q, expected gate, config, experiment or layer", tokenKind)
+		case kind != "":
+			return "", "", User{}, errors.New("a query evaluates a single gate, config, experiment or layer")
+		}
+		kind, name = tokenKind, tokenName
+	}
+	if kind == "" {
+		return "", "", User{}, errors.New("missing the gate, config, experiment or layer to evaluate")
+	}
+	return kind, name, user, nil
+}
+
+// Splits on spaces outside double quotes, removing the quotes
+func splitQuery(query string) ([]string, error) {
+	var tokens []string
+	var token strings.Builder
+	inToken, inQuotes := false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inToken = true
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if inToken {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				inToken = false
+			}
+		default:
+			token.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("unterminated quote")
+	}
+	if inToken {
+		tokens = append(tokens, token.String())
+	}
+	return tokens, nil
+}
+
+func cutQueryToken(token string, sep string) (string, string, bool) {
+	i := strings.Index(token, sep)
+	if i < 0 {
+		return "", "", false
+	}
+	return token[:i], token[i+len(sep):], true
+}
+
+func setQueryUserField(user *User, key string, value string) error {
+	field := strings.TrimPrefix(key, "user.")
+	if field == key {
+		return fmt.Errorf("unknown field %q, user fields start with \"user.\"", key)
+	}
+	if mapName, mapKey, ok := cutQueryToken(field, "."); ok && mapKey != "" {
+		switch strings.ToLower(mapName) {
+		case "custom":
+			if user.Custom == nil {
+				user.Custom = make(map[string]interface{})
+			}
+			user.Custom[mapKey] = value
+		case "customids":
+			if user.CustomIDs == nil {
+				user.CustomIDs = make(map[string]string)
+			}
+			user.CustomIDs[mapKey] = value
+		case "privateattributes":
+			if user.PrivateAttributes == nil {
+				user.PrivateAttributes = make(map[string]interface{})
+			}
+			user.PrivateAttributes[mapKey] = value
+		case "environment", "statsigenvironment":
+			if user.StatsigEnvironment == nil {
+				user.StatsigEnvironment = make(map[string]string)
+			}
+			user.StatsigEnvironment[mapKey] = value
+		default:
+			return fmt.Errorf("unknown user field %q", key)
+		}
+		return nil
+	}
+	switch strings.ToLower(field) {
+	case "userid", "id":
+		user.UserID = value
+	case "email":
+		user.Email = value
+	case "ip", "ipaddress":
+		user.IpAddress = value
+	case "useragent":
+		user.UserAgent = value
+	case "country":
+		user.Country = value
+	case "locale":
+		user.Locale = value
+	case "appversion":
+		user.AppVersion = value
+	default:
+		return fmt.Errorf("unknown user field %q", key)
+	}
+	return nil
+}
+
+// Sticky experiment assignments are neither read nor saved, so a query evaluates the current rules
+func (e *evaluator) evalQuery(user User, kind string, name string) *evalResult {
+	switch kind {
+	case queryKindGate:
+		return e.evalGate(user, name, 0)
+	case queryKindLayer:
+		return e.evalLayer(user, name, 0)
+	}
+	// evalConfig without persisted values deletes the user's sticky assignment
+	config, hasConfig := e.store.getDynamicConfig(name)
+	if _, hasOverride := e.getConfigOverride(name); hasConfig && !hasOverride && e.inTenant(name) {
+		if precomputed, ok := e.getPrecomputedConfig(user, name); ok {
+			return precomputed
+		}
+		return e.eval(user, config, 1)
+	}
+	return e.evalConfig(user, name, nil, 0)
+}
+
+func (e *evaluator) explainQuery(user User, kind string, name string, res *evalResult) []string {
+	var spec configSpec
+	var hasSpec bool
+	switch kind {
+	case queryKindGate:
+		spec, hasSpec = e.store.getGate(name)
+	case queryKindLayer:
+		spec, hasSpec = e.store.getLayerConfig(name)
+	default:
+		spec, hasSpec = e.store.getDynamicConfig(name)
+	}
+	reason := reasonUnrecognized
+	if res.EvaluationDetails != nil {
+		reason = res.EvaluationDetails.reason
+	}
+	switch {
+	case reason == reasonLocalOverride:
+		return []string{fmt.Sprintf("%s %s is overridden locally", kind, name)}
+	case reason == reasonPrecomputed:
+		return []string{fmt.Sprintf("%s %s was precomputed for this user", kind, name)}
+	case !hasSpec || !e.inTenant(name):
+		return []string{fmt.Sprintf("%s %s is not in the ruleset", kind, name)}
+	case !spec.Enabled:
+		return []string{fmt.Sprintf("%s %s is disabled", kind, name)}
+	case e.isMissingUnitID(user, spec):
+		return []string{fmt.Sprintf("the user has no %s, which %s %s is evaluated on", spec.IDType, kind, name)}
+	}
+	var lines []string
+	for _, rule := range spec.Rules {
+		if !e.evalRule(user, rule, 1).Pass {
+			lines = append(lines, fmt.Sprintf("rule %s: conditions not met", describeQueryRule(rule)))
+			for _, cond := range rule.Conditions {
+				if !e.evalCondition(user, cond, 2).Pass {
+					lines = append(lines, "  failed: "+describeQueryCondition(cond))
+				}
+			}
+			continue
+		}
+		if res.ConfigDelegate != "" {
+			return append(lines, fmt.Sprintf("rule %s: matched, delegated to experiment %s", describeQueryRule(rule), res.ConfigDelegate))
+		}
+		if !e.evalPassPercent(user, rule, spec) {
+			return append(lines, fmt.Sprintf("rule %s: matched, but the user is outside its %g%% pass percentage", describeQueryRule(rule), rule.PassPercentage))
+		}
+		line := fmt.Sprintf("rule %s: matched, the user is within its %g%% pass percentage", describeQueryRule(rule), rule.PassPercentage)
+		if rule.GroupName != "" {
+			line += ", group " + rule.GroupName
+		}
+		return append(lines, line)
+	}
+	return append(lines, "no rule matched, the default value is served")
+}
+
+func describeQueryRule(rule configRule) string {
+	if rule.Name == "" || rule.Name == rule.ID {
+		return rule.ID
+	}
+	return fmt.Sprintf("%s (%s)", rule.Name, rule.ID)
+}
+
+func describeQueryCondition(cond configCondition) string {
+	parts := []string{cond.Type}
+	if cond.Field != "" {
+		parts = append(parts, cond.Field)
+	}
+	if cond.Operator != "" {
+		parts = append(parts, cond.Operator)
+	}
+	if cond.TargetValue != nil {
+		parts = append(parts, fmt.Sprint(cond.TargetValue))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,91 @@
+package statsig
+
+import (
+	"strings"
+	"testing"
+)
+
+const querySpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [
+		{"name": "new_checkout", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "US users", "id": "us_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+				"conditions": [{"type": "user_field", "field": "country", "operator": "any", "targetValue": ["US"]}]},
+			{"name": "Pro plan", "id": "pro_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+				"conditions": [{"type": "user_field", "field": "plan", "operator": "any", "targetValue": ["pro"]}]},
+			{"name": "Holdout", "id": "holdout_rule", "salt": "s", "passPercentage": 0, "returnValue": true,
+				"conditions": [{"type": "public", "operator": null, "targetValue": null}]}]}
+	],
+	"dynamic_configs": [
+		{"name": "pricing", "type": "dynamic_config", "salt": "s", "enabled": true, "defaultValue": {"price": 10}, "rules": [
+			{"name": "German users", "id": "de_rule", "salt": "s", "passPercentage": 100, "returnValue": {"price": 12},
+				"conditions": [{"type": "user_field", "field": "country", "operator": "any", "targetValue": ["DE"]}]}]}
+	],
+	"layer_configs": []
+}`
+
+func TestQuery(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      querySpecs,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	result, err := c.Query(`gate:new_checkout user.userID=42 user.country=DE user.custom.plan=pro`)
+	if err != nil {
+		t.Fatalf("Expected the query to succeed, received %s", err)
+	}
+	if !result.Pass || result.RuleID != "pro_rule" || result.Kind != "gate" || result.Name != "new_checkout" {
+		t.Errorf("Expected the gate to pass the pro rule, received %+v", result)
+	}
+	if result.User.UserID != "42" || result.User.Country != "DE" || result.User.Custom["plan"] != "pro" {
+		t.Errorf("Expected the query to describe the user, received %+v", result.User)
+	}
+	if result.Reason != string(reasonBootstrap) {
+		t.Errorf("Expected the reason to be Bootstrap, received %s", result.Reason)
+	}
+	explanation := strings.Join(result.Explanation, "\n")
+	if !strings.Contains(explanation, "US users (us_rule): conditions not met") ||
+		!strings.Contains(explanation, "failed: user_field country any [US]") ||
+		!strings.Contains(explanation, "Pro plan (pro_rule): matched") {
+		t.Errorf("Expected the explanation to walk the rules, received\n%s", explanation)
+	}
+
+	result, _ = c.Query(`gate:new_checkout user.userID=42 user.country=FR`)
+	if result.Pass || result.RuleID != "holdout_rule" || !strings.Contains(strings.Join(result.Explanation, "\n"), "outside its 0% pass percentage") {
+		t.Errorf("Expected the user to fall into the holdout, received %+v", result)
+	}
+
+	result, _ = c.Query(`config:pricing user.customIDs.deviceID=d1 "user.country=DE"`)
+	if result.Value["price"] != float64(12) || result.RuleID != "de_rule" {
+		t.Errorf("Expected the German price, received %+v", result)
+	}
+
+	c.OverrideGate("new_checkout", false)
+	result, _ = c.Query(`gate:new_checkout user.userID=42 user.country=US`)
+	if result.Pass || result.Reason != string(reasonLocalOverride) || len(result.Explanation) != 1 {
+		t.Errorf("Expected the override to be explained, received %+v", result)
+	}
+
+	if len(c.logger.events) != 0 {
+		t.Errorf("Expected queries not to log exposures, received %d events", len(c.logger.events))
+	}
+
+	for _, query := range []string{
+		``,
+		`user.userID=42`,
+		`gate:a config:b user.userID=42`,
+		`flag:new_checkout user.userID=42`,
+		`gate:new_checkout user.userID=42 user.height=2`,
+		`gate:new_checkout country=DE user.userID=42`,
+		`gate:new_checkout user.country=DE`,
+		`gate:new_checkout user.userID="42`,
+	} {
+		if _, err := c.Query(query); err == nil {
+			t.Errorf("Expected %q to be rejected", query)
+		}
+	}
+}
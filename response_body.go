@@ -0,0 +1,128 @@
+package statsig
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Caps decompressed response bodies unless TransportOptions.MaxResponseBytes is set
+const defaultMaxResponseBytes = 256 << 20
+
+// Returned when a response body exceeds TransportOptions.MaxResponseBytes once decompressed
+type ResponseTooLargeError struct {
+	URL   string
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("Response from %s exceeded the limit of %d bytes", e.URL, e.Limit)
+}
+
+// Returned when a response is not JSON, e.g. an HTML error page from a proxy
+type UnexpectedContentTypeError struct {
+	URL         string
+	ContentType string
+}
+
+func (e *UnexpectedContentTypeError) Error() string {
+	return fmt.Sprintf("Unexpected content type %q in response from %s", e.ContentType, e.URL)
+}
+
+// Responses without a content type, or sniffed as plain text by test servers, are accepted
+func (transport *transport) validateContentType(response *http.Response) error {
+	contentType := response.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "text/plain" || mediaType == "application/octet-stream") {
+		return nil
+	}
+	return &UnexpectedContentTypeError{URL: transport.requestURL(response), ContentType: contentType}
+}
+
+// Decompresses the body per its Content-Encoding and caps how much of it can be read.
+// Bodies already decompressed by net/http are only capped.
+func (transport *transport) responseBody(response *http.Response) (io.Reader, error) {
+	var body io.Reader = response.Body
+	encoding := strings.ToLower(strings.TrimSpace(response.Header.Get("Content-Encoding")))
+	if response.Uncompressed {
+		encoding = ""
+	}
+	switch encoding {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decompress gzip response: %s", err.Error())
+		}
+		body = reader
+	case "deflate":
+		reader, err := newDeflateReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decompress deflate response: %s", err.Error())
+		}
+		body = reader
+	default:
+		return nil, fmt.Errorf("Unsupported content encoding %q in response from %s", encoding, transport.requestURL(response))
+	}
+	limit := transport.options.TransportOptions.MaxResponseBytes
+	if limit <= 0 {
+		limit = defaultMaxResponseBytes
+	}
+	return &cappedReader{reader: body, remaining: limit, err: &ResponseTooLargeError{URL: transport.requestURL(response), Limit: limit}}, nil
+}
+
+// Servers disagree on whether deflate bodies carry the zlib wrapper, so accept both
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// The CDN path contains the SDK key, which must not end up in logged errors
+func (transport *transport) requestURL(response *http.Response) string {
+	if response.Request == nil || response.Request.URL == nil {
+		return ""
+	}
+	u := *response.Request.URL
+	u.RawQuery = ""
+	return strings.Replace(u.String(), transport.sdkKey, "<sdk key>", -1)
+}
+
+// Fails with err instead of returning more than remaining bytes
+type cappedReader struct {
+	reader    io.Reader
+	remaining int64
+	err       error
+}
+
+func (r *cappedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, r.err
+	}
+	// Read one byte past the limit to tell a body of exactly the limit from a larger one
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), r.err
+	}
+	return n, err
+}
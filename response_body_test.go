@@ -0,0 +1,91 @@
+package statsig
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseBodyHandling(t *testing.T) {
+	compress := func(encoding string, body string) []byte {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		}
+		_, _ = w.Write([]byte(body))
+		_ = w.Close()
+		return buf.Bytes()
+	}
+	var contentType, contentEncoding, acceptEncoding string
+	var body []byte
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		acceptEncoding = req.Header.Get("Accept-Encoding")
+		if contentType != "" {
+			res.Header().Set("Content-Type", contentType)
+		}
+		if contentEncoding != "" {
+			res.Header().Set("Content-Encoding", contentEncoding)
+		}
+		_, _ = res.Write(body)
+	}))
+	defer testServer.Close()
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	tr := newTransport("secret-key", &Options{API: testServer.URL, TransportOptions: TransportOptions{MaxResponseBytes: 64}})
+
+	for _, encoding := range []string{"gzip", "deflate", "raw-deflate"} {
+		contentType, contentEncoding, body = "application/json", strings.TrimPrefix(encoding, "raw-"), compress(encoding, `{"name":"test"}`)
+		var out ServerResponse
+		if _, err := tr.post("/123", nil, &out, RequestOptions{}); err != nil || out.Name != "test" {
+			t.Errorf("Expected the %s response to be decoded, received %+v, %v", encoding, out, err)
+		}
+	}
+	if acceptEncoding != "gzip, deflate" {
+		t.Errorf("Expected gzip and deflate to be accepted, received %s", acceptEncoding)
+	}
+
+	contentType, contentEncoding, body = "text/html; charset=utf-8", "", []byte("<html>Bad gateway</html>")
+	var out ServerResponse
+	_, err := tr.post("/123", nil, &out, RequestOptions{})
+	var contentTypeErr *UnexpectedContentTypeError
+	if !errors.As(err, &contentTypeErr) || contentTypeErr.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("Expected an UnexpectedContentTypeError, received %v", err)
+	}
+
+	for _, encoding := range []string{"", "gzip"} {
+		contentType, contentEncoding, body = "application/json", encoding, []byte(`{"name":"`+strings.Repeat("a", 100)+`"}`)
+		if encoding != "" {
+			// Compresses to well under the limit, so only the decompressed size trips it
+			body = compress(encoding, string(body))
+		}
+		_, err = tr.post("/123", nil, &out, RequestOptions{})
+		var tooLargeErr *ResponseTooLargeError
+		if !errors.As(err, &tooLargeErr) || tooLargeErr.Limit != 64 {
+			t.Errorf("Expected a ResponseTooLargeError for encoding %q, received %v", encoding, err)
+		}
+	}
+
+	contentType, contentEncoding, body = "", "", []byte(`{"name":"`+strings.Repeat("a", 53)+`"}`)
+	if _, err = tr.post("/123", nil, &out, RequestOptions{}); err != nil {
+		t.Errorf("Expected a body of exactly the limit to be read, received %v", err)
+	}
+
+	contentType, body = "text/html", []byte("<html></html>")
+	_, err = tr.download_config_specs(context.Background(), 0, "", &out)
+	if err == nil || strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("Expected an error without the SDK key, received %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package statsig
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRetryBaseBackoff = time.Second
+	defaultRetryMaxBackoff  = 30 * time.Second
+)
+
+// Retries of config spec syncs, ID list syncs and event flushes. Until MaxAttempts is set, event
+// flushes are retried 5 times on retryable status codes, waiting ten times longer each time, and
+// syncs are not retried.
+type RetryOptions struct {
+	MaxAttempts          int           // Attempts per request, including the first. Network errors are retried as well as RetryableStatusCodes
+	BaseBackoff          time.Duration // Wait before the first retry, doubled for each one after. Defaults to one second
+	MaxBackoff           time.Duration // Defaults to 30 seconds
+	Jitter               float64       // Fraction of each wait that is randomized, from 0 to 1, so instances don't retry in lockstep
+	RetryableStatusCodes []int         // Defaults to 408, 500, 502, 503, 504, 522, 524 and 599
+}
+
+type retryPolicy struct {
+	retries       int
+	backoff       time.Duration
+	multiplier    int
+	maxBackoff    time.Duration
+	jitter        float64
+	statusCodes   map[int]bool // retryableStatusCode is used when nil
+	networkErrors bool
+}
+
+// Requests opting into Options.RetryOptions use it once MaxAttempts is set, others keep their own retries
+func (transport *transport) retryPolicy(options RequestOptions) retryPolicy {
+	retryOptions := transport.options.RetryOptions
+	if !options.useRetryOptions || retryOptions.MaxAttempts <= 0 {
+		return retryPolicy{retries: options.retries, backoff: options.backoff, multiplier: backoffMultiplier}
+	}
+	policy := retryPolicy{
+		retries:       retryOptions.MaxAttempts - 1,
+		backoff:       retryOptions.BaseBackoff,
+		multiplier:    2,
+		maxBackoff:    retryOptions.MaxBackoff,
+		jitter:        retryOptions.Jitter,
+		networkErrors: true,
+	}
+	if policy.backoff <= 0 {
+		policy.backoff = defaultRetryBaseBackoff
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = defaultRetryMaxBackoff
+	}
+	if policy.jitter < 0 {
+		policy.jitter = 0
+	} else if policy.jitter > 1 {
+		policy.jitter = 1
+	}
+	if retryOptions.RetryableStatusCodes != nil {
+		policy.statusCodes = make(map[int]bool, len(retryOptions.RetryableStatusCodes))
+		for _, code := range retryOptions.RetryableStatusCodes {
+			policy.statusCodes[code] = true
+		}
+	}
+	return policy
+}
+
+func (p retryPolicy) shouldRetry(ctx context.Context, response *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if response == nil {
+		return err != nil && p.networkErrors
+	}
+	if p.statusCodes != nil {
+		return p.statusCodes[response.StatusCode]
+	}
+	return retryableStatusCode(response.StatusCode)
+}
+
+// The wait before the given retry, counted from 0
+func (p retryPolicy) backoffFor(retry int) time.Duration {
+	backoff := p.backoff
+	for i := 0; i < retry && (p.maxBackoff <= 0 || backoff < p.maxBackoff); i++ {
+		backoff *= time.Duration(p.multiplier)
+	}
+	if p.maxBackoff > 0 && backoff > p.maxBackoff {
+		backoff = p.maxBackoff
+	}
+	if p.jitter > 0 {
+		backoff -= time.Duration(rand.Float64() * p.jitter * float64(backoff))
+	}
+	return backoff
+}
+
+// Returns false if ctx is done before the backoff has passed
+func (p retryPolicy) wait(ctx context.Context, retry int) bool {
+	timer := time.NewTimer(p.backoffFor(retry))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,118 @@
+package statsig
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryOptions(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var failures, attempts int32
+	var failWith int32 = http.StatusServiceUnavailable
+	var emptyBodies int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "log_event") {
+			if body, _ := io.ReadAll(req.Body); len(body) == 0 {
+				atomic.AddInt32(&emptyBodies, 1)
+			}
+		}
+		atomic.AddInt32(&attempts, 1)
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			if atomic.LoadInt32(&failWith) == 0 {
+				// Drops the connection, failing the request with a network error
+				conn, _, _ := res.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			res.WriteHeader(int(atomic.LoadInt32(&failWith)))
+			return
+		}
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write(specs)
+			return
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	newRetryClient := func(retryOptions RetryOptions) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			RetryOptions:         retryOptions,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	fastRetries := RetryOptions{MaxAttempts: 3, BaseBackoff: time.Millisecond}
+
+	t.Run("syncs are not retried by default", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 1)
+		c := newRetryClient(RetryOptions{})
+		defer c.Shutdown()
+		if c.CheckGate(User{UserID: "123"}, "always_on_gate") {
+			t.Errorf("Expected initialization to fail without retries")
+		}
+	})
+
+	for _, failure := range []int32{http.StatusServiceUnavailable, 0} {
+		atomic.StoreInt32(&failWith, failure)
+		atomic.StoreInt32(&failures, 2)
+		c := newRetryClient(fastRetries)
+		if !c.CheckGate(User{UserID: "123"}, "always_on_gate") {
+			t.Errorf("Expected the config spec sync to be retried after failure %d", failure)
+		}
+		c.Shutdown()
+	}
+
+	t.Run("retries only the configured status codes", func(t *testing.T) {
+		tr := newTransport("secret-key", &Options{API: testServer.URL, RetryOptions: RetryOptions{
+			MaxAttempts: 3, BaseBackoff: time.Millisecond, RetryableStatusCodes: []int{http.StatusTooManyRequests},
+		}})
+		atomic.StoreInt32(&failWith, http.StatusTooManyRequests)
+		atomic.StoreInt32(&failures, 2)
+		if _, err := tr.get_id_lists(nil); err != nil {
+			t.Errorf("Expected 429 to be retried, received %v", err)
+		}
+		atomic.StoreInt32(&failWith, http.StatusServiceUnavailable)
+		atomic.StoreInt32(&failures, 2)
+		atomic.StoreInt32(&attempts, 0)
+		if _, err := tr.get_id_lists(nil); err == nil || atomic.LoadInt32(&attempts) != 1 {
+			t.Errorf("Expected 503 not to be retried, received %v after %d attempts", err, attempts)
+		}
+	})
+
+	t.Run("gives up after MaxAttempts and resends the body", func(t *testing.T) {
+		tr := newTransport("secret-key", &Options{API: testServer.URL, RetryOptions: fastRetries})
+		atomic.StoreInt32(&failures, 5)
+		atomic.StoreInt32(&attempts, 0)
+		atomic.StoreInt32(&emptyBodies, 0)
+		l := newLogger(tr, &Options{}, newDiagnostics(&Options{}))
+		defer l.flush(true)
+		if err := l.postEvents([]interface{}{Event{EventName: "a"}}, newEventBatchID()); err == nil {
+			t.Errorf("Expected the flush to fail after MaxAttempts")
+		}
+		if atomic.LoadInt32(&attempts) != 3 || atomic.LoadInt32(&emptyBodies) != 0 {
+			t.Errorf("Expected 3 attempts with the events in each, received %d attempts and %d empty bodies", attempts, emptyBodies)
+		}
+	})
+
+	t.Run("backs off exponentially with jitter up to MaxBackoff", func(t *testing.T) {
+		tr := newTransport("secret-key", &Options{RetryOptions: RetryOptions{
+			MaxAttempts: 10, BaseBackoff: time.Second, MaxBackoff: 5 * time.Second, Jitter: 0.5,
+		}})
+		policy := tr.retryPolicy(RequestOptions{useRetryOptions: true})
+		for retry, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+			if backoff := policy.backoffFor(retry); backoff > expected || backoff < expected/2 {
+				t.Errorf("Expected retry %d to wait between %v and %v, received %v", retry, expected/2, expected, backoff)
+			}
+		}
+		if legacy := tr.retryPolicy(RequestOptions{retries: maxRetries}); legacy.retries != maxRetries || legacy.networkErrors {
+			t.Errorf("Expected requests not using RetryOptions to keep their own retries, received %+v", legacy)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package statsig
+
+import (
+	"time"
+)
+
+const defaultMaxRulesetFreezeDuration = 24 * time.Hour
+
+// The state of a ruleset freeze started with FreezeRuleset
+type RulesetFreezeStatus struct {
+	Frozen          bool
+	FrozenAt        time.Time
+	ExpiresAt       time.Time // The freeze is lifted automatically after Options.MaxRulesetFreezeDuration
+	SkippedSyncs    int       // Ruleset updates received but not applied while frozen
+	PendingSyncTime int64     // The time of the latest ruleset waiting to be applied, 0 if none
+}
+
+type rulesetFreeze struct {
+	frozen       bool
+	frozenAt     time.Time
+	maxDuration  time.Duration
+	expires      *time.Timer
+	pendingSpecs *downloadConfigSpecResponse
+	skippedSyncs int
+}
+
+func (s *store) freezeRuleset() {
+	maxDuration := s.options.MaxRulesetFreezeDuration
+	if maxDuration <= 0 {
+		maxDuration = defaultMaxRulesetFreezeDuration
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.freeze.frozen {
+		return
+	}
+	s.freeze = rulesetFreeze{frozen: true, frozenAt: time.Now(), maxDuration: maxDuration}
+	var expires *time.Timer
+	expires = time.AfterFunc(maxDuration, func() {
+		s.mu.RLock()
+		current := s.freeze.expires == expires
+		s.mu.RUnlock()
+		if current {
+			Logger().LogError("Ruleset freeze exceeded its maximum duration and was lifted, applying the latest ruleset\n")
+			s.unfreezeRuleset()
+		}
+	})
+	s.freeze.expires = expires
+}
+
+// Lifts the freeze and applies the latest ruleset received while frozen
+func (s *store) unfreezeRuleset() {
+	s.mu.Lock()
+	pending := s.freeze.pendingSpecs
+	if s.freeze.expires != nil {
+		s.freeze.expires.Stop()
+	}
+	s.freeze = rulesetFreeze{}
+	s.mu.Unlock()
+	if pending == nil {
+		return
+	}
+	if _, updated := s.setConfigSpecs(*pending); updated {
+		s.rulesetListeners.notify(*pending, s.getLastRulesetUpdate())
+	}
+}
+
+// Holds back specs received while frozen. Returns false if the specs should be applied.
+func (s *store) deferIfFrozen(specs downloadConfigSpecResponse) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.freeze.frozen {
+		return false
+	}
+	s.freeze.pendingSpecs = &specs
+	s.freeze.skippedSyncs++
+	return true
+}
+
+func (s *store) getRulesetFreezeStatus() RulesetFreezeStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.freeze.frozen {
+		return RulesetFreezeStatus{}
+	}
+	status := RulesetFreezeStatus{
+		Frozen:       true,
+		FrozenAt:     s.freeze.frozenAt,
+		ExpiresAt:    s.freeze.frozenAt.Add(s.freeze.maxDuration),
+		SkippedSyncs: s.freeze.skippedSyncs,
+	}
+	if s.freeze.pendingSpecs != nil {
+		status.PendingSyncTime = s.freeze.pendingSpecs.Time
+	}
+	return status
+}
@@ -0,0 +1,99 @@
+package statsig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRulesetFreeze(t *testing.T) {
+	var serveEmptyRuleset int32
+	dcs, _ := os.ReadFile("download_config_specs.json")
+	emptyRuleset := "{\"feature_gates\":[],\"dynamic_configs\":[],\"layer_configs\":[],\"layers\":{},\"has_updates\":true,\"time\":2}"
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			if atomic.LoadInt32(&serveEmptyRuleset) == 1 {
+				_, _ = res.Write([]byte(emptyRuleset))
+			} else {
+				_, _ = res.Write(dcs)
+			}
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+	user := User{UserID: "a_user"}
+
+	newClient := func(maxFreezeDuration time.Duration) *Client {
+		atomic.StoreInt32(&serveEmptyRuleset, 0)
+		return NewClientWithOptions("secret-key", &Options{
+			API:                      testServer.URL,
+			ConfigSyncInterval:       50 * time.Millisecond,
+			MaxRulesetFreezeDuration: maxFreezeDuration,
+			OutputLoggerOptions:      getOutputLoggerOptionsForTest(t),
+			StatsigLoggerOptions:     getStatsigLoggerOptionsForTest(t),
+		})
+	}
+
+	t.Run("holds back updates until unfrozen", func(t *testing.T) {
+		c := newClient(0)
+		defer c.Shutdown()
+		if !c.CheckGate(user, "always_on_gate") {
+			t.Fatalf("Expected gate to pass before freezing")
+		}
+
+		c.FreezeRuleset()
+		atomic.StoreInt32(&serveEmptyRuleset, 1)
+		time.Sleep(200 * time.Millisecond)
+		if !c.CheckGate(user, "always_on_gate") {
+			t.Errorf("Expected the frozen ruleset to still be used")
+		}
+		status := c.GetRulesetFreezeStatus()
+		if !status.Frozen || status.SkippedSyncs == 0 || status.PendingSyncTime != 2 {
+			t.Errorf("Unexpected freeze status %+v", status)
+		}
+		if status.ExpiresAt.Sub(status.FrozenAt) != defaultMaxRulesetFreezeDuration {
+			t.Errorf("Expected the default maximum freeze duration")
+		}
+
+		c.UnfreezeRuleset()
+		if c.CheckGate(user, "always_on_gate") {
+			t.Errorf("Expected the pending ruleset to be applied on unfreeze")
+		}
+		if c.GetRulesetFreezeStatus().Frozen {
+			t.Errorf("Expected the ruleset to be unfrozen")
+		}
+	})
+
+	t.Run("lifts the freeze after the maximum duration", func(t *testing.T) {
+		c := newClient(time.Millisecond)
+		defer c.Shutdown()
+
+		c.FreezeRuleset()
+		atomic.StoreInt32(&serveEmptyRuleset, 1)
+		time.Sleep(200 * time.Millisecond)
+		if c.CheckGate(user, "always_on_gate") {
+			t.Errorf("Expected the latest ruleset to be applied after the freeze expired")
+		}
+		if c.GetRulesetFreezeStatus().Frozen {
+			t.Errorf("Expected the ruleset to be unfrozen")
+		}
+	})
+
+	t.Run("reports the freeze as lifted once it expires without a sync", func(t *testing.T) {
+		c := newClient(20 * time.Millisecond)
+		defer c.Shutdown()
+
+		c.FreezeRuleset()
+		c.evaluator.store.stopPolling()
+		time.Sleep(100 * time.Millisecond)
+		if c.GetRulesetFreezeStatus().Frozen {
+			t.Errorf("Expected the freeze to be lifted at its expiry")
+		}
+	})
+}
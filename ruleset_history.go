@@ -0,0 +1,211 @@
+package statsig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+const defaultRulesetHistoryMaxBytes = 32 << 20
+
+// Describes a retained ruleset, see ListRulesetSnapshots
+type RulesetSnapshotInfo struct {
+	AppliedAt       time.Time `json:"appliedAt"`       // When this SDK instance started serving the ruleset
+	Time            int64     `json:"time"`            // The ruleset's time from Statsig, used to look it up with GetRulesetSnapshot
+	CompressedBytes int       `json:"compressedBytes"` // Memory held by the snapshot
+	FeatureGates    int       `json:"featureGates"`
+	DynamicConfigs  int       `json:"dynamicConfigs"`
+	LayerConfigs    int       `json:"layerConfigs"`
+}
+
+// A ruleset as it was applied by a previous sync, stored as gzipped download_config_specs JSON
+type rulesetSnapshot struct {
+	info       RulesetSnapshotInfo
+	compressed []byte
+}
+
+// Snapshots ordered from oldest to newest. The oldest are evicted once there are more than
+// size snapshots or they hold more than maxBytes in total.
+type rulesetHistory struct {
+	snapshots  []rulesetSnapshot
+	totalBytes int
+	size       int
+	maxBytes   int
+}
+
+func newRulesetHistory(options *Options) rulesetHistory {
+	maxBytes := options.RulesetHistoryMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultRulesetHistoryMaxBytes
+	}
+	return rulesetHistory{size: options.RulesetHistorySize, maxBytes: maxBytes}
+}
+
+func (h *rulesetHistory) enabled() bool {
+	return h.size > 0
+}
+
+func (h *rulesetHistory) push(snapshot rulesetSnapshot) {
+	h.snapshots = append(h.snapshots, snapshot)
+	h.totalBytes += len(snapshot.compressed)
+	for len(h.snapshots) > 0 && (len(h.snapshots) > h.size || h.totalBytes > h.maxBytes) {
+		h.totalBytes -= len(h.snapshots[0].compressed)
+		h.snapshots[0] = rulesetSnapshot{}
+		h.snapshots = h.snapshots[1:]
+	}
+}
+
+func newRulesetSnapshot(specs downloadConfigSpecResponse) (rulesetSnapshot, error) {
+	serialized, err := json.Marshal(specs)
+	if err != nil {
+		return rulesetSnapshot{}, err
+	}
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err = writer.Write(serialized); err != nil {
+		return rulesetSnapshot{}, err
+	}
+	if err = writer.Close(); err != nil {
+		return rulesetSnapshot{}, err
+	}
+	return rulesetSnapshot{
+		info: RulesetSnapshotInfo{
+			AppliedAt:       time.Now(),
+			Time:            specs.Time,
+			CompressedBytes: buffer.Len(),
+			FeatureGates:    len(specs.FeatureGates),
+			DynamicConfigs:  len(specs.DynamicConfigs),
+			LayerConfigs:    len(specs.LayerConfigs),
+		},
+		compressed: buffer.Bytes(),
+	}, nil
+}
+
+func (s *rulesetSnapshot) decompress() ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(s.compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+func (s *rulesetSnapshot) specs() (downloadConfigSpecResponse, error) {
+	var specs downloadConfigSpecResponse
+	serialized, err := s.decompress()
+	if err == nil {
+		err = json.Unmarshal(serialized, &specs)
+	}
+	return specs, err
+}
+
+// Called with the specs applied by setConfigSpecs. Syncs that return an identical ruleset
+// do not add a snapshot.
+func (s *store) recordRulesetSnapshot(specs downloadConfigSpecResponse) {
+	s.mu.RLock()
+	enabled := s.history.enabled()
+	unchanged := len(s.history.snapshots) > 0 && s.lastSpecDelta == (specDelta{})
+	s.mu.RUnlock()
+	if !enabled || unchanged {
+		return
+	}
+	snapshot, err := newRulesetSnapshot(specs)
+	if err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to retain ruleset snapshot: %s\n", err.Error()))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history.push(snapshot)
+}
+
+func (s *store) listRulesetSnapshots() []RulesetSnapshotInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	infos := make([]RulesetSnapshotInfo, 0, len(s.history.snapshots))
+	for _, snapshot := range s.history.snapshots {
+		infos = append(infos, snapshot.info)
+	}
+	return infos
+}
+
+func (s *store) getRulesetSnapshot(rulesetTime int64) (rulesetSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := len(s.history.snapshots) - 1; i >= 0; i-- {
+		if s.history.snapshots[i].info.Time == rulesetTime {
+			return s.history.snapshots[i], nil
+		}
+	}
+	return rulesetSnapshot{}, fmt.Errorf("No retained ruleset has time %d", rulesetTime)
+}
+
+// Finds the ruleset that was being served at the given time
+func (s *store) getRulesetSnapshotAt(at time.Time) (rulesetSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshots := s.history.snapshots
+	if len(snapshots) == 0 {
+		return rulesetSnapshot{}, fmt.Errorf("No ruleset history is retained, set Options.RulesetHistorySize")
+	}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if !snapshots[i].info.AppliedAt.After(at) {
+			return snapshots[i], nil
+		}
+	}
+	return rulesetSnapshot{}, fmt.Errorf("The oldest retained ruleset was applied at %s, after %s",
+		snapshots[0].info.AppliedAt.Format(time.RFC3339), at.Format(time.RFC3339))
+}
+
+// Builds an evaluator over the snapshot's specs and the current ID lists, which are not retained
+func (e *evaluator) forRulesetSnapshot(snapshot rulesetSnapshot) (*evaluator, error) {
+	specs, err := snapshot.specs()
+	if err != nil {
+		return nil, err
+	}
+	e.store.mu.RLock()
+	idLists := make(map[string]*idList, len(e.store.idLists))
+	for name, list := range e.store.idLists {
+		idLists[name] = list
+	}
+	e.store.mu.RUnlock()
+	// Historical evaluations must not be reported as if they were served
+	options := *e.options
+	options.OnMissingUnitID = nil
+	options.RulesetHistorySize = 0
+	historical := &evaluator{
+		store: &store{
+			idLists:       idLists,
+			initReason:    reasonHistorical,
+			errorBoundary: e.store.errorBoundary,
+			diagnostics:   newDiagnostics(&options),
+			sdkKey:        e.store.sdkKey,
+			options:       &options,
+		},
+		countryLookup:   e.countryLookup,
+		uaParser:        e.uaParser,
+		gateOverrides:   make(map[string]bool),
+		configOverrides: make(map[string]map[string]interface{}),
+		layerOverrides:  make(map[string]map[string]interface{}),
+		precomputed:     make(map[string]*precomputedEvaluations),
+		options:         &options,
+	}
+	specs.HasUpdates = true
+	historical.store.setConfigSpecs(specs)
+	return historical, nil
+}
+
+func (e *evaluator) checkGateAtTime(user User, gateName string, at time.Time) (*evalResult, error) {
+	snapshot, err := e.store.getRulesetSnapshotAt(at)
+	if err != nil {
+		return nil, err
+	}
+	historical, err := e.forRulesetSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return historical.evalGate(user, gateName, 0), nil
+}
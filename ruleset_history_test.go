@@ -0,0 +1,137 @@
+package statsig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckGateAtTime(t *testing.T) {
+	fixture, _ := os.ReadFile("download_config_specs.json")
+	var specs map[string]interface{}
+	_ = json.Unmarshal(fixture, &specs)
+	specs["feature_gates"].([]interface{})[0].(map[string]interface{})["enabled"] = false
+	specs["time"] = 1631638014812
+	modifiedDCS, _ := json.Marshal(specs)
+	specs["feature_gates"].([]interface{})[1].(map[string]interface{})["enabled"] = false
+	specs["time"] = 1631638014813
+	secondModifiedDCS, _ := json.Marshal(specs)
+
+	var payload atomic.Value
+	payload.Store(fixture)
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write(payload.Load().([]byte))
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	beforeInit := time.Now()
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   20 * time.Millisecond,
+		RulesetHistorySize:   2,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a_user"}
+
+	afterInit := time.Now()
+	payload.Store(modifiedDCS)
+	time.Sleep(100 * time.Millisecond)
+	c.logger.events = make([]interface{}, 0)
+
+	gate, err := c.CheckGateAtTime(user, "always_on_gate", afterInit)
+	if err != nil || !gate.Value || gate.RuleID != "6N6Z8ODekNYZ7F8gFdoLP5" {
+		t.Errorf("Expected the gate to pass in the initial ruleset, received %+v %v", gate, err)
+	}
+	gate, err = c.CheckGateAtTime(user, "always_on_gate", time.Now())
+	if err != nil || gate.Value || gate.RuleID != RuleIDDisabled {
+		t.Errorf("Expected the gate to be disabled in the current ruleset, received %+v %v", gate, err)
+	}
+	if len(c.logger.events) != 0 {
+		t.Errorf("Expected no exposures for historical evaluations, received %d", len(c.logger.events))
+	}
+	if _, err = c.CheckGateAtTime(user, "always_on_gate", beforeInit); err == nil {
+		t.Errorf("Expected an error before the oldest retained ruleset")
+	}
+
+	payload.Store(secondModifiedDCS)
+	time.Sleep(100 * time.Millisecond)
+	if _, err = c.CheckGateAtTime(user, "always_on_gate", afterInit); err == nil {
+		t.Errorf("Expected the initial ruleset to be evicted")
+	}
+}
+
+func TestCheckGateAtTimeDisabled(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(bytes),
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	if _, err := c.CheckGateAtTime(User{UserID: "a_user"}, "always_on_gate", time.Now()); err == nil {
+		t.Errorf("Expected an error without RulesetHistorySize")
+	}
+}
+
+func TestRulesetSnapshots(t *testing.T) {
+	fixture, _ := os.ReadFile("download_config_specs.json")
+	var specs downloadConfigSpecResponse
+	_ = json.Unmarshal(fixture, &specs)
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(fixture),
+		RulesetHistorySize:   3,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	snapshots := c.ListRulesetSnapshots()
+	if len(snapshots) != 1 {
+		t.Fatalf("Expected the bootstrapped ruleset to be retained, received %+v", snapshots)
+	}
+	info := snapshots[0]
+	if info.Time != specs.Time || info.FeatureGates != 4 || info.DynamicConfigs != 2 || info.LayerConfigs != 3 {
+		t.Errorf("Unexpected snapshot info %+v", info)
+	}
+	if info.CompressedBytes <= 0 || info.CompressedBytes >= len(fixture)/2 {
+		t.Errorf("Expected the snapshot to be compressed, received %d bytes", info.CompressedBytes)
+	}
+
+	rules, err := c.GetRulesetSnapshot(specs.Time)
+	var retained downloadConfigSpecResponse
+	if err != nil || json.Unmarshal([]byte(rules), &retained) != nil || len(retained.FeatureGates) != 4 {
+		t.Errorf("Expected to inspect the retained ruleset, received %v", err)
+	}
+	if _, err = c.GetRulesetSnapshot(1); err == nil {
+		t.Errorf("Expected an error for an unknown ruleset time")
+	}
+
+	t.Run("evicts the oldest snapshots beyond the memory cap", func(t *testing.T) {
+		history := newRulesetHistory(&Options{RulesetHistorySize: 10, RulesetHistoryMaxBytes: 2*info.CompressedBytes + 1})
+		for i := 0; i < 4; i++ {
+			specs.Time = int64(i)
+			snapshot, _ := newRulesetSnapshot(specs)
+			history.push(snapshot)
+		}
+		if len(history.snapshots) != 2 || history.snapshots[0].info.Time != 2 || history.snapshots[1].info.Time != 3 {
+			t.Errorf("Expected the 2 newest snapshots to be kept, received %d", len(history.snapshots))
+		}
+		if history.totalBytes > 2*info.CompressedBytes+1 {
+			t.Errorf("Expected the memory cap to be respected, received %d bytes", history.totalBytes)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Receives the ruleset as JSON, and the time it was generated, whenever new config specs are applied
+type RulesetListener func(rules string, time int64)
+
+// Receives the specs that changed whenever new config specs are applied, e.g. to react to specific flags
+type RulesetUpdateListener func(update RulesetUpdate)
+
+// The specs added, removed and changed by applying a new ruleset
+type RulesetUpdate struct {
+	PreviousTime int64        // Time the replaced ruleset was generated, 0 if there was none
+	Time         int64        // Time the new ruleset was generated
+	Added        RulesetSpecs // Specs in the new ruleset only
+	Removed      RulesetSpecs // Specs in the replaced ruleset only
+	Changed      RulesetSpecs // Specs whose definition changed in any way, e.g. their rules, salt or enabled state
+}
+
+// Names of specs by type, sorted
+type RulesetSpecs struct {
+	FeatureGates   []string
+	DynamicConfigs []string // Including experiments
+	Layers         []string
+}
+
+func (r *RulesetSpecs) add(key string) {
+	switch {
+	case strings.HasPrefix(key, "gate:"):
+		r.FeatureGates = append(r.FeatureGates, strings.TrimPrefix(key, "gate:"))
+	case strings.HasPrefix(key, "config:"):
+		r.DynamicConfigs = append(r.DynamicConfigs, strings.TrimPrefix(key, "config:"))
+	case strings.HasPrefix(key, "layer:"):
+		r.Layers = append(r.Layers, strings.TrimPrefix(key, "layer:"))
+	}
+}
+
+func (r *RulesetSpecs) sort() {
+	sort.Strings(r.FeatureGates)
+	sort.Strings(r.DynamicConfigs)
+	sort.Strings(r.Layers)
+}
+
+// Diffs the spec hashes from hashConfigSpecs of the replaced and new rulesets
+func newRulesetUpdate(previous map[string]uint64, current map[string]uint64, previousTime int64, time int64) RulesetUpdate {
+	update := RulesetUpdate{PreviousTime: previousTime, Time: time}
+	for key, hash := range current {
+		previousHash, ok := previous[key]
+		if !ok {
+			update.Added.add(key)
+		} else if previousHash != hash {
+			update.Changed.add(key)
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			update.Removed.add(key)
+		}
+	}
+	update.Added.sort()
+	update.Removed.sort()
+	update.Changed.sort()
+	return update
+}
+
+func (s *store) getLastRulesetUpdate() RulesetUpdate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastRulesetUpdate
+}
+
+// Exactly one of listener and updateListener is set
+type registeredRulesetListener struct {
+	listener       RulesetListener
+	updateListener RulesetUpdateListener
+	callSite       string // Where the listener was added, to identify it in logs
+}
+
+type rulesetListeners struct {
+	listeners     []*registeredRulesetListener
+	slowThreshold time.Duration
+	mu            sync.RWMutex
+}
+
+func newRulesetListeners(options *Options, rulesUpdatedCallback RulesetListener, rulesetUpdatedCallback RulesetUpdateListener) *rulesetListeners {
+	slowThreshold := options.RulesetListenerOptions.SlowThreshold
+	if slowThreshold <= 0 {
+		slowThreshold = time.Second
+	}
+	listeners := &rulesetListeners{slowThreshold: slowThreshold}
+	if rulesUpdatedCallback != nil {
+		listeners.add(rulesUpdatedCallback, "Options.RulesUpdatedCallback")
+	}
+	if rulesetUpdatedCallback != nil {
+		listeners.addUpdateListener(rulesetUpdatedCallback, "Options.RulesetUpdatedCallback")
+	}
+	return listeners
+}
+
+// Returns a function that removes the listener
+func (l *rulesetListeners) add(listener RulesetListener, callSite string) func() {
+	return l.register(&registeredRulesetListener{listener: listener, callSite: callSite})
+}
+
+// Returns a function that removes the listener
+func (l *rulesetListeners) addUpdateListener(listener RulesetUpdateListener, callSite string) func() {
+	return l.register(&registeredRulesetListener{updateListener: listener, callSite: callSite})
+}
+
+func (l *rulesetListeners) register(registered *registeredRulesetListener) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, registered)
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, existing := range l.listeners {
+			if existing == registered {
+				l.listeners = append(l.listeners[:i:i], l.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (l *rulesetListeners) list() []*registeredRulesetListener {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.listeners
+}
+
+// Whether the store needs to diff applied rulesets
+func (l *rulesetListeners) hasUpdateListeners() bool {
+	if l == nil {
+		return false
+	}
+	for _, registered := range l.list() {
+		if registered.updateListener != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Calls each listener in the order they were added. A listener that panics is logged and
+// does not prevent the remaining listeners from being called. Must not be called with store.mu held.
+func (l *rulesetListeners) notify(specs downloadConfigSpecResponse, update RulesetUpdate) {
+	listeners := l.list()
+	if len(listeners) == 0 {
+		return
+	}
+	var rules string
+	for _, registered := range listeners {
+		if registered.listener != nil && rules == "" {
+			v, _ := json.Marshal(specs)
+			rules = string(v[:])
+		}
+		start := time.Now()
+		l.call(registered, rules, specs.Time, update)
+		if elapsed := time.Since(start); elapsed > l.slowThreshold {
+			Logger().Log(fmt.Sprintf("Ruleset listener added at %s took %s to return, which delays the listeners after it\n", registered.callSite, elapsed), nil)
+		}
+	}
+}
+
+func (l *rulesetListeners) call(registered *registeredRulesetListener, rules string, time int64, update RulesetUpdate) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			Logger().LogError(fmt.Sprintf("Ruleset listener added at %s panicked: %s\n", registered.callSite, toError(recovered).Error()))
+		}
+	}()
+	if registered.updateListener != nil {
+		registered.updateListener(update)
+		return
+	}
+	registered.listener(rules, time)
+}
+
+// Identifies the first caller outside of the SDK, e.g. the caller of AddRulesetListener
+func getListenerCallSite() string {
+	return (&callSiteMetrics{depth: 1}).getCallSite()
+}
@@ -0,0 +1,159 @@
+package statsig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRulesetListeners(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var syncs int64
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			// A new time on every sync, so each one is an update
+			updated := `"time": ` + strconv.FormatInt(atomic.AddInt64(&syncs, 1), 10)
+			_, _ = res.Write([]byte(strings.Replace(string(specs), `"time": 1631638014811`, updated, 1)))
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	var mu sync.Mutex
+	var calls []string
+	var logs []string
+	record := func(name string) RulesetListener {
+		return func(rules string, _ int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !strings.Contains(rules, "always_on_gate") {
+				t.Errorf("Expected %s to receive the ruleset", name)
+			}
+			calls = append(calls, name)
+		}
+	}
+	InitializeGlobalOutputLogger(OutputLoggerOptions{LogCallback: func(message string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		logs = append(logs, message)
+	}})
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                    testServer.URL,
+		RulesUpdatedCallback:   record("callback"),
+		RulesetListenerOptions: RulesetListenerOptions{SlowThreshold: 20 * time.Millisecond},
+		StatsigLoggerOptions:   getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	c.AddRulesetListener(func(string, int64) { panic("listener failure") })
+	c.AddRulesetListener(func(string, int64) { time.Sleep(30 * time.Millisecond) })
+	removeWriter := c.AddRulesetListener(record("writer"))
+	c.AddRulesetListener(record("auditor"))
+
+	mu.Lock()
+	calls = nil
+	mu.Unlock()
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	removeWriter()
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(calls, ",") != "callback,writer,auditor,callback,auditor" {
+		t.Errorf("Expected listeners to be called in order despite a panic, received %v", calls)
+	}
+	var panicked, slow bool
+	for _, log := range logs {
+		if strings.Contains(log, "ruleset_listeners_test.go") {
+			panicked = panicked || strings.Contains(log, "panicked: listener failure")
+			slow = slow || strings.Contains(log, "to return")
+		}
+	}
+	if !panicked || !slow {
+		t.Errorf("Expected the panicking and slow listeners to be logged with where they were added, received %v", logs)
+	}
+}
+
+func TestRulesetUpdateListeners(t *testing.T) {
+	rulesets := []string{
+		`{"has_updates": true, "time": 1, "feature_gates": [
+			{"name": "kept", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": []},
+			{"name": "edited", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": []},
+			{"name": "deleted", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": []}],
+		"dynamic_configs": [], "layer_configs": []}`,
+		`{"has_updates": true, "time": 2, "feature_gates": [
+			{"name": "kept", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": []},
+			{"name": "edited", "type": "feature_gate", "salt": "s", "enabled": false, "defaultValue": false, "rules": []}],
+		"dynamic_configs": [
+			{"name": "experiment", "type": "dynamic_config", "salt": "s", "enabled": true, "defaultValue": {}, "rules": []}],
+		"layer_configs": [
+			{"name": "layer", "type": "dynamic_config", "salt": "s", "enabled": true, "defaultValue": {}, "rules": []}]}`,
+	}
+	var version int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write([]byte(rulesets[atomic.LoadInt32(&version)]))
+		} else {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	var mu sync.Mutex
+	var updates []RulesetUpdate
+	var calls []string
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		IDListSyncInterval:   time.Hour,
+		RulesUpdatedCallback: func(string, int64) { mu.Lock(); calls = append(calls, "callback"); mu.Unlock() },
+		RulesetUpdatedCallback: func(update RulesetUpdate) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, "update callback")
+			updates = append(updates, update)
+		},
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	remove := c.AddRulesetUpdateListener(func(RulesetUpdate) { mu.Lock(); calls = append(calls, "listener"); mu.Unlock() })
+
+	atomic.StoreInt32(&version, 1)
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	remove()
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(calls, ",") != "callback,update callback,callback,update callback,listener,callback,update callback" {
+		t.Errorf("Expected update listeners to be called in order with other listeners, received %v", calls)
+	}
+	if len(updates) != 3 {
+		t.Fatalf("Expected 3 updates, received %d", len(updates))
+	}
+	initial := updates[0]
+	if initial.PreviousTime != 0 || initial.Time != 1 || strings.Join(initial.Added.FeatureGates, ",") != "deleted,edited,kept" {
+		t.Errorf("Expected the initial ruleset to add every spec, received %+v", initial)
+	}
+	update := updates[1]
+	if update.PreviousTime != 1 || update.Time != 2 {
+		t.Errorf("Expected the update to span times 1 to 2, received %d to %d", update.PreviousTime, update.Time)
+	}
+	if strings.Join(update.Changed.FeatureGates, ",") != "edited" || strings.Join(update.Removed.FeatureGates, ",") != "deleted" ||
+		len(update.Added.FeatureGates) != 0 || strings.Join(update.Added.DynamicConfigs, ",") != "experiment" ||
+		strings.Join(update.Added.Layers, ",") != "layer" {
+		t.Errorf("Expected the update to describe the changed specs, received %+v", update)
+	}
+	resynced := updates[2]
+	if resynced.PreviousTime != 2 || len(resynced.Added.FeatureGates)+len(resynced.Removed.FeatureGates)+len(resynced.Changed.FeatureGates) != 0 {
+		t.Errorf("Expected an identical ruleset to change nothing, received %+v", resynced)
+	}
+}
@@ -0,0 +1,88 @@
+package statsig
+
+// Evaluates against the ruleset that was being served when Client.Snapshot was called, so every check
+// made while handling a request agrees even if a sync lands midway. Overrides are shared with the
+// client, and the ID lists loaded at the time keep receiving updates. Snapshots are cheap to create and
+// hold no resources, so one can be taken per request and dropped afterwards.
+type SnapshotClient struct {
+	rulesetTime int64
+	client      *Client
+}
+
+// Takes a snapshot of the ruleset the client is currently serving, see SnapshotClient
+func (c *Client) Snapshot() *SnapshotClient {
+	scoped := *c
+	scoped.evaluator = c.evaluator.pinned()
+	// Evaluations must not be shared with callers evaluating against another ruleset
+	scoped.coalescer = newEvaluationCoalescer(c.options)
+	return &SnapshotClient{rulesetTime: scoped.evaluator.store.lastSyncTime, client: &scoped}
+}
+
+// Shares the overrides and precomputed evaluations of e, evaluated against the specs e serves now
+func (e *evaluator) pinned() *evaluator {
+	return &evaluator{
+		store:                  e.store.pinned(),
+		countryLookup:          e.countryLookup,
+		uaParser:               e.uaParser,
+		persistentStorageUtils: e.persistentStorageUtils,
+		options:                e.options,
+		parent:                 e,
+		tenant:                 e.tenant,
+	}
+}
+
+// Syncs replace the spec maps rather than modifying them, so the pinned store can hold on to them
+func (s *store) pinned() *store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	idLists := make(map[string]*idList, len(s.idLists))
+	for name, list := range s.idLists {
+		idLists[name] = list
+	}
+	// Fetching unknown specs would not change the pinned ruleset
+	options := *s.options
+	options.UnknownSpecFetchOptions.Enabled = false
+	options.RulesetHistorySize = 0
+	return &store{
+		featureGates:         s.featureGates,
+		dynamicConfigs:       s.dynamicConfigs,
+		layerConfigs:         s.layerConfigs,
+		experimentToLayer:    s.experimentToLayer,
+		sdkKeysToAppID:       s.sdkKeysToAppID,
+		hashedSDKKeysToAppID: s.hashedSDKKeysToAppID,
+		idLists:              idLists,
+		lastSyncTime:         s.lastSyncTime,
+		initialSyncTime:      s.initialSyncTime,
+		initReason:           s.initReason,
+		errorBoundary:        s.errorBoundary,
+		diagnostics:          s.diagnostics,
+		metrics:              s.metrics,
+		sdkKey:               s.sdkKey,
+		options:              &options,
+	}
+}
+
+// The time of the pinned ruleset, 0 if the client had not loaded one
+func (p *SnapshotClient) RulesetTime() int64 {
+	return p.rulesetTime
+}
+
+// Checks the value of a Feature Gate for the given user against the pinned ruleset
+func (p *SnapshotClient) CheckGate(user User, gate string) bool {
+	return p.client.CheckGate(user, gate)
+}
+
+// Gets the DynamicConfig value for the given user against the pinned ruleset
+func (p *SnapshotClient) GetConfig(user User, config string) DynamicConfig {
+	return p.client.GetConfig(user, config)
+}
+
+// Gets the DynamicConfig value of an Experiment for the given user against the pinned ruleset
+func (p *SnapshotClient) GetExperiment(user User, experiment string) DynamicConfig {
+	return p.client.GetExperiment(user, experiment)
+}
+
+// Gets the Layer object for the given user against the pinned ruleset
+func (p *SnapshotClient) GetLayer(user User, layer string) Layer {
+	return p.client.GetLayer(user, layer)
+}
@@ -0,0 +1,54 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	ruleset := func(time int, pass int, color string) string {
+		return fmt.Sprintf(`{"has_updates": true, "time": %d, "feature_gates": [
+			{"name": "rollout", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+				{"name": "all", "id": "all_rule", "salt": "s", "passPercentage": %d, "returnValue": true, "conditions": [{"type": "public"}]}]}
+		], "dynamic_configs": [
+			{"name": "theme", "type": "dynamic_config", "salt": "s", "enabled": true, "defaultValue": {"color": "%s"}, "rules": []}
+		], "layer_configs": []}`, time, pass, color)
+	}
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      ruleset(1, 100, "blue"),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a"}
+
+	snapshot := c.Snapshot()
+	var specs downloadConfigSpecResponse
+	_ = json.Unmarshal([]byte(ruleset(2, 0, "red")), &specs)
+	c.evaluator.store.setConfigSpecs(specs)
+
+	pinned, latest := snapshot.GetConfig(user, "theme"), c.GetConfig(user, "theme")
+	if !snapshot.CheckGate(user, "rollout") || pinned.GetString("color", "") != "blue" {
+		t.Errorf("Expected the snapshot to evaluate against the ruleset it was taken from")
+	}
+	if c.CheckGate(user, "rollout") || latest.GetString("color", "") != "red" {
+		t.Errorf("Expected the client to evaluate against the latest ruleset")
+	}
+	if snapshot.RulesetTime() != 1 || c.Snapshot().RulesetTime() != 2 {
+		t.Errorf("Unexpected snapshot ruleset times %d", snapshot.RulesetTime())
+	}
+	if pinned.EvaluationDetails.configSyncTime != 1 {
+		t.Errorf("Expected evaluation details to report the pinned ruleset, received %d", pinned.EvaluationDetails.configSyncTime)
+	}
+
+	c.OverrideGate("rollout", false)
+	if snapshot.CheckGate(user, "rollout") {
+		t.Errorf("Expected overrides to apply to snapshots")
+	}
+	c.RemoveGateOverride("rollout")
+	if snapshot.GetExperiment(user, "unknown").RuleID != "" || snapshot.GetLayer(user, "unknown").RuleID != "" {
+		t.Errorf("Expected unknown specs to evaluate as unrecognized")
+	}
+}
@@ -0,0 +1,177 @@
+package statsig
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sdkStatsEventName = "statsig::sdk_stats"
+
+// A point-in-time snapshot of the SDK's internal sizes, useful for
+// correlating SDK growth with process memory
+type SDKStats struct {
+	FeatureGateCount         int                   `json:"featureGateCount"`
+	DynamicConfigCount       int                   `json:"dynamicConfigCount"`
+	LayerConfigCount         int                   `json:"layerConfigCount"`
+	IDListCount              int                   `json:"idListCount"`
+	IDListEntryCount         int64                 `json:"idListEntryCount"`
+	IDListSkewCount          int64                 `json:"idListSkewCount"`                    // Summed IDListStats.SkewCount
+	IDListFalsePositiveRates map[string]float64    `json:"idListFalsePositiveRates,omitempty"` // Estimated, for lists stored as bloom filters
+	IDLists                  []IDListStats         `json:"idLists,omitempty"`                  // Per list, sorted by name. Only delivered to StatsCallback
+	EventQueueDepth          int                   `json:"eventQueueDepth"`
+	HeapAllocBytes           uint64                `json:"heapAllocBytes"`
+	RSSBytes                 uint64                `json:"rssBytes"`
+	ClockSkewMs              int64                 `json:"clockSkewMs"`
+	RulesetAgeMs             int64                 `json:"rulesetAgeMs"` // Time since the served ruleset was generated, by the server's clock
+	ConfigSpecSync           ConfigSpecSyncMetrics `json:"configSpecSync"`
+	MissingUnitIDCounts      map[string]int64      `json:"missingUnitIDCounts,omitempty"` // Evaluations for users without the spec's custom ID, by ID type
+	DroppedEventCounts       map[string]int64      `json:"droppedEventCounts,omitempty"`  // Events dropped by partitioned event queues, by queue
+	SDKVersion               SDKVersionStatus      `json:"sdkVersion"`
+	Time                     int64                 `json:"time"`
+}
+
+type sdkStatsReporter struct {
+	evaluator *evaluator
+	logger    *logger
+	callback  func(stats SDKStats)
+	tick      *time.Ticker
+	done      chan bool
+	once      sync.Once
+}
+
+func newSDKStatsReporter(evaluator *evaluator, logger *logger, options *Options) *sdkStatsReporter {
+	if options.SDKStatsOptions.ReportingInterval <= 0 {
+		return nil
+	}
+	reporter := &sdkStatsReporter{
+		evaluator: evaluator,
+		logger:    logger,
+		callback:  options.SDKStatsOptions.StatsCallback,
+		tick:      time.NewTicker(options.SDKStatsOptions.ReportingInterval),
+		done:      make(chan bool),
+	}
+	go reporter.backgroundReport()
+	return reporter
+}
+
+func (r *sdkStatsReporter) backgroundReport() {
+	for {
+		select {
+		case <-r.tick.C:
+			r.report()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *sdkStatsReporter) report() {
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(err)
+		}
+	}()
+	stats := collectSDKStats(r.evaluator, r.logger)
+	if r.callback != nil {
+		r.callback(stats)
+		return
+	}
+	r.logger.logInternal(diagnosticsEvent{
+		EventName: sdkStatsEventName,
+		Time:      stats.Time,
+		Metadata: map[string]interface{}{
+			"featureGateCount":         stats.FeatureGateCount,
+			"dynamicConfigCount":       stats.DynamicConfigCount,
+			"layerConfigCount":         stats.LayerConfigCount,
+			"idListCount":              stats.IDListCount,
+			"idListEntryCount":         stats.IDListEntryCount,
+			"idListSkewCount":          stats.IDListSkewCount,
+			"idListFalsePositiveRates": stats.IDListFalsePositiveRates,
+			"eventQueueDepth":          stats.EventQueueDepth,
+			"heapAllocBytes":           stats.HeapAllocBytes,
+			"rssBytes":                 stats.RSSBytes,
+			"clockSkewMs":              stats.ClockSkewMs,
+			"rulesetAgeMs":             stats.RulesetAgeMs,
+			"sdkVersionBelowMinimum":   stats.SDKVersion.BelowMinimum,
+		},
+	})
+}
+
+func (r *sdkStatsReporter) stop() {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		r.tick.Stop()
+		close(r.done)
+	})
+}
+
+func collectSDKStats(e *evaluator, l *logger) SDKStats {
+	stats := SDKStats{Time: getUnixMilli(), IDListFalsePositiveRates: make(map[string]float64)}
+	stats.MissingUnitIDCounts = e.missingUnitIDs.snapshot()
+
+	s := e.store
+	s.mu.RLock()
+	stats.FeatureGateCount = len(s.featureGates)
+	stats.DynamicConfigCount = len(s.dynamicConfigs)
+	stats.LayerConfigCount = len(s.layerConfigs)
+	s.mu.RUnlock()
+
+	stats.ConfigSpecSync = s.getConfigSpecSyncMetrics()
+	stats.RulesetAgeMs = s.rulesetAge().Milliseconds()
+	stats.IDLists = s.getIDListStats()
+	stats.IDListCount = len(stats.IDLists)
+	for _, list := range stats.IDLists {
+		stats.IDListEntryCount += list.EntryCount
+		stats.IDListSkewCount += list.SkewCount
+	}
+	for _, list := range s.getIDLists() {
+		if list.bloom != nil {
+			stats.IDListFalsePositiveRates[list.Name] = list.bloom.estimatedFalsePositiveRate()
+		}
+	}
+
+	l.mu.Lock()
+	stats.EventQueueDepth = len(l.events)
+	l.mu.Unlock()
+	if l.queues != nil {
+		stats.DroppedEventCounts = make(map[string]int64)
+		for _, q := range []*eventQueue{l.queues.exposures, l.queues.custom} {
+			depth, dropped := q.depth()
+			stats.EventQueueDepth += depth
+			stats.DroppedEventCounts[q.name] = dropped
+		}
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	stats.HeapAllocBytes = memStats.HeapAlloc
+	stats.RSSBytes = getProcessRSS()
+	if l.transport != nil {
+		stats.ClockSkewMs = l.transport.getClockSkew()
+		stats.SDKVersion = l.transport.getSDKVersionStatus()
+	}
+	return stats
+}
+
+// Reads the resident set size from procfs. Returns 0 on platforms without it.
+func getProcessRSS() uint64 {
+	contents, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(contents))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * uint64(os.Getpagesize())
+}
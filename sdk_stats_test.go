@@ -0,0 +1,90 @@
+package statsig
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSDKStats(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+
+	t.Run("collects spec counts and queue depth", func(t *testing.T) {
+		c := NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      string(bytes),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+		defer c.Shutdown()
+		c.CheckGate(User{UserID: "a_user"}, "always_on_gate")
+
+		stats := c.GetSDKStats()
+		if stats.FeatureGateCount != 4 {
+			t.Errorf("Expected 4 feature gates, received %d", stats.FeatureGateCount)
+		}
+		if stats.DynamicConfigCount != 2 {
+			t.Errorf("Expected 2 dynamic configs, received %d", stats.DynamicConfigCount)
+		}
+		if stats.EventQueueDepth != 1 {
+			t.Errorf("Expected 1 queued event, received %d", stats.EventQueueDepth)
+		}
+		if stats.HeapAllocBytes == 0 {
+			t.Errorf("Expected heap alloc bytes to be set")
+		}
+	})
+
+	t.Run("reports periodically to the callback", func(t *testing.T) {
+		var mu sync.Mutex
+		reports := make([]SDKStats, 0)
+		c := NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      string(bytes),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			SDKStatsOptions: SDKStatsOptions{
+				ReportingInterval: 20 * time.Millisecond,
+				StatsCallback: func(stats SDKStats) {
+					mu.Lock()
+					defer mu.Unlock()
+					reports = append(reports, stats)
+				},
+			},
+		})
+		waitForCondition(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(reports) >= 2
+		})
+		c.Shutdown()
+		mu.Lock()
+		count := len(reports)
+		mu.Unlock()
+		time.Sleep(60 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		if len(reports) != count {
+			t.Errorf("Expected no reports after shutdown")
+		}
+	})
+
+	t.Run("logs stats events without a callback", func(t *testing.T) {
+		c := NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      string(bytes),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			SDKStatsOptions:      SDKStatsOptions{ReportingInterval: 20 * time.Millisecond},
+		})
+		defer c.Shutdown()
+		waitForCondition(t, func() bool {
+			c.logger.mu.Lock()
+			defer c.logger.mu.Unlock()
+			for _, evt := range c.logger.events {
+				if e, ok := evt.(diagnosticsEvent); ok && e.EventName == sdkStatsEventName {
+					return true
+				}
+			}
+			return false
+		})
+	})
+}
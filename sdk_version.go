@@ -0,0 +1,118 @@
+package statsig
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Response headers through which the Statsig API advertises the SDK versions it supports
+const (
+	sdkMinimumVersionHeader     = "STATSIG-SDK-MINIMUM-VERSION"
+	sdkRecommendedVersionHeader = "STATSIG-SDK-RECOMMENDED-VERSION"
+)
+
+// How this SDK's version compares to the versions the Statsig API advertises, e.g. to track version
+// skew across a fleet. The SDK version is sent with every request in the STATSIG-SDK-VERSION header
+type SDKVersionStatus struct {
+	CurrentVersion     string
+	MinimumVersion     string    // Empty until advertised
+	RecommendedVersion string    // Empty until advertised
+	BelowMinimum       bool      // This version is older than MinimumVersion and should be upgraded
+	BelowRecommended   bool      // This version is older than RecommendedVersion
+	AdvertisedAt       time.Time // When the last response advertising a version was received, zero if none has
+}
+
+type sdkVersionTracker struct {
+	status SDKVersionStatus
+	mu     sync.Mutex
+}
+
+func (transport *transport) recordSDKVersions(response *http.Response) {
+	minimum := response.Header.Get(sdkMinimumVersionHeader)
+	recommended := response.Header.Get(sdkRecommendedVersionHeader)
+	if minimum == "" && recommended == "" {
+		return
+	}
+	tracker := &transport.sdkVersions
+	tracker.mu.Lock()
+	changed := minimum != tracker.status.MinimumVersion || recommended != tracker.status.RecommendedVersion
+	current := transport.metadata.SDKVersion
+	tracker.status = SDKVersionStatus{
+		CurrentVersion:     current,
+		MinimumVersion:     minimum,
+		RecommendedVersion: recommended,
+		BelowMinimum:       minimum != "" && compareSDKVersions(current, minimum) < 0,
+		BelowRecommended:   recommended != "" && compareSDKVersions(current, recommended) < 0,
+		AdvertisedAt:       time.Now(),
+	}
+	status := tracker.status
+	tracker.mu.Unlock()
+	if !changed || (!status.BelowMinimum && !status.BelowRecommended) {
+		return
+	}
+	if status.BelowMinimum {
+		Logger().LogError(fmt.Sprintf("Statsig SDK version %s is below the minimum supported version %s, please upgrade\n",
+			current, minimum))
+	} else {
+		Logger().logRecord(logLevelWarn, "A newer Statsig SDK version is recommended",
+			logAttr{"current_version", current}, logAttr{"recommended_version", recommended})
+	}
+	if callback := transport.options.OnSDKVersionSkew; callback != nil {
+		defer func() {
+			if err := recover(); err != nil {
+				Logger().LogError(fmt.Sprintf("OnSDKVersionSkew panicked: %s\n", toError(err).Error()))
+			}
+		}()
+		callback(status)
+	}
+}
+
+func (transport *transport) getSDKVersionStatus() SDKVersionStatus {
+	tracker := &transport.sdkVersions
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	status := tracker.status
+	status.CurrentVersion = transport.metadata.SDKVersion
+	return status
+}
+
+// Compares dotted numeric versions, ignoring pre-release and build suffixes. Missing parts count as 0
+func compareSDKVersions(a, b string) int {
+	aParts, bParts := sdkVersionParts(a), sdkVersionParts(b)
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart int
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+		if aPart != bPart {
+			if aPart < bPart {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func sdkVersionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	var parts []int
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,83 @@
+package statsig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSDKVersionStatus(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var minimum atomic.Value
+	minimum.Store("1.0.0")
+	var sentVersion atomic.Value
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		sentVersion.Store(req.Header.Get("STATSIG-SDK-VERSION"))
+		res.Header().Set(sdkMinimumVersionHeader, minimum.Load().(string))
+		res.Header().Set(sdkRecommendedVersionHeader, "999.0.0")
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write(specs)
+			return
+		}
+		_, _ = res.Write([]byte("{}"))
+	}))
+	defer testServer.Close()
+	var mu sync.Mutex
+	var reported []SDKVersionStatus
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		OnSDKVersionSkew: func(status SDKVersionStatus) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, status)
+		},
+	})
+	defer c.Shutdown()
+
+	current := getStatsigMetadata().SDKVersion
+	if sent, _ := sentVersion.Load().(string); sent != current {
+		t.Errorf("Expected requests to report SDK version %s, received %s", current, sent)
+	}
+	status := c.GetSDKVersionStatus()
+	if status.CurrentVersion != current || status.MinimumVersion != "1.0.0" || status.BelowMinimum ||
+		!status.BelowRecommended || status.AdvertisedAt.IsZero() {
+		t.Errorf("Unexpected version status %+v", status)
+	}
+	if stats := c.GetSDKStats(); stats.SDKVersion.RecommendedVersion != "999.0.0" {
+		t.Errorf("Expected SDK stats to include the version status, received %+v", stats.SDKVersion)
+	}
+
+	// The callback is only called again once the advertised versions change
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	minimum.Store("999.0.0")
+	c.evaluator.store.fetchConfigSpecsFromServer(false)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 2 || reported[0].BelowMinimum || !reported[1].BelowMinimum {
+		t.Errorf("Expected the skew to be reported when first advertised and when the minimum changed, received %+v", reported)
+	}
+}
+
+func TestCompareSDKVersions(t *testing.T) {
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.18.0", "1.18.0", 0},
+		{"1.18.0", "1.9.2", 1},
+		{"1.18.0", "1.18.1", -1},
+		{"v1.18", "1.18.0", 0},
+		{"1.18.0-beta.1", "1.18.0", 0},
+		{"2.0.0", "10.0.0", -1},
+	} {
+		if result := compareSDKVersions(test.a, test.b); result != test.expected {
+			t.Errorf("Expected comparing %s to %s to return %d, received %d", test.a, test.b, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package statsig
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+)
+
+// Runs a replacement evaluation engine alongside the SDK's evaluator on a sample of gate, config, experiment
+// and layer evaluations, and reports where their results differ, so large evaluator rewrites can be validated
+// on production traffic. Results and exposures always come from the SDK's evaluator. Evaluations served
+// from overrides, persisted values or the server are not compared, nor are those a sync lands in the middle of
+type ShadowEvaluationOptions struct {
+	SampleRate   float64                           // Fraction of evaluations run in shadow, between 0 and 1. Disabled when 0
+	Evaluator    ShadowEvaluator                   // Defaults to the reference engine, which evaluates conditions from the raw specs without the plans compiled at ingest
+	OnDivergence func(divergence ShadowDivergence) // Called synchronously for each divergence. Divergences are logged if unset
+}
+
+// An evaluation engine run in shadow of the SDK's evaluator. Called synchronously on the evaluating goroutine
+type ShadowEvaluator interface {
+	// Evaluates the spec for a user already normalized with the environment. specType is one of
+	// SpecTypeFeatureGate, SpecTypeDynamicConfig or SpecTypeLayer
+	Evaluate(specType string, name string, user User) (Result, error)
+}
+
+// An evaluation whose pass, value or rule ID differs between the SDK's evaluator and the shadow evaluator
+type ShadowDivergence struct {
+	Type     string // One of SpecTypeFeatureGate, SpecTypeDynamicConfig or SpecTypeLayer
+	Name     string
+	User     User
+	Expected Result // From the SDK's evaluator
+	Actual   Result // From the shadow evaluator, empty if it returned an error
+	Err      error
+}
+
+type ShadowEvaluationStats struct {
+	Compared          int64
+	Diverged          int64            // Including evaluations the shadow evaluator returned an error for
+	DivergencesBySpec map[string]int64 // Keyed by type:name, e.g. feature_gate:new_checkout
+}
+
+type shadowEvaluation struct {
+	e         *evaluator
+	evaluator ShadowEvaluator // The reference engine if nil
+	options   ShadowEvaluationOptions
+	stats     ShadowEvaluationStats
+	mu        sync.Mutex
+}
+
+func newShadowEvaluation(e *evaluator, options *Options) *shadowEvaluation {
+	shadowOptions := options.ShadowEvaluationOptions
+	if shadowOptions.SampleRate <= 0 {
+		return nil
+	}
+	return &shadowEvaluation{
+		e:         e,
+		evaluator: shadowOptions.Evaluator,
+		options:   shadowOptions,
+		stats:     ShadowEvaluationStats{DivergencesBySpec: make(map[string]int64)},
+	}
+}
+
+func (s *shadowEvaluation) compare(specType string, name string, user User, res *evalResult) {
+	// The evaluation details record which ruleset the result was evaluated against
+	if s == nil || res.FetchFromServer || res.EvaluationDetails == nil {
+		return
+	}
+	switch res.EvaluationDetails.reason {
+	case reasonLocalOverride, reasonPersisted, reasonUnrecognized:
+		return
+	}
+	rate := s.options.SampleRate
+	if rate < 1 && rand.Float64() >= rate {
+		return
+	}
+	// Both engines evaluate the ruleset the SDK's evaluator used, so a sync in between is not a divergence
+	ruleset := s.e.store.pinRuleset()
+	if ruleset.lastSyncTime != res.EvaluationDetails.configSyncTime {
+		return
+	}
+	expected := newShadowResult(specType, name, res)
+	actual, err := s.evaluate(specType, name, user, ruleset)
+	if s.evaluator != nil {
+		// Custom evaluators read their own ruleset, which cannot be pinned, so comparisons a sync lands in are dropped
+		s.e.store.mu.RLock()
+		synced := s.e.store.lastSyncTime != ruleset.lastSyncTime
+		s.e.store.mu.RUnlock()
+		if synced {
+			return
+		}
+	}
+	diverged := err != nil || expected.Pass != actual.Pass || expected.RuleID != actual.RuleID ||
+		(specType != SpecTypeFeatureGate && !reflect.DeepEqual(expected.Value, actual.Value))
+	s.mu.Lock()
+	s.stats.Compared++
+	if diverged {
+		s.stats.Diverged++
+		s.stats.DivergencesBySpec[specType+":"+name]++
+	}
+	s.mu.Unlock()
+	if !diverged {
+		return
+	}
+	divergence := ShadowDivergence{Type: specType, Name: name, User: user, Expected: expected, Actual: actual, Err: err}
+	if s.options.OnDivergence == nil {
+		if err != nil {
+			Logger().LogError(fmt.Sprintf("Shadow evaluator failed for %s %s: %s\n", specType, name, err.Error()))
+		} else {
+			Logger().Log(fmt.Sprintf("Shadow evaluation divergence for %s %s: expected %v (rule %s), received %v (rule %s)\n",
+				specType, name, expected.Pass, expected.RuleID, actual.Pass, actual.RuleID), nil)
+		}
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("ShadowEvaluationOptions.OnDivergence panicked: %s\n", toError(err).Error()))
+		}
+	}()
+	s.options.OnDivergence(divergence)
+}
+
+// Panics in the shadow evaluator are reported as divergences rather than failing the evaluation
+func (s *shadowEvaluation) evaluate(specType string, name string, user User, ruleset *store) (result Result, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result, err = Result{}, toError(recovered)
+		}
+	}()
+	if s.evaluator == nil {
+		return referenceEvaluator{e: s.e.forReference(ruleset)}.Evaluate(specType, name, user)
+	}
+	return s.evaluator.Evaluate(specType, name, user)
+}
+
+// A store serving the current ruleset and ID lists, which later syncs do not change
+func (s *store) pinRuleset() *store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	idLists := make(map[string]*idList, len(s.idLists))
+	for name, list := range s.idLists {
+		idLists[name] = list
+	}
+	return &store{
+		featureGates:      s.featureGates,
+		dynamicConfigs:    s.dynamicConfigs,
+		layerConfigs:      s.layerConfigs,
+		experimentToLayer: s.experimentToLayer,
+		idLists:           idLists,
+		lastSyncTime:      s.lastSyncTime,
+		initialSyncTime:   s.initialSyncTime,
+		initReason:        s.initReason,
+		errorBoundary:     s.errorBoundary,
+		diagnostics:       s.diagnostics,
+		sdkKey:            s.sdkKey,
+		options:           s.options,
+		transport:         s.transport,
+	}
+}
+
+func (s *shadowEvaluation) getStats() ShadowEvaluationStats {
+	if s == nil {
+		return ShadowEvaluationStats{DivergencesBySpec: map[string]int64{}}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.DivergencesBySpec = make(map[string]int64, len(s.stats.DivergencesBySpec))
+	for key, count := range s.stats.DivergencesBySpec {
+		stats.DivergencesBySpec[key] = count
+	}
+	return stats
+}
+
+func newShadowResult(specType string, name string, res *evalResult) Result {
+	result := Result{Name: name, Type: specType, Pass: res.Pass, Value: map[string]interface{}{}, RuleID: res.RuleID, GroupName: res.GroupName}
+	if specType != SpecTypeFeatureGate {
+		result.Value = res.ConfigValue.Value
+		result.GroupName = res.ConfigValue.GroupName
+	}
+	return result
+}
+
+// Evaluates specs the way the SDK did before evaluation plans: conditions are parsed on every evaluation,
+// and constant and precomputed gates are evaluated in full
+type referenceEvaluator struct {
+	e *evaluator
+}
+
+// Shares the overrides, but evaluates the given store without the plans compiled at ingest
+func (e *evaluator) forReference(store *store) *evaluator {
+	return &evaluator{
+		store:                  store,
+		countryLookup:          e.countryLookup,
+		uaParser:               e.uaParser,
+		persistentStorageUtils: e.persistentStorageUtils,
+		options:                e.options,
+		parent:                 e,
+		tenant:                 e.tenant,
+		reference:              true,
+	}
+}
+
+func (r referenceEvaluator) Evaluate(specType string, name string, user User) (Result, error) {
+	var spec configSpec
+	var ok bool
+	switch specType {
+	case SpecTypeFeatureGate:
+		spec, ok = r.e.store.getGate(name)
+	case SpecTypeDynamicConfig:
+		spec, ok = r.e.store.getDynamicConfig(name)
+	case SpecTypeLayer:
+		spec, ok = r.e.store.getLayerConfig(name)
+	}
+	if !ok {
+		return Result{}, fmt.Errorf("No %s named %s in the ruleset", specType, name)
+	}
+	return newShadowResult(specType, name, r.e.eval(user, spec, 1)), nil
+}
+
+// The reference engine compiles conditions on every evaluation
+func (e *evaluator) compiledCondition(cond configCondition) *compiledCondition {
+	if e.reference {
+		return compileCondition(cond)
+	}
+	return cond.getCompiled()
+}
@@ -0,0 +1,122 @@
+package statsig
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+type flippedGateEvaluator struct{}
+
+func (flippedGateEvaluator) Evaluate(specType string, name string, user User) (Result, error) {
+	if name == "on_for_statsig_email" {
+		panic("not implemented")
+	}
+	return Result{Name: name, Type: specType, Pass: false, Value: map[string]interface{}{}, RuleID: "6N6Z8ODekNYZ7F8gFdoLP5"}, nil
+}
+
+func TestShadowEvaluation(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	newShadowClient := func(options ShadowEvaluationOptions) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:               true,
+			BootstrapValues:         string(specs),
+			StatsigLoggerOptions:    getStatsigLoggerOptionsForTest(t),
+			ShadowEvaluationOptions: options,
+		})
+	}
+	users := []User{
+		{UserID: "a_user"},
+		{UserID: "b_user", Email: "b@statsig.com"},
+		{UserID: "c_user", Email: "c@example.com", Country: "US"},
+	}
+
+	t.Run("the reference engine agrees with the evaluator", func(t *testing.T) {
+		var divergences []ShadowDivergence
+		c := newShadowClient(ShadowEvaluationOptions{
+			SampleRate:   1,
+			OnDivergence: func(divergence ShadowDivergence) { divergences = append(divergences, divergence) },
+		})
+		defer c.Shutdown()
+		for _, user := range users {
+			c.CheckGate(user, "always_on_gate")
+			c.CheckGate(user, "on_for_statsig_email")
+			c.CheckGate(user, "fractional_gate")
+			c.GetConfig(user, "test_config")
+			c.GetExperiment(user, "sample_experiment")
+			c.GetLayer(user, "a_layer")
+		}
+		stats := c.GetShadowEvaluationStats()
+		if stats.Compared != 18 || stats.Diverged != 0 || len(divergences) != 0 {
+			t.Errorf("Expected 18 comparisons without divergences, received %+v %+v", stats, divergences)
+		}
+
+		c.OverrideGate("always_on_gate", false)
+		c.CheckGate(users[0], "always_on_gate")
+		c.CheckGate(users[0], "not_a_gate")
+		if compared := c.GetShadowEvaluationStats().Compared; compared != 18 {
+			t.Errorf("Expected overridden and unrecognized specs not to be compared, received %d comparisons", compared)
+		}
+	})
+
+	t.Run("reports divergences and shadow evaluator failures", func(t *testing.T) {
+		var divergences []ShadowDivergence
+		c := newShadowClient(ShadowEvaluationOptions{
+			SampleRate:   1,
+			Evaluator:    flippedGateEvaluator{},
+			OnDivergence: func(divergence ShadowDivergence) { divergences = append(divergences, divergence) },
+		})
+		defer c.Shutdown()
+		if !c.CheckGate(users[0], "always_on_gate") {
+			t.Errorf("Expected results to come from the SDK's evaluator")
+		}
+		c.CheckGate(users[1], "on_for_statsig_email")
+		if len(divergences) != 2 {
+			t.Fatalf("Expected 2 divergences, received %+v", divergences)
+		}
+		first := divergences[0]
+		if first.Type != SpecTypeFeatureGate || first.Name != "always_on_gate" || !first.Expected.Pass || first.Actual.Pass || first.Err != nil {
+			t.Errorf("Unexpected divergence %+v", first)
+		}
+		if divergences[1].Err == nil {
+			t.Errorf("Expected the shadow evaluator panic to be reported, received %+v", divergences[1])
+		}
+		if stats := c.GetShadowEvaluationStats(); stats.Diverged != 2 || stats.DivergencesBySpec["feature_gate:always_on_gate"] != 1 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("compares against the ruleset the evaluator used", func(t *testing.T) {
+		var divergences []ShadowDivergence
+		c := newShadowClient(ShadowEvaluationOptions{
+			SampleRate:   1,
+			OnDivergence: func(divergence ShadowDivergence) { divergences = append(divergences, divergence) },
+		})
+		defer c.Shutdown()
+		res := c.evaluator.evalGate(users[0], "always_on_gate", 0)
+		ruleset := c.evaluator.store.pinRuleset()
+
+		var updated downloadConfigSpecResponse
+		_ = json.Unmarshal(specs, &updated)
+		updated.Time++
+		for i := range updated.FeatureGates {
+			if updated.FeatureGates[i].Name == "always_on_gate" {
+				updated.FeatureGates[i].Enabled = false
+			}
+		}
+		c.evaluator.store.setConfigSpecs(updated)
+
+		if actual, err := c.evaluator.shadow.evaluate(SpecTypeFeatureGate, "always_on_gate", users[0], ruleset); err != nil || !actual.Pass {
+			t.Errorf("Expected the reference engine to evaluate the pinned ruleset, received %+v %v", actual, err)
+		}
+		c.evaluator.shadow.compare(SpecTypeFeatureGate, "always_on_gate", users[0], res)
+		if stats := c.GetShadowEvaluationStats(); stats.Compared != 0 || len(divergences) != 0 {
+			t.Errorf("Expected a result from the previous ruleset not to be compared, received %+v %+v", stats, divergences)
+		}
+		c.CheckGate(users[0], "always_on_gate")
+		if stats := c.GetShadowEvaluationStats(); stats.Compared != 1 || stats.Diverged != 0 {
+			t.Errorf("Expected results from the current ruleset to be compared, received %+v", stats)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package statsig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var sharedMemoryFileNameSanitizer = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+/**
+ * A data adapter backed by memory-mapped files in a shared directory, e.g. on /dev/shm, for hosts
+ * running many processes with the same SDK key. Exactly one process per host should be created as
+ * the syncer; it polls Statsig and publishes every update. All other processes read the published
+ * snapshot instead of polling the network themselves. Updates are published by atomic rename,
+ * so readers always see a complete file without locking. Platforms without mmap read and write
+ * the files directly.
+ */
+type sharedMemoryDataAdapter struct {
+	dir      string
+	isSyncer bool
+}
+
+// Creates a data adapter that shares config specs and ID lists between processes through files in dir
+func NewSharedMemoryDataAdapter(dir string, isSyncer bool) IDataAdapter {
+	return &sharedMemoryDataAdapter{dir: dir, isSyncer: isSyncer}
+}
+
+func (d *sharedMemoryDataAdapter) Initialize() {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to create shared memory directory %s: %s\n", d.dir, err.Error()))
+	}
+}
+
+func (d *sharedMemoryDataAdapter) Shutdown() {}
+
+func (d *sharedMemoryDataAdapter) Get(key string) string {
+	contents, err := readMappedFile(d.pathForKey(key))
+	if err != nil {
+		return ""
+	}
+	return contents
+}
+
+func (d *sharedMemoryDataAdapter) Set(key string, value string) {
+	if !d.isSyncer {
+		return
+	}
+	path := d.pathForKey(key)
+	tmp, err := os.CreateTemp(d.dir, ".tmp-")
+	if err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to write shared memory file %s: %s\n", path, err.Error()))
+		return
+	}
+	err = writeMappedFile(tmp, value)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	tmp.Close()
+	if err == nil {
+		// Rename is atomic, so readers holding a mapping of the previous file are unaffected
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		Logger().LogError(fmt.Sprintf("Failed to write shared memory file %s: %s\n", path, err.Error()))
+	}
+}
+
+func (d *sharedMemoryDataAdapter) ShouldBeUsedForQueryingUpdates(key string) bool {
+	return !d.isSyncer
+}
+
+func (d *sharedMemoryDataAdapter) pathForKey(key string) string {
+	return filepath.Join(d.dir, sharedMemoryFileNameSanitizer.ReplaceAllString(key, "_"))
+}
@@ -0,0 +1,22 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package statsig
+
+import (
+	"os"
+)
+
+// Reads the file into memory, since it cannot be mapped on this platform
+func readMappedFile(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(contents), nil
+}
+
+func writeMappedFile(f *os.File, contents string) error {
+	_, err := f.WriteString(contents)
+	return err
+}
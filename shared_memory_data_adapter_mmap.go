@@ -0,0 +1,47 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package statsig
+
+import (
+	"os"
+	"syscall"
+)
+
+// Maps the file read-only and copies it out, so the mapping is released before the file is replaced
+func readMappedFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() == 0 {
+		return "", nil
+	}
+	mapped, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = syscall.Munmap(mapped) }()
+	return string(mapped), nil
+}
+
+// Sizes the file and writes the contents through a shared mapping
+func writeMappedFile(f *os.File, contents string) error {
+	if len(contents) == 0 {
+		return nil
+	}
+	if err := f.Truncate(int64(len(contents))); err != nil {
+		return err
+	}
+	mapped, err := syscall.Mmap(int(f.Fd()), 0, len(contents), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	copy(mapped, contents)
+	return syscall.Munmap(mapped)
+}
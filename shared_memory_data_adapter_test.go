@@ -0,0 +1,72 @@
+package statsig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSharedMemoryDataAdapter(t *testing.T) {
+	dir := t.TempDir()
+	var dcsCount int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			atomic.AddInt32(&dcsCount, 1)
+			bytes, _ := os.ReadFile("download_config_specs.json")
+			_, _ = res.Write(bytes)
+		}
+	}))
+	defer testServer.Close()
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+
+	t.Run("readers load specs published by the syncer", func(t *testing.T) {
+		syncer := NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			DataAdapter:          NewSharedMemoryDataAdapter(dir, true),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+		defer syncer.Shutdown()
+		if atomic.LoadInt32(&dcsCount) != 1 {
+			t.Errorf("Expected the syncer to download config specs once")
+		}
+
+		reader := NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			DataAdapter:          NewSharedMemoryDataAdapter(dir, false),
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+		defer reader.Shutdown()
+		if atomic.LoadInt32(&dcsCount) != 1 {
+			t.Errorf("Expected the reader to not download config specs")
+		}
+		if !reader.CheckGate(User{UserID: "a_user"}, "always_on_gate") {
+			t.Errorf("Expected always_on_gate to pass from shared specs")
+		}
+		if reader.evaluator.store.initReason != reasonDataAdapter {
+			t.Errorf("Expected reader to be initialized from the data adapter")
+		}
+	})
+
+	t.Run("only the syncer writes and polls the network", func(t *testing.T) {
+		reader := NewSharedMemoryDataAdapter(dir, false)
+		reader.Set("some_key", "value")
+		if reader.Get("some_key") != "" {
+			t.Errorf("Expected readers to never write")
+		}
+		if !reader.ShouldBeUsedForQueryingUpdates(CONFIG_SPECS_KEY) {
+			t.Errorf("Expected readers to poll the shared snapshot")
+		}
+		syncer := NewSharedMemoryDataAdapter(dir, true)
+		syncer.Set("statsig.id_lists::list_1", "+abc\n")
+		if reader.Get("statsig.id_lists::list_1") != "+abc\n" {
+			t.Errorf("Expected reader to see value written by the syncer")
+		}
+		if syncer.ShouldBeUsedForQueryingUpdates(CONFIG_SPECS_KEY) {
+			t.Errorf("Expected the syncer to poll the network")
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package statsig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdownCtx(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var logged, logAttempts, blockIDLists int32
+	idListRequested := make(chan struct{}, 1)
+	releaseIDLists := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			_, _ = res.Write(specs)
+		case strings.Contains(req.URL.Path, "get_id_lists"):
+			if atomic.LoadInt32(&blockIDLists) == 1 {
+				select {
+				case idListRequested <- struct{}{}:
+				default:
+				}
+				<-releaseIDLists
+			}
+			_, _ = res.Write([]byte("{}"))
+		case strings.Contains(req.URL.Path, "log_event"):
+			// The first attempt fails, so the final flush must retry
+			if atomic.AddInt32(&logAttempts, 1) == 1 {
+				res.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			var input logEventInput
+			_ = json.NewDecoder(req.Body).Decode(&input)
+			for _, evt := range input.Events {
+				if m, ok := evt.(map[string]interface{}); ok && m["eventName"] == "shutdown_event" {
+					atomic.AddInt32(&logged, 1)
+				}
+			}
+			_, _ = res.Write([]byte("{}"))
+		default:
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+	newShutdownClient := func(idListSyncInterval time.Duration) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                testServer.URL,
+			LoggingInterval:    time.Hour,
+			ConfigSyncInterval: time.Hour,
+			IDListSyncInterval: idListSyncInterval,
+			RetryOptions:       RetryOptions{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+		})
+	}
+
+	// Pollers waiting on an hour-long interval exit immediately, and concurrent calls share one shutdown
+	c := newShutdownClient(time.Hour)
+	c.LogEvent(Event{EventName: "shutdown_event", User: User{UserID: "1"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.ShutdownCtx(ctx)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("Expected the shutdown to succeed, received %v", err)
+		}
+	}
+	if atomic.LoadInt32(&logged) != 1 || atomic.LoadInt32(&logAttempts) < 2 {
+		t.Errorf("Expected pending events to be sent once with retries, received %d in %d attempts", logged, logAttempts)
+	}
+	if err := c.evaluator.store.drainPollers(ctx); err != nil {
+		t.Errorf("Expected the pollers to have exited, received %v", err)
+	}
+
+	// In-flight ID list downloads are waited for
+	c = newShutdownClient(10 * time.Millisecond)
+	atomic.StoreInt32(&blockIDLists, 1)
+	select {
+	case <-idListRequested:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected an ID list sync to start")
+	}
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+	if err := c.ShutdownCtx(short); err == nil {
+		t.Errorf("Expected an error when the download outlasts ctx")
+	}
+	close(releaseIDLists)
+	done := make(chan error, 1)
+	go func() { done <- c.ShutdownCtx(context.Background()) }()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "Syncs still running") {
+			t.Errorf("Expected later calls to report the interrupted drain, received %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the shutdown to complete once the download finished")
+	}
+}
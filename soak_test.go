@@ -0,0 +1,185 @@
+//go:build soak
+// +build soak
+
+package statsig
+
+// Long running soak test of a client against a mock server that alternates between two rulesets
+// on every sync. Run with
+//
+//	test_api_key=secret-xxx go test -tags soak -run TestSoak -timeout 0 -soak.duration 4h
+//
+// The test fails if the goroutine count or the heap grows past their bounds, or if evaluations
+// for a ruleset differ from the first evaluations seen for that ruleset.
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	soakDuration       = flag.Duration("soak.duration", 2*time.Hour, "How long to run the soak test for")
+	soakSyncInterval   = flag.Duration("soak.sync", 50*time.Millisecond, "Config spec and ID list sync interval")
+	soakSampleInterval = flag.Duration("soak.sample", 10*time.Second, "How often goroutines and memory are checked")
+	soakWarmUp         = flag.Duration("soak.warmup", 30*time.Second, "Time before the goroutine and memory baselines are taken")
+	soakGoroutineSlack = flag.Int("soak.goroutines", 25, "Goroutines allowed above the baseline")
+	soakHeapSlackMB    = flag.Int("soak.heap", 32, "Heap megabytes allowed above twice the baseline")
+)
+
+const (
+	soakBaseTime = int64(1000)
+	soakUsers    = 200
+)
+
+// Serves download_config_specs.json with always_on_gate disabled on odd generations, so
+// evaluations change on every sync while the ruleset time keeps increasing
+func newSoakServer(t *testing.T) (*httptest.Server, *int64) {
+	raw, err := os.ReadFile("download_config_specs.json")
+	if err != nil {
+		t.Fatalf("Failed to read download_config_specs.json: %s", err.Error())
+	}
+	var rulesets [2][]byte
+	for parity := range rulesets {
+		var specs map[string]interface{}
+		_ = json.Unmarshal(raw, &specs)
+		for _, gate := range specs["feature_gates"].([]interface{}) {
+			gate := gate.(map[string]interface{})
+			if gate["name"] == "always_on_gate" {
+				gate["enabled"] = parity == 0
+			}
+		}
+		specs["time"] = -1
+		rulesets[parity], _ = json.Marshal(specs)
+	}
+	var listContent strings.Builder
+	for i := 0; i < soakUsers; i += 3 {
+		h := sha256.Sum256([]byte(fmt.Sprintf("user-%d", i)))
+		listContent.WriteString("+" + base64.StdEncoding.EncodeToString(h[:])[:8] + "\n")
+	}
+
+	generation := new(int64)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			g := atomic.AddInt64(generation, 1)
+			body := strings.Replace(string(rulesets[g%2]), `"time":-1`, fmt.Sprintf(`"time":%d`, soakBaseTime+g), 1)
+			_, _ = res.Write([]byte(body))
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			v, _ := json.Marshal(map[string]idList{
+				"list_1": {Name: "list_1", Size: int64(listContent.Len()), URL: "http://" + req.Host + "/list_1", CreationTime: 1, FileID: "file_1"},
+			})
+			_, _ = res.Write(v)
+		} else if strings.HasSuffix(req.URL.Path, "/list_1") {
+			_, _ = res.Write([]byte(listContent.String()))
+		}
+	}))
+	return server, generation
+}
+
+// Evaluates a fixed set of specs for every soak user without logging exposures
+func soakEvaluations(c *Client) string {
+	var b strings.Builder
+	for i := 0; i < soakUsers; i++ {
+		user := User{UserID: fmt.Sprintf("user-%d", i), Email: fmt.Sprintf("user-%d@statsig.com", i)}
+		if i%2 == 0 {
+			user.Email = fmt.Sprintf("user-%d@example.com", i)
+		}
+		fmt.Fprintf(&b, "%t %t %t %t %s %s %s|",
+			c.CheckGateWithExposureLoggingDisabled(user, "always_on_gate"),
+			c.CheckGateWithExposureLoggingDisabled(user, "on_for_statsig_email"),
+			c.CheckGateWithExposureLoggingDisabled(user, "on_for_id_list"),
+			c.CheckGateWithExposureLoggingDisabled(user, "fractional_gate"),
+			c.GetConfigWithExposureLoggingDisabled(user, "test_config").RuleID,
+			c.GetExperimentWithExposureLoggingDisabled(user, "sample_experiment").RuleID,
+			c.GetLayerWithExposureLoggingDisabled(user, "a_layer").RuleID,
+		)
+	}
+	return b.String()
+}
+
+func soakSyncTime(c *Client) int64 {
+	c.evaluator.store.mu.RLock()
+	defer c.evaluator.store.mu.RUnlock()
+	return c.evaluator.store.lastSyncTime
+}
+
+func soakHeapAlloc() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func TestSoak(t *testing.T) {
+	server, generation := newSoakServer(t)
+	defer server.Close()
+
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  server.URL,
+		ConfigSyncInterval:   *soakSyncInterval,
+		IDListSyncInterval:   *soakSyncInterval,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	expected := map[int64]string{}
+	var checks, skipped int64
+	var baselineGoroutines int
+	var baselineHeap uint64
+	start := time.Now()
+	nextSample := start.Add(*soakWarmUp)
+	for time.Since(start) < *soakDuration {
+		// Exercise exposure logging alongside the checked evaluations
+		c.CheckGate(User{UserID: fmt.Sprintf("user-%d", checks%soakUsers)}, "always_on_gate")
+		c.LogEvent(Event{EventName: "soak", User: User{UserID: "soak"}})
+
+		before := soakSyncTime(c)
+		evaluations := soakEvaluations(c)
+		if after := soakSyncTime(c); after != before || before <= soakBaseTime {
+			// A sync landed mid-check, the evaluations may span two rulesets
+			skipped++
+			continue
+		}
+		parity := (before - soakBaseTime) % 2
+		if previous, ok := expected[parity]; !ok {
+			expected[parity] = evaluations
+		} else if previous != evaluations {
+			t.Fatalf("Evaluations for ruleset %d differ from earlier evaluations of the same ruleset", before)
+		}
+		checks++
+
+		if time.Now().Before(nextSample) {
+			continue
+		}
+		nextSample = time.Now().Add(*soakSampleInterval)
+		goroutines := runtime.NumGoroutine()
+		heap := soakHeapAlloc()
+		if baselineGoroutines == 0 {
+			baselineGoroutines, baselineHeap = goroutines, heap
+			t.Logf("Baseline: %d goroutines, %d KB heap", goroutines, heap/1024)
+			continue
+		}
+		t.Logf("%s: %d syncs, %d checks (%d skipped), %d goroutines, %d KB heap",
+			time.Since(start).Round(time.Second), atomic.LoadInt64(generation), checks, skipped, goroutines, heap/1024)
+		if goroutines > baselineGoroutines+*soakGoroutineSlack {
+			t.Fatalf("Goroutines grew from %d to %d", baselineGoroutines, goroutines)
+		}
+		if heapLimit := 2*baselineHeap + uint64(*soakHeapSlackMB)*1024*1024; heap > heapLimit {
+			t.Fatalf("Heap grew from %d KB to %d KB", baselineHeap/1024, heap/1024)
+		}
+	}
+	if len(expected) != 2 {
+		t.Errorf("Expected evaluations against both rulesets, only saw %d", len(expected))
+	}
+}
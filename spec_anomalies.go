@@ -0,0 +1,121 @@
+package statsig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of SpecAnomalyError
+const (
+	SpecAnomalyCountShrink    = "count_shrink"     // A sync left far fewer specs of a type than the previous ruleset
+	SpecAnomalySaltChange     = "salt_change"      // Existing specs were re-salted, reshuffling every user's assignment
+	SpecAnomalyRuleCountSwing = "rule_count_swing" // Existing specs gained or lost most of their rules
+)
+
+const (
+	defaultSpecShrinkFactor    = 2
+	defaultSpecRuleSwingFactor = 5
+	// Rulesets with fewer specs of a type than this are too small for count shrinks to be meaningful
+	minSpecsForShrinkAnomaly = 10
+)
+
+// Reports syncs that change the ruleset in ways that usually indicate a console misconfiguration.
+// Anomalies are logged, counted in MetricSpecAnomalies and passed to Options.OnSDKError. The ruleset is
+// still applied.
+type SpecAnomalyOptions struct {
+	ShrinkFactor    float64 // A sync leaving fewer than 1/ShrinkFactor of the previous gates, configs or layers is reported. Defaults to 2
+	RuleSwingFactor float64 // Specs whose rule count grows or shrinks by this factor are reported. Defaults to 5
+	Disabled        bool
+}
+
+// Passed to Options.OnSDKError when a sync changes the ruleset anomalously
+type SpecAnomalyError struct {
+	Kind     string   // SpecAnomalyCountShrink, SpecAnomalySaltChange or SpecAnomalyRuleCountSwing
+	SpecType string   // SpecTypeFeatureGate, SpecTypeDynamicConfig or SpecTypeLayer
+	Names    []string // The re-salted or swinging specs, sorted. Empty for count shrinks
+	Previous int      // The spec count before a count shrink
+	Current  int      // The spec count after a count shrink
+	SyncTime int64    // The time of the ruleset that caused the anomaly
+}
+
+func (e *SpecAnomalyError) Error() string {
+	switch e.Kind {
+	case SpecAnomalyCountShrink:
+		return fmt.Sprintf("Config spec sync at %d shrank %s specs from %d to %d", e.SyncTime, e.SpecType, e.Previous, e.Current)
+	case SpecAnomalySaltChange:
+		return fmt.Sprintf("Config spec sync at %d changed the salt of %s specs: %s", e.SyncTime, e.SpecType, strings.Join(e.Names, ", "))
+	default:
+		return fmt.Sprintf("Config spec sync at %d swung the rule count of %s specs: %s", e.SyncTime, e.SpecType, strings.Join(e.Names, ", "))
+	}
+}
+
+// Compares one spec type of the incoming ruleset against the one it replaces
+func detectSpecAnomalies(options SpecAnomalyOptions, specType string, previous map[string]configSpec, current map[string]configSpec, syncTime int64) []*SpecAnomalyError {
+	if options.Disabled || len(previous) == 0 {
+		return nil
+	}
+	shrinkFactor := options.ShrinkFactor
+	if shrinkFactor <= 1 {
+		shrinkFactor = defaultSpecShrinkFactor
+	}
+	swingFactor := options.RuleSwingFactor
+	if swingFactor <= 1 {
+		swingFactor = defaultSpecRuleSwingFactor
+	}
+	var anomalies []*SpecAnomalyError
+	if len(previous) >= minSpecsForShrinkAnomaly && float64(len(current))*shrinkFactor < float64(len(previous)) {
+		anomalies = append(anomalies, &SpecAnomalyError{
+			Kind: SpecAnomalyCountShrink, SpecType: specType, Previous: len(previous), Current: len(current), SyncTime: syncTime,
+		})
+	}
+	var resalted, swinging []string
+	for name, spec := range current {
+		old, ok := previous[name]
+		if !ok {
+			continue
+		}
+		if old.Salt != spec.Salt {
+			resalted = append(resalted, name)
+		}
+		fewer, more := len(old.Rules), len(spec.Rules)
+		if fewer > more {
+			fewer, more = more, fewer
+		}
+		if fewer < 1 {
+			fewer = 1
+		}
+		if float64(more) >= swingFactor*float64(fewer) {
+			swinging = append(swinging, name)
+		}
+	}
+	if len(resalted) > 0 {
+		sort.Strings(resalted)
+		anomalies = append(anomalies, &SpecAnomalyError{Kind: SpecAnomalySaltChange, SpecType: specType, Names: resalted, SyncTime: syncTime})
+	}
+	if len(swinging) > 0 {
+		sort.Strings(swinging)
+		anomalies = append(anomalies, &SpecAnomalyError{Kind: SpecAnomalyRuleCountSwing, SpecType: specType, Names: swinging, SyncTime: syncTime})
+	}
+	return anomalies
+}
+
+func (s *store) reportSpecAnomalies(anomalies []*SpecAnomalyError) {
+	for _, anomaly := range anomalies {
+		Logger().LogError(anomaly)
+		s.metrics.specAnomaly(anomaly)
+		reportSDKError(s.options, anomaly)
+	}
+}
+
+func reportSDKError(options *Options, err error) {
+	if options.OnSDKError == nil {
+		return
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			Logger().LogError(fmt.Sprintf("OnSDKError panicked: %s\n", toError(recovered).Error()))
+		}
+	}()
+	options.OnSDKError(err)
+}
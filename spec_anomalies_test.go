@@ -0,0 +1,102 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSpecAnomalies(t *testing.T) {
+	rules := func(count int) string {
+		parts := make([]string, count)
+		for i := range parts {
+			parts[i] = fmt.Sprintf(`{"name": "r%d", "id": "r%d", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}`, i, i)
+		}
+		return strings.Join(parts, ",")
+	}
+	ruleset := func(time int, gates int, salt string, swingRules int) string {
+		parts := make([]string, gates)
+		for i := range parts {
+			ruleCount, gateSalt := 1, "salt"
+			if i == 0 {
+				gateSalt = salt
+			}
+			if i == 1 {
+				ruleCount = swingRules
+			}
+			parts[i] = fmt.Sprintf(`{"name": "gate_%d", "type": "feature_gate", "salt": "%s", "enabled": true, "defaultValue": false, "rules": [%s]}`, i, gateSalt, rules(ruleCount))
+		}
+		return fmt.Sprintf(`{"has_updates": true, "time": %d, "feature_gates": [%s], "dynamic_configs": [], "layer_configs": []}`, time, strings.Join(parts, ","))
+	}
+	applySpecs := func(c *Client, specsJSON string) {
+		var specs downloadConfigSpecResponse
+		if err := json.Unmarshal([]byte(specsJSON), &specs); err != nil {
+			t.Fatalf("Invalid test ruleset: %v", err)
+		}
+		c.evaluator.store.setConfigSpecs(specs)
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	var metrics []Metric
+	newAnomalyClient := func(anomalyOptions SpecAnomalyOptions) *Client {
+		errs, metrics = nil, nil
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      ruleset(1, 12, "salt", 1),
+			SpecAnomalyOptions:   anomalyOptions,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+			OnSDKError: func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			},
+			MetricsOptions: MetricsOptions{Hook: func(metric Metric) {
+				mu.Lock()
+				defer mu.Unlock()
+				if metric.Name == MetricSpecAnomalies {
+					metrics = append(metrics, metric)
+				}
+			}},
+		})
+	}
+
+	c := newAnomalyClient(SpecAnomalyOptions{})
+	defer c.Shutdown()
+	applySpecs(c, ruleset(2, 12, "salt", 2))
+	if len(errs) != 0 {
+		t.Errorf("Expected ordinary updates not to be reported, received %v", errs)
+	}
+	applySpecs(c, ruleset(3, 5, "new_salt", 5))
+	expected := []SpecAnomalyError{
+		{Kind: SpecAnomalyCountShrink, SpecType: SpecTypeFeatureGate, Previous: 12, Current: 5, SyncTime: 3},
+		{Kind: SpecAnomalySaltChange, SpecType: SpecTypeFeatureGate, Names: []string{"gate_0"}, SyncTime: 3},
+	}
+	if len(errs) != len(expected) || len(metrics) != len(expected) {
+		t.Fatalf("Expected %d anomalies, received %v", len(expected), errs)
+	}
+	for i, err := range errs {
+		anomaly, ok := err.(*SpecAnomalyError)
+		if !ok || anomaly.Error() != expected[i].Error() {
+			t.Errorf("Expected %v, received %v", expected[i].Error(), err)
+		}
+		if metrics[i].Labels["kind"] != expected[i].Kind || metrics[i].Labels["type"] != SpecTypeFeatureGate {
+			t.Errorf("Unexpected anomaly metric %+v", metrics[i])
+		}
+	}
+	// 2 rules to 5 is below the default swing factor, 5 to 0 is not
+	applySpecs(c, ruleset(4, 5, "new_salt", 0))
+	if len(errs) != 3 || errs[2].(*SpecAnomalyError).Kind != SpecAnomalyRuleCountSwing || errs[2].(*SpecAnomalyError).Names[0] != "gate_1" {
+		t.Errorf("Expected a rule count swing, received %v", errs)
+	}
+
+	d := newAnomalyClient(SpecAnomalyOptions{Disabled: true})
+	defer d.Shutdown()
+	applySpecs(d, ruleset(2, 1, "new_salt", 10))
+	if len(errs) != 0 {
+		t.Errorf("Expected no anomalies when disabled, received %v", errs)
+	}
+}
@@ -0,0 +1,62 @@
+package statsig
+
+import "sort"
+
+const (
+	SpecTypeFeatureGate   = "feature_gate"
+	SpecTypeDynamicConfig = "dynamic_config"
+	SpecTypeLayer         = "layer"
+)
+
+// A summary of a loaded gate, config, experiment or layer
+type SpecInfo struct {
+	Name               string
+	Type               string // One of SpecTypeFeatureGate, SpecTypeDynamicConfig or SpecTypeLayer
+	Entity             string // e.g. experiment, autotune or holdout for dynamic configs, as set in the console
+	Enabled            bool
+	IsActive           bool // Whether the experiment is running, false for other entities
+	IDType             string
+	RuleCount          int
+	Layer              string // The layer an experiment belongs to, if any
+	ExplicitParameters []string
+	TargetAppIDs       []string
+}
+
+// Snapshots the loaded specs, ordered by type then name, so that no lock is held while they are visited
+func (s *store) getSpecInfos() []SpecInfo {
+	s.mu.RLock()
+	infos := make([]SpecInfo, 0, len(s.featureGates)+len(s.dynamicConfigs)+len(s.layerConfigs))
+	for _, spec := range s.featureGates {
+		infos = append(infos, newSpecInfo(spec, SpecTypeFeatureGate, ""))
+	}
+	for _, spec := range s.dynamicConfigs {
+		infos = append(infos, newSpecInfo(spec, SpecTypeDynamicConfig, s.experimentToLayer[spec.Name]))
+	}
+	for _, spec := range s.layerConfigs {
+		infos = append(infos, newSpecInfo(spec, SpecTypeLayer, ""))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Type != infos[j].Type {
+			return infos[i].Type < infos[j].Type
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+func newSpecInfo(spec configSpec, specType string, layer string) SpecInfo {
+	return SpecInfo{
+		Name:               spec.Name,
+		Type:               specType,
+		Entity:             spec.Entity,
+		Enabled:            spec.Enabled,
+		IsActive:           spec.IsActive != nil && *spec.IsActive,
+		IDType:             spec.IDType,
+		RuleCount:          len(spec.Rules),
+		Layer:              layer,
+		ExplicitParameters: append([]string(nil), spec.ExplicitParameters...),
+		TargetAppIDs:       append([]string(nil), spec.TargetAppIDs...),
+	}
+}
@@ -0,0 +1,67 @@
+package statsig
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestForEachSpec(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(bytes),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	t.Run("visits every spec in a stable order", func(t *testing.T) {
+		names := make([]string, 0)
+		c.ForEachSpec(func(spec SpecInfo) bool {
+			names = append(names, spec.Type+":"+spec.Name)
+			// The callback runs without store locks held
+			c.CheckGate(User{UserID: "a_user"}, "always_on_gate")
+			return true
+		})
+		expected := []string{
+			"dynamic_config:sample_experiment",
+			"dynamic_config:test_config",
+			"feature_gate:always_on_gate",
+			"feature_gate:fractional_gate",
+			"feature_gate:on_for_id_list",
+			"feature_gate:on_for_statsig_email",
+			"layer:a_layer",
+			"layer:b_layer_no_alloc",
+			"layer:c_layer_with_holdout",
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("Expected %v, received %v", expected, names)
+		}
+	})
+
+	t.Run("stops when the callback returns false", func(t *testing.T) {
+		visited := 0
+		c.ForEachSpec(func(spec SpecInfo) bool {
+			visited++
+			return visited < 3
+		})
+		if visited != 3 {
+			t.Errorf("Expected 3 specs to be visited, received %d", visited)
+		}
+	})
+
+	t.Run("describes the spec", func(t *testing.T) {
+		var gate SpecInfo
+		c.ForEachSpec(func(spec SpecInfo) bool {
+			if spec.Name == "always_on_gate" {
+				gate = spec
+				return false
+			}
+			return true
+		})
+		if !gate.Enabled || gate.Type != SpecTypeFeatureGate || gate.RuleCount != 1 {
+			t.Errorf("Unexpected spec info %+v", gate)
+		}
+	})
+}
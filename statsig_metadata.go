@@ -2,6 +2,8 @@ package statsig
 
 import (
 	"runtime"
+
+	"github.com/google/uuid"
 )
 
 type statsigMetadata struct {
@@ -9,6 +11,7 @@ type statsigMetadata struct {
 	SDKVersion      string `json:"sdkVersion"`
 	LanguageVersion string `json:"languageVersion"`
 	SessionID       string `json:"sessionID"`
+	InstanceID      string `json:"instanceID,omitempty"` // Unique to each Client, while SessionID is shared by the process
 }
 
 func getStatsigMetadata() statsigMetadata {
@@ -19,3 +22,9 @@ func getStatsigMetadata() statsigMetadata {
 		SessionID:       SessionID(),
 	}
 }
+
+func getInstanceMetadata() statsigMetadata {
+	metadata := getStatsigMetadata()
+	metadata.InstanceID = uuid.NewString()
+	return metadata
+}
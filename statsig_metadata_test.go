@@ -1,9 +1,11 @@
 package statsig
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -51,3 +53,56 @@ func TestStatsigMetadata(t *testing.T) {
 	})
 	ShutdownAndDangerouslyClearInstance()
 }
+
+func TestInstanceID(t *testing.T) {
+	var mu sync.Mutex
+	headers := make(map[string]map[string]bool)
+	var loggedInstanceIDs []string
+	server := makeTestServer(func(req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if headers[req.URL.Path] == nil {
+			headers[req.URL.Path] = make(map[string]bool)
+		}
+		headers[req.URL.Path][req.Header.Get("STATSIG-SDK-INSTANCE-ID")] = true
+		if strings.Contains(req.URL.Path, "log_event") {
+			body := &logEventInput{}
+			_ = json.NewDecoder(req.Body).Decode(body)
+			loggedInstanceIDs = append(loggedInstanceIDs, body.StatsigMetadata.InstanceID)
+		}
+	})
+	defer server.Close()
+	newInstanceClient := func() *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			API:                  server.URL,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+
+	first, second := newInstanceClient(), newInstanceClient()
+	if first.GetInstanceID() == "" || first.GetInstanceID() == second.GetInstanceID() {
+		t.Errorf("Expected a unique instance ID per client, received %q and %q", first.GetInstanceID(), second.GetInstanceID())
+	}
+	first.LogEvent(Event{EventName: "event", User: User{UserID: "123"}})
+	first.Shutdown()
+	second.Shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for path, ids := range headers {
+		if strings.Contains(path, "download_config_specs") && (!ids[first.GetInstanceID()] || !ids[second.GetInstanceID()]) {
+			t.Errorf("Expected requests to carry each client's instance ID, received %v", ids)
+		}
+		if ids[""] {
+			t.Errorf("Expected every request to %s to carry an instance ID", path)
+		}
+	}
+	var loggedByFirst bool
+	for _, id := range loggedInstanceIDs {
+		loggedByFirst = loggedByFirst || id == first.GetInstanceID()
+	}
+	if !loggedByFirst {
+		t.Errorf("Expected events to carry the instance ID in their metadata, received %v", loggedInstanceIDs)
+	}
+}
@@ -1,10 +1,13 @@
 package statsig
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"net/http"
 	"strconv"
@@ -27,6 +30,21 @@ type configSpec struct {
 	IsActive           *bool           `json:"isActive,omitempty"`
 	HasSharedParams    *bool           `json:"hasSharedParams,omitempty"`
 	TargetAppIDs       []string        `json:"targetAppIDs,omitempty"`
+	DefaultVariant     string          `json:"defaultVariant,omitempty"` // Returned by GetVariant when no rule with variants passes
+	constant           *constantGate   // Set at ingest for gates whose result does not depend on the user
+	hash               uint64          // Of the spec's raw JSON, set while decoding to count changes between syncs
+}
+
+type configSpecJSON configSpec
+
+func (c *configSpec) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*configSpecJSON)(c)); err != nil {
+		return err
+	}
+	hasher := fnv.New64a()
+	_, _ = hasher.Write(data)
+	c.hash = hasher.Sum64()
+	return nil
 }
 
 func (c configSpec) hasTargetAppID(appId string) bool {
@@ -52,6 +70,7 @@ type configRule struct {
 	IDType            string            `json:"idType"`
 	ConfigDelegate    string            `json:"configDelegate"`
 	IsExperimentGroup *bool             `json:"isExperimentGroup,omitempty"`
+	Variants          []gateVariant     `json:"variants,omitempty"` // Weighted string variants assigned to users passing a gate's rule
 }
 
 type configCondition struct {
@@ -61,6 +80,7 @@ type configCondition struct {
 	TargetValue      interface{}            `json:"targetValue"`
 	AdditionalValues map[string]interface{} `json:"additionalValues"`
 	IDType           string                 `json:"idType"`
+	compiled         *compiledCondition
 }
 
 type downloadConfigSpecResponse struct {
@@ -78,12 +98,55 @@ type downloadConfigSpecResponse struct {
 }
 
 type idList struct {
-	Name         string `json:"name"`
-	Size         int64  `json:"size"`
-	CreationTime int64  `json:"creationTime"`
-	URL          string `json:"url"`
-	FileID       string `json:"fileID"`
-	ids          *sync.Map
+	Name            string `json:"name"`
+	Size            int64  `json:"size"`
+	CreationTime    int64  `json:"creationTime"`
+	URL             string `json:"url"`
+	FileID          string `json:"fileID"`
+	ids             *sync.Map
+	bloom           *bloomFilter
+	file            *idListFile
+	bytesDownloaded int64
+	lastSyncTime    int64
+	synced          int32 // Set once the list has caught up with a manifest, including lists the manifest reports as empty
+	lastError       atomic.Value
+	skipped         bool  // Exceeded IDListMemoryLimit, replaced by a new list once it fits
+	skewCount       int64 // Carried over when the list is reset
+}
+
+// Where the next sync reads the list from. Bloom filters cannot remove IDs, so they are rebuilt from the whole list
+func (l *idList) syncOffset() int64 {
+	if l.bloom != nil {
+		return 0
+	}
+	return atomic.LoadInt64(&l.Size)
+}
+
+func (l *idList) contains(id string) bool {
+	if l.bloom != nil {
+		return l.bloom.contains(id)
+	}
+	if l.file != nil {
+		return l.file.contains(id)
+	}
+	_, ok := l.ids.Load(id)
+	return ok
+}
+
+func (l *idList) add(id string) {
+	if l.file != nil {
+		l.file.add(id)
+	} else {
+		l.ids.Store(id, true)
+	}
+}
+
+func (l *idList) remove(id string) {
+	if l.file != nil {
+		l.file.remove(id)
+	} else {
+		l.ids.Delete(id)
+	}
 }
 
 type DataSource string
@@ -104,22 +167,51 @@ type store struct {
 	lastSyncTime         int64
 	initialSyncTime      int64
 	initReason           evaluationReason
+	bootstrapError       error
+	freeze               rulesetFreeze
+	specHashes           map[string]uint64
+	lastSpecDelta        specDelta
+	lastRulesetUpdate    RulesetUpdate // Only diffed while a RulesetUpdateListener is registered
+	etag                 configSpecsETag
+	syncMetrics          ConfigSpecSyncMetrics
+	polling              adaptivePolling
+	configWatchers       configWatchers
+	history              rulesetHistory
+	idListNameWarnings   sync.Map
+	idListSkewWarnings   sync.Map // List name to the FileID last logged as skewed
+	targetListWarnings   sync.Map
+	unknownSpecFetch     unknownSpecFetch
+	syncStream           *configSyncStream
 	initializedIDLists   bool
+	ready                chan struct{} // Closed once the initial sync completes
+	adapterWrites        *adapterWriteBehind
+	staleAdapterSync     bool // The adapter's config specs were last synced over a sync interval ago, see resumeFromAdapterSyncMetadata
+	metrics              *metricsReporter
 	transport            *transport
 	configSyncInterval   time.Duration
 	idListSyncInterval   time.Duration
 	shutdown             bool
-	rulesUpdatedCallback func(rules string, time int64)
+	stopped              chan struct{} // Closed by stopPolling, waking the pollers
+	pollTimer            func(interval time.Duration) (<-chan time.Time, func() bool)
+	pollers              sync.WaitGroup // The initial sync, ruleset and ID list pollers and config sync stream, including any sync they are running
+	rulesetListeners     *rulesetListeners
 	errorBoundary        *errorBoundary
 	dataAdapter          IDataAdapter
 	syncFailureCount     int
 	diagnostics          *diagnostics
 	mu                   sync.RWMutex
 	sdkKey               string
+	options              *Options
 }
 
 var syncOutdatedMax = 2 * time.Minute
 
+// Starts the timers pollers wait on between syncs. Read once per store, so tests can replace it to drive polling
+var newPollTimer = func(interval time.Duration) (<-chan time.Time, func() bool) {
+	timer := time.NewTimer(interval)
+	return timer.C, timer.Stop
+}
+
 func newStore(
 	transport *transport,
 	errorBoundary *errorBoundary,
@@ -145,6 +237,7 @@ func newStore(
 		options.DataAdapter,
 		diagnostics,
 		sdkKey,
+		options,
 	)
 }
 
@@ -158,56 +251,107 @@ func newStoreInternal(
 	dataAdapter IDataAdapter,
 	diagnostics *diagnostics,
 	sdkKey string,
+	options *Options,
 ) *store {
+	dataAdapter = newNamespacedDataAdapter(dataAdapter, sdkKey, options)
 	store := &store{
-		featureGates:         make(map[string]configSpec),
-		dynamicConfigs:       make(map[string]configSpec),
-		idLists:              make(map[string]*idList),
-		transport:            transport,
-		configSyncInterval:   configSyncInterval,
-		idListSyncInterval:   idListSyncInterval,
-		rulesUpdatedCallback: rulesUpdatedCallback,
-		errorBoundary:        errorBoundary,
-		initReason:           reasonUninitialized,
-		initializedIDLists:   false,
-		dataAdapter:          dataAdapter,
-		syncFailureCount:     0,
-		diagnostics:          diagnostics,
-		sdkKey:               sdkKey,
+		featureGates:       make(map[string]configSpec),
+		dynamicConfigs:     make(map[string]configSpec),
+		idLists:            make(map[string]*idList),
+		transport:          transport,
+		configSyncInterval: configSyncInterval,
+		idListSyncInterval: idListSyncInterval,
+		rulesetListeners:   newRulesetListeners(options, rulesUpdatedCallback, options.RulesetUpdatedCallback),
+		errorBoundary:      errorBoundary,
+		initReason:         reasonUninitialized,
+		initializedIDLists: false,
+		dataAdapter:        dataAdapter,
+		syncFailureCount:   0,
+		diagnostics:        diagnostics,
+		sdkKey:             sdkKey,
+		options:            options,
+		polling:            newAdaptivePolling(configSyncInterval, options.AdaptivePollingOptions),
+		history:            newRulesetHistory(options),
+		stopped:            make(chan struct{}),
+		pollTimer:          newPollTimer,
+		syncStream:         newConfigSyncStream(options),
+		ready:              make(chan struct{}),
+		adapterWrites:      newAdapterWriteBehind(dataAdapter),
+		metrics:            newMetricsReporter(options),
 	}
 	firstAttempt := true
 	if dataAdapter != nil {
 		firstAttempt = false
 		dataAdapter.Initialize()
 		store.fetchConfigSpecsFromAdapter()
-	} else if bootstrapValues != "" {
+		store.resumeFromAdapterSyncMetadata()
+	} else if bootstrapValues != "" || options.BootstrapReader != nil || options.BootstrapFileOptions.Path != "" {
 		firstAttempt = false
-		if _, updated := store.processConfigSpecs(bootstrapValues, store.addDiagnostics().bootstrap()); updated {
-			store.mu.Lock()
-			store.initReason = reasonBootstrap
-			store.mu.Unlock()
+		if err := store.processBootstrap(bootstrapValues, options.BootstrapReader); err != nil {
+			store.bootstrapError = err
+			logBootstrapWarning(err)
+			errorBoundary.logException(err)
+			if options.StrictBootstrap {
+				close(store.ready)
+				return store
+			}
 		}
 	}
-	if store.lastSyncTime == 0 {
+	// The initial sync counts as a poller, so shutdown waits for it and for the pollers it starts
+	store.pollers.Add(1)
+	if options.AsyncInitOptions.Enabled {
+		go store.initialSync(firstAttempt)
+	} else {
+		store.initialSync(firstAttempt)
+	}
+	return store
+}
+
+// Syncs config specs from the network if no other source provided them, then ID lists, before polling for changes
+func (s *store) initialSync(firstAttempt bool) {
+	defer s.pollers.Done()
+	if s.options.DisableNetworkConfigSync && s.dataAdapter == nil {
+		Logger().LogError("DisableNetworkConfigSync has no effect without a DataAdapter, syncing config specs from the network")
+	}
+	s.mu.RLock()
+	lastSyncTime := s.lastSyncTime
+	s.mu.RUnlock()
+	if lastSyncTime == 0 && s.options.DisableNetworkConfigSync && s.dataAdapter != nil {
+		Logger().LogStep(StatsigProcessInitialize, "No config specs found in the data adapter, network config sync is disabled")
+	} else if lastSyncTime == 0 {
 		if !firstAttempt {
-			store.diagnostics.initDiagnostics.logProcess("Retrying with network...")
+			s.diagnostics.initDiagnostics.logProcess("Retrying with network...")
 		}
-		store.fetchConfigSpecsFromServer(true)
+		s.fetchConfigSpecsFromServer(true)
+	} else if s.ownsDataAdapter() && s.staleAdapterSync {
+		// Catches up from the adapter's config specs, rather than serving them until the first poll
+		s.fetchConfigSpecsFromServer(false)
 	}
-	store.mu.Lock()
-	store.initialSyncTime = store.lastSyncTime
-	store.mu.Unlock()
-	if store.dataAdapter != nil {
-		store.fetchIDListsFromAdapter()
+	s.mu.Lock()
+	s.initialSyncTime = s.lastSyncTime
+	if s.lastSyncTime != lastSyncTime {
+		s.saveSyncMetadataToAdapter()
+	}
+	s.mu.Unlock()
+	if s.dataAdapter != nil {
+		s.fetchIDListsFromAdapter()
 	} else {
-		store.fetchIDListsFromServer()
+		s.fetchIDListsFromServer()
 	}
-	store.mu.Lock()
-	store.initializedIDLists = true
-	store.mu.Unlock()
-	go store.pollForRulesetChanges()
-	go store.pollForIDListChanges()
-	return store
+	s.mu.Lock()
+	s.initializedIDLists = true
+	s.mu.Unlock()
+	s.pollers.Add(2)
+	go s.pollForRulesetChanges()
+	go s.pollForIDListChanges()
+	if s.syncStream != nil && !s.shouldQueryDataAdapter(CONFIG_SPECS_KEY) {
+		s.pollers.Add(1)
+		go s.streamConfigSpecs()
+	}
+	// The initial sync is persisted before initialization completes
+	s.adapterWrites.flush()
+	close(s.ready)
+	s.callOnReady()
 }
 
 func (s *store) getGate(name string) (configSpec, bool) {
@@ -264,6 +408,7 @@ func (s *store) fetchConfigSpecsFromAdapter() {
 	}
 }
 
+// Written synchronously, as restarts and other instances read the config specs from the adapter
 func (s *store) saveConfigSpecsToAdapter(specs downloadConfigSpecResponse) {
 	if s.dataAdapter == nil {
 		return
@@ -280,6 +425,7 @@ func (s *store) saveConfigSpecsToAdapter(specs downloadConfigSpecResponse) {
 }
 
 func (s *store) handleSyncError(err error, isColdStart bool) {
+	s.metrics.configSyncFailure()
 	s.syncFailureCount += 1
 	failDuration := time.Duration(s.syncFailureCount) * s.configSyncInterval
 	if isColdStart {
@@ -296,9 +442,15 @@ func (s *store) handleSyncError(err error, isColdStart bool) {
 }
 
 func (s *store) fetchConfigSpecsFromServer(isColdStart bool) {
+	s.syncConfigSpecsFromServer(context.Background(), isColdStart)
+}
+
+func (s *store) syncConfigSpecsFromServer(ctx context.Context, isColdStart bool) {
+	start := time.Now()
+	defer func() { s.metrics.configSync(time.Since(start)) }()
 	s.addDiagnostics().downloadConfigSpecs().networkRequest().start().mark()
-	var specs downloadConfigSpecResponse
-	res, err := s.transport.download_config_specs(s.lastSyncTime, &specs)
+	decoded := decodedConfigSpecs{stream: s.options.StreamConfigSpecs}
+	res, err := s.transport.download_config_specs(ctx, s.lastSyncTime, s.getConfigSpecsETag(), &decoded)
 	if res == nil || err != nil {
 		marker := s.addDiagnostics().downloadConfigSpecs().networkRequest().end().success(false)
 		if res != nil {
@@ -310,21 +462,39 @@ func (s *store) fetchConfigSpecsFromServer(isColdStart bool) {
 	}
 	s.addDiagnostics().downloadConfigSpecs().networkRequest().end().
 		success(true).statusCode(res.StatusCode).sdkRegion(safeGetFirst(res.Header["X-Statsig-Region"])).mark()
+	if res.StatusCode == http.StatusNotModified {
+		s.recordConfigSpecsNotModified()
+		return
+	}
+	if s.applyNetworkConfigSpecs(decoded.specs, decoded.bytes, decoded.decodeDuration) {
+		s.setConfigSpecsETag(res)
+	}
+}
+
+// Applies config specs received from the network, by download_config_specs or the config sync stream.
+// Returns false if the specs were rejected
+func (s *store) applyNetworkConfigSpecs(specs downloadConfigSpecResponse, downloadedBytes int64, parseDuration time.Duration) bool {
 	parsed, updated := s.processConfigSpecs(specs, s.addDiagnostics().downloadConfigSpecs())
 	if parsed {
+		s.recordConfigSpecSync(downloadedBytes, parseDuration, updated)
 		s.mu.Lock()
-		defer s.mu.Unlock()
+		s.polling.recordSync(updated)
 		if updated {
 			s.initReason = reasonNetwork
-			if s.rulesUpdatedCallback != nil {
-				v, _ := json.Marshal(specs)
-				s.rulesUpdatedCallback(string(v[:]), specs.Time)
-			}
-			s.saveConfigSpecsToAdapter(specs)
 		} else {
 			s.initReason = reasonNetworkNotModified
 		}
+		s.mu.Unlock()
+		if updated {
+			// The specs are written before the metadata, so the metadata never describes a sync the adapter lacks
+			s.saveConfigSpecsToAdapter(specs)
+			s.mu.RLock()
+			s.saveSyncMetadataToAdapter()
+			s.mu.RUnlock()
+			s.rulesetListeners.notify(specs, s.getLastRulesetUpdate())
+		}
 	}
+	return parsed
 }
 
 func (s *store) processConfigSpecs(configSpecs interface{}, diagnosticsMarker *marker) (bool, bool) {
@@ -357,18 +527,27 @@ func (s *store) setConfigSpecs(specs downloadConfigSpecResponse) (bool, bool) {
 	}
 
 	if specs.HasUpdates {
+		if s.deferIfFrozen(specs) {
+			return true, false
+		}
 		newGates := make(map[string]configSpec)
 		for _, gate := range specs.FeatureGates {
+			compileConfigSpec(&gate)
+			s.warnEmptyTargetLists(gate)
 			newGates[gate.Name] = gate
 		}
 
 		newConfigs := make(map[string]configSpec)
 		for _, config := range specs.DynamicConfigs {
+			compileConfigSpec(&config)
+			s.warnEmptyTargetLists(config)
 			newConfigs[config.Name] = config
 		}
 
 		newLayers := make(map[string]configSpec)
 		for _, layer := range specs.LayerConfigs {
+			compileConfigSpec(&layer)
+			s.warnEmptyTargetLists(layer)
 			newLayers[layer.Name] = layer
 		}
 
@@ -379,7 +558,16 @@ func (s *store) setConfigSpecs(specs downloadConfigSpecResponse) (bool, bool) {
 			}
 		}
 
+		newHashes := hashConfigSpecs(specs)
+
 		s.mu.Lock()
+		previousHashes := s.specHashes
+		previousGates, previousConfigs, previousLayers := s.featureGates, s.dynamicConfigs, s.layerConfigs
+		s.lastSpecDelta = diffConfigSpecHashes(previousHashes, newHashes)
+		if s.rulesetListeners.hasUpdateListeners() {
+			s.lastRulesetUpdate = newRulesetUpdate(previousHashes, newHashes, s.lastSyncTime, specs.Time)
+		}
+		s.specHashes = newHashes
 		s.featureGates = newGates
 		s.dynamicConfigs = newConfigs
 		s.layerConfigs = newLayers
@@ -388,6 +576,13 @@ func (s *store) setConfigSpecs(specs downloadConfigSpecResponse) (bool, bool) {
 		s.hashedSDKKeysToAppID = specs.HashedSDKKeysToAppID
 		s.lastSyncTime = specs.Time
 		s.mu.Unlock()
+		s.recordRulesetSnapshot(specs)
+		s.notifyConfigWatchers(previousHashes, newHashes)
+		anomalyOptions := s.options.SpecAnomalyOptions
+		anomalies := detectSpecAnomalies(anomalyOptions, SpecTypeFeatureGate, previousGates, newGates, specs.Time)
+		anomalies = append(anomalies, detectSpecAnomalies(anomalyOptions, SpecTypeDynamicConfig, previousConfigs, newConfigs, specs.Time)...)
+		anomalies = append(anomalies, detectSpecAnomalies(anomalyOptions, SpecTypeLayer, previousLayers, newLayers, specs.Time)...)
+		s.reportSpecAnomalies(anomalies)
 		return true, true
 	}
 	return true, false
@@ -396,7 +591,7 @@ func (s *store) setConfigSpecs(specs downloadConfigSpecResponse) (bool, bool) {
 func (s *store) getIDList(name string) *idList {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	list, ok := s.idLists[name]
+	list, ok := s.idLists[s.idListKey(name)]
 	if ok {
 		return list
 	}
@@ -406,13 +601,14 @@ func (s *store) getIDList(name string) *idList {
 func (s *store) deleteIDList(name string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	delete(s.idLists, name)
+	closeIDListFile(s.idLists[s.idListKey(name)])
+	delete(s.idLists, s.idListKey(name))
 }
 
 func (s *store) setIDList(name string, list *idList) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.idLists[name] = list
+	s.idLists[s.idListKey(name)] = list
 }
 
 func (s *store) fetchIDListsFromServer() {
@@ -456,6 +652,17 @@ func (s *store) saveIDListsToAdapter(idLists map[string]*idList) {
 	if s.dataAdapter == nil {
 		return
 	}
+	// Lists are saved under their manifest names, which may differ from their keys in s.idLists
+	s.mu.RLock()
+	byName := make(map[string]*idList, len(idLists))
+	for _, list := range idLists {
+		// Lists in files are left out, as saving their contents would load them into memory
+		if list.file == nil {
+			byName[list.Name] = list
+		}
+	}
+	s.mu.RUnlock()
+	idLists = byName
 	idListsJSON, err := json.Marshal(idLists)
 	defer func() {
 		if err := recover(); err != nil {
@@ -466,6 +673,10 @@ func (s *store) saveIDListsToAdapter(idLists map[string]*idList) {
 		for name := range idLists {
 			buf := new(bytes.Buffer)
 			list := s.getIDList(name)
+			if list.bloom != nil {
+				// Bloom filters cannot be enumerated, so their lists were saved as downloaded
+				continue
+			}
 			list.ids.Range(func(key, value interface{}) bool {
 				buf.WriteString(fmt.Sprintf("+%s\n", key))
 				return true
@@ -476,6 +687,19 @@ func (s *store) saveIDListsToAdapter(idLists map[string]*idList) {
 	}
 }
 
+func (s *store) getBloomFilterFalsePositiveRate(name string) (float64, bool) {
+	if s.options == nil {
+		return 0, false
+	}
+	bloomOptions := s.options.IDListBloomFilterOptions
+	for _, list := range bloomOptions.Lists {
+		if s.idListKey(list) == s.idListKey(name) {
+			return bloomOptions.FalsePositiveRate, true
+		}
+	}
+	return 0, false
+}
+
 func (s *store) processIDListsFromNetwork(idLists map[string]idList) {
 	s.addDiagnostics().getIdListSources().process().start().idListCount(len(idLists)).mark()
 	s.processIDLists(idLists, NetworkDataSource)
@@ -489,8 +713,14 @@ func (s *store) processIDListsFromAdapter(idLists map[string]idList) {
 }
 
 func (s *store) processIDLists(idLists map[string]idList, source DataSource) {
+	idLists = s.resolveIDListNames(idLists)
+	admitted := s.admitIDLists(idLists)
 	wg := sync.WaitGroup{}
 	for name, serverList := range idLists {
+		if admitted != nil && !admitted[name] && serverList.URL != "" && serverList.FileID != "" {
+			s.skipIDList(name, serverList)
+			continue
+		}
 		localList := s.getIDList(name)
 		if localList == nil {
 			localList = &idList{Name: name}
@@ -498,12 +728,12 @@ func (s *store) processIDLists(idLists map[string]idList, source DataSource) {
 		}
 
 		// skip if server list is invalid
-		if serverList.URL == "" || serverList.CreationTime < localList.CreationTime || serverList.FileID == "" {
+		if serverList.URL == "" || serverList.FileID == "" || s.ignoreSkewedIDList(localList, serverList) {
 			continue
 		}
 
 		// reset the local list if returns server list has a newer file
-		if serverList.FileID != localList.FileID && serverList.CreationTime >= localList.CreationTime {
+		if serverList.FileID != localList.FileID {
 			localList = &idList{
 				Name:         localList.Name,
 				Size:         0,
@@ -511,12 +741,20 @@ func (s *store) processIDLists(idLists map[string]idList, source DataSource) {
 				URL:          serverList.URL,
 				FileID:       serverList.FileID,
 				ids:          &sync.Map{},
+				skewCount:    atomic.LoadInt64(&localList.skewCount),
 			}
+			if fpRate, ok := s.getBloomFilterFalsePositiveRate(name); ok {
+				localList.bloom = newBloomFilter(fpRate)
+			} else if s.useIDListFile(name) {
+				localList.file = newIDListFile(s.options.IDListFileOptions, name, serverList.FileID)
+			}
+			closeIDListFile(s.getIDList(name))
 			s.setIDList(name, localList)
 		}
 
 		// skip if server list is not bigger
 		if serverList.Size <= localList.Size {
+			localList.markSynced()
 			continue
 		}
 
@@ -533,22 +771,33 @@ func (s *store) processIDLists(idLists map[string]idList, source DataSource) {
 		}(name, localList)
 	}
 	wg.Wait()
-	for name := range s.idLists {
-		if _, ok := idLists[name]; !ok {
-			s.deleteIDList(name)
+	manifestKeys := make(map[string]bool, len(idLists))
+	for name := range idLists {
+		manifestKeys[s.idListKey(name)] = true
+	}
+	s.mu.Lock()
+	for key, list := range s.idLists {
+		if !manifestKeys[key] {
+			closeIDListFile(list)
+			delete(s.idLists, key)
 		}
 	}
+	s.mu.Unlock()
+	if s.metrics != nil {
+		s.metrics.idLists(s.getIDListStats())
+	}
 }
 
 func (s *store) downloadSingleIDListFromServer(list *idList) {
 	s.addDiagnostics().getIdList().networkRequest().start().url(list.URL).mark()
-	res, err := s.transport.get_id_list(list.URL, map[string]string{"Range": fmt.Sprintf("bytes=%d-", list.Size)})
+	res, err := s.transport.get_id_list(list.URL, map[string]string{"Range": fmt.Sprintf("bytes=%d-", list.syncOffset())})
 	if err != nil || res == nil {
 		marker := s.addDiagnostics().getIdList().networkRequest().end().url(list.URL).success(false)
 		if res != nil {
 			marker.statusCode(res.StatusCode).sdkRegion(safeGetFirst(res.Header["X-Statsig-Region"]))
 		}
 		marker.mark()
+		list.recordSyncError(err)
 		s.errorBoundary.logException(err)
 		return
 	}
@@ -567,7 +816,7 @@ func (s *store) getSingleIDListFromAdapter(list *idList) {
 	}()
 	content := s.dataAdapter.Get(fmt.Sprintf("%s::%s", ID_LISTS_KEY, list.Name))
 	contentBytes := []byte(content)
-	content = string(contentBytes[list.Size:])
+	content = string(contentBytes[list.syncOffset():])
 	s.addDiagnostics().dataStoreIDList().fetch().end().success(true).mark()
 	s.processSingleIDListFromAdapter(list, content)
 }
@@ -577,13 +826,23 @@ func (s *store) processSingleIDListFromNetwork(list *idList, res *http.Response)
 	length, err := strconv.Atoi(res.Header.Get("content-length"))
 	if err != nil || length <= 0 {
 		s.addDiagnostics().getIdList().process().end().url(list.URL).success(false).mark()
+		list.recordSyncError(err)
 		s.errorBoundary.logException(err)
 		return
 	}
 
-	bodyBytes, err := io.ReadAll(res.Body)
+	body, err := s.transport.responseBody(res)
+	if err == nil && list.file != nil {
+		s.processIDListFileFromNetwork(list, bufio.NewReader(body), length)
+		return
+	}
+	var bodyBytes []byte
+	if err == nil {
+		bodyBytes, err = io.ReadAll(body)
+	}
 	if err != nil {
 		s.addDiagnostics().getIdList().process().end().url(list.URL).success(false).mark()
+		list.recordSyncError(err)
 		s.errorBoundary.logException(err)
 		return
 	}
@@ -595,6 +854,49 @@ func (s *store) processSingleIDListFromNetwork(list *idList, res *http.Response)
 		return
 	}
 	s.processSingleIDList(list, content, length)
+	if list.bloom != nil {
+		s.saveBloomFilterIDListToAdapter(list, content)
+	}
+	s.addDiagnostics().getIdList().process().end().url(list.URL).success(true).mark()
+}
+
+// Bloom filter lists are downloaded in full, so the download is saved as is, ahead of the manifest listing
+// it, for clients reading ID lists from the adapter
+func (s *store) saveBloomFilterIDListToAdapter(list *idList, content string) {
+	if s.dataAdapter == nil {
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("Error calling data adapter set: %s\n", toError(err).Error()))
+		}
+	}()
+	s.dataAdapter.Set(fmt.Sprintf("%s::%s", ID_LISTS_KEY, list.Name), content)
+}
+
+// Streamed into the list's files, so large lists are never held in memory whole
+func (s *store) processIDListFileFromNetwork(list *idList, body *bufio.Reader, length int) {
+	first, err := body.Peek(2)
+	if err != nil && err != io.EOF {
+		s.addDiagnostics().getIdList().process().end().url(list.URL).success(false).mark()
+		list.recordSyncError(err)
+		s.errorBoundary.logException(err)
+		return
+	}
+	if len(first) <= 1 || (first[0] != '-' && first[0] != '+') {
+		s.addDiagnostics().getIdList().process().end().url(list.URL).success(false).mark()
+		s.deleteIDList(list.Name)
+		return
+	}
+	read, err := list.file.load(body)
+	if err != nil {
+		s.addDiagnostics().getIdList().process().end().url(list.URL).success(false).mark()
+		list.recordSyncError(err)
+		s.errorBoundary.logException(err)
+		return
+	}
+	atomic.AddInt64(&list.Size, int64(length))
+	list.recordSync(int(read))
 	s.addDiagnostics().getIdList().process().end().url(list.URL).success(true).mark()
 }
 
@@ -605,6 +907,22 @@ func (s *store) processSingleIDListFromAdapter(list *idList, content string) {
 }
 
 func (s *store) processSingleIDList(list *idList, content string, length int) {
+	if list.bloom != nil {
+		list.bloom.rebuild(content)
+		atomic.StoreInt64(&list.Size, int64(length))
+		list.recordSync(len(content))
+		return
+	}
+	if list.file != nil {
+		if _, err := list.file.load(strings.NewReader(content)); err != nil {
+			list.recordSyncError(err)
+			s.errorBoundary.logException(err)
+			return
+		}
+		atomic.AddInt64(&list.Size, int64(length))
+		list.recordSync(len(content))
+		return
+	}
 	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -614,17 +932,21 @@ func (s *store) processSingleIDList(list *idList, content string, length int) {
 		id := line[1:]
 		op := string(line[0])
 		if op == "+" {
-			list.ids.Store(id, true)
+			list.add(id)
 		} else if op == "-" {
-			list.ids.Delete(id)
+			list.remove(id)
 		}
 	}
 	atomic.AddInt64((&list.Size), int64(length))
+	list.recordSync(len(content))
 }
 
 func (s *store) pollForIDListChanges() {
+	defer s.pollers.Done()
 	for {
-		time.Sleep(s.idListSyncInterval)
+		if !s.waitForNextPoll(s.idListSyncInterval) {
+			break
+		}
 		stop := func() bool {
 			s.mu.RLock()
 			defer s.mu.RUnlock()
@@ -633,7 +955,7 @@ func (s *store) pollForIDListChanges() {
 		if stop {
 			break
 		}
-		if s.dataAdapter != nil && s.dataAdapter.ShouldBeUsedForQueryingUpdates(ID_LISTS_KEY) {
+		if s.shouldQueryDataAdapter(ID_LISTS_KEY) {
 			s.fetchIDListsFromAdapter()
 		} else {
 			s.fetchIDListsFromServer()
@@ -642,8 +964,11 @@ func (s *store) pollForIDListChanges() {
 }
 
 func (s *store) pollForRulesetChanges() {
+	defer s.pollers.Done()
 	for {
-		time.Sleep(s.configSyncInterval)
+		if !s.waitForNextPoll(s.getConfigSyncInterval()) {
+			break
+		}
 		stop := func() bool {
 			s.mu.RLock()
 			defer s.mu.RUnlock()
@@ -652,7 +977,11 @@ func (s *store) pollForRulesetChanges() {
 		if stop {
 			break
 		}
-		if s.dataAdapter != nil && s.dataAdapter.ShouldBeUsedForQueryingUpdates(CONFIG_SPECS_KEY) {
+		// Polling resumes whenever the stream is disconnected
+		if s.syncStream.isConnected() {
+			continue
+		}
+		if s.shouldQueryDataAdapter(CONFIG_SPECS_KEY) {
 			s.fetchConfigSpecsFromAdapter()
 		} else {
 			s.fetchConfigSpecsFromServer(false)
@@ -660,10 +989,56 @@ func (s *store) pollForRulesetChanges() {
 	}
 }
 
+// With network config sync disabled the data adapter is the only source of updates,
+// regardless of what the adapter reports
+func (s *store) shouldQueryDataAdapter(key string) bool {
+	if s.dataAdapter == nil {
+		return false
+	}
+	return s.options.DisableNetworkConfigSync || s.dataAdapter.ShouldBeUsedForQueryingUpdates(key)
+}
+
+// Whether this client syncs config specs from the network and persists them, rather than
+// reading the ones another instance writes to the adapter
+func (s *store) ownsDataAdapter() bool {
+	return s.dataAdapter != nil && !s.shouldQueryDataAdapter(CONFIG_SPECS_KEY)
+}
+
+// Returns false if polling was stopped while waiting
+func (s *store) waitForNextPoll(interval time.Duration) bool {
+	fired, stop := s.pollTimer(interval)
+	defer stop()
+	select {
+	case <-fired:
+		return true
+	case <-s.stopped:
+		return false
+	}
+}
+
 func (s *store) stopPolling() {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	if !s.shutdown && s.stopped != nil {
+		close(s.stopped)
+	}
 	s.shutdown = true
+	s.mu.Unlock()
+	s.syncStream.stop()
+}
+
+// Waits for the pollers to exit, including any sync they were running when polling was stopped
+func (s *store) drainPollers(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		s.pollers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (s *store) addDiagnostics() *marker {
@@ -0,0 +1,105 @@
+package statsig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var errLoggingDisabled = errors.New("Event logging is disabled")
+
+type exposureAckKey struct{}
+
+// Collects the outcome of exposures sent synchronously by evaluations made with a context from
+// WithExposureAck, e.g. for entitlement decisions that must not be made unless their exposure
+// was recorded. Use sparingly, since every such exposure is sent in its own request.
+type ExposureAck struct {
+	sent int
+	errs []string
+	mu   sync.Mutex
+}
+
+// Returns a context that makes CheckGateCtx, GetGateCtx, GetConfigCtx, GetExperimentCtx and the
+// parameter getters of a Layer from GetLayerCtx send their exposure before returning, bound to ctx,
+// instead of queueing it. Exposures that fail to send are neither retried nor spooled and are
+// reported by the ack, so that the caller can decide whether to proceed.
+func WithExposureAck(ctx context.Context) (context.Context, *ExposureAck) {
+	ack := &ExposureAck{}
+	return context.WithValue(contextOrBackground(ctx), exposureAckKey{}, ack), ack
+}
+
+func exposureAckFromContext(ctx context.Context) *ExposureAck {
+	if ctx == nil {
+		return nil
+	}
+	ack, _ := ctx.Value(exposureAckKey{}).(*ExposureAck)
+	return ack
+}
+
+// Returns an error describing every exposure that was not acknowledged, or nil if all were
+func (a *ExposureAck) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Failed to send %d of %d exposures: %s", len(a.errs), a.sent, strings.Join(a.errs, "; "))
+}
+
+// The number of exposures sent, including those that failed
+func (a *ExposureAck) Sent() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.sent
+}
+
+func (a *ExposureAck) record(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sent++
+	if err != nil {
+		a.errs = append(a.errs, err.Error())
+	}
+}
+
+// Sends the events in their own request, bypassing the queue
+func (l *logger) logSync(ctx context.Context, events []interface{}) error {
+	if l.disabled {
+		return errLoggingDisabled
+	}
+	if l.isLoggingPaused() {
+		return errLoggingPaused
+	}
+	batchID := newEventBatchID()
+	l.writeToEventSink(events, batchID)
+	return l.postEventsCtx(ctx, events, batchID)
+}
+
+func (l *logger) logCustomSync(ctx context.Context, evt Event) error {
+	evt.User = l.loggedUser(evt.User)
+	if evt.Time == 0 {
+		evt.Time = l.transport.eventTime()
+	}
+	evt.TimeSinceInit = l.getTimeSinceInit()
+	aliased := l.aliases.apply(evt)
+	events := make([]interface{}, 0, len(aliased))
+	for _, e := range aliased {
+		events = append(events, e)
+	}
+	return l.logSync(ctx, events)
+}
+
+func (l *logger) logExposureSync(ctx context.Context, evt ExposureEvent) error {
+	evt.User = l.loggedUser(evt.User)
+	if evt.Time == 0 {
+		evt.Time = l.transport.eventTime()
+	}
+	evt.TimeSinceInit = l.getTimeSinceInit()
+	l.exportExposure(evt)
+	if l.options.ExposureExportOptions.DisableStatsigExposureLogging {
+		return nil
+	}
+	return l.logSync(ctx, []interface{}{evt})
+}
@@ -0,0 +1,119 @@
+package statsig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSynchronousLogging(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var failLogging int32
+	var mu sync.Mutex
+	var requests [][]string
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			_, _ = res.Write(specs)
+		case strings.Contains(req.URL.Path, "log_event"):
+			if atomic.LoadInt32(&failLogging) == 1 {
+				res.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			var input logEventInput
+			_ = json.NewDecoder(req.Body).Decode(&input)
+			var names []string
+			for _, evt := range input.Events {
+				if m, ok := evt.(map[string]interface{}); ok {
+					names = append(names, m["eventName"].(string))
+				}
+			}
+			mu.Lock()
+			requests = append(requests, names)
+			mu.Unlock()
+			_, _ = res.Write([]byte("{}"))
+		default:
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                 testServer.URL,
+		LoggingInterval:     time.Hour,
+		ConfigSyncInterval:  time.Hour,
+		IDListSyncInterval:  time.Hour,
+		CoalesceEvaluations: true,
+		RetryOptions:        RetryOptions{MaxAttempts: 1, BaseBackoff: time.Millisecond},
+	})
+	defer c.Shutdown()
+	takeRequests := func() [][]string {
+		mu.Lock()
+		defer mu.Unlock()
+		taken := requests
+		requests = nil
+		return taken
+	}
+	user := User{UserID: "1"}
+
+	c.RegisterEventAlias("purchase", EventAlias{MetricEventName: "revenue"})
+	if err := c.LogEventSync(context.Background(), Event{EventName: "purchase", User: user}); err != nil {
+		t.Errorf("Expected the event to be acknowledged, received %s", err)
+	}
+	if sent := takeRequests(); len(sent) != 1 || strings.Join(sent[0], ",") != "purchase,revenue" {
+		t.Errorf("Expected the event and its alias to be sent in one request before returning, received %v", sent)
+	}
+	if err := c.LogEventSync(context.Background(), Event{User: user}); err == nil {
+		t.Errorf("Expected an event without a name to be rejected")
+	}
+
+	ctx, ack := WithExposureAck(context.Background())
+	if !c.CheckGateCtx(ctx, user, "always_on_gate") {
+		t.Errorf("Expected always_on_gate to pass")
+	}
+	layer := c.GetLayerCtx(ctx, user, "a_layer")
+	layer.GetBool("layer_param", false)
+	if err := ack.Err(); err != nil || ack.Sent() != 2 {
+		t.Errorf("Expected 2 acknowledged exposures, received %d with %v", ack.Sent(), err)
+	}
+	sent := takeRequests()
+	if len(sent) != 2 || sent[0][0] != string(GateExposureEventName) || sent[1][0] != string(LayerExposureEventName) {
+		t.Errorf("Expected each exposure to be sent in its own request, received %v", sent)
+	}
+	if len(c.logger.events) != 0 {
+		t.Errorf("Expected acknowledged exposures not to be queued, received %d events", len(c.logger.events))
+	}
+
+	atomic.StoreInt32(&failLogging, 1)
+	ctx, ack = WithExposureAck(context.Background())
+	c.GetExperimentCtx(ctx, user, "sample_experiment")
+	if err := ack.Err(); err == nil || ack.Sent() != 1 {
+		t.Errorf("Expected the failed exposure to be reported, received %d with %v", ack.Sent(), err)
+	}
+	if err := c.LogEventSync(context.Background(), Event{EventName: "purchase", User: user}); err == nil {
+		t.Errorf("Expected the failed event to be reported")
+	}
+	if len(c.logger.events) != 0 {
+		t.Errorf("Expected failed synchronous events not to be queued for retry, received %d events", len(c.logger.events))
+	}
+	atomic.StoreInt32(&failLogging, 0)
+
+	// Evaluations without an ack keep queueing their exposures
+	c.CheckGate(user, "always_on_gate")
+	if len(c.logger.events) != 1 || len(takeRequests()) != 0 {
+		t.Errorf("Expected the exposure to be queued, received %d queued events", len(c.logger.events))
+	}
+
+	c.PauseLogging()
+	if err := c.LogEventSync(context.Background(), Event{EventName: "purchase", User: user}); err != errLoggingPaused {
+		t.Errorf("Expected events not to be sent while logging is paused, received %v", err)
+	}
+	c.ResumeLogging()
+}
@@ -0,0 +1,130 @@
+package statsig
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Tenant specs are named "<tenant>::<spec>" unless RegisterTenant is given a filter
+const tenantSpecSeparator = "::"
+
+// Evaluates only the specs belonging to one tenant, see Client.RegisterTenant. Specs outside
+// the tenant, including gates and experiments referenced by the tenant's specs, evaluate as
+// unrecognized.
+type TenantClient struct {
+	partition *tenantPartition
+	client    *Client
+}
+
+type TenantStats struct {
+	Evaluations int64 `json:"evaluations"` // Gate, config, experiment and layer checks through the tenant's client
+	Rejected    int64 `json:"rejected"`    // Lookups of specs outside the tenant, which evaluated as unrecognized
+}
+
+type tenantPartition struct {
+	name        string
+	filter      func(specName string) bool
+	evaluations int64
+	rejected    int64
+}
+
+func (p *tenantPartition) contains(specName string) bool {
+	if p.filter(specName) {
+		return true
+	}
+	atomic.AddInt64(&p.rejected, 1)
+	return false
+}
+
+type tenantRegistry struct {
+	tenants map[string]*TenantClient
+	mu      sync.RWMutex
+}
+
+func newTenantRegistry() *tenantRegistry {
+	return &tenantRegistry{tenants: make(map[string]*TenantClient)}
+}
+
+func (r *tenantRegistry) register(c *Client, tenant string, filter func(specName string) bool) *TenantClient {
+	if filter == nil {
+		prefix := tenant + tenantSpecSeparator
+		filter = func(specName string) bool { return strings.HasPrefix(specName, prefix) }
+	}
+	partition := &tenantPartition{name: tenant, filter: filter}
+	scoped := *c
+	scoped.evaluator = c.evaluator.forTenant(partition)
+	// Evaluations must not be shared with callers outside the tenant, which see its specs as unrecognized
+	scoped.coalescer = newEvaluationCoalescer(c.options)
+	tenantClient := &TenantClient{partition: partition, client: &scoped}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenants[tenant] = tenantClient
+	return tenantClient
+}
+
+func (r *tenantRegistry) get(tenant string) (*TenantClient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tenantClient, ok := r.tenants[tenant]
+	return tenantClient, ok
+}
+
+func (r *tenantRegistry) stats() map[string]TenantStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := make(map[string]TenantStats, len(r.tenants))
+	for name, tenantClient := range r.tenants {
+		stats[name] = tenantClient.GetStats()
+	}
+	return stats
+}
+
+// Shares the store, overrides and precomputed evaluations of e, restricted to the partition's specs
+func (e *evaluator) forTenant(partition *tenantPartition) *evaluator {
+	return &evaluator{
+		store:                  e.store,
+		countryLookup:          e.countryLookup,
+		uaParser:               e.uaParser,
+		persistentStorageUtils: e.persistentStorageUtils,
+		options:                e.options,
+		parent:                 e,
+		tenant:                 partition,
+	}
+}
+
+func (e *evaluator) inTenant(specName string) bool {
+	return e.tenant == nil || e.tenant.contains(specName)
+}
+
+// Checks the value of a Feature Gate of the tenant for the given user
+func (t *TenantClient) CheckGate(user User, gate string) bool {
+	atomic.AddInt64(&t.partition.evaluations, 1)
+	return t.client.CheckGate(user, gate)
+}
+
+// Gets the DynamicConfig value of the tenant for the given user
+func (t *TenantClient) GetConfig(user User, config string) DynamicConfig {
+	atomic.AddInt64(&t.partition.evaluations, 1)
+	return t.client.GetConfig(user, config)
+}
+
+// Gets the DynamicConfig value of an Experiment of the tenant for the given user
+func (t *TenantClient) GetExperiment(user User, experiment string) DynamicConfig {
+	atomic.AddInt64(&t.partition.evaluations, 1)
+	return t.client.GetExperiment(user, experiment)
+}
+
+// Gets the Layer of the tenant for the given user
+func (t *TenantClient) GetLayer(user User, layer string) Layer {
+	atomic.AddInt64(&t.partition.evaluations, 1)
+	return t.client.GetLayer(user, layer)
+}
+
+// Gets the evaluation and rejected lookup counts of the tenant
+func (t *TenantClient) GetStats() TenantStats {
+	return TenantStats{
+		Evaluations: atomic.LoadInt64(&t.partition.evaluations),
+		Rejected:    atomic.LoadInt64(&t.partition.rejected),
+	}
+}
@@ -0,0 +1,80 @@
+package statsig
+
+import (
+	"strings"
+	"testing"
+)
+
+const tenantSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [
+		{"name": "tenant_a::feature", "type": "feature_gate", "salt": "a", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "public", "id": "tenant_a_rule", "salt": "a", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}
+		]},
+		{"name": "tenant_a::depends_on_b", "type": "feature_gate", "salt": "a", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "nested", "id": "nested_rule", "salt": "a", "passPercentage": 100, "returnValue": true,
+			 "conditions": [{"type": "pass_gate", "targetValue": "tenant_b::feature"}]}
+		]},
+		{"name": "tenant_b::feature", "type": "feature_gate", "salt": "b", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "public", "id": "tenant_b_rule", "salt": "b", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}
+		]},
+		{"name": "shared_feature", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "rules": [
+			{"name": "public", "id": "shared_rule", "salt": "s", "passPercentage": 100, "returnValue": true, "conditions": [{"type": "public"}]}
+		]}
+	],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+func TestTenantPartitioning(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      tenantSpecs,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		CoalesceEvaluations:  true,
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a_user"}
+
+	tenantA := c.RegisterTenant("tenant_a", nil)
+	tenantB := c.RegisterTenant("tenant_b", func(specName string) bool {
+		return strings.HasPrefix(specName, "tenant_b::") || specName == "shared_feature"
+	})
+
+	if tenantA.client.coalescer == nil || tenantA.client.coalescer == c.coalescer || tenantA.client.coalescer == tenantB.client.coalescer {
+		t.Errorf("Expected each tenant to coalesce only its own evaluations")
+	}
+	if !tenantA.CheckGate(user, "tenant_a::feature") {
+		t.Errorf("Expected tenant_a to evaluate its own gate")
+	}
+	if tenantA.CheckGate(user, "tenant_b::feature") || tenantA.CheckGate(user, "shared_feature") {
+		t.Errorf("Expected tenant_a to be isolated from other specs")
+	}
+	if tenantA.CheckGate(user, "tenant_a::depends_on_b") {
+		t.Errorf("Expected nested gates outside the tenant to evaluate as unrecognized")
+	}
+	if !c.CheckGate(user, "tenant_a::depends_on_b") {
+		t.Errorf("Expected the client to evaluate across tenants")
+	}
+	if !tenantB.CheckGate(user, "tenant_b::feature") || !tenantB.CheckGate(user, "shared_feature") {
+		t.Errorf("Expected tenant_b to evaluate the specs matching its filter")
+	}
+
+	c.OverrideGate("tenant_a::feature", false)
+	if tenantA.CheckGate(user, "tenant_a::feature") {
+		t.Errorf("Expected tenants to respect client overrides")
+	}
+
+	stats := c.GetTenantStats()
+	if stats["tenant_a"] != (TenantStats{Evaluations: 5, Rejected: 3}) {
+		t.Errorf("Unexpected tenant_a stats %+v", stats["tenant_a"])
+	}
+	if stats["tenant_b"] != (TenantStats{Evaluations: 2, Rejected: 0}) {
+		t.Errorf("Unexpected tenant_b stats %+v", stats["tenant_b"])
+	}
+	if registered, ok := c.GetTenant("tenant_a"); !ok || registered != tenantA {
+		t.Errorf("Expected to look up the registered tenant")
+	}
+}
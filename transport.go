@@ -2,13 +2,18 @@ package statsig
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,6 +27,9 @@ const (
 	backoffMultiplier = 10
 )
 
+// Local clocks further than this from the server's Date header are reported as skewed
+const clockSkewWarningThreshold = 10 * time.Second
+
 type transport struct {
 	api                       string
 	apiForDownloadConfigSpecs string
@@ -29,6 +37,9 @@ type transport struct {
 	metadata                  statsigMetadata // Safe to read from but not thread safe to write into. If value needs to change, please ensure thread safety.
 	client                    *http.Client
 	options                   *Options
+	clockSkew                 int64 // Milliseconds the local clock is ahead of the server, from the last response Date header
+	clockSkewWarned           int32
+	sdkVersions               sdkVersionTracker
 }
 
 func newTransport(secret string, options *Options) *transport {
@@ -44,44 +55,92 @@ func newTransport(secret string, options *Options) *transport {
 	return &transport{
 		api:                       api,
 		apiForDownloadConfigSpecs: apiForDownloadConfigSpecs,
-		metadata:                  getStatsigMetadata(),
+		metadata:                  getInstanceMetadata(),
 		sdkKey:                    secret,
-		client:                    &http.Client{Timeout: time.Second * 3},
+		client:                    newHTTPClient(options, time.Second*3),
 		options:                   options,
 	}
 }
 
+// Builds the client used for all SDK requests, routed through the proxy or unix socket in
+// Options.TransportOptions. Only this client is affected, never http.DefaultTransport.
+func newHTTPClient(options *Options, timeout time.Duration) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	transportOptions := options.TransportOptions
+	if transportOptions.ProxyURL == "" && transportOptions.UnixSocketPath == "" {
+		return client
+	}
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	if transportOptions.UnixSocketPath != "" {
+		// The sidecar listening on the socket handles egress, so ignore proxy environment variables
+		httpTransport.Proxy = nil
+		socketPath := transportOptions.UnixSocketPath
+		dialer := &net.Dialer{}
+		httpTransport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+	if transportOptions.ProxyURL != "" {
+		proxyURL, err := url.Parse(transportOptions.ProxyURL)
+		if err != nil {
+			Logger().LogError(fmt.Errorf("Invalid TransportOptions.ProxyURL, sending requests without a proxy: %s", err.Error()))
+		} else {
+			httpTransport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+	client.Transport = httpTransport
+	return client
+}
+
 type RequestOptions struct {
 	retries int
 	backoff time.Duration
+	ctx     context.Context // Cancels the request and is available to the http.RoundTripper, e.g. for trace propagation
+	headers map[string]string
+
+	useRetryOptions bool // Retried per Options.RetryOptions in place of retries and backoff once it is set
 }
 
 func (opts *RequestOptions) fill_defaults() {
 	if opts.backoff == 0 {
 		opts.backoff = time.Second
 	}
+	if opts.ctx == nil {
+		opts.ctx = context.Background()
+	}
 }
 
-func (transport *transport) download_config_specs(sinceTime int64, responseBody interface{}) (*http.Response, error) {
+// Responds with http.StatusNotModified, without parsing a body, when etag is set and still current
+func (transport *transport) download_config_specs(ctx context.Context, sinceTime int64, etag string, responseBody interface{}) (*http.Response, error) {
 	var endpoint string
 	if transport.options.DisableCDN {
 		endpoint = fmt.Sprintf("/download_config_specs?sinceTime=%d", sinceTime)
 	} else {
 		endpoint = fmt.Sprintf("/download_config_specs/%s.json?sinceTime=%d", transport.sdkKey, sinceTime)
 	}
-	return transport.get(endpoint, responseBody, RequestOptions{})
+	options := RequestOptions{ctx: ctx, useRetryOptions: true}
+	if etag != "" {
+		options.headers = map[string]string{"If-None-Match": etag}
+	}
+	return transport.get(endpoint, responseBody, options)
 }
 
 func (transport *transport) get_id_lists(responseBody interface{}) (*http.Response, error) {
-	return transport.post("/get_id_lists", nil, responseBody, RequestOptions{})
+	return transport.post("/get_id_lists", nil, responseBody, RequestOptions{useRetryOptions: true})
 }
 
 func (transport *transport) get_id_list(url string, headers map[string]string) (*http.Response, error) {
+	if transport.options.LocalMode {
+		return nil, nil
+	}
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	// ID lists are read from their own URLs, but still report the SDK version
+	req.Header.Set("STATSIG-SDK-TYPE", transport.metadata.SDKType)
+	req.Header.Set("STATSIG-SDK-VERSION", transport.metadata.SDKVersion)
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
@@ -97,33 +156,67 @@ func (transport *transport) get(endpoint string, responseBody interface{}, optio
 	return transport.doRequest("GET", endpoint, nil, responseBody, options)
 }
 
-func (transport *transport) buildRequest(method, endpoint string, body interface{}) (*http.Request, error) {
+func (transport *transport) buildRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Request, error) {
 	if transport.options.LocalMode {
 		return nil, nil
 	}
 
+	isLogEvent := strings.Contains(endpoint, "log_event")
+	compress := isLogEvent && transport.options.CompressEvents
 	var bodyBuf io.Reader
+	var bodyBytes []byte
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+		var err error
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
-		bodyBuf = bytes.NewBuffer(bodyBytes)
+		if compress {
+			compressed, err := gzipBytes(bodyBytes)
+			if err != nil {
+				return nil, err
+			}
+			bodyBuf = bytes.NewBuffer(compressed)
+		} else {
+			bodyBuf = bytes.NewBuffer(bodyBytes)
+		}
 	}
-	req, err := http.NewRequest(method, transport.buildURL(endpoint), bodyBuf)
+	req, err := http.NewRequestWithContext(ctx, method, transport.buildURL(endpoint), bodyBuf)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Add("STATSIG-API-KEY", transport.sdkKey)
 	req.Header.Set("Content-Type", "application/json")
+	// Set explicitly so proxies see both encodings; net/http then leaves decompression to parseResponse
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
 	req.Header.Add("STATSIG-CLIENT-TIME", strconv.FormatInt(getUnixMilli(), 10))
 	req.Header.Add("STATSIG-SERVER-SESSION-ID", transport.metadata.SessionID)
+	req.Header.Add("STATSIG-SDK-INSTANCE-ID", transport.metadata.InstanceID)
 	req.Header.Add("STATSIG-SDK-TYPE", transport.metadata.SDKType)
 	req.Header.Add("STATSIG-SDK-VERSION", transport.metadata.SDKVersion)
+	if compress && body != nil {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if isLogEvent {
+		// Signed before compression, so receivers verify the body they decode
+		transport.signEventRequest(req, bodyBytes)
+	}
 	return req, nil
 }
 
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (transport *transport) buildURL(endpoint string) string {
 	if strings.Contains(endpoint, "download_config_specs") {
 		return transport.apiForDownloadConfigSpecs + endpoint
@@ -139,54 +232,87 @@ func (transport *transport) doRequest(
 	out interface{},
 	options RequestOptions,
 ) (*http.Response, error) {
-	request, err := transport.buildRequest(method, endpoint, in)
-	if request == nil || err != nil {
-		return nil, err
-	}
 	options.fill_defaults()
-	return retry(options.retries, time.Duration(options.backoff), func() (*http.Response, bool, error) {
-		response, err := transport.client.Do(request)
-		if err != nil {
-			return response, response != nil, err
+	policy := transport.retryPolicy(options)
+	for attempt := 0; ; attempt++ {
+		// Rebuilt for every attempt, as the body of the previous one has been consumed
+		request, err := transport.buildRequest(options.ctx, method, endpoint, in)
+		if request == nil || err != nil {
+			return nil, err
 		}
-		drainAndCloseBody := func() {
-			if response.Body != nil {
-				// Drain body to re-use the same connection
-				_, _ = io.Copy(ioutil.Discard, response.Body)
-				response.Body.Close()
-			}
+		for k, v := range options.headers {
+			request.Header.Set(k, v)
+		}
+		response, err := transport.attempt(request, out)
+		if err == nil || attempt >= policy.retries || !policy.shouldRetry(options.ctx, response, err) {
+			return response, err
+		}
+		if !policy.wait(options.ctx, attempt) {
+			return response, err
 		}
-		defer drainAndCloseBody()
+	}
+}
 
-		if response.StatusCode >= 200 && response.StatusCode < 300 {
-			return response, false, transport.parseResponse(response, out)
+func (transport *transport) attempt(request *http.Request, out interface{}) (*http.Response, error) {
+	response, err := transport.client.Do(request)
+	if err != nil {
+		return response, err
+	}
+	transport.recordClockSkew(response)
+	transport.recordSDKVersions(response)
+	drainAndCloseBody := func() {
+		if response.Body != nil {
+			// Drain body to re-use the same connection
+			_, _ = io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
 		}
+	}
+	defer drainAndCloseBody()
 
-		return response, retryableStatusCode(response.StatusCode), fmt.Errorf("http response error code: %d", response.StatusCode)
-	})
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return response, transport.parseResponse(response, out)
+	}
+	// Only sent in reply to a conditional request, which the caller checks for
+	if response.StatusCode == http.StatusNotModified {
+		return response, nil
+	}
+
+	return response, fmt.Errorf("http response error code: %d", response.StatusCode)
 }
 
-func (transport *transport) parseResponse(response *http.Response, out interface{}) error {
-	if out == nil {
-		return nil
+func (transport *transport) recordClockSkew(response *http.Response) {
+	serverTime, err := http.ParseTime(response.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	skew := getUnixMilli() - serverTime.UnixNano()/int64(time.Millisecond)
+	atomic.StoreInt64(&transport.clockSkew, skew)
+	if (skew > clockSkewWarningThreshold.Milliseconds() || -skew > clockSkewWarningThreshold.Milliseconds()) &&
+		atomic.CompareAndSwapInt32(&transport.clockSkewWarned, 0, 1) {
+		Logger().LogError(fmt.Sprintf("Local clock differs from Statsig server time by %dms. "+
+			"Event timestamps may be inaccurate; use timeSinceInit for ordering.\n", skew))
 	}
-	return json.NewDecoder(response.Body).Decode(&out)
 }
 
-func retry(retries int, backoff time.Duration, fn func() (*http.Response, bool, error)) (*http.Response, error) {
-	for {
-		if response, retry, err := fn(); retry {
-			if retries <= 0 {
-				return response, err
-			}
+func (transport *transport) getClockSkew() int64 {
+	return atomic.LoadInt64(&transport.clockSkew)
+}
 
-			retries--
-			time.Sleep(backoff)
-			backoff = backoff * backoffMultiplier
-		} else {
-			return response, err
-		}
+func (transport *transport) parseResponse(response *http.Response, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	if err := transport.validateContentType(response); err != nil {
+		return err
+	}
+	body, err := transport.responseBody(response)
+	if err != nil {
+		return err
+	}
+	if decoded, ok := out.(*decodedConfigSpecs); ok {
+		return decoded.decodeFrom(body)
 	}
+	return json.NewDecoder(body).Decode(&out)
 }
 
 func retryableStatusCode(code int) bool {
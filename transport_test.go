@@ -1,10 +1,19 @@
 package statsig
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type Empty struct{}
@@ -85,3 +94,164 @@ func TestRetries(t *testing.T) {
 		t.Errorf("Expected successful request but got error")
 	}
 }
+
+func TestClockSkew(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	tr := newTransport("secret", &Options{API: testServer.URL})
+	_, _ = tr.post("/log_event", nil, nil, RequestOptions{})
+
+	skew := tr.getClockSkew()
+	if skew < time.Hour.Milliseconds()-2000 || skew > time.Hour.Milliseconds()+2000 {
+		t.Errorf("Expected clock skew of about one hour, received %dms", skew)
+	}
+}
+
+func TestUnixSocketTransport(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "egress.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("Unix sockets are not supported: %s", err.Error())
+	}
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(res).Encode(ServerResponse{Name: req.Host})
+	}))
+	testServer.Listener = listener
+	testServer.Start()
+	defer testServer.Close()
+
+	opt := &Options{
+		API:              "http://statsig.sidecar/v1",
+		TransportOptions: TransportOptions{UnixSocketPath: socketPath},
+	}
+	n := newTransport("secret", opt)
+	var out ServerResponse
+	_, err = n.post("/123", Empty{}, &out, RequestOptions{})
+	if err != nil {
+		t.Fatalf("Expected the request to be sent through the unix socket: %s", err.Error())
+	}
+	if out.Name != "statsig.sidecar" {
+		t.Errorf("Expected the original host to be preserved, received %s", out.Name)
+	}
+}
+
+func TestSOCKS5ProxyTransport(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(res).Encode(ServerResponse{Name: "via_proxy"})
+	}))
+	defer testServer.Close()
+
+	var connections int32
+	proxy, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer proxy.Close()
+	go func() {
+		for {
+			conn, err := proxy.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&connections, 1)
+			go serveSOCKS5(conn)
+		}
+	}()
+
+	opt := &Options{
+		API:              testServer.URL,
+		TransportOptions: TransportOptions{ProxyURL: "socks5://" + proxy.Addr().String()},
+	}
+	n := newTransport("secret", opt)
+	var out ServerResponse
+	_, err := n.post("/123", Empty{}, &out, RequestOptions{})
+	if err != nil {
+		t.Fatalf("Expected the request to be sent through the proxy: %s", err.Error())
+	}
+	if out.Name != "via_proxy" || atomic.LoadInt32(&connections) != 1 {
+		t.Errorf("Expected one proxied connection, received %d", connections)
+	}
+}
+
+// Minimal no-auth SOCKS5 CONNECT server for IPv4 and domain targets
+func serveSOCKS5(conn net.Conn) {
+	defer conn.Close()
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	_, _ = io.ReadFull(conn, methods)
+	_, _ = conn.Write([]byte{5, 0})
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		_, _ = io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		length := make([]byte, 1)
+		_, _ = io.ReadFull(conn, length)
+		domain := make([]byte, length[0])
+		_, _ = io.ReadFull(conn, domain)
+		host = string(domain)
+	default:
+		return
+	}
+	port := make([]byte, 2)
+	_, _ = io.ReadFull(conn, port)
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	go func() { _, _ = io.Copy(target, conn) }()
+	_, _ = io.Copy(conn, target)
+}
+
+func TestCompressEvents(t *testing.T) {
+	key := []byte("signing-key")
+	var encoding, signature string
+	var body []byte
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		encoding = req.Header.Get("Content-Encoding")
+		signature = req.Header.Get(eventSignatureHeader)
+		body, _ = io.ReadAll(req.Body)
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	input := map[string]interface{}{"events": []map[string]string{{"eventName": "a"}}}
+
+	tr := newTransport("secret", &Options{API: testServer.URL, CompressEvents: true, EventSigningOptions: EventSigningOptions{Key: key}})
+	if _, err := tr.post("/log_event", input, nil, RequestOptions{}); err != nil {
+		t.Fatalf("Expected the request to succeed, received %v", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if encoding != "gzip" || err != nil {
+		t.Fatalf("Expected a gzipped body, received encoding %q, %v", encoding, err)
+	}
+	decompressed, _ := io.ReadAll(reader)
+	expected, _ := json.Marshal(input)
+	if !bytes.Equal(decompressed, expected) || !VerifyEventRequestBody(key, decompressed, signature) {
+		t.Errorf("Expected the decompressed body to be signed, received %s", decompressed)
+	}
+
+	if _, err = tr.post("/get_id_lists", input, nil, RequestOptions{}); err != nil || encoding != "" {
+		t.Errorf("Expected only log_event requests to be compressed, received encoding %q, %v", encoding, err)
+	}
+	uncompressed := newTransport("secret", &Options{API: testServer.URL})
+	if _, err = uncompressed.post("/log_event", input, nil, RequestOptions{}); err != nil || encoding != "" || !bytes.Equal(body, expected) {
+		t.Errorf("Expected events to be sent uncompressed by default, received encoding %q, %v", encoding, err)
+	}
+}
@@ -26,6 +26,9 @@ type Event struct {
 	Value     string            `json:"value"`
 	Metadata  map[string]string `json:"metadata"`
 	Time      int64             `json:"time"`
+	// Milliseconds since the SDK was initialized, measured with the monotonic clock so
+	// it is unaffected by wall-clock adjustments. Set by the SDK when the event is logged.
+	TimeSinceInit int64 `json:"timeSinceInit,omitempty"`
 }
 
 type configBase struct {
@@ -42,18 +45,44 @@ type FeatureGate struct {
 	Value       bool   `json:"value"`
 	RuleID      string `json:"rule_id"`
 	GroupName   string `json:"group_name"`
+	Variant     string `json:"variant,omitempty"` // The variant assigned to the user by a gate with weighted variants. See GetVariant
 	LogExposure *func(configBase, string)
+
+	evaluationDetails *evaluationDetails
 }
 
 // A json blob configured in the Statsig Console
 type DynamicConfig struct {
 	configBase
+
+	experiment experimentAssignment
 }
 
 type Layer struct {
 	configBase
 }
 
+// Well-known rule IDs returned in place of a console-defined rule ID
+const (
+	RuleIDDefault         = "default"         // No rule passed, the default value was returned
+	RuleIDDisabled        = "disabled"        // The gate or config is disabled in the console
+	RuleIDOverride        = "override"        // Set by OverrideGate, OverrideConfig or OverrideLayer
+	RuleIDPrestart        = "prestart"        // The experiment has not been started
+	RuleIDLayerAssignment = "layerAssignment" // The user was not allocated to the experiment by its layer
+	RuleIDFallback        = "fallback"        // The gate was not in the ruleset and its Options.GateFallbacks function was used
+)
+
+// Reports whether the experiment was running when evaluated, i.e. has been started and not disabled.
+// Overridden values are reported as active.
+func IsExperimentActive(experiment DynamicConfig) bool {
+	switch experiment.RuleID {
+	case "", RuleIDDisabled, RuleIDPrestart:
+		return false
+	default:
+		return true
+	}
+}
+
 func NewGate(name string, value bool, ruleID string, groupName string) *FeatureGate {
 	return &FeatureGate{
 		Name:      name,
@@ -68,7 +97,7 @@ func NewConfig(name string, value map[string]interface{}, ruleID string, groupNa
 		value = make(map[string]interface{})
 	}
 	return &DynamicConfig{
-		configBase{
+		configBase: configBase{
 			Name:              name,
 			Value:             value,
 			RuleID:            ruleID,
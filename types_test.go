@@ -89,3 +89,21 @@ func TestBasic(t *testing.T) {
 		t.Errorf("Failed to get number array")
 	}
 }
+
+func TestIsExperimentActive(t *testing.T) {
+	tests := map[string]bool{
+		"7pxzqZ6bRMwqC4gnGVTcDy": true,
+		RuleIDDefault:            true,
+		RuleIDOverride:           true,
+		RuleIDLayerAssignment:    true,
+		RuleIDDisabled:           false,
+		RuleIDPrestart:           false,
+		"":                       false,
+	}
+	for ruleID, expected := range tests {
+		experiment := NewConfig("an_experiment", nil, ruleID, "", nil)
+		if IsExperimentActive(*experiment) != expected {
+			t.Errorf("Expected IsExperimentActive to be %t for rule ID %q", expected, ruleID)
+		}
+	}
+}
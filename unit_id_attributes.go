@@ -0,0 +1,49 @@
+package statsig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Fills custom IDs missing from the user with the attributes named by Options.UnitIDAttributes, so rules
+// targeting a console unit type, e.g. companyID, bucket every user of an organization together
+func fillUnitIDsFromAttributes(user User, options Options) User {
+	if len(options.UnitIDAttributes) == 0 {
+		return user
+	}
+	var customIDs map[string]string
+	for idType, field := range options.UnitIDAttributes {
+		if getUnitID(user, idType) != "" {
+			continue
+		}
+		unitID := unitIDFromAttribute(getFromUser(user, field, options.AttributePrecedence))
+		if unitID == "" {
+			continue
+		}
+		if customIDs == nil {
+			// Copy so the caller's map is not modified
+			customIDs = make(map[string]string, len(user.CustomIDs)+len(options.UnitIDAttributes))
+			for k, v := range user.CustomIDs {
+				customIDs[k] = v
+			}
+		}
+		customIDs[idType] = unitID
+	}
+	if customIDs != nil {
+		user.CustomIDs = customIDs
+	}
+	return user
+}
+
+func unitIDFromAttribute(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
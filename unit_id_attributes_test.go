@@ -0,0 +1,75 @@
+package statsig
+
+import (
+	"fmt"
+	"testing"
+)
+
+const unitIDAttributeSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [{
+		"name": "org_rollout", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false, "idType": "companyID",
+		"rules": [{
+			"name": "ramp", "id": "ramp_rule", "salt": "r", "passPercentage": 50, "returnValue": true, "idType": "companyID",
+			"conditions": [{"type": "public"}]
+		}]
+	}],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+func TestUnitIDAttributes(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      unitIDAttributeSpecs,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		UnitIDAttributes:     map[string]string{"companyID": "company"},
+	})
+	defer c.Shutdown()
+
+	t.Run("buckets every user of a company together", func(t *testing.T) {
+		passed := 0
+		companies := 200
+		for i := 0; i < companies; i++ {
+			company := fmt.Sprintf("company_%d", i)
+			value := c.CheckGate(User{UserID: "user_0", Custom: map[string]interface{}{"company": company}}, "org_rollout")
+			for j := 1; j < 10; j++ {
+				user := User{UserID: fmt.Sprintf("user_%d", j), Custom: map[string]interface{}{"company": company}}
+				if c.CheckGate(user, "org_rollout") != value {
+					t.Fatalf("Expected every user of %s to receive %v", company, value)
+				}
+			}
+			if value {
+				passed++
+			}
+		}
+		if passed < companies/4 || passed > companies*3/4 {
+			t.Errorf("Expected about half of the companies to pass, received %d of %d", passed, companies)
+		}
+	})
+
+	t.Run("matches the equivalent custom ID", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			fromAttribute := c.CheckGate(User{UserID: "a", Custom: map[string]interface{}{"company": float64(i)}}, "org_rollout")
+			fromCustomID := c.CheckGate(User{UserID: "b", CustomIDs: map[string]string{"companyID": fmt.Sprint(i)}}, "org_rollout")
+			if fromAttribute != fromCustomID {
+				t.Errorf("Expected company %d to match its custom ID", i)
+			}
+		}
+	})
+
+	t.Run("prefers the user's custom ID and does not modify it", func(t *testing.T) {
+		customIDs := map[string]string{"deviceID": "d"}
+		user := User{UserID: "a", Custom: map[string]interface{}{"company": "x"}, CustomIDs: customIDs}
+		normalized := normalizeUser(user, *c.options)
+		if normalized.CustomIDs["companyID"] != "x" || len(customIDs) != 1 {
+			t.Errorf("Expected the attribute on a copy of the custom IDs, received %+v and %+v", normalized.CustomIDs, customIDs)
+		}
+		user.CustomIDs = map[string]string{"companyid": "y"}
+		if normalized := normalizeUser(user, *c.options); len(normalized.CustomIDs) != 1 {
+			t.Errorf("Expected the existing custom ID to be kept, received %+v", normalized.CustomIDs)
+		}
+	})
+}
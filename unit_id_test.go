@@ -0,0 +1,130 @@
+package statsig
+
+import (
+	"testing"
+)
+
+const unitKeyedSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [],
+	"dynamic_configs": [{
+		"name": "device_experiment",
+		"type": "dynamic_config",
+		"salt": "device_salt",
+		"enabled": true,
+		"idType": "stableID",
+		"entity": "experiment",
+		"defaultValue": {"color": "grey"},
+		"rules": [{
+			"name": "all", "id": "rule_all", "groupName": "Test", "salt": "rule_salt", "passPercentage": 100,
+			"idType": "stableID", "returnValue": {"color": "blue"},
+			"conditions": [{"type": "public"}]
+		}]
+	}],
+	"layer_configs": [{
+		"name": "device_layer",
+		"type": "dynamic_config",
+		"salt": "layer_salt",
+		"enabled": true,
+		"idType": "stableID",
+		"entity": "layer",
+		"defaultValue": {"size": "small"},
+		"rules": [{
+			"name": "all", "id": "layer_rule", "groupName": "Test", "salt": "rule_salt", "passPercentage": 100,
+			"idType": "stableID", "returnValue": {"size": "large"},
+			"conditions": [{"type": "public"}]
+		}]
+	}]
+}`
+
+func TestUnitKeyedEvaluation(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      unitKeyedSpecs,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	t.Run("evaluates layers for a non-user unit", func(t *testing.T) {
+		c.logger.events = make([]interface{}, 0)
+		layer := c.GetLayerWithUnit("stableID", "device-1", "device_layer")
+		if layer.GetString("size", "") != "large" {
+			t.Errorf("Expected the unit to be allocated to the layer rule")
+		}
+		exposure, ok := c.logger.events[0].(ExposureEvent)
+		if !ok {
+			t.Fatalf("Expected a layer exposure")
+		}
+		if exposure.Metadata["unitIDType"] != "stableID" || exposure.Metadata["unitID"] != "device-1" {
+			t.Errorf("Expected exposure to record the unit, received %+v", exposure.Metadata)
+		}
+	})
+
+	t.Run("evaluates experiments for a non-user unit", func(t *testing.T) {
+		c.logger.events = make([]interface{}, 0)
+		experiment := c.GetExperimentWithUnit("stableID", "device-1", "device_experiment")
+		if experiment.GetString("color", "") != "blue" {
+			t.Errorf("Expected the unit to be allocated to the experiment rule")
+		}
+		exposure := c.logger.events[0].(ExposureEvent)
+		if exposure.Metadata["unitID"] != "device-1" {
+			t.Errorf("Expected exposure to record the unit, received %+v", exposure.Metadata)
+		}
+	})
+
+	t.Run("resolves unit IDs by exact or lower case customIDs keys only", func(t *testing.T) {
+		user := User{CustomIDs: map[string]string{"stableid": "device-2", "StableId": "device-3"}}
+		if getUnitID(user, "stableID") != "device-2" {
+			t.Errorf("Expected unit ID to be resolved by the lower case key")
+		}
+		if getUnitID(User{CustomIDs: map[string]string{"StableId": "device-3"}}, "stableID") != "" {
+			t.Errorf("Expected keys differing in other casings not to match, as in the other SDKs")
+		}
+	})
+
+	t.Run("does not add unit metadata for user keyed specs", func(t *testing.T) {
+		metadata := map[string]string{}
+		addUnitIDMetadata(metadata, User{UserID: "123"}, &logContext{unitIDType: "userID"})
+		if len(metadata) != 0 {
+			t.Errorf("Expected no unit metadata for userID keyed specs")
+		}
+	})
+}
+
+func TestMissingUnitID(t *testing.T) {
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	type missing struct {
+		specName string
+		idType   string
+	}
+	var reported []missing
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:            true,
+		BootstrapValues:      unitKeyedSpecs,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		OnMissingUnitID: func(specName string, idType string, user User) {
+			reported = append(reported, missing{specName, idType})
+		},
+	})
+	defer c.Shutdown()
+
+	experiment := c.GetExperiment(User{UserID: "a_user"}, "device_experiment")
+	if experiment.GetString("color", "") != "grey" || experiment.RuleID != RuleIDDefault {
+		t.Errorf("Expected the default value, received %+v", experiment)
+	}
+	exposure := c.logger.events[0].(ExposureEvent)
+	if exposure.Metadata["unitIDType"] != "stableID" || exposure.Metadata["unitID"] != "" {
+		t.Errorf("Expected the exposure to be logged with an empty unit, received %+v", exposure.Metadata)
+	}
+	if len(reported) != 1 || reported[0] != (missing{"device_experiment", "stableID"}) {
+		t.Errorf("Expected OnMissingUnitID to be called once, received %+v", reported)
+	}
+
+	c.GetLayer(User{UserID: "a_user"}, "device_layer")
+	c.GetExperiment(User{UserID: "a_user", CustomIDs: map[string]string{"stableID": "device-1"}}, "device_experiment")
+	if counts := c.GetSDKStats().MissingUnitIDCounts; counts["stableID"] != 2 {
+		t.Errorf("Expected 2 missing stableID evaluations, received %+v", counts)
+	}
+}
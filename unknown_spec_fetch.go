@@ -0,0 +1,90 @@
+package statsig
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultUnknownSpecFetchCooldown = 5 * time.Second
+
+// State of on-demand fetches for specs missing from the ruleset, see UnknownSpecFetchOptions
+type unknownSpecFetch struct {
+	inFlight  chan struct{} // Closed when the fetch in progress completes
+	lastFetch time.Time
+	mu        sync.Mutex
+}
+
+// Specs excluded from the tenant are not in the ruleset by design, so fetching would not find them
+func (e *evaluator) shouldFetchForUnknownSpec(name string, res *evalResult) bool {
+	if res.EvaluationDetails == nil || res.EvaluationDetails.reason != reasonUnrecognized {
+		return false
+	}
+	return e.tenant == nil || e.tenant.filter(name)
+}
+
+// Fetches the latest config specs, from the same source as polling, after a spec was not found in the
+// ruleset, e.g. one created since the last sync. Callers evaluating at the same time share a single fetch,
+// and no new fetch starts within the cooldown of the previous one. Stops waiting for the fetch when ctx is
+// done, though the fetch itself carries on for the other callers sharing it.
+// Returns true if the ruleset may have changed, so the lookup is worth retrying.
+func (s *store) fetchForUnknownSpec(ctx context.Context) bool {
+	options := s.options.UnknownSpecFetchOptions
+	if !options.Enabled || s.options.LocalMode || s.isShutdown() || ctx.Err() != nil {
+		return false
+	}
+	cooldown := options.Cooldown
+	if cooldown <= 0 {
+		cooldown = defaultUnknownSpecFetchCooldown
+	}
+	f := &s.unknownSpecFetch
+	f.mu.Lock()
+	inFlight := f.inFlight
+	if inFlight == nil {
+		if time.Since(f.lastFetch) < cooldown {
+			f.mu.Unlock()
+			return false
+		}
+		inFlight = make(chan struct{})
+		f.inFlight, f.lastFetch = inFlight, time.Now()
+		go s.runUnknownSpecFetch(detachedContext{ctx}, inFlight)
+	}
+	f.mu.Unlock()
+	select {
+	case <-inFlight:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *store) runUnknownSpecFetch(ctx context.Context, done chan struct{}) {
+	defer func() {
+		s.unknownSpecFetch.mu.Lock()
+		s.unknownSpecFetch.inFlight = nil
+		s.unknownSpecFetch.mu.Unlock()
+		close(done)
+	}()
+	Logger().LogStep(StatsigProcessSync, "Fetching config specs for a spec missing from the ruleset")
+	if s.shouldQueryDataAdapter(CONFIG_SPECS_KEY) {
+		s.fetchConfigSpecsFromAdapter()
+	} else {
+		s.syncConfigSpecsFromServer(ctx, false)
+	}
+}
+
+func (s *store) isShutdown() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.shutdown
+}
+
+// Keeps the values of a context, e.g. trace IDs, without its deadline or cancellation.
+// Used for work shared by several callers, which should not be cancelled by any one of them
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
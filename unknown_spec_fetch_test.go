@@ -0,0 +1,67 @@
+package statsig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUnknownSpecFetch(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	var dcsCalls int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			time.Sleep(20 * time.Millisecond)
+			// always_on_gate is only created after the initial sync
+			if atomic.AddInt32(&dcsCalls, 1) == 1 {
+				_, _ = res.Write([]byte(strings.Replace(string(specs), `"always_on_gate"`, `"other_gate"`, 1)))
+			} else {
+				_, _ = res.Write([]byte(strings.Replace(string(specs), `"time": 1631638014811`, `"time": 1631638014812`, 1)))
+			}
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                     testServer.URL,
+		ConfigSyncInterval:      time.Hour,
+		StatsigLoggerOptions:    getStatsigLoggerOptionsForTest(t),
+		UnknownSpecFetchOptions: UnknownSpecFetchOptions{Enabled: true, Cooldown: 100 * time.Millisecond},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	if !c.CheckGate(user, "always_on_gate") {
+		t.Errorf("Expected a gate created after the initial sync to be fetched on demand")
+	}
+	if calls := atomic.LoadInt32(&dcsCalls); calls != 2 {
+		t.Errorf("Expected one on-demand fetch, received %d requests", calls)
+	}
+
+	c.GetConfig(user, "missing_config")
+	if calls := atomic.LoadInt32(&dcsCalls); calls != 2 {
+		t.Errorf("Expected no fetch within the cooldown, received %d requests", calls)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.GetLayer(user, "missing_layer")
+		}()
+	}
+	wg.Wait()
+	if calls := atomic.LoadInt32(&dcsCalls); calls != 3 {
+		t.Errorf("Expected concurrent evaluations to share a single fetch, received %d requests", calls)
+	}
+}
@@ -0,0 +1,59 @@
+package statsig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+// Resolves User.UserAgent for ua_based conditions on the os_name, os_version, browser_name and
+// browser_version fields, used when the user has none of them set. Called concurrently from evaluations.
+type UserAgentParser interface {
+	ParseUserAgent(userAgent string) UserAgentInfo
+}
+
+// Versions are dot separated, e.g. "17.4.1". Fields the parser could not resolve are empty
+type UserAgentInfo struct {
+	OSName         string
+	OSVersion      string
+	BrowserName    string
+	BrowserVersion string
+}
+
+type embeddedUserAgentParser struct {
+	parser *uaparser.Parser
+}
+
+// The ua-parser regexes bundled with the SDK, used unless Options.UserAgentParser is set
+func NewEmbeddedUserAgentParser() UserAgentParser {
+	return &embeddedUserAgentParser{parser: uaparser.NewFromSaved()}
+}
+
+func (p *embeddedUserAgentParser) ParseUserAgent(userAgent string) UserAgentInfo {
+	client := p.parser.Parse(userAgent)
+	return UserAgentInfo{
+		OSName:         client.Os.Family,
+		OSVersion:      strings.Join(removeEmptyStrings([]string{client.Os.Major, client.Os.Minor, client.Os.Patch, client.Os.PatchMinor}), "."),
+		BrowserName:    client.UserAgent.Family,
+		BrowserVersion: strings.Join(removeEmptyStrings([]string{client.UserAgent.Major, client.UserAgent.Minor, client.UserAgent.Patch}), "."),
+	}
+}
+
+func newUserAgentParser(options *Options) UserAgentParser {
+	if options.UserAgentParser != nil {
+		return options.UserAgentParser
+	}
+	return NewEmbeddedUserAgentParser()
+}
+
+// A parser that panics resolves nothing, so the condition is evaluated as for an unknown user agent
+func parseUserAgent(parser UserAgentParser, userAgent string) (info UserAgentInfo) {
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(fmt.Sprintf("UserAgentParser panicked: %s", toError(err).Error()))
+			info = UserAgentInfo{}
+		}
+	}()
+	return parser.ParseUserAgent(userAgent)
+}
@@ -0,0 +1,68 @@
+package statsig
+
+import (
+	"testing"
+)
+
+const userAgentSpecs = `{
+	"has_updates": true,
+	"time": 1,
+	"feature_gates": [{
+		"name": "ios_17", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "ios", "id": "ios_rule", "salt": "s", "passPercentage": 100, "returnValue": true,
+			"conditions": [
+				{"type": "ua_based", "field": "os_name", "operator": "any", "targetValue": ["iOS"]},
+				{"type": "ua_based", "field": "os_version", "operator": "version_gte", "targetValue": "17.0"}
+			]
+		}]
+	}],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+type mapUserAgentParser map[string]UserAgentInfo
+
+func (m mapUserAgentParser) ParseUserAgent(userAgent string) UserAgentInfo {
+	if userAgent == "panic" {
+		panic("parse failed")
+	}
+	return m[userAgent]
+}
+
+func TestUserAgentParser(t *testing.T) {
+	newParserClient := func(parser UserAgentParser) *Client {
+		InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:            true,
+			BootstrapValues:      userAgentSpecs,
+			UserAgentParser:      parser,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+	}
+
+	c := newParserClient(mapUserAgentParser{
+		"new-iphone": {OSName: "iOS", OSVersion: "17.4.1"},
+		"old-iphone": {OSName: "iOS", OSVersion: "16.2"},
+	})
+	defer c.Shutdown()
+	if !c.CheckGate(User{UserID: "a", UserAgent: "new-iphone"}, "ios_17") {
+		t.Errorf("Expected the user agent to be resolved by the custom parser")
+	}
+	if c.CheckGate(User{UserID: "a", UserAgent: "old-iphone"}, "ios_17") {
+		t.Errorf("Expected older versions to fail")
+	}
+	if c.CheckGate(User{UserID: "a", UserAgent: "unknown"}, "ios_17") || c.CheckGate(User{UserID: "a", UserAgent: "panic"}, "ios_17") {
+		t.Errorf("Expected unresolved user agents and panicking parsers to fail the condition")
+	}
+
+	info := NewEmbeddedUserAgentParser().ParseUserAgent("Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Mobile/15E148 Safari/604.1")
+	if info.OSName != "iOS" || info.OSVersion != "17.4.1" || info.BrowserName != "Mobile Safari" || info.BrowserVersion != "17.4.1" {
+		t.Errorf("Unexpected embedded parser result %+v", info)
+	}
+	d := newParserClient(nil)
+	defer d.Shutdown()
+	if _, ok := d.evaluator.uaParser.(*embeddedUserAgentParser); !ok {
+		t.Errorf("Expected the embedded parser to be used by default")
+	}
+}
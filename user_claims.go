@@ -0,0 +1,157 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Maps the claims of a parsed JWT onto a User. Claim names are matched exactly against the
+// top-level claims, so namespaced claims such as "https://example.com/org" can be used as is.
+type ClaimMapping struct {
+	UserID               string            // Claim holding the user ID, "sub" if unset
+	Email                string            // Claim holding the email, "email" if unset. Set to "-" to ignore emails
+	RequireEmailVerified bool              // Only map the email when the "email_verified" claim is true
+	Country              string            // Claim holding the user's country
+	Locale               string            // Claim holding the user's locale
+	Custom               map[string]string // Claim name to User.Custom key
+	PrivateAttributes    map[string]string // Claim name to User.PrivateAttributes key
+	CustomIDs            map[string]string // Claim name to User.CustomIDs ID type
+	Strict               bool              // Fail if any mapped claim is missing, not only the user ID
+}
+
+const (
+	defaultUserIDClaim = "sub"
+	defaultEmailClaim  = "email"
+	ignoreClaim        = "-"
+)
+
+// Builds a User from JWT claims according to the mapping.
+// An error is returned if the user ID claim is missing or empty, if a mapped claim has a type that
+// cannot be used for its field, or if the email is malformed.
+func UserFromClaims(claims map[string]interface{}, mapping ClaimMapping) (User, error) {
+	user := User{}
+	userIDClaim := mapping.UserID
+	if userIDClaim == "" {
+		userIDClaim = defaultUserIDClaim
+	}
+	userID, err := stringClaim(claims, userIDClaim, true)
+	if err != nil {
+		return User{}, err
+	}
+	user.UserID = userID
+
+	emailClaim := mapping.Email
+	if emailClaim == "" {
+		emailClaim = defaultEmailClaim
+	}
+	if emailClaim != ignoreClaim {
+		email, err := stringClaim(claims, emailClaim, mapping.Strict && mapping.Email != "")
+		if err != nil {
+			return User{}, err
+		}
+		if email != "" && !strings.Contains(email, "@") {
+			return User{}, fmt.Errorf("Claim %q is not a valid email address", emailClaim)
+		}
+		if email != "" && mapping.RequireEmailVerified {
+			verified, _ := claims["email_verified"].(bool)
+			if !verified {
+				email = ""
+			}
+		}
+		user.Email = email
+	}
+
+	if mapping.Country != "" {
+		if user.Country, err = stringClaim(claims, mapping.Country, mapping.Strict); err != nil {
+			return User{}, err
+		}
+	}
+	if mapping.Locale != "" {
+		if user.Locale, err = stringClaim(claims, mapping.Locale, mapping.Strict); err != nil {
+			return User{}, err
+		}
+	}
+
+	for claim, idType := range mapping.CustomIDs {
+		id, err := stringClaim(claims, claim, mapping.Strict)
+		if err != nil {
+			return User{}, err
+		}
+		if id == "" {
+			continue
+		}
+		if user.CustomIDs == nil {
+			user.CustomIDs = make(map[string]string)
+		}
+		user.CustomIDs[idType] = id
+	}
+
+	if user.Custom, err = attributeClaims(claims, mapping.Custom, mapping.Strict); err != nil {
+		return User{}, err
+	}
+	if user.PrivateAttributes, err = attributeClaims(claims, mapping.PrivateAttributes, mapping.Strict); err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// Reads a claim that must be a string; integral numbers such as numeric subject IDs are
+// converted. A missing claim is an error only when required.
+func stringClaim(claims map[string]interface{}, claim string, required bool) (string, error) {
+	raw, ok := claims[claim]
+	if !ok || raw == nil {
+		if required {
+			return "", fmt.Errorf("Missing required claim %q", claim)
+		}
+		return "", nil
+	}
+	var value string
+	switch v := raw.(type) {
+	case string:
+		value = v
+	case json.Number:
+		value = v.String()
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("Claim %q must be a string or an integer, received %v", claim, v)
+		}
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		value = strconv.Itoa(v)
+	case int64:
+		value = strconv.FormatInt(v, 10)
+	default:
+		return "", fmt.Errorf("Claim %q must be a string, received %T", claim, raw)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" && required {
+		return "", fmt.Errorf("Claim %q is empty", claim)
+	}
+	return value, nil
+}
+
+// Copies claims into an attribute map. Values are kept as decoded, except that nested objects are
+// rejected since conditions cannot target them.
+func attributeClaims(claims map[string]interface{}, mapping map[string]string, strict bool) (map[string]interface{}, error) {
+	var attributes map[string]interface{}
+	for claim, key := range mapping {
+		raw, ok := claims[claim]
+		if !ok || raw == nil {
+			if strict {
+				return nil, fmt.Errorf("Missing required claim %q", claim)
+			}
+			continue
+		}
+		if _, isObject := raw.(map[string]interface{}); isObject {
+			return nil, fmt.Errorf("Claim %q is an object and cannot be used as attribute %q", claim, key)
+		}
+		if attributes == nil {
+			attributes = make(map[string]interface{})
+		}
+		attributes[key] = raw
+	}
+	return attributes, nil
+}
@@ -0,0 +1,97 @@
+package statsig
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUserFromClaims(t *testing.T) {
+	var claims map[string]interface{}
+	_ = json.Unmarshal([]byte(`{
+		"sub": "user-123",
+		"email": "jane@statsig.com",
+		"email_verified": true,
+		"locale": "en_US",
+		"https://example.com/org": "org-9",
+		"roles": ["admin", "editor"],
+		"plan": "pro",
+		"tenant": {"id": "t1"}
+	}`), &claims)
+
+	t.Run("maps standard and custom claims", func(t *testing.T) {
+		user, err := UserFromClaims(claims, ClaimMapping{
+			Locale:            "locale",
+			Custom:            map[string]string{"roles": "roles"},
+			PrivateAttributes: map[string]string{"plan": "plan"},
+			CustomIDs:         map[string]string{"https://example.com/org": "orgID"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if user.UserID != "user-123" || user.Email != "jane@statsig.com" || user.Locale != "en_US" {
+			t.Errorf("Expected standard claims to be mapped, received %+v", user)
+		}
+		if user.CustomIDs["orgID"] != "org-9" {
+			t.Errorf("Expected orgID custom ID, received %+v", user.CustomIDs)
+		}
+		if roles, ok := user.Custom["roles"].([]interface{}); !ok || len(roles) != 2 {
+			t.Errorf("Expected roles custom attribute, received %+v", user.Custom)
+		}
+		if user.PrivateAttributes["plan"] != "pro" {
+			t.Errorf("Expected plan private attribute, received %+v", user.PrivateAttributes)
+		}
+	})
+
+	t.Run("converts integral numeric user IDs", func(t *testing.T) {
+		user, err := UserFromClaims(map[string]interface{}{"uid": float64(42)}, ClaimMapping{UserID: "uid"})
+		if err != nil || user.UserID != "42" {
+			t.Errorf("Expected userID 42, received %q (%v)", user.UserID, err)
+		}
+		if _, err := UserFromClaims(map[string]interface{}{"uid": 4.2}, ClaimMapping{UserID: "uid"}); err == nil {
+			t.Errorf("Expected an error for a fractional user ID")
+		}
+	})
+
+	t.Run("rejects missing or empty user IDs", func(t *testing.T) {
+		if _, err := UserFromClaims(map[string]interface{}{"email": "a@b.com"}, ClaimMapping{}); err == nil {
+			t.Errorf("Expected an error for a missing sub claim")
+		}
+		if _, err := UserFromClaims(map[string]interface{}{"sub": " "}, ClaimMapping{}); err == nil {
+			t.Errorf("Expected an error for an empty sub claim")
+		}
+	})
+
+	t.Run("validates claim types and emails", func(t *testing.T) {
+		if _, err := UserFromClaims(map[string]interface{}{"sub": "u", "email": "not-an-email"}, ClaimMapping{}); err == nil {
+			t.Errorf("Expected an error for a malformed email")
+		}
+		if _, err := UserFromClaims(map[string]interface{}{"sub": true}, ClaimMapping{}); err == nil {
+			t.Errorf("Expected an error for a boolean user ID")
+		}
+		if _, err := UserFromClaims(claims, ClaimMapping{Custom: map[string]string{"tenant": "tenant"}}); err == nil {
+			t.Errorf("Expected an error for an object attribute")
+		}
+	})
+
+	t.Run("drops unverified emails when required", func(t *testing.T) {
+		user, _ := UserFromClaims(map[string]interface{}{"sub": "u", "email": "a@b.com"}, ClaimMapping{RequireEmailVerified: true})
+		if user.Email != "" {
+			t.Errorf("Expected unverified email to be dropped, received %q", user.Email)
+		}
+		user, _ = UserFromClaims(map[string]interface{}{"sub": "u", "email": "a@b.com"}, ClaimMapping{Email: ignoreClaim})
+		if user.Email != "" {
+			t.Errorf("Expected email to be ignored, received %q", user.Email)
+		}
+	})
+
+	t.Run("requires every mapped claim in strict mode", func(t *testing.T) {
+		mapping := ClaimMapping{Country: "country", Strict: true}
+		if _, err := UserFromClaims(claims, mapping); err == nil {
+			t.Errorf("Expected an error for a missing country claim")
+		}
+		mapping.Strict = false
+		if _, err := UserFromClaims(claims, mapping); err != nil {
+			t.Errorf("Expected missing optional claims to be skipped, received %s", err.Error())
+		}
+	})
+}
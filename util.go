@@ -1,6 +1,7 @@
 package statsig
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
@@ -18,6 +19,13 @@ func defaultString(v, d string) string {
 	return v
 }
 
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
 func getHash(key string) []byte {
 	hasher := sha256.New()
 	bytes := []byte(key)
@@ -0,0 +1,188 @@
+// Package statsig is the v2 API of the Statsig server SDK. Methods take a context first and return
+// errors instead of logging them, Client is an interface so it can be replaced in tests, and options
+// are passed as functional options.
+//
+// The v2 API is a package of the v1 module, so both are released together. The v2 client wraps a v1
+// client. Use FromV1 to adopt v2 with an existing v1 client, and Client.V1 for v1 methods without a
+// v2 equivalent, so call sites can be migrated incrementally.
+package statsig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/statsig-io/go-sdk"
+)
+
+type (
+	User          = v1.User
+	Event         = v1.Event
+	FeatureGate   = v1.FeatureGate
+	DynamicConfig = v1.DynamicConfig
+	Layer         = v1.Layer
+	IDataAdapter  = v1.IDataAdapter
+)
+
+var (
+	ErrEmptyUser      = errors.New(v1.EmptyUserError)
+	ErrEmptyEventName = errors.New("Event names must not be empty")
+)
+
+type Client interface {
+	CheckGate(ctx context.Context, user User, gate string) (bool, error)
+	GetGate(ctx context.Context, user User, gate string) (FeatureGate, error)
+	GetConfig(ctx context.Context, user User, config string) (DynamicConfig, error)
+	GetExperiment(ctx context.Context, user User, experiment string) (DynamicConfig, error)
+	GetLayer(ctx context.Context, user User, layer string) (Layer, error)
+	LogEvent(ctx context.Context, event Event) error
+	// Flushes events and stops background work, returning ctx's error if it is done first
+	Shutdown(ctx context.Context) error
+	// The wrapped v1 client, for methods without a v2 equivalent
+	V1() *v1.Client
+}
+
+type Option func(options *v1.Options)
+
+func WithAPI(api string) Option {
+	return func(options *v1.Options) { options.API = api }
+}
+
+func WithEnvironment(tier string) Option {
+	return func(options *v1.Options) { options.Environment.Tier = tier }
+}
+
+// Evaluates without network requests, e.g. in tests, using only the bootstrap values and overrides
+func WithLocalMode() Option {
+	return func(options *v1.Options) { options.LocalMode = true }
+}
+
+func WithBootstrapValues(values string) Option {
+	return func(options *v1.Options) { options.BootstrapValues = values }
+}
+
+func WithInitTimeout(timeout time.Duration) Option {
+	return func(options *v1.Options) { options.InitTimeout = timeout }
+}
+
+func WithDataAdapter(adapter IDataAdapter) Option {
+	return func(options *v1.Options) { options.DataAdapter = adapter }
+}
+
+// Sets any v1 option, for those without a v2 equivalent
+func WithV1Options(configure func(options *v1.Options)) Option {
+	return Option(configure)
+}
+
+type client struct {
+	v1 *v1.Client
+}
+
+// Initializes a client with the given SDK key, returning an error where v1 would panic, e.g. for an invalid SDK key
+func New(sdkKey string, options ...Option) (result Client, err error) {
+	v1Options := &v1.Options{}
+	for _, option := range options {
+		option(v1Options)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = nil
+			if recoveredErr, ok := recovered.(error); ok {
+				err = recoveredErr
+			} else {
+				err = fmt.Errorf("%v", recovered)
+			}
+		}
+	}()
+	v1Client := v1.NewClientWithOptions(sdkKey, v1Options)
+	if details := v1Client.GetInitializeDetails(); v1Options.StrictBootstrap && details.Error != nil {
+		v1Client.Shutdown()
+		return nil, details.Error
+	}
+	return FromV1(v1Client), nil
+}
+
+// Wraps an initialized v1 client. Shutting down either shuts down both
+func FromV1(v1Client *v1.Client) Client {
+	return &client{v1: v1Client}
+}
+
+func (c *client) V1() *v1.Client {
+	return c.v1
+}
+
+func validate(ctx context.Context, user User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if user.UserID == "" && len(user.CustomIDs) == 0 {
+		return ErrEmptyUser
+	}
+	return nil
+}
+
+func (c *client) CheckGate(ctx context.Context, user User, gate string) (bool, error) {
+	result, err := c.GetGate(ctx, user, gate)
+	return result.Value, err
+}
+
+// Returns ctx's error along with the default value when ctx is done before a network request made
+// during the evaluation completes
+func (c *client) GetGate(ctx context.Context, user User, gate string) (FeatureGate, error) {
+	if err := validate(ctx, user); err != nil {
+		return *v1.NewGate(gate, false, "", ""), err
+	}
+	result := c.v1.GetGateCtx(ctx, user, gate)
+	return result, ctx.Err()
+}
+
+func (c *client) GetConfig(ctx context.Context, user User, config string) (DynamicConfig, error) {
+	if err := validate(ctx, user); err != nil {
+		return *v1.NewConfig(config, nil, "", "", nil), err
+	}
+	result := c.v1.GetConfigCtx(ctx, user, config)
+	return result, ctx.Err()
+}
+
+func (c *client) GetExperiment(ctx context.Context, user User, experiment string) (DynamicConfig, error) {
+	if err := validate(ctx, user); err != nil {
+		return *v1.NewConfig(experiment, nil, "", "", nil), err
+	}
+	result := c.v1.GetExperimentCtx(ctx, user, experiment)
+	return result, ctx.Err()
+}
+
+func (c *client) GetLayer(ctx context.Context, user User, layer string) (Layer, error) {
+	if err := validate(ctx, user); err != nil {
+		return *v1.NewLayer(layer, nil, "", "", nil), err
+	}
+	result := c.v1.GetLayerCtx(ctx, user, layer)
+	return result, ctx.Err()
+}
+
+func (c *client) LogEvent(ctx context.Context, event Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if event.EventName == "" {
+		return ErrEmptyEventName
+	}
+	c.v1.LogEvent(event)
+	return nil
+}
+
+// Shutdown continues in the background after ctx is done
+func (c *client) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.v1.Shutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
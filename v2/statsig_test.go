@@ -0,0 +1,87 @@
+package statsig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	v1 "github.com/statsig-io/go-sdk"
+)
+
+func TestClient(t *testing.T) {
+	specs, _ := os.ReadFile("../download_config_specs.json")
+	user := User{UserID: "123", Email: "a@statsig.com"}
+	newTestClient := func(t *testing.T) Client {
+		c, err := New("secret-key", WithLocalMode(), WithBootstrapValues(string(specs)), WithV1Options(func(options *v1.Options) {
+			options.StatsigLoggerOptions.DisableAllLogging = true
+		}))
+		if err != nil {
+			t.Fatalf("Expected the client to initialize, received %s", err.Error())
+		}
+		return c
+	}
+
+	t.Run("evaluates with the v1 client", func(t *testing.T) {
+		c := newTestClient(t)
+		defer c.Shutdown(context.Background())
+		ctx := context.Background()
+		if pass, err := c.CheckGate(ctx, user, "always_on_gate"); !pass || err != nil {
+			t.Errorf("Expected the gate to pass, received %v %v", pass, err)
+		}
+		if config, err := c.GetConfig(ctx, user, "test_config"); config.GetNumber("number", 0) != 7 || err != nil {
+			t.Errorf("Expected the config value, received %+v %v", config.Value, err)
+		}
+		if experiment, err := c.GetExperiment(ctx, user, "sample_experiment"); experiment.Name != "sample_experiment" || err != nil {
+			t.Errorf("Expected the experiment, received %+v %v", experiment, err)
+		}
+		if layer, err := c.GetLayer(ctx, user, "a_layer"); layer.Name != "a_layer" || err != nil {
+			t.Errorf("Expected the layer, received %+v %v", layer, err)
+		}
+		if err := c.LogEvent(ctx, Event{EventName: "purchase", User: user}); err != nil {
+			t.Errorf("Expected the event to be logged, received %s", err.Error())
+		}
+		if c.V1().CheckGate(user, "always_on_gate") != true {
+			t.Errorf("Expected the v1 client to be usable directly")
+		}
+	})
+
+	t.Run("returns errors instead of logging them", func(t *testing.T) {
+		c := newTestClient(t)
+		defer c.Shutdown(context.Background())
+		if pass, err := c.CheckGate(context.Background(), User{}, "always_on_gate"); pass || err != ErrEmptyUser {
+			t.Errorf("Expected ErrEmptyUser, received %v %v", pass, err)
+		}
+		if err := c.LogEvent(context.Background(), Event{User: user}); err != ErrEmptyEventName {
+			t.Errorf("Expected ErrEmptyEventName, received %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if config, err := c.GetConfig(ctx, user, "test_config"); !errors.Is(err, context.Canceled) || len(config.Value) != 0 {
+			t.Errorf("Expected the default value and context.Canceled, received %+v %v", config.Value, err)
+		}
+		if err := c.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected Shutdown to return once ctx is done, received %v", err)
+		}
+	})
+
+	t.Run("returns initialization panics as errors", func(t *testing.T) {
+		if c, err := New("client-key"); c != nil || err == nil || err.Error() != v1.InvalidSDKKeyError {
+			t.Errorf("Expected an invalid SDK key error, received %v", err)
+		}
+	})
+
+	t.Run("wraps existing v1 clients", func(t *testing.T) {
+		v1Client := v1.NewClientWithOptions("secret-key", &v1.Options{LocalMode: true, BootstrapValues: string(specs)})
+		c := FromV1(v1Client)
+		if c.V1() != v1Client {
+			t.Errorf("Expected the v1 client to be wrapped")
+		}
+		if pass, _ := c.CheckGate(context.Background(), user, "always_on_gate"); !pass {
+			t.Errorf("Expected the gate to pass")
+		}
+		if err := c.Shutdown(context.Background()); err != nil {
+			t.Errorf("Expected Shutdown to succeed, received %s", err.Error())
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package statsig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Options for Client.WarmUp
+type WarmUpOptions struct {
+	SpecNames      []string      // Gates, configs, experiments and layers that must be present in the ruleset
+	WaitForIDLists bool          // Also wait for the ID lists referenced by the specs, and the specs they depend on, to be downloaded
+	RetryInterval  time.Duration // Time between re-syncs while waiting, 1 second if unset
+}
+
+const defaultWarmUpRetryInterval = time.Second
+
+// Reports what WarmUp is still waiting for
+type warmUpStatus struct {
+	missingSpecs   []string
+	missingIDLists []string
+}
+
+func (w warmUpStatus) ready() bool {
+	return len(w.missingSpecs) == 0 && len(w.missingIDLists) == 0
+}
+
+func (w warmUpStatus) String() string {
+	var parts []string
+	if len(w.missingSpecs) > 0 {
+		parts = append(parts, fmt.Sprintf("specs [%s]", strings.Join(w.missingSpecs, ", ")))
+	}
+	if len(w.missingIDLists) > 0 {
+		parts = append(parts, fmt.Sprintf("ID lists [%s]", strings.Join(w.missingIDLists, ", ")))
+	}
+	return strings.Join(parts, " and ")
+}
+
+func (s *store) getSpecByName(name string) (configSpec, bool) {
+	if spec, ok := s.getGate(name); ok {
+		return spec, true
+	}
+	if spec, ok := s.getDynamicConfig(name); ok {
+		return spec, true
+	}
+	return s.getLayerConfig(name)
+}
+
+// Checks the named specs, following pass_gate/fail_gate conditions and config delegates so that
+// a spec is only ready once everything its evaluation depends on is loaded
+func (s *store) getWarmUpStatus(options WarmUpOptions) warmUpStatus {
+	status := warmUpStatus{}
+	visited := make(map[string]bool)
+	idLists := make(map[string]bool)
+	pending := append([]string{}, options.SpecNames...)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		spec, ok := s.getSpecByName(name)
+		if !ok {
+			status.missingSpecs = append(status.missingSpecs, name)
+			continue
+		}
+		for _, rule := range spec.Rules {
+			if rule.ConfigDelegate != "" {
+				pending = append(pending, rule.ConfigDelegate)
+			}
+			for _, cond := range rule.Conditions {
+				switch strings.ToLower(cond.Type) {
+				case "pass_gate", "fail_gate":
+					pending = append(pending, toString(cond.TargetValue))
+				}
+				switch strings.ToLower(cond.Operator) {
+				case "in_segment_list", "not_in_segment_list":
+					idLists[toString(cond.TargetValue)] = true
+				}
+			}
+		}
+	}
+	if options.WaitForIDLists {
+		for name := range idLists {
+			if list := s.getIDListForCondition(name); list == nil || !list.isSynced() {
+				status.missingIDLists = append(status.missingIDLists, name)
+			}
+		}
+	}
+	sort.Strings(status.missingSpecs)
+	sort.Strings(status.missingIDLists)
+	return status
+}
+
+// Re-syncs config specs and ID lists outside of the polling schedule
+func (s *store) syncForWarmUp(status warmUpStatus) {
+	if len(status.missingSpecs) > 0 {
+		if s.shouldQueryDataAdapter(CONFIG_SPECS_KEY) {
+			s.fetchConfigSpecsFromAdapter()
+		} else if !s.options.DisableNetworkConfigSync || s.dataAdapter == nil {
+			s.fetchConfigSpecsFromServer(false)
+		}
+	}
+	if len(status.missingIDLists) > 0 {
+		if s.shouldQueryDataAdapter(ID_LISTS_KEY) {
+			s.fetchIDListsFromAdapter()
+		} else {
+			s.fetchIDListsFromServer()
+		}
+	}
+}
+
+func (s *store) warmUp(ctx context.Context, options WarmUpOptions) error {
+	retryInterval := options.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = defaultWarmUpRetryInterval
+	}
+	for {
+		status := s.getWarmUpStatus(options)
+		if status.ready() {
+			return nil
+		}
+		Logger().LogStep(StatsigProcessInitialize, fmt.Sprintf("Warming up, waiting for %s", status))
+		timer := time.NewTimer(retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("Warm up did not complete, still waiting for %s: %w", status, ctx.Err())
+		case <-timer.C:
+		}
+		s.syncForWarmUp(status)
+	}
+}
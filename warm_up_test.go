@@ -0,0 +1,133 @@
+package statsig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const warmUpSpecs = `{
+	"has_updates": true,
+	"time": 2,
+	"feature_gates": [{
+		"name": "kill_switch", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "employees", "id": "r1", "salt": "s", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "pass_gate", "targetValue": "employees_gate"}]
+		}]
+	}, {
+		"name": "employees_gate", "type": "feature_gate", "salt": "s", "enabled": true, "defaultValue": false,
+		"rules": [{
+			"name": "list", "id": "r2", "salt": "s", "passPercentage": 100, "returnValue": true,
+			"conditions": [{"type": "unit_id", "operator": "in_segment_list", "targetValue": "employees", "idType": "userID"}]
+		}]
+	}],
+	"dynamic_configs": [],
+	"layer_configs": []
+}`
+
+func TestWarmUp(t *testing.T) {
+	var specRequests, idListRequests int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			// The gates only appear from the third sync onwards
+			if atomic.AddInt32(&specRequests, 1) < 3 {
+				_, _ = res.Write([]byte(`{"has_updates": true, "time": 1, "feature_gates": [], "dynamic_configs": [], "layer_configs": []}`))
+				return
+			}
+			_, _ = res.Write([]byte(warmUpSpecs))
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			if atomic.AddInt32(&idListRequests, 1) < 3 {
+				_, _ = res.Write([]byte("{}"))
+				return
+			}
+			v, _ := json.Marshal(map[string]idList{
+				"employees": {Name: "employees", Size: 10, URL: "http://" + req.Host + "/list/employees", CreationTime: 1, FileID: "file_1"},
+			})
+			_, _ = res.Write(v)
+		} else if strings.HasSuffix(req.URL.Path, "/list/employees") {
+			h := sha256.Sum256([]byte("a"))
+			_, _ = res.Write([]byte("+" + base64.StdEncoding.EncodeToString(h[:])[:8] + "\n"))
+		}
+	}))
+	defer testServer.Close()
+
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		IDListSyncInterval:   time.Hour,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	t.Run("times out naming what is missing", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := c.WarmUp(ctx, WarmUpOptions{SpecNames: []string{"not_a_gate"}, RetryInterval: 5 * time.Millisecond})
+		if err == nil || !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "specs [not_a_gate]") {
+			t.Errorf("Expected a deadline error naming the missing spec, received %v", err)
+		}
+	})
+
+	t.Run("waits for specs, their dependencies and ID lists", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := c.WarmUp(ctx, WarmUpOptions{SpecNames: []string{"kill_switch"}, WaitForIDLists: true, RetryInterval: time.Millisecond})
+		if err != nil {
+			t.Fatalf("Expected warm up to complete, received %s", err.Error())
+		}
+		if !c.CheckGate(User{UserID: "a"}, "kill_switch") {
+			t.Errorf("Expected kill_switch to pass once the employees list is loaded")
+		}
+	})
+
+	t.Run("is immediately ready without ID lists", func(t *testing.T) {
+		status := c.evaluator.store.getWarmUpStatus(WarmUpOptions{SpecNames: []string{"kill_switch", "employees_gate"}})
+		if !status.ready() {
+			t.Errorf("Expected specs to be ready, missing %s", status)
+		}
+	})
+}
+
+func TestWarmUpEmptyIDList(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write([]byte(warmUpSpecs))
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			v, _ := json.Marshal(map[string]idList{
+				"employees": {Name: "employees", Size: 0, URL: "http://" + req.Host + "/list/employees", CreationTime: 1, FileID: "file_1"},
+			})
+			_, _ = res.Write(v)
+		}
+	}))
+	defer testServer.Close()
+
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		IDListSyncInterval:   time.Hour,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	// The manifest reports nothing to download, so the empty list is as loaded as it will get
+	status := c.evaluator.store.getWarmUpStatus(WarmUpOptions{SpecNames: []string{"kill_switch"}, WaitForIDLists: true})
+	if !status.ready() {
+		t.Errorf("Expected an empty synced ID list to be ready, missing %s", status)
+	}
+	if c.CheckGate(User{UserID: "a"}, "kill_switch") {
+		t.Errorf("Expected kill_switch to fail with an empty employees list")
+	}
+}